@@ -0,0 +1,88 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package core
+
+import (
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/contrib/ginrus"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/ottenwbe/recipes-manager/utils"
+)
+
+const (
+	accessLogSampleRateCfg = "html.accessLog.sampleRate"
+)
+
+var (
+	accessLogSampleRate float64
+)
+
+func init() {
+	utils.Config.SetDefault(accessLogSampleRateCfg, 1.0)
+	accessLogSampleRate = utils.Config.GetFloat64(accessLogSampleRateCfg)
+}
+
+// discardLogger swallows the access logs of requests which are not sampled
+var discardLogger = &log.Logger{
+	Out:       ioutil.Discard,
+	Formatter: &log.TextFormatter{},
+	Hooks:     make(log.LevelHooks),
+	Level:     log.PanicLevel,
+}
+
+// sampledLogger passes the access logs of a fraction of successful requests to its logger;
+// the access logs of failed requests (4xx/5xx) are always passed on
+type sampledLogger struct {
+	logger *log.Logger
+	rate   float64
+	sample func() float64
+}
+
+// WithFields is called by the ginrus middleware with the fields of a finished request
+func (s *sampledLogger) WithFields(fields log.Fields) *log.Entry {
+	if status, ok := fields["status"].(int); ok && status >= http.StatusBadRequest {
+		return s.logger.WithFields(fields)
+	}
+	if s.sample() < s.rate {
+		return s.logger.WithFields(fields)
+	}
+	return discardLogger.WithFields(fields)
+}
+
+// accessLogMiddleware logs requests with ginrus; only the fraction rate (0.0–1.0) of successful requests is logged
+func accessLogMiddleware(logger *log.Logger, rate float64) gin.HandlerFunc {
+	rate = math.Max(0, math.Min(1, rate))
+	if rate == 1 {
+		return ginrus.Ginrus(logger, time.RFC3339, true)
+	}
+	return ginrus.Ginrus(&sampledLogger{logger: logger, rate: rate, sample: rand.Float64}, time.RFC3339, true)
+}
@@ -0,0 +1,95 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package core
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	log "github.com/sirupsen/logrus"
+)
+
+var _ = Describe("access log", func() {
+
+	var (
+		logger *log.Logger
+		out    *bytes.Buffer
+	)
+
+	BeforeEach(func() {
+		out = &bytes.Buffer{}
+		logger = log.New()
+		logger.SetOutput(out)
+	})
+
+	serve := func(handler gin.HandlerFunc, status int, n int) {
+		router := gin.New()
+		router.Use(handler)
+		router.GET("/test", func(c *gin.Context) {
+			c.Status(status)
+		})
+		for i := 0; i < n; i++ {
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/test", nil))
+		}
+	}
+
+	lines := func() int {
+		return strings.Count(out.String(), "\n")
+	}
+
+	It("logs all requests by default", func() {
+		serve(accessLogMiddleware(logger, 1), http.StatusOK, 3)
+		Expect(lines()).To(Equal(3))
+	})
+
+	It("logs no successful requests with a sample rate of 0", func() {
+		serve(accessLogMiddleware(logger, 0), http.StatusOK, 3)
+		Expect(lines()).To(Equal(0))
+	})
+
+	It("always logs failed requests", func() {
+		serve(accessLogMiddleware(logger, 0), http.StatusNotFound, 2)
+		serve(accessLogMiddleware(logger, 0), http.StatusInternalServerError, 2)
+		Expect(lines()).To(Equal(4))
+	})
+
+	It("logs the sampled fraction of successful requests", func() {
+		samples := []float64{0.1, 0.9, 0.2, 0.8}
+		sampled := &sampledLogger{logger: logger, rate: 0.5, sample: func() float64 {
+			s := samples[0]
+			samples = samples[1:]
+			return s
+		}}
+		for i := 0; i < 4; i++ {
+			sampled.WithFields(log.Fields{"status": http.StatusOK}).Info()
+		}
+		Expect(lines()).To(Equal(2))
+	})
+})
@@ -24,10 +24,27 @@
 
 package core
 
+import "net/http"
+
+const (
+	statusOK          = "ok"
+	statusUnavailable = "unavailable"
+)
+
+// Status of the service
+type Status struct {
+	// Status is either ok or unavailable
+	Status string `json:"status"`
+	// Failed lists the names of all failed checks
+	Failed []string `json:"failed,omitempty"`
+}
+
 // AddCoreAPIToHandler constructs an API for recipes
 func AddCoreAPIToHandler(handler Handler) {
 	v1 := handler.API(1)
 	v1.GET("/version", prepareVersionRoutes)
+	v1.GET("/health", prepareHealthRoutes)
+	v1.GET("/ready", prepareReadyRoutes(handler))
 }
 
 // Version example
@@ -37,5 +54,33 @@ func AddCoreAPIToHandler(handler Handler) {
 // @Success 200 {object} Version
 // @Router /version [get]
 func prepareVersionRoutes(c *APICallContext) {
-	c.JSON(200, AppVersion())
+	RespondJSON(c, 200, AppVersion())
+}
+
+// Health example
+// @Summary Check if the service is alive
+// @Description The service is alive whenever it can answer requests
+// @Produce  json
+// @Success 200 {object} Status
+// @Router /health [get]
+func prepareHealthRoutes(c *APICallContext) {
+	RespondJSON(c, http.StatusOK, Status{Status: statusOK})
+}
+
+// Ready example
+// @Summary Check if the service is ready
+// @Description The service is ready when all backends, e.g., the database, can be reached
+// @Produce  json
+// @Success 200 {object} Status
+// @Failure 503 {object} Status
+// @Router /ready [get]
+func prepareReadyRoutes(handler Handler) func(c *APICallContext) {
+	return func(c *APICallContext) {
+		failed := handler.FailedReadinessChecks()
+		if len(failed) > 0 {
+			RespondJSON(c, http.StatusServiceUnavailable, Status{Status: statusUnavailable, Failed: failed})
+		} else {
+			RespondJSON(c, http.StatusOK, Status{Status: statusOK})
+		}
+	}
 }
@@ -0,0 +1,79 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("api", func() {
+
+	var handler Handler
+
+	BeforeEach(func() {
+		handler = NewHandler()
+		AddCoreAPIToHandler(handler)
+	})
+
+	get := func(path string) (int, Status) {
+		var status Status
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		Expect(json.NewDecoder(w.Body).Decode(&status)).To(Succeed())
+		return w.Code, status
+	}
+
+	Context("health", func() {
+		It("is always ok", func() {
+			handler.AddReadinessCheck("failing", func() error { return errors.New("failed") })
+			code, status := get("/api/v1/health")
+			Expect(code).To(Equal(http.StatusOK))
+			Expect(status.Status).To(Equal("ok"))
+		})
+	})
+
+	Context("readiness", func() {
+		It("is ready when all checks succeed", func() {
+			handler.AddReadinessCheck("db", func() error { return nil })
+			code, status := get("/api/v1/ready")
+			Expect(code).To(Equal(http.StatusOK))
+			Expect(status.Status).To(Equal("ok"))
+		})
+
+		It("reports the names of failed checks", func() {
+			handler.AddReadinessCheck("db", func() error { return errors.New("unreachable") })
+			handler.AddReadinessCheck("cache", func() error { return nil })
+			code, status := get("/api/v1/ready")
+			Expect(code).To(Equal(http.StatusServiceUnavailable))
+			Expect(status.Failed).To(Equal([]string{"db"}))
+		})
+	})
+})
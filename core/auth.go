@@ -0,0 +1,101 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package core
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ottenwbe/recipes-manager/utils"
+)
+
+const (
+	apiKeysCfg = "auth.apikeys"
+
+	bearerPrefix = "Bearer "
+
+	// userKey is the key of the authenticated user in the context of a call
+	userKey = "user"
+
+	//AnonymousUser is the user of secured calls if no api keys are configured
+	AnonymousUser = "anonymous"
+)
+
+var apiKeys []string
+
+func init() {
+	utils.Config.SetDefault(apiKeysCfg, "")
+	apiKeys = splitList(utils.Config.GetString(apiKeysCfg))
+}
+
+// authMiddleware rejects calls without a valid api key in the Authorization header.
+// Calls are always accepted if no api keys are configured.
+func authMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if len(apiKeys) == 0 {
+			c.Set(userKey, AnonymousUser)
+			c.Next()
+			return
+		}
+
+		authorization := c.Request.Header.Get("Authorization")
+		key := strings.TrimPrefix(authorization, bearerPrefix)
+		if !strings.HasPrefix(authorization, bearerPrefix) || !validAPIKey(key) {
+			c.Header("WWW-Authenticate", "Bearer")
+			AbortWithAPIError(c, http.StatusUnauthorized, "Unauthorized", "a valid api key is required")
+			return
+		}
+
+		c.Set(userKey, userOfAPIKey(key))
+		c.Next()
+	}
+}
+
+// validAPIKey compares the key with all configured keys in constant time
+func validAPIKey(key string) bool {
+	valid := false
+	for _, apiKey := range apiKeys {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(apiKey)) == 1 {
+			valid = true
+		}
+	}
+	return valid
+}
+
+// userOfAPIKey identifies the user of an api key without revealing the key
+func userOfAPIKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
+
+//AuthenticatedUser returns the user of a secured call; it is empty for calls that are not secured
+func AuthenticatedUser(c *APICallContext) string {
+	return c.GetString(userKey)
+}
@@ -0,0 +1,110 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package core
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("auth", func() {
+
+	var (
+		r           Handler
+		defaultKeys []string
+		call        func(method string, path string, authorization string) int
+	)
+
+	BeforeEach(func() {
+		defaultKeys = apiKeys
+
+		r = NewHandler()
+		v1 := r.API(1)
+		v1.GET("/public", func(c *APICallContext) { c.Status(http.StatusOK) })
+		v1.Secured().POST("/secured", func(c *APICallContext) { c.Status(http.StatusOK) })
+		v1.Secured().GET("/user", func(c *APICallContext) { c.String(http.StatusOK, AuthenticatedUser(c)) })
+
+		call = func(method string, path string, authorization string) int {
+			w := httptest.NewRecorder()
+			request := httptest.NewRequest(method, path, nil)
+			if authorization != "" {
+				request.Header.Set("Authorization", authorization)
+			}
+			r.ServeHTTP(w, request)
+			return w.Code
+		}
+	})
+
+	AfterEach(func() {
+		apiKeys = defaultKeys
+	})
+
+	It("should not require a key if none are configured", func() {
+		apiKeys = []string{}
+		Expect(call(http.MethodPost, "/api/v1/secured", "")).To(Equal(http.StatusOK))
+	})
+
+	It("should reject secured calls without a valid key", func() {
+		apiKeys = splitList("key1, key2")
+		Expect(call(http.MethodPost, "/api/v1/secured", "")).To(Equal(http.StatusUnauthorized))
+		Expect(call(http.MethodPost, "/api/v1/secured", "Bearer key3")).To(Equal(http.StatusUnauthorized))
+		Expect(call(http.MethodPost, "/api/v1/secured", "key2")).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("should accept secured calls with a valid key", func() {
+		apiKeys = splitList("key1, key2")
+		Expect(call(http.MethodPost, "/api/v1/secured", "Bearer key2")).To(Equal(http.StatusOK))
+	})
+
+	It("should not secure public routes", func() {
+		apiKeys = splitList("key1")
+		Expect(call(http.MethodGet, "/api/v1/public", "")).To(Equal(http.StatusOK))
+	})
+
+	It("should identify the user of a secured call by the api key", func() {
+		apiKeys = splitList("key1, key2")
+		user := func(authorization string) string {
+			w := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodGet, "/api/v1/user", nil)
+			request.Header.Set("Authorization", authorization)
+			r.ServeHTTP(w, request)
+			return w.Body.String()
+		}
+
+		Expect(user("Bearer key1")).To(Equal(user("Bearer key1")))
+		Expect(user("Bearer key1")).ToNot(Equal(user("Bearer key2")))
+		Expect(user("Bearer key1")).ToNot(ContainSubstring("key1"))
+	})
+
+	It("should use the anonymous user if no keys are configured", func() {
+		apiKeys = []string{}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/user", nil))
+		Expect(w.Body.String()).To(Equal(AnonymousUser))
+	})
+})
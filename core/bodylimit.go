@@ -0,0 +1,74 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package core
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ottenwbe/recipes-manager/utils"
+)
+
+const (
+	maxBodyBytesCfg = "html.maxBodyBytes"
+)
+
+var (
+	maxBodyBytes int64
+)
+
+func init() {
+	utils.Config.SetDefault(maxBodyBytesCfg, 8<<20)
+	maxBodyBytes = utils.Config.GetInt64(maxBodyBytesCfg)
+}
+
+// bodyTooLarge is the message of errors returned when reading beyond the limit of a http.MaxBytesReader
+const bodyTooLarge = "http: request body too large"
+
+// bodyLimitMiddleware stops reading request bodies after maxBytes. The middleware does nothing if maxBytes is not positive.
+func bodyLimitMiddleware(maxBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if maxBytes > 0 && c.Request.Body != nil {
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+		}
+		c.Next()
+	}
+}
+
+// IsBodyTooLarge checks if reading a request body failed since the body exceeds html.maxBodyBytes
+func IsBodyTooLarge(err error) bool {
+	return err != nil && strings.Contains(err.Error(), bodyTooLarge)
+}
+
+// AbortWithBodyError responds with 413 if the request body exceeds html.maxBodyBytes and with 400 otherwise
+func AbortWithBodyError(c *APICallContext, message string, err error) {
+	if IsBodyTooLarge(err) {
+		AbortWithAPIError(c, http.StatusRequestEntityTooLarge, "Request body too large", "")
+		return
+	}
+	AbortWithAPIError(c, http.StatusBadRequest, message, err.Error())
+}
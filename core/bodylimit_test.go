@@ -0,0 +1,83 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package core
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("body limit", func() {
+
+	var (
+		r              Handler
+		defaultMaxSize int64
+		post           func(body string) *httptest.ResponseRecorder
+	)
+
+	BeforeEach(func() {
+		defaultMaxSize = maxBodyBytes
+		maxBodyBytes = 32
+
+		r = NewHandler()
+		r.API(1).POST("/body", func(c *APICallContext) {
+			var input map[string]string
+			if err := c.ShouldBindJSON(&input); err != nil {
+				AbortWithBodyError(c, "Could not read JSON input", err)
+				return
+			}
+			c.Status(http.StatusNoContent)
+		})
+
+		post = func(body string) *httptest.ResponseRecorder {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/body", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+			return w
+		}
+	})
+
+	AfterEach(func() {
+		maxBodyBytes = defaultMaxSize
+	})
+
+	It("accepts bodies within the limit", func() {
+		Expect(post(`{"name":"soup"}`).Code).To(Equal(http.StatusNoContent))
+	})
+
+	It("rejects bodies exceeding the limit with 413", func() {
+		w := post(`{"name":"` + strings.Repeat("a", 64) + `"}`)
+		Expect(w.Code).To(Equal(http.StatusRequestEntityTooLarge))
+	})
+
+	It("rejects malformed bodies within the limit with 400", func() {
+		Expect(post(`{`).Code).To(Equal(http.StatusBadRequest))
+	})
+})
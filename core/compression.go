@@ -0,0 +1,237 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"net"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/ottenwbe/recipes-manager/utils"
+)
+
+const (
+	compressionEnabledCfg  = "html.compression.enabled"
+	compressionMinBytesCfg = "html.compression.minBytes"
+)
+
+var (
+	compressionEnabled  bool
+	compressionMinBytes int
+)
+
+func init() {
+	utils.Config.SetDefault(compressionEnabledCfg, false)
+	utils.Config.SetDefault(compressionMinBytesCfg, 1024)
+	compressionEnabled = utils.Config.GetBool(compressionEnabledCfg)
+	compressionMinBytes = int(utils.Config.GetInt64(compressionMinBytesCfg))
+}
+
+// eventStreamType is the media type of server-sent events
+const eventStreamType = "text/event-stream"
+
+// incompressibleTypes lists content types that are already compressed
+var incompressibleTypes = []string{"image/", "video/", "audio/", "application/zip", "application/gzip", "application/x-gzip"}
+
+// compressionMiddleware compresses responses with gzip if the client accepts it.
+// Responses are buffered to decide whether compression is worth it, i.e., bodies smaller than minBytes are sent as they are.
+// Streams, i.e., responses that are flushed by their handler or are server-sent events, are neither buffered nor compressed.
+func compressionMiddleware(minBytes int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Writer.Header().Add("Vary", "Accept-Encoding")
+
+		if !acceptsGzip(c.Request.Header.Get("Accept-Encoding")) {
+			c.Next()
+			return
+		}
+
+		writer := &bufferedWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		defer func() {
+			c.Writer = writer.ResponseWriter
+			writer.flush(minBytes)
+		}()
+
+		c.Next()
+	}
+}
+
+// acceptsGzip checks if an Accept-Encoding header allows gzip, i.e., 'gzip, deflate' but not 'gzip;q=0'
+func acceptsGzip(acceptEncoding string) bool {
+	for _, encoding := range strings.Split(acceptEncoding, ",") {
+		parts := strings.Split(encoding, ";")
+		name := strings.TrimSpace(parts[0])
+		if name != "gzip" && name != "*" {
+			continue
+		}
+		if len(parts) > 1 && strings.Replace(parts[1], " ", "", -1) == "q=0" {
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+// bufferedWriter holds back the response until the handlers are done, unless the response turns out to be a stream
+type bufferedWriter struct {
+	gin.ResponseWriter
+	status int
+	body   bytes.Buffer
+	// streaming passes all writes through, since the response has to be sent as soon as it is written
+	streaming bool
+}
+
+// isStream checks if the response is a stream and, once it is, passes the buffered and all further writes through
+func (w *bufferedWriter) isStream() bool {
+	if !w.streaming && strings.HasPrefix(w.ResponseWriter.Header().Get("Content-Type"), eventStreamType) {
+		w.passThrough()
+	}
+	return w.streaming
+}
+
+// passThrough writes the buffered response uncompressed and switches to streaming
+func (w *bufferedWriter) passThrough() {
+	w.streaming = true
+	if w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+	if w.body.Len() > 0 {
+		if _, err := w.ResponseWriter.Write(w.body.Bytes()); err != nil {
+			log.WithError(err).Debug("Could not write response")
+		}
+		w.body.Reset()
+	}
+}
+
+func (w *bufferedWriter) WriteHeader(code int) {
+	if w.streaming {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	w.status = code
+}
+
+func (w *bufferedWriter) WriteHeaderNow() {
+	// the header is written when the response is flushed, unless it is a stream
+	if w.isStream() {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	if w.isStream() {
+		return w.ResponseWriter.Write(data)
+	}
+	return w.body.Write(data)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	if w.isStream() {
+		return w.ResponseWriter.WriteString(s)
+	}
+	return w.body.WriteString(s)
+}
+
+// Flush is called by handlers which stream their response, so the response is passed through from now on
+func (w *bufferedWriter) Flush() {
+	if !w.streaming {
+		w.passThrough()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// Hijack takes over the connection, e.g., for a Stream; nothing is buffered that could be written afterwards
+func (w *bufferedWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.streaming = true
+	return w.ResponseWriter.Hijack()
+}
+
+func (w *bufferedWriter) Status() int {
+	if w.streaming || w.status == 0 {
+		return w.ResponseWriter.Status()
+	}
+	return w.status
+}
+
+func (w *bufferedWriter) Size() int {
+	if w.streaming {
+		return w.ResponseWriter.Size()
+	}
+	return w.body.Len()
+}
+
+func (w *bufferedWriter) Written() bool {
+	if w.streaming {
+		return w.ResponseWriter.Written()
+	}
+	return w.status != 0 || w.body.Len() > 0
+}
+
+// flush writes the buffered response, compressed if it is large enough and not compressed already
+func (w *bufferedWriter) flush(minBytes int) {
+	if w.streaming {
+		return
+	}
+	header := w.ResponseWriter.Header()
+	compress := w.body.Len() > 0 && w.body.Len() >= minBytes &&
+		header.Get("Content-Encoding") == "" && !incompressible(header.Get("Content-Type"))
+
+	if compress {
+		header.Set("Content-Encoding", "gzip")
+		header.Del("Content-Length")
+	}
+	if w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+
+	if !compress {
+		if _, err := w.ResponseWriter.Write(w.body.Bytes()); err != nil {
+			log.WithError(err).Debug("Could not write response")
+		}
+		return
+	}
+
+	gz := gzip.NewWriter(w.ResponseWriter)
+	if _, err := gz.Write(w.body.Bytes()); err != nil {
+		log.WithError(err).Debug("Could not write compressed response")
+	}
+	if err := gz.Close(); err != nil {
+		log.WithError(err).Debug("Could not write compressed response")
+	}
+}
+
+func incompressible(contentType string) bool {
+	for _, prefix := range incompressibleTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,123 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package core
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("compression", func() {
+
+	var (
+		r              Handler
+		large          = strings.Repeat("recipe ", 1000)
+		small          = "recipe"
+		request        func(path string, acceptEncoding string) *httptest.ResponseRecorder
+		defaultEnabled bool
+	)
+
+	BeforeEach(func() {
+		defaultEnabled = compressionEnabled
+		compressionEnabled = true
+
+		r = NewHandler()
+		r.API(1).GET("/large", func(c *APICallContext) { c.String(http.StatusOK, large) })
+		r.API(1).GET("/small", func(c *APICallContext) { c.String(http.StatusOK, small) })
+		r.API(1).GET("/image", func(c *APICallContext) { c.Data(http.StatusOK, "image/jpeg", []byte(large)) })
+		r.API(1).GET("/events", func(c *APICallContext) { c.Data(http.StatusOK, "text/event-stream", []byte("data: "+large+"\n\n")) })
+		r.API(1).GET("/flushed", func(c *APICallContext) {
+			c.String(http.StatusOK, small)
+			c.Writer.Flush()
+			_, _ = c.Writer.WriteString(large)
+		})
+
+		request = func(path string, acceptEncoding string) *httptest.ResponseRecorder {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+			r.ServeHTTP(w, req)
+			return w
+		}
+	})
+
+	AfterEach(func() {
+		compressionEnabled = defaultEnabled
+	})
+
+	It("should compress large responses", func() {
+		w := request("/api/v1/large", "gzip, deflate")
+
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Header().Get("Content-Encoding")).To(Equal("gzip"))
+		reader, err := gzip.NewReader(w.Body)
+		Expect(err).ToNot(HaveOccurred())
+		body, _ := ioutil.ReadAll(reader)
+		Expect(string(body)).To(Equal(large))
+	})
+
+	It("should not compress responses if the client does not accept it", func() {
+		w := request("/api/v1/large", "gzip;q=0")
+		Expect(w.Header().Get("Content-Encoding")).To(BeEmpty())
+		Expect(w.Body.String()).To(Equal(large))
+	})
+
+	It("should not compress small responses", func() {
+		w := request("/api/v1/small", "gzip")
+		Expect(w.Header().Get("Content-Encoding")).To(BeEmpty())
+		Expect(w.Body.String()).To(Equal(small))
+	})
+
+	It("should not compress images", func() {
+		w := request("/api/v1/image", "gzip")
+		Expect(w.Header().Get("Content-Encoding")).To(BeEmpty())
+		Expect(w.Body.String()).To(Equal(large))
+	})
+
+	It("should not buffer server-sent events", func() {
+		w := request("/api/v1/events", "gzip")
+		Expect(w.Header().Get("Content-Encoding")).To(BeEmpty())
+		Expect(w.Body.String()).To(Equal("data: " + large + "\n\n"))
+	})
+
+	It("should pass flushed responses through", func() {
+		w := request("/api/v1/flushed", "gzip")
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Flushed).To(BeTrue())
+		Expect(w.Header().Get("Content-Encoding")).To(BeEmpty())
+		Expect(w.Body.String()).To(Equal(small + large))
+	})
+
+	It("should keep the status code", func() {
+		w := request("/api/v1/unknown", "gzip")
+		Expect(w.Code).To(Equal(http.StatusNotFound))
+	})
+})
@@ -0,0 +1,84 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package core
+
+import "fmt"
+
+// APIError is the body of all error responses
+type APIError struct {
+	// Code is the HTTP status code of the response
+	Code int `json:"code"`
+	// ErrorCode is a machine-readable code of the error, i.e., not_found; unlike the message it is not translated
+	ErrorCode string `json:"error,omitempty"`
+	// Message is a short description of the error
+	Message string `json:"message"`
+	// Detail explains the cause of the error, i.e., the id of a missing recipe
+	Detail string `json:"detail,omitempty"`
+	// Fields lists the invalid fields of a rejected input
+	Fields []FieldError `json:"fields,omitempty"`
+	// RequestID correlates an unexpected error with the log entries of the request
+	RequestID string `json:"requestId,omitempty"`
+}
+
+const (
+	// ErrorNotFound is the error code of responses to unknown paths
+	ErrorNotFound = "not_found"
+	// ErrorMethodNotAllowed is the error code of responses to known paths requested with an unsupported method
+	ErrorMethodNotAllowed = "method_not_allowed"
+)
+
+// FieldError describes why a field of an input is invalid
+type FieldError struct {
+	// Field is the path of the field in the JSON input, i.e., components[0].name
+	Field string `json:"field"`
+	// Message explains what is wrong with the field
+	Message string `json:"message"`
+}
+
+// Error returns the message and, if available, the detail of the error
+func (e *APIError) Error() string {
+	if e.Detail == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%v: %v", e.Message, e.Detail)
+}
+
+// AbortWithAPIError stops the processing of a call and responds with an APIError encoded as JSON. The message is translated into the language of the client.
+func AbortWithAPIError(c *APICallContext, code int, message string, detail string) {
+	c.Abort()
+	RespondJSON(c, code, &APIError{Code: code, Message: T(c, message), Detail: detail})
+}
+
+// AbortWithErrorCode stops the processing of a call and responds with an APIError which, in addition to the message, carries a machine-readable error code
+func AbortWithErrorCode(c *APICallContext, code int, errorCode string, message string, detail string) {
+	c.Abort()
+	RespondJSON(c, code, &APIError{Code: code, ErrorCode: errorCode, Message: T(c, message), Detail: detail})
+}
+
+// AbortWithFieldErrors stops the processing of a call and responds with an APIError listing the invalid fields
+func AbortWithFieldErrors(c *APICallContext, code int, message string, fields []FieldError) {
+	c.Abort()
+	RespondJSON(c, code, &APIError{Code: code, Message: T(c, message), Fields: fields})
+}
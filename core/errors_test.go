@@ -0,0 +1,72 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package core
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("errors", func() {
+
+	It("are returned as JSON", func() {
+		handler := NewHandler()
+		handler.API(1).GET("/fail", func(c *APICallContext) {
+			AbortWithAPIError(c, http.StatusNotFound, "No such thing", "42")
+		})
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/fail", nil))
+
+		var apiError APIError
+		Expect(json.NewDecoder(w.Body).Decode(&apiError)).To(Succeed())
+		Expect(w.Code).To(Equal(http.StatusNotFound))
+		Expect(apiError).To(Equal(APIError{Code: http.StatusNotFound, Message: "No such thing", Detail: "42"}))
+	})
+
+	It("can list invalid fields", func() {
+		handler := NewHandler()
+		handler.API(1).POST("/fail", func(c *APICallContext) {
+			AbortWithFieldErrors(c, http.StatusBadRequest, "Invalid input", []FieldError{{Field: "name", Message: "must not be empty"}})
+		})
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/fail", nil))
+
+		var apiError APIError
+		Expect(json.NewDecoder(w.Body).Decode(&apiError)).To(Succeed())
+		Expect(w.Code).To(Equal(http.StatusBadRequest))
+		Expect(apiError.Fields).To(Equal([]FieldError{{Field: "name", Message: "must not be empty"}}))
+	})
+
+	It("contain the detail in their description", func() {
+		Expect((&APIError{Message: "No such thing", Detail: "42"}).Error()).To(Equal("No such thing: 42"))
+		Expect((&APIError{Message: "No such thing"}).Error()).To(Equal("No such thing"))
+	})
+})
@@ -0,0 +1,96 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package core
+
+import (
+	"path"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/ottenwbe/recipes-manager/utils"
+)
+
+// featuresCfg is the configuration key of the map of features, e.g., features.csv=false disables the feature csv
+const featuresCfg = "features"
+
+// FeatureEnabled tells if a feature is enabled by the configuration features.<name>.
+// Features are enabled unless they are disabled explicitly.
+func FeatureEnabled(name string) bool {
+	key := featuresCfg + "." + name
+	value := utils.Config.GetString(key)
+	if value == "" {
+		return true
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		log.WithError(err).WithField("key", key).Warn("Invalid feature flag, the feature stays enabled")
+		return true
+	}
+	return enabled
+}
+
+// disabledRoutes drops all endpoints of a disabled feature, so that they respond like unknown paths and are not listed as allowed methods
+type disabledRoutes struct {
+	path string
+}
+
+// Route of a disabled feature is disabled as well
+func (d *disabledRoutes) Route(relativePath string) Routes {
+	return &disabledRoutes{path.Join(d.path, relativePath)}
+}
+
+// GET endpoint of a disabled feature is not registered
+func (d *disabledRoutes) GET(string, func(c *APICallContext)) {}
+
+// PUT endpoint of a disabled feature is not registered
+func (d *disabledRoutes) PUT(string, func(c *APICallContext)) {}
+
+// DELETE endpoint of a disabled feature is not registered
+func (d *disabledRoutes) DELETE(string, func(c *APICallContext)) {}
+
+// PATCH endpoint of a disabled feature is not registered
+func (d *disabledRoutes) PATCH(string, func(c *APICallContext)) {}
+
+// POST endpoint of a disabled feature is not registered
+func (d *disabledRoutes) POST(string, func(c *APICallContext)) {}
+
+// Stream endpoint of a disabled feature is not registered
+func (d *disabledRoutes) Stream(string, func(c *APICallContext)) {}
+
+// Secured routes of a disabled feature are disabled as well
+func (d *disabledRoutes) Secured() Routes {
+	return d
+}
+
+// FeatureGate of a disabled feature is disabled as well
+func (d *disabledRoutes) FeatureGate(string) Routes {
+	return d
+}
+
+// Path of the routes
+func (d *disabledRoutes) Path() string {
+	return d.path
+}
@@ -0,0 +1,123 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package core
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ottenwbe/recipes-manager/utils"
+)
+
+var _ = Describe("feature flags", func() {
+
+	var (
+		handler Handler
+		config  utils.RecipeConfig
+	)
+
+	serve := func(method string, path string) (*httptest.ResponseRecorder, APIError) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		var apiError APIError
+		_ = json.NewDecoder(w.Body).Decode(&apiError)
+		return w, apiError
+	}
+
+	BeforeEach(func() {
+		config = utils.Config
+		utils.Config = &featureTestConfig{RecipeConfig: config, flags: map[string]string{
+			"features.testDisabled": "false",
+			"features.testEnabled":  "true",
+			"features.testInvalid":  "maybe",
+		}}
+
+		handler = NewHandler()
+		v1 := handler.API(1)
+		v1.FeatureGate("testDisabled").GET("/disabled", func(c *APICallContext) { c.Status(http.StatusOK) })
+		v1.FeatureGate("testDisabled").Secured().POST("/disabled", func(c *APICallContext) { c.Status(http.StatusOK) })
+		v1.FeatureGate("testEnabled").GET("/enabled", func(c *APICallContext) { c.Status(http.StatusOK) })
+		v1.FeatureGate("testUnknown").GET("/unknown", func(c *APICallContext) { c.Status(http.StatusOK) })
+		v1.FeatureGate("testDisabled").Secured().POST("/import", func(c *APICallContext) { c.Status(http.StatusOK) })
+		v1.GET("/mixed", func(c *APICallContext) { c.Status(http.StatusOK) })
+		v1.FeatureGate("testDisabled").DELETE("/mixed", func(c *APICallContext) { c.Status(http.StatusOK) })
+	})
+
+	AfterEach(func() {
+		utils.Config = config
+	})
+
+	It("enables features unless they are disabled", func() {
+		Expect(FeatureEnabled("testEnabled")).To(BeTrue())
+		Expect(FeatureEnabled("testUnknown")).To(BeTrue())
+		Expect(FeatureEnabled("testInvalid")).To(BeTrue())
+		Expect(FeatureEnabled("testDisabled")).To(BeFalse())
+	})
+
+	It("serves endpoints of enabled features", func() {
+		w, _ := serve(http.MethodGet, "/api/v1/enabled")
+		Expect(w.Code).To(Equal(http.StatusOK))
+
+		w, _ = serve(http.MethodGet, "/api/v1/unknown")
+		Expect(w.Code).To(Equal(http.StatusOK))
+	})
+
+	It("hides endpoints of disabled features", func() {
+		w, apiError := serve(http.MethodGet, "/api/v1/disabled")
+		Expect(w.Code).To(Equal(http.StatusNotFound))
+		Expect(apiError).To(Equal(APIError{Code: http.StatusNotFound, ErrorCode: ErrorNotFound, Message: "Not found", Detail: "/api/v1/disabled"}))
+	})
+
+	It("hides secured endpoints of disabled features without asking for an api key", func() {
+		w, _ := serve(http.MethodPost, "/api/v1/disabled")
+		Expect(w.Code).To(Equal(http.StatusNotFound))
+	})
+
+	It("does not list methods of disabled features as allowed", func() {
+		w, _ := serve(http.MethodGet, "/api/v1/import")
+		Expect(w.Code).To(Equal(http.StatusNotFound))
+
+		w, _ = serve(http.MethodDelete, "/api/v1/mixed")
+		Expect(w.Code).To(Equal(http.StatusMethodNotAllowed))
+		Expect(w.Header().Get("Allow")).To(Equal("GET"))
+	})
+})
+
+// featureTestConfig answers the feature flags of the tests and passes all other keys to the configuration
+type featureTestConfig struct {
+	utils.RecipeConfig
+	flags map[string]string
+}
+
+func (f *featureTestConfig) GetString(key string) string {
+	if flag, ok := f.flags[key]; ok {
+		return flag
+	}
+	return f.RecipeConfig.GetString(key)
+}
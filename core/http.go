@@ -27,40 +27,144 @@ package core
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
-	"github.com/gin-gonic/contrib/ginrus"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/satori/go.uuid"
 	log "github.com/sirupsen/logrus"
 	swaggerFiles "github.com/swaggo/files"
 	"github.com/swaggo/gin-swagger"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 
 	// based on swagger documentation
-	_ "github.com/ottenwbe/recipes-manager/docs"
+	"github.com/ottenwbe/recipes-manager/docs"
 
 	"github.com/ottenwbe/recipes-manager/utils"
 )
 
 const (
-	addressCfg         = "html.address"
-	corsAllowOriginCfg = "html.cors.origin"
-
-	baseAPIPath = "api"
+	addressCfg          = "html.address"
+	corsAllowOriginCfg  = "html.cors.origin"
+	corsAllowMethodsCfg = "html.cors.methods"
+	corsAllowHeadersCfg = "html.cors.headers"
+	readTimeoutCfg      = "html.timeouts.read"
+	writeTimeoutCfg     = "html.timeouts.write"
+	idleTimeoutCfg      = "html.timeouts.idle"
+	shutdownTimeoutCfg  = "html.shutdown.timeout"
+	tlsCertFileCfg      = "html.tls.certFile"
+	tlsKeyFileCfg       = "html.tls.keyFile"
+	h2cCfg              = "html.http2.h2c"
+	basePathCfg         = "html.basePath"
+	redirectCfg         = "html.redirectTrailingSlash"
+	methodNotAllowedCfg = "html.methodNotAllowed"
+
+	anyOrigin = "*"
+
+	// RequestIDHeader is used to correlate a request with its log entries
+	RequestIDHeader = "X-Request-ID"
+	// AcceptVersionHeader selects the api version of requests to unversioned paths, i.e., /api/recipes
+	AcceptVersionHeader = "Accept-Version"
+	// DeprecationHeader marks responses of api versions which have a successor
+	DeprecationHeader = "Deprecation"
+	// defaultAPIVersion serves requests to unversioned paths without an AcceptVersionHeader
+	defaultAPIVersion int16 = 1
+	// maxRequestIDLength limits the length of request ids provided by clients
+	maxRequestIDLength = 128
+	// loggerKey is the key of the request-scoped logger in an APICallContext
+	loggerKey = "logger"
 )
 
 var (
-	defaultAddress string
-	corsOrigin     string
+	defaultAddress  string
+	corsOrigins     []string
+	corsMethods     string
+	corsHeaders     string
+	readTimeout     time.Duration
+	writeTimeout    time.Duration
+	idleTimeout     time.Duration
+	shutdownTimeout time.Duration
+	tlsCertFile     string
+	tlsKeyFile      string
+	//h2cEnabled serves HTTP/2 without TLS, e.g., behind a proxy which terminates TLS
+	h2cEnabled bool
+	//apiBasePath prefixes all versioned api routes, i.e., /api for /api/v1
+	apiBasePath string
+	//redirectTrailingSlash redirects paths with a superfluous or missing trailing slash and paths with a wrong case
+	redirectTrailingSlash bool
+	//methodNotAllowed responds with 405 instead of 404 to known paths requested with an unsupported method
+	methodNotAllowed bool
 )
 
 // init configures the handler for api calls when the core package is initialized
 func init() {
 	utils.Config.SetDefault(addressCfg, ":8080")
-	utils.Config.SetDefault(corsAllowOriginCfg, "*")
+	utils.Config.SetDefault(corsAllowOriginCfg, anyOrigin)
+	utils.Config.SetDefault(corsAllowMethodsCfg, "GET, PATCH, POST, PUT, DELETE")
+	utils.Config.SetDefault(corsAllowHeadersCfg, "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-Request-ID, Accept-Version")
 	defaultAddress = utils.Config.GetString(addressCfg)
-	corsOrigin = utils.Config.GetString(corsAllowOriginCfg)
+	corsOrigins = splitList(utils.Config.GetString(corsAllowOriginCfg))
+	corsMethods = utils.Config.GetString(corsAllowMethodsCfg)
+	corsHeaders = utils.Config.GetString(corsAllowHeadersCfg)
+
+	utils.Config.SetDefault(readTimeoutCfg, "15s")
+	utils.Config.SetDefault(writeTimeoutCfg, "15s")
+	utils.Config.SetDefault(idleTimeoutCfg, "60s")
+	readTimeout = utils.Config.GetDuration(readTimeoutCfg)
+	writeTimeout = utils.Config.GetDuration(writeTimeoutCfg)
+	idleTimeout = utils.Config.GetDuration(idleTimeoutCfg)
+
+	utils.Config.SetDefault(shutdownTimeoutCfg, "10s")
+	shutdownTimeout = utils.Config.GetDuration(shutdownTimeoutCfg)
+
+	utils.Config.SetDefault(tlsCertFileCfg, "")
+	utils.Config.SetDefault(tlsKeyFileCfg, "")
+	tlsCertFile = utils.Config.GetString(tlsCertFileCfg)
+	tlsKeyFile = utils.Config.GetString(tlsKeyFileCfg)
+
+	utils.Config.SetDefault(h2cCfg, false)
+	h2cEnabled = utils.Config.GetBool(h2cCfg)
+
+	utils.Config.SetDefault(basePathCfg, "/api")
+	apiBasePath = normalizeBasePath(utils.Config.GetString(basePathCfg))
+
+	utils.Config.SetDefault(redirectCfg, true)
+	redirectTrailingSlash = utils.Config.GetBool(redirectCfg)
+
+	utils.Config.SetDefault(methodNotAllowedCfg, true)
+	methodNotAllowed = utils.Config.GetBool(methodNotAllowedCfg)
+}
+
+// normalizeBasePath ensures that a base path starts with a slash and does not end with one, i.e., cook/api/ becomes /cook/api
+func normalizeBasePath(basePath string) string {
+	trimmed := strings.Trim(strings.TrimSpace(basePath), "/")
+	if trimmed == "" {
+		return ""
+	}
+	return "/" + trimmed
+}
+
+// splitList splits a comma separated configuration value and trims all elements
+func splitList(list string) []string {
+	result := make([]string, 0)
+	for _, element := range strings.Split(list, ",") {
+		if trimmed := strings.TrimSpace(element); trimmed != "" {
+			result = append(result, trimmed)
+		}
+	}
+	return result
 }
 
 //Routes is managing a set of API endpoints.
@@ -80,11 +184,23 @@ type Routes interface {
 	PUT(string, func(c *APICallContext))
 	//DELETE endpoint is added to the routes set and registers a corresponding handler
 	DELETE(string, func(c *APICallContext))
+	//Secured returns the same set of endpoints, but all endpoints added to it require a valid api key
+	Secured() Routes
+	//FeatureGate returns the same set of endpoints, but endpoints added to it are not registered if the feature is disabled, see FeatureEnabled
+	FeatureGate(name string) Routes
+	//Stream endpoint is added to the routes set as GET endpoint with a long-lived response, see OpenStream; it is not limited by the request timeout
+	Stream(string, func(c *APICallContext))
 }
 
 //Handler is a facade for a HTTP handler and can be implemented by a concrete handler like gin.
 type Handler interface {
 	API(version int16) Routes
+	//AddReadinessCheck registers a named check that has to succeed for the service to be ready
+	AddReadinessCheck(name string, check func() error)
+	//FailedReadinessChecks runs all readiness checks and returns the names of the failed ones
+	FailedReadinessChecks() []string
+	//RegisterMetrics adds collectors to the metrics exposed at /metrics
+	RegisterMetrics(collectors ...prometheus.Collector) error
 	http.Handler
 }
 
@@ -94,8 +210,10 @@ type APICallContext = gin.Context
 //NewHandler creates a handler for API calls with a pre-configured ADDRESS
 func NewHandler() Handler {
 	handler := &ginHandler{
-		gin.New(),
-		make(map[string]Routes),
+		handler:         gin.New(),
+		routerGroups:    make(map[string]Routes),
+		readinessChecks: make(map[string]func() error),
+		streams:         make(map[string]bool),
 	}
 	handler.configure()
 	return handler
@@ -109,14 +227,109 @@ func NewHandler() Handler {
 // @BasePath /api/v1
 
 type ginHandler struct {
-	handler      *gin.Engine
-	routerGroups map[string]Routes
+	handler         *gin.Engine
+	routerGroups    map[string]Routes
+	readinessChecks map[string]func() error
+	//checksMtx guards the readiness checks, which are added during startup and run during requests
+	checksMtx sync.RWMutex
+	metrics   *httpMetrics
+	//latestVersion is the highest api version with routes
+	latestVersion int16
+	//streams are the full paths of the routes with long-lived responses
+	streams map[string]bool
 }
 
 func (g *ginHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	if version, ok := unversionedAPIPath(request.URL.Path); ok {
+		writer.Header().Add("Vary", AcceptVersionHeader)
+		request = withAPIVersion(request, acceptedVersion(request.Header.Get(AcceptVersionHeader)), version)
+	}
 	g.handler.ServeHTTP(writer, request)
 }
 
+// unversionedAPIPath checks if a path addresses the api without a version, i.e., /api/recipes instead of /api/v1/recipes.
+// The remainder of the path after /api is returned. Without a base path, all paths are versioned.
+func unversionedAPIPath(path string) (string, bool) {
+	prefix := apiBasePath + "/"
+	if apiBasePath == "" || !strings.HasPrefix(path, prefix) {
+		return "", false
+	}
+	rest := strings.TrimPrefix(path, prefix)
+	segment := strings.SplitN(rest, "/", 2)[0]
+	if _, err := parseVersion(segment); err == nil && strings.HasPrefix(segment, "v") {
+		return "", false
+	}
+	return "/" + rest, true
+}
+
+// acceptedVersion parses the AcceptVersionHeader, i.e., 2 or v2. Requests without a valid header are served by the defaultAPIVersion.
+func acceptedVersion(header string) int16 {
+	if version, err := parseVersion(header); err == nil {
+		return version
+	}
+	return defaultAPIVersion
+}
+
+func parseVersion(version string) (int16, error) {
+	n, err := strconv.ParseInt(strings.TrimPrefix(strings.TrimSpace(version), "v"), 10, 16)
+	if err == nil && n < 1 {
+		err = fmt.Errorf("invalid api version %v", version)
+	}
+	return int16(n), err
+}
+
+// withAPIVersion routes a copy of the request to the given version of the api
+func withAPIVersion(request *http.Request, version int16, rest string) *http.Request {
+	versioned := request.Clone(request.Context())
+	versioned.URL.Path = apiBasePath + "/" + v(version) + rest
+	versioned.URL.RawPath = ""
+	return versioned
+}
+
+// deprecationMiddleware marks responses of an api version as deprecated once a newer version has routes
+func (g *ginHandler) deprecationMiddleware(version int16) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if latest := g.latestVersion; latest > version {
+			c.Header(DeprecationHeader, "true")
+			c.Header("Link", fmt.Sprintf("<%v/%v>; rel=\"successor-version\"", apiBasePath, v(latest)))
+		}
+		c.Next()
+	}
+}
+
+//AddReadinessCheck registers a named check that has to succeed for the service to be ready
+func (g *ginHandler) AddReadinessCheck(name string, check func() error) {
+	g.checksMtx.Lock()
+	defer g.checksMtx.Unlock()
+	g.readinessChecks[name] = check
+}
+
+//FailedReadinessChecks runs all readiness checks and returns the sorted names of the failed ones
+func (g *ginHandler) FailedReadinessChecks() []string {
+	g.checksMtx.RLock()
+	defer g.checksMtx.RUnlock()
+
+	failed := make([]string, 0)
+	for name, check := range g.readinessChecks {
+		if err := check(); err != nil {
+			log.WithError(err).WithField("check", name).Warn("Readiness check failed")
+			failed = append(failed, name)
+		}
+	}
+	sort.Strings(failed)
+	return failed
+}
+
+//RegisterMetrics adds collectors to the metrics exposed at /metrics
+func (g *ginHandler) RegisterMetrics(collectors ...prometheus.Collector) error {
+	for _, collector := range collectors {
+		if err := g.metrics.registry.Register(collector); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (g *ginHandler) addSubGroup(groupName string, subGroupName string) Routes {
 	rg, ok := g.routerGroups[groupName]
 	if !ok {
@@ -131,38 +344,72 @@ func v(version int16) string {
 	return fmt.Sprintf("v%v", version)
 }
 
-//API registers the endpoint /api/v<version> and returns a group of endpoints under /api/v<version>
+//API registers the endpoint /api/v<version> and returns a group of endpoints under /api/v<version>; the base path /api is configured by html.basePath
 func (g *ginHandler) API(version int16) Routes {
 	rg, ok := g.routerGroups[v(version)]
 	if !ok {
-		rg = g.addSubGroup(baseAPIPath, v(version))
+		rg = g.addSubGroup(apiBasePath, v(version))
+		rg.(*ginRoutes).rg.Use(g.deprecationMiddleware(version))
 		g.routerGroups[v(version)] = rg
+		if version > g.latestVersion {
+			g.latestVersion = version
+		}
 	}
 	return rg
 }
 
 func (g *ginHandler) route(route string) Routes {
-	return &ginRoutes{g.handler.Group(route)}
+	return &ginRoutes{g.handler.Group(route), g.streams}
 }
 
 // configure the default middleware with a logger and recovery (crash-free) middleware
 func (g *ginHandler) configure() {
 
-	url := ginSwagger.URL("doc.json") // The url pointing to API definition
-	g.handler.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler, url))
+	// redirect hand-typed paths like /api/v1/recipes/ or /API/v1/recipes to /api/v1/recipes instead of responding with 404
+	g.handler.RedirectTrailingSlash = redirectTrailingSlash
+	g.handler.RedirectFixedPath = redirectTrailingSlash
 
-	g.handler.Use(ginrus.Ginrus(log.StandardLogger(), time.RFC3339, true))
+	// respond to unknown paths and methods with an APIError, like to all other errors
+	g.handler.HandleMethodNotAllowed = methodNotAllowed
+	g.handler.NoRoute(notFoundHandler())
+	g.handler.NoMethod(methodNotAllowedHandler(g.handler.Routes))
+
+	if docs.Enabled {
+		url := ginSwagger.URL("doc.json") // The url pointing to API definition
+		g.handler.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler, url))
+	}
+
+	g.metrics = newHTTPMetrics(metricsNamespace)
+	g.handler.GET(metricsPath, g.metrics.handler())
+
+	g.handler.Use(requestIDMiddleware())
+	g.handler.Use(g.metrics.middleware())
+	g.handler.Use(accessLogMiddleware(log.StandardLogger(), accessLogSampleRate))
 	g.handler.Use(g.corsMiddleware())
-	// Return 500 if there was a panic.
-	g.handler.Use(gin.Recovery())
+	g.handler.Use(rateLimitMiddleware(rateLimitRPS, rateLimitBurst))
+	g.handler.Use(bodyLimitMiddleware(maxBodyBytes))
+	g.handler.Use(timeoutMiddleware(requestTimeout, g.streams))
+	if validateRequests {
+		if schemas, err := newRequestSchemas(1); err != nil {
+			log.WithError(err).Error("Could not read the API definition, requests are not validated")
+		} else {
+			g.handler.Use(requestValidationMiddleware(schemas))
+		}
+	}
+	if compressionEnabled {
+		g.handler.Use(compressionMiddleware(compressionMinBytes))
+	}
+	// Return 500 with an APIError if there was a panic.
+	g.handler.Use(recoveryMiddleware(debugResponses))
 }
 
 type ginRoutes struct {
-	rg *gin.RouterGroup
+	rg      *gin.RouterGroup
+	streams map[string]bool
 }
 
 func (g *ginRoutes) Route(path string) Routes {
-	return &ginRoutes{g.rg.Group(path)}
+	return &ginRoutes{g.rg.Group(path), g.streams}
 }
 
 //GET endpoint for a specific path and a corresponding handler
@@ -190,6 +437,26 @@ func (g *ginRoutes) POST(path string, handler func(c *APICallContext)) {
 	g.rg.POST(path, handler)
 }
 
+//Secured routes require a valid api key, see authMiddleware
+func (g *ginRoutes) Secured() Routes {
+	return &ginRoutes{g.rg.Group("", authMiddleware()), g.streams}
+}
+
+//FeatureGate hides the routes if the feature is disabled by the configuration features.<name>
+func (g *ginRoutes) FeatureGate(name string) Routes {
+	if FeatureEnabled(name) {
+		return g
+	}
+	log.WithField("feature", name).Info("Feature is disabled")
+	return &disabledRoutes{g.Path()}
+}
+
+//Stream endpoint for a specific path and a corresponding handler, which responds with a long-lived stream, see OpenStream
+func (g *ginRoutes) Stream(relativePath string, handler func(c *APICallContext)) {
+	g.streams[path.Join(g.rg.BasePath(), relativePath)] = true
+	g.rg.GET(relativePath, handler)
+}
+
 //PATH of the given route
 func (g *ginRoutes) Path() string {
 	return g.rg.BasePath()
@@ -197,10 +464,13 @@ func (g *ginRoutes) Path() string {
 
 func (g *ginHandler) corsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", corsOrigin)
+		if origin := allowedOrigin(c.Request.Header.Get("Origin")); origin != "" {
+			c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
+		}
+		c.Writer.Header().Add("Vary", "Origin")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, PATCH, POST, PUT")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", corsHeaders)
+		c.Writer.Header().Set("Access-Control-Allow-Methods", corsMethods)
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -211,22 +481,173 @@ func (g *ginHandler) corsMiddleware() gin.HandlerFunc {
 	}
 }
 
+// requestIDMiddleware propagates the client's request id or generates a new one.
+// The id is echoed in the response and attached to the request-scoped logger, see LoggerFrom.
+func requestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.Request.Header.Get(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = uuid.NewV4().String()
+		}
+
+		c.Writer.Header().Set(RequestIDHeader, requestID)
+		c.Set(loggerKey, log.WithField("request_id", requestID))
+
+		c.Next()
+	}
+}
+
+// validRequestID accepts non-empty ids of printable ASCII characters that are not too long
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range requestID {
+		if r < '!' || r > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// LoggerFrom returns the logger of a call, which attaches the call's request id to all entries
+func LoggerFrom(c *APICallContext) *log.Entry {
+	if logger, ok := c.Get(loggerKey); ok {
+		if entry, ok := logger.(*log.Entry); ok {
+			return entry
+		}
+	}
+	return log.NewEntry(log.StandardLogger())
+}
+
+// allowedOrigin returns the value of the Access-Control-Allow-Origin header for a request's origin.
+// A configured origin that matches the request's origin is echoed back, since credentialed requests do not support a wildcard.
+// An empty string is returned if the origin is not allowed.
+func allowedOrigin(requestOrigin string) string {
+	wildcard := false
+	for _, origin := range corsOrigins {
+		if requestOrigin != "" && origin == requestOrigin {
+			return requestOrigin
+		}
+		wildcard = wildcard || origin == anyOrigin
+	}
+	if wildcard {
+		return anyOrigin
+	}
+	return ""
+}
+
 // Server interface which extends the http.Server
 type Server struct {
-	Address       string
-	server        *http.Server
-	stopWaitGroup *sync.WaitGroup
+	Address         string
+	server          *http.Server
+	stopWaitGroup   *sync.WaitGroup
+	shutdownTimeout time.Duration
+	inFlight        *int64
+	certFile        string
+	keyFile         string
+	// stopping is closed when the server is closed, so that streams end, see OpenStream
+	stopping chan struct{}
+	stop     *sync.Once
+}
+
+//ServerConfig defines the address and the timeouts of a Server
+type ServerConfig struct {
+	Address string
+	//ReadTimeout is the maximum duration for reading an entire request
+	ReadTimeout time.Duration
+	//WriteTimeout is the maximum duration before timing out writes of a response
+	WriteTimeout time.Duration
+	//IdleTimeout is the maximum duration to wait for the next request of a keep-alive connection
+	IdleTimeout time.Duration
+	//ShutdownTimeout is the maximum duration to wait for in-flight requests when the server is closed
+	ShutdownTimeout time.Duration
+	//CertFile and KeyFile enable TLS if both are set
+	CertFile string
+	KeyFile  string
+	//H2C serves HTTP/2 over cleartext connections if TLS is not enabled; with TLS, HTTP/2 is always negotiated
+	H2C bool
+}
+
+//DefaultServerConfig listens on the given address and uses the timeouts configured by html.timeouts
+func DefaultServerConfig(addr string) ServerConfig {
+	return ServerConfig{
+		Address:         addr,
+		ReadTimeout:     readTimeout,
+		WriteTimeout:    writeTimeout,
+		IdleTimeout:     idleTimeout,
+		ShutdownTimeout: shutdownTimeout,
+		CertFile:        tlsCertFile,
+		KeyFile:         tlsKeyFile,
+		H2C:             h2cEnabled,
+	}
+}
+
+//NewServerWithConfig creates a new server with a given address and timeouts.
+//The server negotiates HTTP/2 with clients that support it when TLS is enabled, otherwise only if H2C is set.
+func NewServerWithConfig(config ServerConfig, handler http.Handler) Server {
+	inFlight := new(int64)
+	stopping := make(chan struct{})
+	server := &http.Server{
+		Addr:         config.Address,
+		Handler:      countInFlight(inFlight, withServerStopping(stopping, handler)),
+		ReadTimeout:  config.ReadTimeout,
+		WriteTimeout: config.WriteTimeout,
+		IdleTimeout:  config.IdleTimeout,
+	}
+	enableHTTP2(server, config)
+	return Server{
+		Address:         config.Address,
+		server:          server,
+		stopWaitGroup:   &sync.WaitGroup{},
+		shutdownTimeout: config.ShutdownTimeout,
+		inFlight:        inFlight,
+		certFile:        config.CertFile,
+		keyFile:         config.KeyFile,
+		stopping:        stopping,
+		stop:            &sync.Once{}}
+}
+
+//TLS is true if the server is configured with a certificate and a key
+func (s Server) TLS() bool {
+	return s.certFile != "" && s.keyFile != ""
+}
+
+//enableHTTP2 adds h2 to the protocols negotiated via TLS and, if configured, serves cleartext HTTP/2 connections.
+//Both share one HTTP/2 server, so that its connections are closed gracefully on shutdown as well.
+func enableHTTP2(server *http.Server, config ServerConfig) {
+	h2s := &http2.Server{IdleTimeout: config.IdleTimeout}
+	if err := http2.ConfigureServer(server, h2s); err != nil {
+		log.WithError(err).Error("Could not enable HTTP/2, serving HTTP/1.1 only")
+		return
+	}
+	if config.H2C && (config.CertFile == "" || config.KeyFile == "") {
+		server.Handler = h2c.NewHandler(server.Handler, h2s)
+	}
+}
+
+//swaggerHost of a server listening on addr. Servers which listen on all interfaces have no host,
+//in this case the Swagger UI sends requests to the host which served the documentation.
+func swaggerHost(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil || host == "" || net.ParseIP(host).IsUnspecified() {
+		return ""
+	}
+	return addr
+}
+
+//countInFlight keeps track of the number of requests which are currently handled
+func countInFlight(inFlight *int64, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(inFlight, 1)
+		defer atomic.AddInt64(inFlight, -1)
+		handler.ServeHTTP(w, r)
+	})
 }
 
 //NewServerA creates a new server using a given address to listen to
 func NewServerA(addr string, handler http.Handler) Server {
-	return Server{
-		Address: addr,
-		server: &http.Server{
-			Addr:    addr,
-			Handler: handler,
-		},
-		stopWaitGroup: &sync.WaitGroup{}}
+	return NewServerWithConfig(DefaultServerConfig(addr), handler)
 }
 
 //NewServerH creates a new server using the default address with a custom handler
@@ -241,20 +662,71 @@ func NewServer() Server {
 
 //Run the server for the API
 func (s Server) Run() *sync.WaitGroup {
-	s.stopWaitGroup.Add(1)
+	errs := s.Serve()
 	go func() {
-		if err := s.server.ListenAndServe(); err != nil {
+		for err := range errs {
 			log.Errorf("Server's not running: %s\n", err)
 		}
-		s.stopWaitGroup.Done()
 	}()
 	return s.stopWaitGroup
 }
 
-//Close the server
+//Serve starts the server in the background.
+//An error that stops the server, e.g., when the address is already in use, is sent on the returned channel.
+//The channel is closed when the server stopped.
+func (s Server) Serve() <-chan error {
+	errs := make(chan error, 1)
+	docs.Configure(swaggerHost(s.Address), apiBasePath+"/"+v(defaultAPIVersion), s.TLS())
+	s.stopWaitGroup.Add(1)
+	go func() {
+		defer s.stopWaitGroup.Done()
+		defer close(errs)
+		if err := s.listenAndServe(); err != nil && err != http.ErrServerClosed {
+			errs <- err
+		}
+	}()
+	return errs
+}
+
+func (s Server) listenAndServe() error {
+	if s.TLS() {
+		return s.server.ListenAndServeTLS(s.certFile, s.keyFile)
+	}
+	return s.server.ListenAndServe()
+}
+
+//RunAndWait runs the server and blocks until the context is cancelled or the process receives SIGINT or SIGTERM.
+//Afterwards, the server is closed. An error is returned if the server could not be run or closed.
+func (s Server) RunAndWait(ctx context.Context) error {
+	errs := s.Serve()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	select {
+	case err, ok := <-errs:
+		if ok {
+			return err
+		}
+		return nil
+	case <-ctx.Done():
+		log.Info("Context is done, closing the server")
+	case sig := <-signals:
+		log.Infof("Received signal %v, closing the server", sig)
+	}
+
+	return s.Close()
+}
+
+//Close the server gracefully, i.e., in-flight requests are given the configured shutdown timeout to finish and streams are ended
 func (s Server) Close() error {
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	s.stop.Do(func() { close(s.stopping) })
+	ctx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
 	defer cancel()
 	err := s.server.Shutdown(ctx)
+	if err == context.DeadlineExceeded {
+		log.WithError(err).WithField("requests", atomic.LoadInt64(s.inFlight)).Warnf("Shutdown timeout of %v exceeded, in-flight requests are cancelled", s.shutdownTimeout)
+	}
 	return err
 }
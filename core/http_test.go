@@ -28,8 +28,28 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
+	"time"
 
+	"golang.org/x/net/http2"
+
+	"github.com/ottenwbe/recipes-manager/docs"
 	"github.com/ottenwbe/recipes-manager/utils"
 )
 
@@ -53,6 +73,194 @@ var _ = Describe("http", func() {
 			s := NewServer()
 			Expect(s.Address).To(Equal(utils.Config.GetString(addressCfg)))
 		})
+
+		It("should apply the configured timeouts", func() {
+			s := NewServer()
+			Expect(s.server.ReadTimeout).To(Equal(utils.Config.GetDuration(readTimeoutCfg)))
+			Expect(s.server.WriteTimeout).To(Equal(15 * time.Second))
+			Expect(s.server.IdleTimeout).To(Equal(60 * time.Second))
+		})
+
+		It("should enable TLS if a certificate and a key are configured", func() {
+			Expect(NewServer().TLS()).To(BeFalse())
+			Expect(NewServerWithConfig(ServerConfig{CertFile: "cert.pem", KeyFile: "key.pem"}, NewHandler()).TLS()).To(BeTrue())
+		})
+
+		It("should document the host of the server if it is known", func() {
+			Expect(swaggerHost("recipes.example.com:8080")).To(Equal("recipes.example.com:8080"))
+			Expect(swaggerHost(":8080")).To(BeEmpty())
+			Expect(swaggerHost("0.0.0.0:8080")).To(BeEmpty())
+		})
+
+		It("should apply custom timeouts", func() {
+			s := NewServerWithConfig(ServerConfig{Address: "localhost:0", ReadTimeout: time.Second, WriteTimeout: 2 * time.Second, IdleTimeout: 3 * time.Second}, NewHandler())
+			Expect(s.Address).To(Equal("localhost:0"))
+			Expect(s.server.ReadTimeout).To(Equal(time.Second))
+			Expect(s.server.WriteTimeout).To(Equal(2 * time.Second))
+			Expect(s.server.IdleTimeout).To(Equal(3 * time.Second))
+		})
+	})
+
+	Context("running the server", func() {
+		It("should return when the context is cancelled", func() {
+			s := NewServerA("localhost:0", NewHandler())
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			Expect(s.RunAndWait(ctx)).To(Succeed())
+		})
+
+		It("should report when the address is already in use", func() {
+			l, err := net.Listen("tcp", "localhost:0")
+			Expect(err).ToNot(HaveOccurred())
+			defer l.Close()
+
+			s := NewServerA(l.Addr().String(), NewHandler())
+			Expect(s.RunAndWait(context.Background())).ToNot(Succeed())
+		})
+
+		It("should cancel in-flight requests after the shutdown timeout", func() {
+			l, err := net.Listen("tcp", "localhost:0")
+			Expect(err).ToNot(HaveOccurred())
+			addr := l.Addr().String()
+			Expect(l.Close()).To(Succeed())
+
+			started, release := make(chan bool), make(chan bool)
+			defer close(release)
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				started <- true
+				<-release
+			})
+			s := NewServerWithConfig(ServerConfig{Address: addr, ShutdownTimeout: 50 * time.Millisecond}, handler)
+			s.Serve()
+
+			go func() {
+				// retry until the server listens; the request itself is cancelled by the shutdown
+				for {
+					if _, err := http.Get("http://" + addr); err == nil || !strings.Contains(err.Error(), "refused") {
+						return
+					}
+					time.Sleep(10 * time.Millisecond)
+				}
+			}()
+			Eventually(started).Should(Receive())
+
+			Expect(s.Close()).To(Equal(context.DeadlineExceeded))
+			Expect(*s.inFlight).To(Equal(int64(1)))
+		})
+	})
+
+	Context("HTTP/2", func() {
+
+		var (
+			addr   string
+			dir    string
+			server *Server
+		)
+
+		ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+
+		serve := func(config ServerConfig) {
+			config.Address = addr
+			s := NewServerWithConfig(config, ok)
+			s.Serve()
+			server = &s
+			Eventually(func() error {
+				conn, err := net.Dial("tcp", addr)
+				if err == nil {
+					conn.Close()
+				}
+				return err
+			}).Should(Succeed())
+		}
+
+		h2cClient := &http.Client{Transport: &http2.Transport{
+			AllowHTTP: true,
+			DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
+				return net.Dial(network, addr)
+			},
+		}}
+
+		BeforeEach(func() {
+			l, err := net.Listen("tcp", "localhost:0")
+			Expect(err).ToNot(HaveOccurred())
+			addr = l.Addr().String()
+			Expect(l.Close()).To(Succeed())
+
+			dir, err = ioutil.TempDir("", "http2")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			if server != nil {
+				server.Close()
+				server = nil
+			}
+			os.RemoveAll(dir)
+		})
+
+		It("should negotiate h2 via TLS", func() {
+			certFile, keyFile := writeSelfSignedCertificate(dir)
+			serve(ServerConfig{CertFile: certFile, KeyFile: keyFile})
+
+			conn, err := tls.Dial("tcp", addr, &tls.Config{InsecureSkipVerify: true, NextProtos: []string{"h2", "http/1.1"}})
+			Expect(err).ToNot(HaveOccurred())
+			defer conn.Close()
+			Expect(conn.ConnectionState().NegotiatedProtocol).To(Equal("h2"))
+		})
+
+		It("should serve cleartext HTTP/2 if h2c is enabled", func() {
+			serve(ServerConfig{H2C: true})
+
+			resp, err := h2cClient.Get("http://" + addr)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.Proto).To(Equal("HTTP/2.0"))
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			resp, err = http.Get("http://" + addr)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.Proto).To(Equal("HTTP/1.1"))
+		})
+
+		It("should serve cleartext connections with HTTP/1.1 only by default", func() {
+			serve(ServerConfig{})
+
+			_, err := h2cClient.Get("http://" + addr)
+			Expect(err).To(HaveOccurred())
+
+			resp, err := http.Get("http://" + addr)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		})
+	})
+
+	Context("swagger ui", func() {
+		var defaultEnabled bool
+
+		BeforeEach(func() {
+			defaultEnabled = docs.Enabled
+		})
+
+		AfterEach(func() {
+			docs.Enabled = defaultEnabled
+		})
+
+		serveDoc := func() int {
+			w := httptest.NewRecorder()
+			NewHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/swagger/doc.json", nil))
+			return w.Code
+		}
+
+		It("should be served if enabled", func() {
+			docs.Enabled = true
+			Expect(serveDoc()).To(Equal(http.StatusOK))
+		})
+
+		It("should not be served if disabled", func() {
+			docs.Enabled = false
+			Expect(serveDoc()).To(Equal(http.StatusNotFound))
+		})
 	})
 
 	Context("routes", func() {
@@ -63,5 +271,212 @@ var _ = Describe("http", func() {
 			Expect(v1.Path()).To(Equal("/api/v1"))
 			Expect(r.(*ginHandler).routerGroups).To(HaveKey("v1"))
 		})
+
+		It("should serve PUT and DELETE endpoints", func() {
+			r := NewHandler()
+			v1 := r.API(1)
+			v1.PUT("/test", func(c *APICallContext) { c.Status(http.StatusOK) })
+			v1.DELETE("/test", func(c *APICallContext) { c.Status(http.StatusOK) })
+
+			for _, method := range []string{http.MethodPut, http.MethodDelete} {
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, httptest.NewRequest(method, "/api/v1/test", nil))
+				Expect(w.Code).To(Equal(http.StatusOK))
+			}
+		})
+
+		It("should serve all api routes under the configured base path", func() {
+			defaultBasePath := apiBasePath
+			defer func() { apiBasePath = defaultBasePath }()
+			apiBasePath = normalizeBasePath("cook/api/")
+
+			r := NewHandler()
+			AddCoreAPIToHandler(r)
+			Expect(r.API(1).Path()).To(Equal("/cook/api/v1"))
+
+			for _, path := range []string{"/cook/api/v1/version", "/cook/api/v1/ready", "/cook/api/version"} {
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+				Expect(w.Code).To(Equal(http.StatusOK), path)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/version", nil))
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+		})
+
+		It("should redirect paths with a trailing slash or a wrong case", func() {
+			r := NewHandler()
+			r.API(1).GET("/test", func(c *APICallContext) { c.Status(http.StatusOK) })
+
+			for _, path := range []string{"/api/v1/test/", "/API/v1/Test"} {
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+				Expect(w.Code).To(Equal(http.StatusMovedPermanently), path)
+				Expect(w.Header().Get("Location")).To(Equal("/api/v1/test"), path)
+			}
+		})
+
+		It("should not redirect paths if disabled", func() {
+			defaultRedirect := redirectTrailingSlash
+			defer func() { redirectTrailingSlash = defaultRedirect }()
+			redirectTrailingSlash = false
+
+			r := NewHandler()
+			r.API(1).GET("/test", func(c *APICallContext) { c.Status(http.StatusOK) })
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/test/", nil))
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+		})
+
+		It("should serve versions at the root without a base path", func() {
+			defaultBasePath := apiBasePath
+			defer func() { apiBasePath = defaultBasePath }()
+			apiBasePath = normalizeBasePath("/")
+
+			r := NewHandler()
+			AddCoreAPIToHandler(r)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/version", nil))
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Header().Get("Vary")).ToNot(ContainSubstring(AcceptVersionHeader))
+		})
+	})
+
+	Context("api versions", func() {
+		var r Handler
+
+		get := func(path string, acceptVersion string) *httptest.ResponseRecorder {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			if acceptVersion != "" {
+				req.Header.Set(AcceptVersionHeader, acceptVersion)
+			}
+			r.ServeHTTP(w, req)
+			return w
+		}
+
+		BeforeEach(func() {
+			r = NewHandler()
+			r.API(1).GET("/test", func(c *APICallContext) { c.String(http.StatusOK, "v1") })
+		})
+
+		It("should serve unversioned paths with v1 by default", func() {
+			w := get("/api/test", "")
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Body.String()).To(Equal("v1"))
+			Expect(w.Header().Get("Vary")).To(ContainSubstring(AcceptVersionHeader))
+		})
+
+		It("should serve unversioned paths with the version of the Accept-Version header", func() {
+			r.API(2).GET("/test", func(c *APICallContext) { c.String(http.StatusOK, "v2") })
+
+			Expect(get("/api/test", "2").Body.String()).To(Equal("v2"))
+			Expect(get("/api/test", "v2").Body.String()).To(Equal("v2"))
+			Expect(get("/api/test", "invalid").Body.String()).To(Equal("v1"))
+			Expect(get("/api/test", "3").Code).To(Equal(http.StatusNotFound))
+		})
+
+		It("should not rewrite versioned paths", func() {
+			r.API(2).GET("/test", func(c *APICallContext) { c.String(http.StatusOK, "v2") })
+			Expect(get("/api/v1/test", "2").Body.String()).To(Equal("v1"))
+		})
+
+		It("should not mark the latest version as deprecated", func() {
+			Expect(get("/api/v1/test", "").Header().Get(DeprecationHeader)).To(BeEmpty())
+		})
+
+		It("should mark older versions as deprecated once a successor exists", func() {
+			r.API(2).GET("/test", func(c *APICallContext) { c.String(http.StatusOK, "v2") })
+
+			w := get("/api/v1/test", "")
+			Expect(w.Header().Get(DeprecationHeader)).To(Equal("true"))
+			Expect(w.Header().Get("Link")).To(Equal(`</api/v2>; rel="successor-version"`))
+			Expect(get("/api/v2/test", "").Header().Get(DeprecationHeader)).To(BeEmpty())
+		})
+	})
+
+	Context("request ids", func() {
+		var r Handler
+
+		BeforeEach(func() {
+			r = NewHandler()
+			r.API(1).GET("/test", func(c *APICallContext) {
+				c.String(http.StatusOK, "%v", LoggerFrom(c).Data["request_id"])
+			})
+		})
+
+		It("should propagate the client's request id", func() {
+			w := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodGet, "/api/v1/test", nil)
+			request.Header.Set(RequestIDHeader, "abc-123")
+			r.ServeHTTP(w, request)
+			Expect(w.Header().Get(RequestIDHeader)).To(Equal("abc-123"))
+			Expect(w.Body.String()).To(Equal("abc-123"))
+		})
+
+		It("should generate a request id if none is given", func() {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/test", nil))
+			Expect(w.Header().Get(RequestIDHeader)).ToNot(BeEmpty())
+			Expect(w.Body.String()).To(Equal(w.Header().Get(RequestIDHeader)))
+		})
+
+		It("should replace invalid request ids", func() {
+			w := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodGet, "/api/v1/test", nil)
+			request.Header.Set(RequestIDHeader, "invalid id")
+			r.ServeHTTP(w, request)
+			Expect(w.Header().Get(RequestIDHeader)).ToNot(Equal("invalid id"))
+		})
+	})
+
+	Context("cors", func() {
+		It("should allow all CRUD methods in preflight requests", func() {
+			r := NewHandler()
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodOptions, "/api/v1/test", nil))
+			Expect(w.Code).To(Equal(http.StatusNoContent))
+			Expect(w.Header().Get("Access-Control-Allow-Methods")).To(Equal("GET, PATCH, POST, PUT, DELETE"))
+		})
+
+		It("should use the wildcard origin by default", func() {
+			Expect(allowedOrigin("http://localhost")).To(Equal("*"))
+		})
+
+		It("should echo one of multiple configured origins", func() {
+			defaultOrigins := corsOrigins
+			defer func() { corsOrigins = defaultOrigins }()
+
+			corsOrigins = splitList("http://a.example.com, http://b.example.com")
+
+			Expect(allowedOrigin("http://b.example.com")).To(Equal("http://b.example.com"))
+			Expect(allowedOrigin("http://c.example.com")).To(BeEmpty())
+		})
 	})
 })
+
+//writeSelfSignedCertificate for localhost to dir and return the paths of the certificate and the key
+func writeSelfSignedCertificate(dir string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).ToNot(HaveOccurred())
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Expect(err).ToNot(HaveOccurred())
+	keyBytes, err := x509.MarshalECPrivateKey(key)
+	Expect(err).ToNot(HaveOccurred())
+
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	Expect(ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert}), 0600)).To(Succeed())
+	Expect(ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyBytes}), 0600)).To(Succeed())
+	return certFile, keyFile
+}
@@ -0,0 +1,180 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/text/language"
+
+	"github.com/ottenwbe/recipes-manager/utils"
+)
+
+const (
+	i18nDirCfg = "i18n.dir"
+	// languageKey caches the index of the language of a call in its context
+	languageKey = "language"
+)
+
+var (
+	// translations of messages into the languages of the bundles in the configured directory
+	translations *Translations
+)
+
+func init() {
+	utils.Config.SetDefault(i18nDirCfg, "i18n")
+	var err error
+	if translations, err = LoadTranslations(utils.Config.GetString(i18nDirCfg)); err != nil {
+		log.WithError(err).Error("Could not load translations, messages are not translated")
+		translations = NewTranslations(nil)
+	}
+}
+
+// Translations of messages and unit names. Keys are the English messages and the canonical unit names, e.g., "No such recipe" or "tbsp".
+// English needs no bundle; keys which are missing in a bundle are not translated.
+type Translations struct {
+	// bundles map keys to translated messages; the first bundle is English
+	bundles []map[string]string
+	matcher language.Matcher
+}
+
+// NewTranslations with bundles by their language, e.g., "de"
+func NewTranslations(bundles map[string]map[string]string) *Translations {
+	t := &Translations{bundles: []map[string]string{{}}}
+	tags := []language.Tag{language.English}
+	for lang, bundle := range bundles {
+		tag, err := language.Parse(lang)
+		if err != nil {
+			log.WithError(err).WithField("language", lang).Warn("Ignoring bundle of unknown language")
+			continue
+		}
+		if tag == language.English {
+			t.bundles[0] = bundle
+			continue
+		}
+		tags = append(tags, tag)
+		t.bundles = append(t.bundles, bundle)
+	}
+	t.matcher = language.NewMatcher(tags)
+	return t
+}
+
+// LoadTranslations reads all bundles of a directory. Bundles are JSON objects mapping keys to messages; the name of a bundle is its language, e.g., de.json.
+// A missing directory results in English messages only.
+func LoadTranslations(dir string) (*Translations, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	bundles := make(map[string]map[string]string)
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		bundle := make(map[string]string)
+		if err = json.Unmarshal(data, &bundle); err != nil {
+			return nil, fmt.Errorf("invalid bundle %v: %w", file, err)
+		}
+		bundles[strings.TrimSuffix(filepath.Base(file), ".json")] = bundle
+	}
+	return NewTranslations(bundles), nil
+}
+
+// Translate a key into the language which matches the Accept-Language header best; unsupported languages fall back to English
+func (t *Translations) Translate(acceptLanguage string, key string) string {
+	return t.translate(t.match(acceptLanguage), key)
+}
+
+// Untranslate finds the key of a message in the language which matches the Accept-Language header best, e.g., to read unit names of clients.
+// Messages which are not found are returned as they are.
+func (t *Translations) Untranslate(acceptLanguage string, message string) string {
+	return t.untranslate(t.match(acceptLanguage), message)
+}
+
+// match returns the index of the bundle whose language matches the Accept-Language header best
+func (t *Translations) match(acceptLanguage string) int {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return 0
+	}
+	_, index, confidence := t.matcher.Match(tags...)
+	if confidence == language.No {
+		return 0
+	}
+	return index
+}
+
+func (t *Translations) translate(index int, key string) string {
+	if translated, ok := t.bundles[index][key]; ok {
+		return translated
+	}
+	if translated, ok := t.bundles[0][key]; ok {
+		return translated
+	}
+	return key
+}
+
+func (t *Translations) untranslate(index int, message string) string {
+	for key, translated := range t.bundles[index] {
+		if translated == message {
+			return key
+		}
+	}
+	return message
+}
+
+// T translates a key into the language requested by the Accept-Language header of a call
+func T(c *APICallContext, key string) string {
+	return translations.translate(callLanguage(c), key)
+}
+
+// Untranslate finds the key of a message in the language requested by the Accept-Language header of a call
+func Untranslate(c *APICallContext, message string) string {
+	return translations.untranslate(callLanguage(c), message)
+}
+
+// Localized marks the response of a call as translated, so that caches keep the responses for different languages apart
+func Localized(c *APICallContext) {
+	callLanguage(c)
+}
+
+// callLanguage returns the index of the bundle matching the call; the index is cached in the call's context.
+// Since the response depends on the language, the response varies by the Accept-Language header.
+func callLanguage(c *APICallContext) int {
+	if index, ok := c.Get(languageKey); ok {
+		return index.(int)
+	}
+	c.Writer.Header().Add("Vary", "Accept-Language")
+	index := translations.match(c.GetHeader("Accept-Language"))
+	c.Set(languageKey, index)
+	return index
+}
@@ -0,0 +1,116 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package core
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("i18n", func() {
+
+	var german = NewTranslations(map[string]map[string]string{
+		"de": {"No such thing": "Nicht gefunden", "tbsp": "EL"},
+	})
+
+	Context("translations", func() {
+		It("translates into the best matching language", func() {
+			Expect(german.Translate("fr;q=0.9, de-AT;q=0.8", "No such thing")).To(Equal("Nicht gefunden"))
+		})
+		It("falls back to English", func() {
+			Expect(german.Translate("fr", "No such thing")).To(Equal("No such thing"))
+			Expect(german.Translate("", "No such thing")).To(Equal("No such thing"))
+			Expect(german.Translate("not a language", "No such thing")).To(Equal("No such thing"))
+			Expect(german.Translate("de", "Unauthorized")).To(Equal("Unauthorized"))
+		})
+		It("finds the key of a translated message", func() {
+			Expect(german.Untranslate("de", "EL")).To(Equal("tbsp"))
+			Expect(german.Untranslate("de", "Prise")).To(Equal("Prise"))
+			Expect(german.Untranslate("en", "EL")).To(Equal("EL"))
+		})
+	})
+
+	Context("bundles", func() {
+		var dir string
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "i18n")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			_ = os.RemoveAll(dir)
+		})
+
+		It("are named after their language", func() {
+			Expect(ioutil.WriteFile(filepath.Join(dir, "de.json"), []byte(`{"Unauthorized": "Nicht autorisiert"}`), 0600)).To(Succeed())
+
+			t, err := LoadTranslations(dir)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(t.Translate("de", "Unauthorized")).To(Equal("Nicht autorisiert"))
+		})
+		It("are optional", func() {
+			t, err := LoadTranslations(filepath.Join(dir, "missing"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(t.Translate("de", "Unauthorized")).To(Equal("Unauthorized"))
+		})
+		It("have to be valid JSON", func() {
+			Expect(ioutil.WriteFile(filepath.Join(dir, "de.json"), []byte(`{"Unauthorized"`), 0600)).To(Succeed())
+
+			_, err := LoadTranslations(dir)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	It("translates the messages of errors", func() {
+		defaultTranslations := translations
+		translations = german
+		defer func() { translations = defaultTranslations }()
+
+		handler := NewHandler()
+		handler.API(1).GET("/fail", func(c *APICallContext) {
+			AbortWithAPIError(c, http.StatusNotFound, "No such thing", "42")
+		})
+
+		w := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, "/api/v1/fail", nil)
+		request.Header.Set("Accept-Language", "de-DE")
+		handler.ServeHTTP(w, request)
+
+		var apiError APIError
+		Expect(json.NewDecoder(w.Body).Decode(&apiError)).To(Succeed())
+		Expect(apiError.Message).To(Equal("Nicht gefunden"))
+		Expect(apiError.Detail).To(Equal("42"))
+		Expect(w.Header()["Vary"]).To(ContainElement("Accept-Language"))
+	})
+})
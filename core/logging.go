@@ -25,23 +25,57 @@
 package core
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/ottenwbe/recipes-manager/utils"
 	log "github.com/sirupsen/logrus"
 )
 
 const (
-	logLevelCFG = "log.level"
+	logLevelCFG  = "log.level"
+	logFormatCFG = "log.format"
+)
+
+const (
+	textLogFormat = "text"
+	jsonLogFormat = "json"
 )
 
 func init() {
 	utils.Config.SetDefault(logLevelCFG, "info")
+	utils.Config.SetDefault(logFormatCFG, textLogFormat)
+
+	configureLogger(log.StandardLogger(), utils.Config.GetString(logLevelCFG), utils.Config.GetString(logFormatCFG))
+}
 
-	logLevelStr := utils.Config.GetString(logLevelCFG)
+// configureLogger applies the level and the format to the logger.
+// The request logging middleware writes to the standard logger and, hence, uses the same format.
+// Invalid levels or formats are reported and fall back to info or text, respectively.
+func configureLogger(logger *log.Logger, levelStr string, format string) {
+	formatter, err := logFormatter(format)
+	if err != nil {
+		logger.WithField("format", format).Warn("Invalid log format, falling back to text")
+	}
+	logger.SetFormatter(formatter)
 
-	level, err := log.ParseLevel(logLevelStr)
+	level, err := log.ParseLevel(levelStr)
 	if err != nil {
+		logger.WithField("level", levelStr).Warn("Invalid log level, falling back to info")
 		level = log.InfoLevel
 	}
-	log.SetLevel(level)
+	logger.SetLevel(level)
+}
 
+// logFormatter returns the formatter for a format, i.e., text or json.
+// Unknown formats result in the text formatter and an error.
+func logFormatter(format string) (log.Formatter, error) {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case textLogFormat:
+		return &log.TextFormatter{}, nil
+	case jsonLogFormat:
+		return &log.JSONFormatter{}, nil
+	default:
+		return &log.TextFormatter{}, fmt.Errorf("unknown log format %q", format)
+	}
 }
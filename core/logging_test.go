@@ -0,0 +1,85 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	log "github.com/sirupsen/logrus"
+)
+
+var _ = Describe("Logging", func() {
+
+	var (
+		logger *log.Logger
+		out    *bytes.Buffer
+	)
+
+	BeforeEach(func() {
+		out = &bytes.Buffer{}
+		logger = log.New()
+		logger.SetOutput(out)
+	})
+
+	Context("Format", func() {
+		It("writes text by default", func() {
+			configureLogger(logger, "info", "text")
+			logger.Info("hello")
+			Expect(out.String()).To(ContainSubstring("msg=hello"))
+		})
+
+		It("writes json if configured", func() {
+			configureLogger(logger, "info", "JSON")
+			logger.WithField("key", "value").Info("hello")
+
+			var entry map[string]interface{}
+			Expect(json.Unmarshal(out.Bytes(), &entry)).To(Succeed())
+			Expect(entry["msg"]).To(Equal("hello"))
+			Expect(entry["key"]).To(Equal("value"))
+		})
+
+		It("falls back to text for unknown formats", func() {
+			configureLogger(logger, "info", "xml")
+			Expect(out.String()).To(ContainSubstring("Invalid log format"))
+			Expect(logger.Formatter).To(BeAssignableToTypeOf(&log.TextFormatter{}))
+		})
+	})
+
+	Context("Level", func() {
+		It("applies valid levels", func() {
+			configureLogger(logger, "debug", "text")
+			Expect(logger.GetLevel()).To(Equal(log.DebugLevel))
+		})
+
+		It("warns and falls back to info for invalid levels", func() {
+			configureLogger(logger, "verbose", "text")
+			Expect(logger.GetLevel()).To(Equal(log.InfoLevel))
+			Expect(out.String()).To(ContainSubstring("Invalid log level"))
+		})
+	})
+})
@@ -0,0 +1,79 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package core
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("metrics", func() {
+
+	It("should count requests by route template", func() {
+		r := NewHandler()
+		r.API(1).GET("/test/:id", func(c *APICallContext) { c.Status(http.StatusOK) })
+
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/test/1", nil))
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/test/2", nil))
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Body.String()).To(ContainSubstring(`recipes_manager_http_requests_total{method="GET",path="/api/v1/test/:id",status="200"} 2`))
+		Expect(w.Body.String()).To(ContainSubstring(`recipes_manager_http_request_duration_seconds_count{method="GET",path="/api/v1/test/:id"} 2`))
+	})
+
+	It("should not use the raw url of unknown routes", func() {
+		r := NewHandler()
+
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/unknown/1", nil))
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+		Expect(w.Body.String()).To(ContainSubstring(`path="unmatched"`))
+		Expect(w.Body.String()).ToNot(ContainSubstring("/unknown/1"))
+	})
+
+	It("should expose registered metrics", func() {
+		r := NewHandler()
+		counter := prometheus.NewCounter(prometheus.CounterOpts{Namespace: MetricsNamespace(), Name: "test_total", Help: "Test counter."})
+		counter.Inc()
+
+		Expect(r.RegisterMetrics(counter)).To(Succeed())
+		Expect(r.RegisterMetrics(counter)).ToNot(Succeed())
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+		Expect(w.Body.String()).To(ContainSubstring("recipes_manager_test_total 1"))
+	})
+})
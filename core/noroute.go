@@ -0,0 +1,88 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package core
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// notFoundHandler responds to unknown paths with an APIError instead of gin's plain text, so that clients can handle all errors alike
+func notFoundHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		AbortWithErrorCode(c, http.StatusNotFound, ErrorNotFound, "Not found", c.Request.URL.Path)
+	}
+}
+
+// methodNotAllowedHandler responds to known paths requested with an unsupported method with an APIError
+// and lists the supported methods in the Allow header
+func methodNotAllowedHandler(routes func() gin.RoutesInfo) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		allowed := allowedMethods(routes(), c.Request.URL.Path)
+		c.Header("Allow", strings.Join(allowed, ", "))
+		AbortWithErrorCode(c, http.StatusMethodNotAllowed, ErrorMethodNotAllowed, "Method not allowed", c.Request.Method+" "+c.Request.URL.Path)
+	}
+}
+
+// allowedMethods returns the sorted methods of all routes matching the path
+func allowedMethods(routes gin.RoutesInfo, path string) []string {
+	methods := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, route := range routes {
+		if !seen[route.Method] && routeMatches(route.Path, path) {
+			seen[route.Method] = true
+			methods = append(methods, route.Method)
+		}
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// routeMatches checks if a path matches the pattern of a route, i.e., /recipes/r/42 matches /recipes/r/:recipe and /swagger/index.html matches /swagger/*any
+func routeMatches(pattern string, path string) bool {
+	patternSegments := strings.Split(pattern, "/")
+	pathSegments := strings.Split(path, "/")
+	for i, segment := range patternSegments {
+		if strings.HasPrefix(segment, "*") {
+			return true
+		}
+		if i >= len(pathSegments) {
+			return false
+		}
+		if strings.HasPrefix(segment, ":") {
+			if pathSegments[i] == "" {
+				return false
+			}
+			continue
+		}
+		if segment != pathSegments[i] {
+			return false
+		}
+	}
+	return len(patternSegments) == len(pathSegments)
+}
@@ -0,0 +1,104 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package core
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("unknown routes", func() {
+
+	var handler Handler
+
+	serve := func(method string, path string) (*httptest.ResponseRecorder, APIError) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		var apiError APIError
+		_ = json.NewDecoder(w.Body).Decode(&apiError)
+		return w, apiError
+	}
+
+	BeforeEach(func() {
+		handler = NewHandler()
+		handler.API(1).GET("/recipes/r/:recipe", func(c *APICallContext) { c.Status(http.StatusOK) })
+		handler.API(1).Secured().DELETE("/recipes/r/:recipe", func(c *APICallContext) { c.Status(http.StatusOK) })
+		handler.API(1).POST("/recipes", func(c *APICallContext) { c.Status(http.StatusOK) })
+	})
+
+	It("are answered with an APIError", func() {
+		w, apiError := serve(http.MethodGet, "/api/v1/recipess")
+
+		Expect(w.Code).To(Equal(http.StatusNotFound))
+		Expect(w.Header().Get("Content-Type")).To(HavePrefix("application/json"))
+		Expect(apiError).To(Equal(APIError{Code: http.StatusNotFound, ErrorCode: "not_found", Message: "Not found", Detail: "/api/v1/recipess"}))
+	})
+
+	It("list the allowed methods of known paths", func() {
+		w, apiError := serve(http.MethodPut, "/api/v1/recipes/r/42")
+
+		Expect(w.Code).To(Equal(http.StatusMethodNotAllowed))
+		Expect(w.Header().Get("Allow")).To(Equal("DELETE, GET"))
+		Expect(apiError).To(Equal(APIError{Code: http.StatusMethodNotAllowed, ErrorCode: "method_not_allowed", Message: "Method not allowed", Detail: "PUT /api/v1/recipes/r/42"}))
+	})
+
+	It("still answer preflight requests", func() {
+		w, _ := serve(http.MethodOptions, "/api/v1/recipes")
+		Expect(w.Code).To(Equal(http.StatusNoContent))
+	})
+
+	It("are not found if methods are not checked", func() {
+		defaultMethodNotAllowed := methodNotAllowed
+		defer func() { methodNotAllowed = defaultMethodNotAllowed }()
+		methodNotAllowed = false
+		handler = NewHandler()
+		handler.API(1).POST("/recipes", func(c *APICallContext) { c.Status(http.StatusOK) })
+
+		w, apiError := serve(http.MethodGet, "/api/v1/recipes")
+
+		Expect(w.Code).To(Equal(http.StatusNotFound))
+		Expect(apiError.Code).To(Equal(http.StatusNotFound))
+		Expect(apiError.ErrorCode).To(Equal(ErrorNotFound))
+	})
+
+	It("match the patterns of routes", func() {
+		routes := gin.RoutesInfo{
+			{Method: http.MethodGet, Path: "/api/v1/recipes/r/:recipe"},
+			{Method: http.MethodGet, Path: "/swagger/*any"},
+			{Method: http.MethodPost, Path: "/api/v1/recipes/r/:recipe/restore"},
+		}
+
+		Expect(allowedMethods(routes, "/api/v1/recipes/r/42")).To(Equal([]string{http.MethodGet}))
+		Expect(allowedMethods(routes, "/api/v1/recipes/r/42/restore")).To(Equal([]string{http.MethodPost}))
+		Expect(allowedMethods(routes, "/swagger/index.html")).To(Equal([]string{http.MethodGet}))
+		Expect(allowedMethods(routes, "/api/v1/recipes/r/")).To(BeEmpty())
+		Expect(allowedMethods(routes, "/api/v1/recipes")).To(BeEmpty())
+	})
+})
@@ -0,0 +1,157 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package core
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ottenwbe/recipes-manager/utils"
+)
+
+const (
+	rateLimitRPSCfg   = "html.ratelimit.rps"
+	rateLimitBurstCfg = "html.ratelimit.burst"
+)
+
+var (
+	rateLimitRPS   float64
+	rateLimitBurst int
+)
+
+func init() {
+	utils.Config.SetDefault(rateLimitRPSCfg, 0)
+	utils.Config.SetDefault(rateLimitBurstCfg, 20)
+	rateLimitRPS = utils.Config.GetFloat64(rateLimitRPSCfg)
+	rateLimitBurst = int(utils.Config.GetInt64(rateLimitBurstCfg))
+}
+
+// rateLimitCleanupInterval defines how often buckets of idle clients are dropped
+const rateLimitCleanupInterval = time.Minute
+
+// tokenBucket holds the tokens of one client; tokens are refilled continuously
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter manages one token bucket per client
+type rateLimiter struct {
+	rps         float64
+	burst       float64
+	buckets     map[string]*tokenBucket
+	lastCleanup time.Time
+	now         func() time.Time
+	mtx         sync.Mutex
+}
+
+// newRateLimiter allows rps requests per second and bursts of up to burst requests per client
+func newRateLimiter(rps float64, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{
+		rps:     rps,
+		burst:   float64(burst),
+		buckets: make(map[string]*tokenBucket),
+		now:     time.Now,
+	}
+}
+
+// allow takes a token from the bucket of a client. If the bucket is empty, the time until the next token is available is returned.
+func (l *rateLimiter) allow(client string) (bool, time.Duration) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	now := l.now()
+	l.cleanup(now)
+
+	bucket, ok := l.buckets[client]
+	if !ok {
+		bucket = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[client] = bucket
+	}
+	bucket.tokens = l.refill(bucket, now)
+	bucket.last = now
+
+	if bucket.tokens < 1 {
+		return false, time.Duration((1 - bucket.tokens) / l.rps * float64(time.Second))
+	}
+	bucket.tokens--
+	return true, 0
+}
+
+func (l *rateLimiter) refill(bucket *tokenBucket, now time.Time) float64 {
+	return math.Min(l.burst, bucket.tokens+now.Sub(bucket.last).Seconds()*l.rps)
+}
+
+// cleanup drops the buckets of clients which have been idle long enough to be full again
+func (l *rateLimiter) cleanup(now time.Time) {
+	if now.Sub(l.lastCleanup) < rateLimitCleanupInterval {
+		return
+	}
+	l.lastCleanup = now
+	for client, bucket := range l.buckets {
+		if l.refill(bucket, now) >= l.burst {
+			delete(l.buckets, client)
+		}
+	}
+}
+
+// rateLimitMiddleware rejects requests of clients which exceed their rate with 429 Too Many Requests.
+// Clients are identified by the IP of their connection; headers like X-Forwarded-For are ignored, since clients can choose them freely.
+// The middleware does nothing if rps is 0.
+func rateLimitMiddleware(rps float64, burst int) gin.HandlerFunc {
+	if rps <= 0 {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	limiter := newRateLimiter(rps, burst)
+	return func(c *gin.Context) {
+		if ok, wait := limiter.allow(remoteHost(c.Request)); !ok {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			AbortWithAPIError(c, http.StatusTooManyRequests, "Too many requests", "")
+			return
+		}
+		c.Next()
+	}
+}
+
+// remoteHost is the IP of the connection of a request without the port
+func remoteHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,145 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package core
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("rate limiting", func() {
+
+	Context("token buckets", func() {
+
+		var (
+			limiter *rateLimiter
+			now     time.Time
+		)
+
+		BeforeEach(func() {
+			now = time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+			limiter = newRateLimiter(2, 3)
+			limiter.now = func() time.Time { return now }
+		})
+
+		It("allows bursts up to the burst size", func() {
+			for i := 0; i < 3; i++ {
+				ok, _ := limiter.allow("client")
+				Expect(ok).To(BeTrue())
+			}
+			ok, wait := limiter.allow("client")
+			Expect(ok).To(BeFalse())
+			Expect(wait).To(Equal(500 * time.Millisecond))
+		})
+
+		It("refills tokens over time", func() {
+			for i := 0; i < 3; i++ {
+				limiter.allow("client")
+			}
+			now = now.Add(500 * time.Millisecond)
+			ok, _ := limiter.allow("client")
+			Expect(ok).To(BeTrue())
+		})
+
+		It("limits each client on its own", func() {
+			for i := 0; i < 3; i++ {
+				limiter.allow("client")
+			}
+			ok, _ := limiter.allow("other")
+			Expect(ok).To(BeTrue())
+		})
+
+		It("drops the buckets of idle clients", func() {
+			limiter.allow("client")
+			now = now.Add(2 * rateLimitCleanupInterval)
+			limiter.allow("other")
+			Expect(limiter.buckets).To(HaveLen(1))
+			Expect(limiter.buckets).To(HaveKey("other"))
+		})
+	})
+
+	Context("middleware", func() {
+
+		var (
+			defaultRPS   float64
+			defaultBurst int
+		)
+
+		BeforeEach(func() {
+			defaultRPS, defaultBurst = rateLimitRPS, rateLimitBurst
+		})
+
+		AfterEach(func() {
+			rateLimitRPS, rateLimitBurst = defaultRPS, defaultBurst
+		})
+
+		request := func(r Handler, forwardedFor ...string) *httptest.ResponseRecorder {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/limited", nil)
+			for _, ip := range forwardedFor {
+				req.Header.Set("X-Forwarded-For", ip)
+			}
+			r.ServeHTTP(w, req)
+			return w
+		}
+
+		newHandler := func() Handler {
+			r := NewHandler()
+			r.API(1).GET("/limited", func(c *APICallContext) { c.String(http.StatusOK, "ok") })
+			return r
+		}
+
+		It("rejects clients exceeding their rate with 429 and Retry-After", func() {
+			rateLimitRPS, rateLimitBurst = 0.5, 1
+			r := newHandler()
+
+			Expect(request(r).Code).To(Equal(http.StatusOK))
+			w := request(r)
+			Expect(w.Code).To(Equal(http.StatusTooManyRequests))
+			Expect(w.Header().Get("Retry-After")).To(Equal("2"))
+		})
+
+		It("limits clients which spoof X-Forwarded-For", func() {
+			rateLimitRPS, rateLimitBurst = 0.5, 1
+			r := newHandler()
+
+			Expect(request(r, "10.0.0.1").Code).To(Equal(http.StatusOK))
+			Expect(request(r, "10.0.0.2").Code).To(Equal(http.StatusTooManyRequests))
+		})
+
+		It("is disabled if rps is 0", func() {
+			rateLimitRPS, rateLimitBurst = 0, 1
+			r := newHandler()
+
+			for i := 0; i < 5; i++ {
+				Expect(request(r).Code).To(Equal(http.StatusOK))
+			}
+		})
+	})
+})
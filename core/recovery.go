@@ -0,0 +1,97 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ottenwbe/recipes-manager/utils"
+)
+
+const (
+	debugCfg = "html.debug"
+)
+
+var (
+	// debugResponses adds the cause and the stack trace of a panic to the response
+	debugResponses bool
+)
+
+func init() {
+	utils.Config.SetDefault(debugCfg, false)
+	debugResponses = utils.Config.GetBool(debugCfg)
+}
+
+// recoveryMiddleware responds with 500 and an APIError if a handler panics. The stack trace is logged, but only sent to clients in debug mode.
+func recoveryMiddleware(debugMode bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if err, ok := recovered.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				// the server aborts the response silently
+				panic(recovered)
+			}
+
+			stack := debug.Stack()
+			LoggerFrom(c).WithField("panic", recovered).WithField("stack", string(stack)).Error("Recovered from panic")
+
+			if brokenConnection(recovered) || c.Writer.Written() {
+				c.Abort()
+				return
+			}
+
+			apiError := &APIError{
+				Code:      http.StatusInternalServerError,
+				Message:   T(c, "Internal server error"),
+				RequestID: c.Writer.Header().Get(RequestIDHeader),
+			}
+			if debugMode {
+				apiError.Detail = fmt.Sprintf("%v\n%s", recovered, stack)
+			}
+			c.Abort()
+			RespondJSON(c, http.StatusInternalServerError, apiError)
+		}()
+		c.Next()
+	}
+}
+
+// brokenConnection checks if a panic was caused by a client that closed the connection, so that no response can be sent
+func brokenConnection(recovered interface{}) bool {
+	err, ok := recovered.(error)
+	if !ok {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "broken pipe") || strings.Contains(msg, "connection reset by peer")
+}
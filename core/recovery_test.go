@@ -0,0 +1,92 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("recovery", func() {
+
+	var (
+		r            Handler
+		defaultDebug bool
+		get          func() (*httptest.ResponseRecorder, APIError)
+	)
+
+	BeforeEach(func() {
+		defaultDebug = debugResponses
+
+		get = func() (*httptest.ResponseRecorder, APIError) {
+			r = NewHandler()
+			r.API(1).GET("/panic", func(c *APICallContext) { panic(errors.New("secret internals")) })
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/panic", nil)
+			req.Header.Set(RequestIDHeader, "panic-request")
+			r.ServeHTTP(w, req)
+
+			var apiError APIError
+			Expect(json.NewDecoder(w.Body).Decode(&apiError)).To(Succeed())
+			return w, apiError
+		}
+	})
+
+	AfterEach(func() {
+		debugResponses = defaultDebug
+	})
+
+	It("responds with an APIError and the request id", func() {
+		w, apiError := get()
+		Expect(w.Code).To(Equal(http.StatusInternalServerError))
+		Expect(w.Header().Get("Content-Type")).To(HavePrefix("application/json"))
+		Expect(apiError.Code).To(Equal(http.StatusInternalServerError))
+		Expect(apiError.RequestID).To(Equal("panic-request"))
+	})
+
+	It("does not leak the cause of the panic", func() {
+		_, apiError := get()
+		Expect(apiError.Detail).To(BeEmpty())
+	})
+
+	It("adds the cause and the stack trace in debug mode", func() {
+		debugResponses = true
+		_, apiError := get()
+		Expect(apiError.Detail).To(HavePrefix("secret internals\n"))
+		Expect(apiError.Detail).To(ContainSubstring("recovery_test.go"))
+	})
+
+	It("detects connections closed by the client", func() {
+		Expect(brokenConnection(errors.New("write tcp: broken pipe"))).To(BeTrue())
+		Expect(brokenConnection("broken pipe")).To(BeFalse())
+		Expect(brokenConnection(errors.New("secret internals"))).To(BeFalse())
+	})
+})
@@ -0,0 +1,100 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package core
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin/binding"
+	"gopkg.in/yaml.v2"
+
+	"github.com/ottenwbe/recipes-manager/utils"
+)
+
+// MIMEYAML is the content type of YAML responses
+const MIMEYAML = "application/yaml"
+
+const (
+	prettyJSONCfg = "html.json.pretty"
+	// prettyParam overrides html.json.pretty for a single call, i.e., ?pretty=true or ?pretty=false
+	prettyParam = "pretty"
+)
+
+var (
+	// prettyJSON indents JSON responses, e.g., to read them while debugging
+	prettyJSON bool
+)
+
+func init() {
+	utils.Config.SetDefault(prettyJSONCfg, false)
+	prettyJSON = utils.Config.GetBool(prettyJSONCfg)
+}
+
+// Respond encodes obj as YAML if the client accepts application/yaml and as JSON otherwise, i.e., for */* or application/json
+func Respond(c *APICallContext, code int, obj interface{}) {
+	c.Writer.Header().Add("Vary", "Accept")
+	switch c.NegotiateFormat(binding.MIMEJSON, MIMEYAML, binding.MIMEYAML) {
+	case MIMEYAML, binding.MIMEYAML:
+		respondYAML(c, code, obj)
+	default:
+		RespondJSON(c, code, obj)
+	}
+}
+
+// RespondJSON encodes obj as JSON, which is indented if html.json.pretty is set or the client asks for it with ?pretty=true
+func RespondJSON(c *APICallContext, code int, obj interface{}) {
+	if wantsPrettyJSON(c) {
+		c.IndentedJSON(code, obj)
+	} else {
+		c.JSON(code, obj)
+	}
+}
+
+// wantsPrettyJSON checks the pretty query parameter and falls back to html.json.pretty if the parameter is missing or no boolean
+func wantsPrettyJSON(c *APICallContext) bool {
+	if pretty, err := strconv.ParseBool(c.Query(prettyParam)); err == nil {
+		return pretty
+	}
+	return prettyJSON
+}
+
+// respondYAML converts obj via JSON, so that YAML responses have the same field names as JSON responses
+func respondYAML(c *APICallContext, code int, obj interface{}) {
+	doc, err := json.Marshal(obj)
+	if err == nil {
+		var value interface{}
+		// JSON is valid YAML
+		if err = yaml.Unmarshal(doc, &value); err == nil {
+			doc, err = yaml.Marshal(value)
+		}
+	}
+	if err != nil {
+		AbortWithAPIError(c, http.StatusInternalServerError, "Could not encode YAML response", err.Error())
+		return
+	}
+	c.Data(code, MIMEYAML+"; charset=utf-8", doc)
+}
@@ -0,0 +1,123 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package core
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("content negotiation", func() {
+
+	type item struct {
+		Name   string   `json:"name"`
+		Amount float32  `json:"amount"`
+		Tags   []string `json:"tags"`
+	}
+
+	var (
+		r   Handler
+		get func(accept string) *httptest.ResponseRecorder
+	)
+
+	BeforeEach(func() {
+		r = NewHandler()
+		r.API(1).GET("/item", func(c *APICallContext) {
+			Respond(c, http.StatusOK, item{Name: "salt", Amount: 1.5, Tags: []string{"spice"}})
+		})
+
+		get = func(accept string) *httptest.ResponseRecorder {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/item", nil)
+			if accept != "" {
+				req.Header.Set("Accept", accept)
+			}
+			r.ServeHTTP(w, req)
+			return w
+		}
+	})
+
+	It("responds with YAML if the client accepts it", func() {
+		w := get(MIMEYAML)
+
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Header().Get("Content-Type")).To(HavePrefix(MIMEYAML))
+		Expect(w.Header()["Vary"]).To(ContainElement("Accept"))
+		Expect(w.Body.String()).To(Equal("amount: 1.5\nname: salt\ntags:\n- spice\n"))
+	})
+
+	It("responds with YAML for the legacy YAML content type", func() {
+		Expect(get("application/x-yaml").Body.String()).To(ContainSubstring("name: salt"))
+	})
+
+	It("responds with JSON by default", func() {
+		for _, accept := range []string{"", "*/*", "application/json", "text/html"} {
+			w := get(accept)
+			Expect(w.Header().Get("Content-Type")).To(HavePrefix("application/json"), accept)
+			Expect(w.Body.String()).To(MatchJSON(`{"name":"salt","amount":1.5,"tags":["spice"]}`), accept)
+		}
+	})
+
+	Context("pretty JSON", func() {
+
+		var defaultPretty bool
+
+		BeforeEach(func() {
+			defaultPretty = prettyJSON
+			r.API(1).GET("/missing", func(c *APICallContext) {
+				AbortWithAPIError(c, http.StatusNotFound, "No such item", "salt")
+			})
+		})
+
+		AfterEach(func() {
+			prettyJSON = defaultPretty
+		})
+
+		request := func(path string) string {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+			return w.Body.String()
+		}
+
+		It("is compact by default", func() {
+			Expect(request("/api/v1/item")).To(Equal(`{"name":"salt","amount":1.5,"tags":["spice"]}`))
+		})
+
+		It("is indented if the client asks for it", func() {
+			Expect(request("/api/v1/item?pretty=true")).To(Equal("{\n    \"name\": \"salt\",\n    \"amount\": 1.5,\n    \"tags\": [\n        \"spice\"\n    ]\n}"))
+			Expect(request("/api/v1/missing?pretty=1")).To(HavePrefix("{\n    \"code\": 404,"))
+		})
+
+		It("is indented if configured unless the client asks for compact JSON", func() {
+			prettyJSON = true
+			Expect(request("/api/v1/item")).To(HavePrefix("{\n"))
+			Expect(request("/api/v1/item?pretty=invalid")).To(HavePrefix("{\n"))
+			Expect(request("/api/v1/item?pretty=false")).To(Equal(`{"name":"salt","amount":1.5,"tags":["spice"]}`))
+		})
+	})
+})
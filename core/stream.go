@@ -0,0 +1,171 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Stream is a long-lived response, e.g., a stream of server-sent events.
+// A stream of a HTTP/1.x request takes over the connection, so that it is not closed by the write timeout of the server.
+// Other streams, i.e., of HTTP/2 requests, are written like all responses and end with the write timeout; clients have to reconnect.
+// All streams are done when the Server is closed, since the server does not wait for connections which were taken over.
+type Stream struct {
+	writer io.Writer
+	flush  func() error
+	done   <-chan struct{}
+	conn   net.Conn
+}
+
+// OpenStream responds with 200 and the headers set so far and returns the stream to write the body to.
+// The stream has to be closed when the handler is done.
+func OpenStream(c *APICallContext) *Stream {
+	c.Status(http.StatusOK)
+	stopping := serverStopping(c.Request)
+	if c.Request.ProtoMajor == 1 {
+		stream, err := hijackStream(c, stopping)
+		if err == nil {
+			return stream
+		}
+		LoggerFrom(c).WithError(err).Debug("Could not take over the connection, the stream ends with the write timeout")
+	}
+
+	c.Writer.WriteHeaderNow()
+	return &Stream{
+		writer: c.Writer,
+		flush: func() error {
+			c.Writer.Flush()
+			return nil
+		},
+		done: untilEither(c.Request.Context().Done(), stopping),
+	}
+}
+
+// serverStoppingKey is the key of the channel in the context of a request which is closed when the Server is closed
+type serverStoppingKey struct{}
+
+// withServerStopping adds the channel, which is closed when the Server is closed, to the context of all requests
+func withServerStopping(stopping <-chan struct{}, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), serverStoppingKey{}, stopping)))
+	})
+}
+
+// serverStopping returns the channel which is closed when the Server of the request is closed; it is nil for other servers
+func serverStopping(r *http.Request) <-chan struct{} {
+	stopping, _ := r.Context().Value(serverStoppingKey{}).(<-chan struct{})
+	return stopping
+}
+
+// untilEither returns a channel which is closed as soon as one of the channels is closed
+func untilEither(first <-chan struct{}, second <-chan struct{}) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-first:
+		case <-second:
+		}
+		close(done)
+	}()
+	return done
+}
+
+// hijackStream takes over the connection of a request and writes the status and the headers of the response
+func hijackStream(c *APICallContext, stopping <-chan struct{}) (*Stream, error) {
+	header := c.Writer.Header().Clone()
+	conn, rw, err := c.Writer.Hijack()
+	if err != nil {
+		return nil, err
+	}
+	// the deadlines of the server are meant for requests, not for streams
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	// without a content length the body ends when the connection is closed
+	header.Del("Content-Length")
+	header.Set("Connection", "close")
+	header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	fmt.Fprintf(rw, "HTTP/1.1 %d %v\r\n", http.StatusOK, http.StatusText(http.StatusOK))
+	header.Write(rw)
+	rw.WriteString("\r\n")
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	disconnected := make(chan struct{})
+	go func() {
+		// clients do not send anything, so reading ends when the connection is closed
+		_, _ = io.Copy(ioutil.Discard, rw.Reader)
+		close(disconnected)
+	}()
+
+	return &Stream{
+		writer: rw.Writer,
+		flush:  rw.Writer.Flush,
+		done:   untilEither(disconnected, stopping),
+		conn:   conn,
+	}, nil
+}
+
+// Write data to the stream; the data is sent when the stream is flushed
+func (s *Stream) Write(data []byte) (int, error) {
+	return s.writer.Write(data)
+}
+
+// WriteString writes a string to the stream; the string is sent when the stream is flushed
+func (s *Stream) WriteString(str string) (int, error) {
+	return io.WriteString(s.writer, str)
+}
+
+// Flush sends the data written so far to the client
+func (s *Stream) Flush() error {
+	return s.flush()
+}
+
+// Done is closed when the client disconnected or the server is closing
+func (s *Stream) Done() <-chan struct{} {
+	return s.done
+}
+
+// Close sends the remaining data and ends the stream
+func (s *Stream) Close() error {
+	if s.conn == nil {
+		return s.Flush()
+	}
+	err := s.Flush()
+	if closeErr := s.conn.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
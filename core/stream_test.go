@@ -0,0 +1,123 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package core
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("streams", func() {
+
+	var (
+		handler            Handler
+		server             *httptest.Server
+		defaultTimeout     time.Duration
+		defaultCompression bool
+		disconnected       chan struct{}
+	)
+
+	BeforeEach(func() {
+		defaultTimeout, defaultCompression = requestTimeout, compressionEnabled
+		requestTimeout, compressionEnabled = 20*time.Millisecond, true
+		disconnected = make(chan struct{})
+
+		handler = NewHandler()
+		handler.API(1).Stream("/stream", func(c *APICallContext) {
+			c.Header("Content-Type", eventStreamType)
+			stream := OpenStream(c)
+			defer stream.Close()
+			for _, data := range []string{"first", "second"} {
+				_, _ = stream.WriteString("data: " + data + "\n\n")
+				Expect(stream.Flush()).To(Succeed())
+				time.Sleep(150 * time.Millisecond)
+			}
+			<-stream.Done()
+			close(disconnected)
+		})
+
+		server = httptest.NewUnstartedServer(handler)
+		server.Config.ReadTimeout = 50 * time.Millisecond
+		server.Config.WriteTimeout = 50 * time.Millisecond
+		server.Start()
+	})
+
+	AfterEach(func() {
+		server.Close()
+		requestTimeout, compressionEnabled = defaultTimeout, defaultCompression
+	})
+
+	It("outlast the timeouts of requests and of the server", func() {
+		request, _ := http.NewRequest(http.MethodGet, server.URL+"/api/v1/stream", nil)
+		request.Header.Set("Accept-Encoding", "gzip")
+		resp, err := http.DefaultClient.Do(request)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(resp.Header.Get("Content-Type")).To(Equal(eventStreamType))
+		Expect(resp.Header.Get("Content-Encoding")).To(BeEmpty())
+		reader := bufio.NewReader(resp.Body)
+		for _, expected := range []string{"data: first\n", "\n", "data: second\n", "\n"} {
+			line, err := reader.ReadString('\n')
+			Expect(err).ToNot(HaveOccurred())
+			Expect(line).To(Equal(expected))
+		}
+
+		resp.Body.Close()
+		Eventually(disconnected).Should(BeClosed())
+	})
+
+	It("end when the server is closed", func() {
+		l, err := net.Listen("tcp", "localhost:0")
+		Expect(err).ToNot(HaveOccurred())
+		addr := l.Addr().String()
+		Expect(l.Close()).To(Succeed())
+		s := NewServerWithConfig(ServerConfig{Address: addr, ShutdownTimeout: time.Second}, handler)
+		s.Serve()
+		defer s.Close()
+
+		var resp *http.Response
+		Eventually(func() error {
+			resp, err = http.Get("http://" + addr + "/api/v1/stream")
+			return err
+		}).Should(Succeed())
+		defer resp.Body.Close()
+		reader := bufio.NewReader(resp.Body)
+		Expect(reader.ReadString('\n')).To(Equal("data: first\n"))
+
+		Expect(s.Close()).To(Succeed())
+		Eventually(disconnected).Should(BeClosed())
+		_, err = io.Copy(ioutil.Discard, reader)
+		Expect(err).ToNot(HaveOccurred())
+	})
+})
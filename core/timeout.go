@@ -0,0 +1,116 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package core
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ottenwbe/recipes-manager/utils"
+)
+
+const (
+	requestTimeoutCfg = "html.requestTimeout"
+)
+
+var (
+	requestTimeout time.Duration
+)
+
+func init() {
+	utils.Config.SetDefault(requestTimeoutCfg, "30s")
+	requestTimeout = utils.Config.GetDuration(requestTimeoutCfg)
+}
+
+// timeoutMiddleware cancels the context of a request after the timeout, so that handlers can stop waiting for slow queries.
+// If a handler exceeds the timeout, its response is replaced with 503. Routes with long-lived responses, see Routes.Stream, are not limited.
+// The middleware does nothing if the timeout is not positive.
+func timeoutMiddleware(timeout time.Duration, streams map[string]bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if timeout <= 0 || streams[c.FullPath()] {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		writer := &timeoutWriter{ResponseWriter: c.Writer, ctx: ctx}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		if writer.timedOut || (ctx.Err() == context.DeadlineExceeded && !c.Writer.Written()) {
+			LoggerFrom(c).WithField("timeout", timeout).Warn("Request timed out")
+			AbortWithAPIError(c, http.StatusServiceUnavailable, "Request timed out", "")
+		}
+	}
+}
+
+// timeoutWriter discards the response of a handler that exceeded the deadline of its context,
+// unless the handler started to respond in time
+type timeoutWriter struct {
+	gin.ResponseWriter
+	ctx      context.Context
+	timedOut bool
+}
+
+// discard checks if the response is written after the deadline
+func (w *timeoutWriter) discard() bool {
+	if !w.timedOut && !w.ResponseWriter.Written() && w.ctx.Err() == context.DeadlineExceeded {
+		w.timedOut = true
+	}
+	return w.timedOut
+}
+
+func (w *timeoutWriter) WriteHeaderNow() {
+	if !w.discard() {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	if w.discard() {
+		return len(data), nil
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	if w.discard() {
+		return len(s), nil
+	}
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *timeoutWriter) Flush() {
+	if !w.discard() {
+		w.ResponseWriter.Flush()
+	}
+}
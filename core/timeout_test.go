@@ -0,0 +1,129 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package core
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("request timeouts", func() {
+
+	var (
+		handler        Handler
+		defaultTimeout time.Duration
+	)
+
+	serve := func(path string, accept string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Accept", accept)
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	BeforeEach(func() {
+		defaultTimeout = requestTimeout
+		requestTimeout = 20 * time.Millisecond
+
+		handler = NewHandler()
+		handler.API(1).GET("/fast", func(c *APICallContext) { c.String(http.StatusOK, "fast") })
+		handler.API(1).GET("/cancelled", func(c *APICallContext) {
+			<-c.Request.Context().Done()
+			c.String(http.StatusOK, "incomplete")
+		})
+		handler.API(1).GET("/slow", func(c *APICallContext) {
+			time.Sleep(50 * time.Millisecond)
+			AbortWithAPIError(c, http.StatusNotFound, "No such thing", "")
+		})
+		handler.API(1).GET("/started", func(c *APICallContext) {
+			c.String(http.StatusOK, "started")
+			time.Sleep(50 * time.Millisecond)
+			_, _ = c.Writer.WriteString(" and finished")
+		})
+		handler.API(1).Stream("/stream", func(c *APICallContext) {
+			if _, ok := c.Request.Context().Deadline(); ok {
+				c.Status(http.StatusInternalServerError)
+				return
+			}
+			c.String(http.StatusOK, "data: unlimited\n\n")
+		})
+	})
+
+	AfterEach(func() {
+		requestTimeout = defaultTimeout
+	})
+
+	It("do not affect fast requests", func() {
+		w := serve("/api/v1/fast", "")
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Body.String()).To(Equal("fast"))
+	})
+
+	It("cancel the context of slow requests and respond with 503", func() {
+		w := serve("/api/v1/cancelled", "")
+
+		var apiError APIError
+		Expect(json.NewDecoder(w.Body).Decode(&apiError)).To(Succeed())
+		Expect(w.Code).To(Equal(http.StatusServiceUnavailable))
+		Expect(apiError).To(Equal(APIError{Code: http.StatusServiceUnavailable, Message: "Request timed out"}))
+	})
+
+	It("replace the responses of handlers which exceed the timeout", func() {
+		w := serve("/api/v1/slow", "")
+		Expect(w.Code).To(Equal(http.StatusServiceUnavailable))
+		Expect(w.Body.String()).ToNot(ContainSubstring("No such thing"))
+	})
+
+	It("keep responses which started in time", func() {
+		w := serve("/api/v1/started", "")
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Body.String()).To(Equal("started and finished"))
+	})
+
+	It("do not limit streams", func() {
+		w := serve("/api/v1/stream", "")
+		Expect(w.Code).To(Equal(http.StatusOK))
+	})
+
+	It("can be disabled", func() {
+		requestTimeout = 0
+		handler = NewHandler()
+		handler.API(1).GET("/unlimited", func(c *APICallContext) {
+			if _, ok := c.Request.Context().Deadline(); ok {
+				c.Status(http.StatusInternalServerError)
+				return
+			}
+			c.Status(http.StatusOK)
+		})
+
+		Expect(serve("/api/v1/unlimited", "").Code).To(Equal(http.StatusOK))
+	})
+})
@@ -0,0 +1,226 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-openapi/spec"
+	"github.com/swaggo/swag"
+
+	"github.com/ottenwbe/recipes-manager/utils"
+)
+
+const (
+	validateRequestsCfg = "html.validateRequests"
+	definitionsRef      = "#/definitions/"
+)
+
+var (
+	validateRequests bool
+)
+
+func init() {
+	utils.Config.SetDefault(validateRequestsCfg, false)
+	validateRequests = utils.Config.GetBool(validateRequestsCfg)
+}
+
+// requestSchemas validates request bodies against the schemas of the Swagger documentation
+type requestSchemas struct {
+	swagger *spec.Swagger
+	prefix  string
+}
+
+// newRequestSchemas reads the Swagger documentation, which describes the routes of the given api version
+func newRequestSchemas(version int16) (*requestSchemas, error) {
+	doc, err := swag.ReadDoc()
+	if err != nil {
+		return nil, err
+	}
+	swagger := &spec.Swagger{}
+	if err = json.Unmarshal([]byte(doc), swagger); err != nil {
+		return nil, err
+	}
+	return &requestSchemas{
+		swagger: swagger,
+		prefix:  apiBasePath + "/" + v(version),
+	}, nil
+}
+
+// bodySchema returns the schema of the body of an operation or nil if the operation is not documented or has no body.
+// The route is given as gin path, i.e., /api/v1/recipes/r/:recipe.
+func (r *requestSchemas) bodySchema(method string, route string) *spec.Schema {
+	if r.swagger.Paths == nil || !strings.HasPrefix(route, r.prefix) {
+		return nil
+	}
+	segments := strings.Split(strings.TrimPrefix(route, r.prefix), "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+
+	item, ok := r.swagger.Paths.Paths[strings.Join(segments, "/")]
+	if !ok {
+		return nil
+	}
+	var operation *spec.Operation
+	switch method {
+	case http.MethodPost:
+		operation = item.Post
+	case http.MethodPut:
+		operation = item.Put
+	case http.MethodPatch:
+		operation = item.Patch
+	}
+	if operation == nil {
+		return nil
+	}
+	for _, param := range operation.Parameters {
+		if param.In == "body" {
+			return param.Schema
+		}
+	}
+	return nil
+}
+
+// validate a decoded JSON value against a schema. Like encoding/json, null values and unknown fields are accepted.
+func (r *requestSchemas) validate(schema *spec.Schema, value interface{}, field string) []FieldError {
+	schema = r.resolve(schema)
+	if schema == nil || value == nil {
+		return nil
+	}
+
+	var errs []FieldError
+	mismatch := func(typ string) []FieldError {
+		return append(errs, FieldError{Field: field, Message: fmt.Sprintf("must be of type %v", typ)})
+	}
+
+	switch {
+	case schema.Type.Contains("object") || (len(schema.Type) == 0 && len(schema.Properties) > 0):
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return mismatch("object")
+		}
+		for _, name := range schema.Required {
+			if _, ok := object[name]; !ok {
+				errs = append(errs, FieldError{Field: joinField(field, name), Message: "is required"})
+			}
+		}
+		for name, property := range schema.Properties {
+			property := property
+			if v, ok := object[name]; ok {
+				errs = append(errs, r.validate(&property, v, joinField(field, name))...)
+			}
+		}
+	case schema.Type.Contains("array"):
+		array, ok := value.([]interface{})
+		if !ok {
+			return mismatch("array")
+		}
+		if schema.Items != nil && schema.Items.Schema != nil {
+			for i, item := range array {
+				errs = append(errs, r.validate(schema.Items.Schema, item, fmt.Sprintf("%v[%v]", field, i))...)
+			}
+		}
+	case schema.Type.Contains("string"):
+		if _, ok := value.(string); !ok {
+			return mismatch("string")
+		}
+	case schema.Type.Contains("integer"):
+		if n, ok := value.(json.Number); !ok {
+			return mismatch("integer")
+		} else if _, err := n.Int64(); err != nil {
+			return mismatch("integer")
+		}
+	case schema.Type.Contains("number"):
+		if _, ok := value.(json.Number); !ok {
+			return mismatch("number")
+		}
+	case schema.Type.Contains("boolean"):
+		if _, ok := value.(bool); !ok {
+			return mismatch("boolean")
+		}
+	}
+	return errs
+}
+
+// resolve references to definitions of the documentation
+func (r *requestSchemas) resolve(schema *spec.Schema) *spec.Schema {
+	for schema != nil && schema.Ref.String() != "" {
+		definition, ok := r.swagger.Definitions[strings.TrimPrefix(schema.Ref.String(), definitionsRef)]
+		if !ok {
+			return nil
+		}
+		schema = &definition
+	}
+	return schema
+}
+
+// joinField appends the name of a property to the path of a field, i.e., components[0] and name
+func joinField(field string, name string) string {
+	if field == "" {
+		return name
+	}
+	return field + "." + name
+}
+
+// requestValidationMiddleware rejects request bodies which do not match the Swagger documentation with 400.
+// Malformed JSON is left to the handlers, so that they can report it as usual.
+func requestValidationMiddleware(schemas *requestSchemas) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		schema := schemas.bodySchema(c.Request.Method, c.FullPath())
+		if schema == nil || c.Request.Body == nil {
+			c.Next()
+			return
+		}
+
+		body, err := ioutil.ReadAll(c.Request.Body)
+		if err != nil {
+			AbortWithBodyError(c, "Could not read request body", err)
+			return
+		}
+		c.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		var value interface{}
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.UseNumber()
+		if decoder.Decode(&value) == nil {
+			if errs := schemas.validate(schema, value, ""); len(errs) > 0 {
+				sort.Slice(errs, func(i, j int) bool { return errs[i].Field < errs[j].Field })
+				AbortWithFieldErrors(c, http.StatusBadRequest, "Request does not match the API definition", errs)
+				return
+			}
+		}
+		c.Next()
+	}
+}
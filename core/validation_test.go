@@ -0,0 +1,115 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package core
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("request validation", func() {
+
+	var (
+		r               Handler
+		defaultValidate bool
+		post            func(path string, body string) *httptest.ResponseRecorder
+	)
+
+	BeforeEach(func() {
+		defaultValidate = validateRequests
+		validateRequests = true
+
+		r = NewHandler()
+		echo := func(c *APICallContext) {
+			body, _ := ioutil.ReadAll(c.Request.Body)
+			c.Data(http.StatusOK, "application/json", body)
+		}
+		r.API(1).POST("/recipes", echo)
+		r.API(1).PUT("/recipes/r/:recipe", echo)
+		r.API(1).POST("/undocumented", echo)
+
+		post = func(path string, body string) *httptest.ResponseRecorder {
+			w := httptest.NewRecorder()
+			method := http.MethodPost
+			if strings.HasPrefix(path, "/api/v1/recipes/r/") {
+				method = http.MethodPut
+			}
+			req := httptest.NewRequest(method, path, strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+			return w
+		}
+	})
+
+	AfterEach(func() {
+		validateRequests = defaultValidate
+	})
+
+	It("passes valid bodies on to the handler", func() {
+		body := `{"name":"Soup","servings":4,"rating":4.5,"components":[{"name":"water","amount":1,"unit":"l"}],"tags":null,"unknown":true}`
+		w := post("/api/v1/recipes", body)
+
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Body.String()).To(Equal(body))
+	})
+
+	It("rejects bodies which do not match the documentation with the invalid fields", func() {
+		w := post("/api/v1/recipes/r/5f6e5a3b-2b1f-4c4b-9f7e-6c1c6a8a9e10", `{"name":1,"servings":1.5,"components":[{"name":"water"},{"name":["salt"]}],"tags":"soup"}`)
+
+		Expect(w.Code).To(Equal(http.StatusBadRequest))
+		var apiError APIError
+		Expect(json.NewDecoder(w.Body).Decode(&apiError)).To(Succeed())
+		Expect(apiError.Fields).To(Equal([]FieldError{
+			{Field: "components[1].name", Message: "must be of type string"},
+			{Field: "name", Message: "must be of type string"},
+			{Field: "servings", Message: "must be of type integer"},
+			{Field: "tags", Message: "must be of type array"},
+		}))
+	})
+
+	It("leaves malformed JSON to the handler", func() {
+		w := post("/api/v1/recipes", `{`)
+		Expect(w.Code).To(Equal(http.StatusOK))
+	})
+
+	It("ignores undocumented routes", func() {
+		w := post("/api/v1/undocumented", `{"name":1}`)
+		Expect(w.Code).To(Equal(http.StatusOK))
+	})
+
+	It("is disabled by default", func() {
+		validateRequests = defaultValidate
+		r = NewHandler()
+		r.API(1).POST("/recipes", func(c *APICallContext) { c.Status(http.StatusNoContent) })
+
+		Expect(post("/api/v1/recipes", `{"name":1}`).Code).To(Equal(http.StatusNoContent))
+	})
+})
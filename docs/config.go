@@ -0,0 +1,81 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package docs
+
+import (
+	"github.com/ottenwbe/recipes-manager/utils"
+)
+
+const (
+	enabledCfg  = "docs.enabled"
+	hostCfg     = "docs.host"
+	basePathCfg = "docs.basePath"
+)
+
+var (
+	//Enabled is true if the Swagger UI should be served
+	Enabled bool
+	//configuredHost is the host set by docs.host, it takes precedence over the host of the running server
+	configuredHost string
+	//configuredBasePath is the base path set by docs.basePath, it takes precedence over the base path of the running server
+	configuredBasePath string
+)
+
+//SetDeployment overrides the host and the base path of the API in the documentation. Empty values are ignored.
+func SetDeployment(host string, basePath string) {
+	if host != "" {
+		SwaggerInfo.Host = host
+	}
+	if basePath != "" {
+		SwaggerInfo.BasePath = basePath
+	}
+}
+
+//Configure documents the API as served by the running server at host and basePath, with https if tls is enabled.
+//A host and a base path configured by docs.host and docs.basePath take precedence.
+func Configure(host string, basePath string, tls bool) {
+	if configuredHost == "" {
+		SwaggerInfo.Host = host
+	}
+	if configuredBasePath == "" {
+		SwaggerInfo.BasePath = basePath
+	}
+	if tls {
+		SwaggerInfo.Schemes = []string{"https"}
+	} else {
+		SwaggerInfo.Schemes = []string{"http"}
+	}
+}
+
+func init() {
+	utils.Config.SetDefault(enabledCfg, true)
+	utils.Config.SetDefault(hostCfg, "")
+	utils.Config.SetDefault(basePathCfg, "")
+
+	Enabled = utils.Config.GetBool(enabledCfg)
+	configuredHost = utils.Config.GetString(hostCfg)
+	configuredBasePath = utils.Config.GetString(basePathCfg)
+	SetDeployment(configuredHost, configuredBasePath)
+}
@@ -0,0 +1,86 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package docs_test
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/swaggo/swag"
+
+	"github.com/ottenwbe/recipes-manager/docs"
+)
+
+var _ = Describe("Docs", func() {
+
+	var defaultInfo = docs.SwaggerInfo
+
+	AfterEach(func() {
+		docs.SwaggerInfo = defaultInfo
+	})
+
+	readSpec := func() map[string]interface{} {
+		doc, err := swag.ReadDoc()
+		Expect(err).ToNot(HaveOccurred())
+		var spec map[string]interface{}
+		Expect(json.Unmarshal([]byte(doc), &spec)).To(Succeed())
+		return spec
+	}
+
+	It("is enabled by default", func() {
+		Expect(docs.Enabled).To(BeTrue())
+	})
+
+	It("documents the overridden host and base path", func() {
+		docs.SetDeployment("recipes.example.com", "/cook/api/v1")
+
+		spec := readSpec()
+		Expect(spec["host"]).To(Equal("recipes.example.com"))
+		Expect(spec["basePath"]).To(Equal("/cook/api/v1"))
+	})
+
+	It("documents the host, base path, and scheme of the running server", func() {
+		docs.Configure("localhost:8080", "/cook/api/v1", false)
+
+		spec := readSpec()
+		Expect(spec["host"]).To(Equal("localhost:8080"))
+		Expect(spec["basePath"]).To(Equal("/cook/api/v1"))
+		Expect(spec["schemes"]).To(Equal([]interface{}{"http"}))
+	})
+
+	It("documents https if tls is enabled", func() {
+		docs.Configure("recipes.example.com", "/api/v1", true)
+
+		Expect(readSpec()["schemes"]).To(Equal([]interface{}{"https"}))
+	})
+
+	It("keeps the defaults for empty overrides", func() {
+		docs.SetDeployment("", "")
+
+		spec := readSpec()
+		Expect(spec["basePath"]).To(Equal("/api/v1"))
+	})
+})
@@ -28,47 +28,87 @@ var doc = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/recipes": {
+        "/health": {
+            "get": {
+                "description": "The service is alive whenever it can answer requests",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Check if the service is alive",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/core.Status"
+                        }
+                    }
+                }
+            }
+        },
+        "/ingredients/suggest": {
             "get": {
-                "description": "A list of ids of recipes is returned",
+                "description": "Distinct names of ingredients of all recipes which start with the query, ignoring the case, e.g., for a type-ahead. The names are in lower case.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Recipes"
                 ],
-                "summary": "Get Recipes",
+                "summary": "Suggest Ingredients",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Search for a specific name",
-                        "name": "name",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Search for a specific term in a description",
-                        "name": "description",
-                        "in": "query"
+                        "description": "Beginning of the ingredient's name",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        }
                     },
-                    {
-                        "type": "string",
-                        "description": "Search for a specific ingredient",
-                        "name": "ingredient",
-                        "in": "query"
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
                     }
+                }
+            }
+        },
+        "/meal-plans": {
+            "get": {
+                "description": "A list of ids of meal plans is returned",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "MealPlans"
                 ],
+                "summary": "Get Meal Plans",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/recipes.RecipeList"
+                            "$ref": "#/definitions/recipes.MealPlanList"
                         }
                     }
                 }
             },
             "post": {
-                "description": "Adds a new recipe, the id will automatically overriden by the backend",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Adds a new meal plan, the id will automatically overriden by the backend. All entries have to reference existing recipes.",
                 "consumes": [
                     "application/json"
                 ],
@@ -76,68 +116,89 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Recipes"
+                    "MealPlans"
                 ],
-                "summary": "Add a new Recipe",
+                "summary": "Add a new Meal Plan",
                 "parameters": [
                     {
-                        "description": "Recipe",
+                        "description": "Meal Plan",
                         "name": "message",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/recipes.Recipe"
+                            "$ref": "#/definitions/recipes.MealPlan"
                         }
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": ""
+                        "description": "",
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "Path of the new meal plan"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
                     }
                 }
             }
         },
-        "/recipes/num": {
+        "/meal-plans/{mealplan}": {
             "get": {
-                "description": "The number of recipes is returned that is managed by the service.",
+                "description": "A specific meal plan is returned",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Recipes"
+                    "MealPlans"
+                ],
+                "summary": "Get a specific Meal Plan",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Meal Plan ID",
+                        "name": "mealplan",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get the number of recipes",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "integer"
+                            "$ref": "#/definitions/recipes.MealPlan"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
                         }
                     }
                 }
             }
         },
-        "/recipes/r/{recipe}": {
+        "/meal-plans/{mealplan}/shopping-list": {
             "get": {
-                "description": "A specific recipe is returned",
+                "description": "The ingredients of all recipes of the meal plan are scaled and merged to a shopping list",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Recipes"
+                    "MealPlans"
                 ],
-                "summary": "Get a specific Recipe",
+                "summary": "Get the shopping list of a Meal Plan",
                 "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Number of Servings",
-                        "name": "servings",
-                        "in": "query"
-                    },
                     {
                         "type": "string",
-                        "description": "Recipe ID",
-                        "name": "recipe",
+                        "description": "Meal Plan ID",
+                        "name": "mealplan",
                         "in": "path",
                         "required": true
                     }
@@ -146,49 +207,174 @@ var doc = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/recipes.Recipe"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/recipes.Ingredients"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "A specific recipe is updates",
-                "consumes": [
+            }
+        },
+        "/ready": {
+            "get": {
+                "description": "The service is ready when all backends, e.g., the database, can be reached",
+                "produces": [
                     "application/json"
                 ],
+                "summary": "Check if the service is ready",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/core.Status"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/core.Status"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipes": {
+            "get": {
+                "description": "A list of ids of recipes is returned. With expand=true a list of RecipeSummary, i.e., the id, name, primary picture, tags, and rating of each recipe, is returned instead of ids.",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/yaml"
                 ],
                 "tags": [
                     "Recipes"
                 ],
-                "summary": "Update a specific Recipe",
+                "summary": "Get Recipes",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Recipe ID",
-                        "name": "recipe",
-                        "in": "path",
-                        "required": true
+                        "description": "Search for a specific name",
+                        "name": "name",
+                        "in": "query"
                     },
                     {
-                        "description": "Recipe",
-                        "name": "message",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/recipes.Recipe"
-                        }
+                        "type": "string",
+                        "description": "Search for a specific term in a description",
+                        "name": "description",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search for a specific ingredient",
+                        "name": "ingredient",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return recipes with this tag",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return recipes changed after this time (RFC3339), e.g., to synchronize clients",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only return recipes which can be prepared and cooked in this many minutes",
+                        "name": "maxTotalTime",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximal number of returned ids (default 50, max 500)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of ids to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "name",
+                            "created",
+                            "rating",
+                            "calories"
+                        ],
+                        "type": "string",
+                        "description": "Field to sort the ids by (default name)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "Direction of the sort (default asc, desc for rating)",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Return summaries of the recipes instead of their ids (default false)",
+                        "name": "expand",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last-Modified of a cached list",
+                        "name": "If-Modified-Since",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/recipes.RecipeList"
+                        },
+                        "headers": {
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "Time of the latest change of any recipe"
+                            },
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "Number of recipes matching the search"
+                            }
+                        }
+                    },
+                    "304": {
                         "description": ""
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
                     }
                 }
             },
-            "delete": {
-                "description": "Deletes a recipe by id",
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Adds a new recipe, the id will automatically overriden by the backend\nClients that retry the request, e.g., on flaky networks, send the same Idempotency-Key, e.g., a UUID, with each attempt. A repeated request is answered like the original one without creating the recipe again; the response has the header Idempotent-Replayed. Keys of different api keys do not collide.",
                 "consumes": [
                     "application/json"
                 ],
@@ -198,70 +384,130 @@ var doc = `{
                 "tags": [
                     "Recipes"
                 ],
-                "summary": "Delete a Recipe",
+                "summary": "Add a new Recipe",
                 "parameters": [
+                    {
+                        "description": "Recipe",
+                        "name": "message",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/recipes.Recipe"
+                        }
+                    },
                     {
                         "type": "string",
-                        "description": "Recipe ID",
-                        "name": "recipe",
-                        "in": "path",
-                        "required": true
+                        "description": "Key of the request, at most 255 characters, which is remembered for recipes.idempotency.ttl (default 24h)",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": ""
+                    "201": {
+                        "description": "",
+                        "headers": {
+                            "Idempotent-Replayed": {
+                                "type": "string",
+                                "description": "true if the recipe was created by an earlier request with the same Idempotency-Key"
+                            },
+                            "Location": {
+                                "type": "string",
+                                "description": "Path of the new recipe"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    },
+                    "409": {
+                        "description": "A request with the same Idempotency-Key is in progress",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    },
+                    "422": {
+                        "description": "The Idempotency-Key was used for a different recipe",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
                     }
                 }
             }
         },
-        "/recipes/r/{recipe}/pictures/{name}": {
+        "/recipes/archived": {
             "get": {
-                "description": "A specific picture of a specific recipe is returned",
+                "description": "A list of ids of archived recipes is returned, ordered by name. Archived recipes can be restored or removed permanently.",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/yaml"
                 ],
                 "tags": [
                     "Recipes"
                 ],
-                "summary": "Get a picture of a",
+                "summary": "Get archived Recipes",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Recipe ID",
-                        "name": "recipe",
-                        "in": "path",
-                        "required": true
+                        "type": "integer",
+                        "description": "Maximal number of returned ids (default 50, max 500)",
+                        "name": "limit",
+                        "in": "query"
                     },
                     {
-                        "type": "string",
-                        "description": "Name of Picture",
-                        "name": "name",
-                        "in": "path",
-                        "required": true
+                        "type": "integer",
+                        "description": "Number of ids to skip",
+                        "name": "offset",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/recipes.RecipePicture"
+                            "$ref": "#/definitions/recipes.RecipeList"
+                        },
+                        "headers": {
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "Number of archived recipes"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
                         }
                     }
                 }
             }
         },
-        "/recipes/rand": {
+        "/recipes/batch": {
             "get": {
-                "description": "A specific picture of a specific recipe is returned",
+                "description": "All existing recipes out of a comma separated list of ids are returned at once.\nIds of recipes that do not exist are listed as notFound.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Recipes"
                 ],
-                "summary": "Get a Random Recipe",
+                "summary": "Get multiple Recipes",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma separated list of Recipe IDs",
+                        "name": "ids",
+                        "in": "query",
+                        "required": true
+                    },
                     {
                         "type": "integer",
                         "description": "Number of Servings",
@@ -273,194 +519,2603 @@ var doc = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/recipes.Recipe"
+                            "$ref": "#/definitions/recipes.RecipeBatch"
                         }
-                    }
-                }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    }
+                }
             }
         },
-        "/sources": {
+        "/recipes/daily": {
             "get": {
-                "description": "List sources",
+                "description": "The recipe of the day is chosen by the date, i.e., all clients see the same recipe for the whole day",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Sources"
+                    "Recipes"
+                ],
+                "summary": "Get the Recipe of the Day",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Preview the recipe of another day (format YYYY-MM-DD)",
+                        "name": "date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of Servings",
+                        "name": "servings",
+                        "in": "query"
+                    }
                 ],
-                "summary": "List sources",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "$ref": "#/definitions/sources.SourceResponse"
-                            }
+                            "$ref": "#/definitions/recipes.Recipe"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
                         }
                     }
                 }
             }
         },
-        "/sources/{source}/connect": {
+        "/recipes/events": {
             "get": {
-                "description": "Trigger the oauth process",
+                "description": "Server-sent events about created, updated, and deleted recipes, e.g., to update a list of recipes without polling. The type of a server-sent event is the type of the change and its data is a RecipeEvent.\nClients that reconnect with the id of the last received event receive the recent events they missed. Idle streams receive comments to keep the connection open.",
                 "produces": [
-                    "application/json"
+                    "text/event-stream"
                 ],
                 "tags": [
-                    "Sources"
+                    "Recipes"
                 ],
-                "summary": "Trigger the oauth process",
+                "summary": "Stream changes of Recipes",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Source ID",
-                        "name": "source",
-                        "in": "path",
-                        "required": true
+                        "description": "Id of the last received event",
+                        "name": "Last-Event-ID",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/sources.SourceOAuthConnectResponse"
+                            "$ref": "#/definitions/recipes.RecipeEvent"
                         }
                     }
                 }
             }
         },
-        "/sources/{source}/oauth": {
+        "/recipes/export.csv": {
             "get": {
-                "description": "Handles Tokens. Typically not directly called.",
+                "description": "All recipes which are not archived are exported as CSV with one row per ingredient, e.g., to edit them in a spreadsheet.\nThe columns are name, servings, prepMinutes, cookMinutes, difficulty, tags (separated by ';'), description, steps (separated by line breaks), ingredient, amount (empty if the ingredient has no amount), amountMax, unit, and group.\nAll columns but the ingredient's are repeated in each row of a recipe; a recipe without ingredients has one row with empty ingredient columns.",
+                "produces": [
+                    "text/csv"
+                ],
+                "tags": [
+                    "Recipes"
+                ],
+                "summary": "Export all Recipes as CSV",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipes/favorites": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "A list of ids of the user's favorite recipes is returned in the order they were marked as favorite",
+                "produces": [
+                    "application/json",
+                    "application/yaml"
+                ],
+                "tags": [
+                    "Favorites"
+                ],
+                "summary": "Get Favorite Recipes",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Maximal number of returned ids (default 50, max 500)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of ids to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/recipes.RecipeList"
+                        },
+                        "headers": {
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "Number of favorite recipes"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipes/import.csv": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Adds the recipes of a CSV file in the layout of /recipes/export.csv. Rows are grouped into recipes by their name; the other columns of a recipe are taken from its first row.\nColumns may be in any order and only name and ingredient are mandatory. Amounts may use a decimal comma.\nRecipes with invalid rows are not imported, the result lists the rejected rows, starting with 1 for the header. The other recipes are imported with new ids.",
+                "consumes": [
+                    "text/csv"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Sources"
+                    "Recipes"
                 ],
-                "summary": "Handles Tokens",
+                "summary": "Import Recipes from CSV",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/recipes.CSVImportResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipes/merge": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Merges duplicates into a primary recipe and deletes the duplicates, e.g., after finding them with the similar recipes. The pictures, notes, and ratings of the duplicates are moved to the primary recipe. Pictures whose names are already taken by the primary recipe or a preceding duplicate are dropped.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Recipes"
+                ],
+                "summary": "Merge Recipes",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Source ID",
-                        "name": "source",
-                        "in": "path",
-                        "required": true
+                        "description": "Primary recipe and its duplicates",
+                        "name": "message",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/recipes.MergeRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "301": {
-                        "description": "Moved Permanently",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/recipes.Recipe"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
                         }
                     }
                 }
             }
         },
-        "/sources/{source}/recipes": {
+        "/recipes/num": {
             "get": {
-                "description": "Download recipes from a source",
+                "description": "The number of recipes is returned that is managed by the service.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Sources"
+                    "Recipes"
                 ],
-                "summary": "Download Recipes from a Source",
+                "summary": "Get the number of recipes",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipes/r/{recipe}": {
+            "get": {
+                "description": "A specific recipe is returned",
+                "produces": [
+                    "application/json",
+                    "application/yaml"
+                ],
+                "tags": [
+                    "Recipes"
+                ],
+                "summary": "Get a specific Recipe",
                 "parameters": [
                     {
+                        "type": "integer",
+                        "description": "Number of Servings",
+                        "name": "servings",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "metric",
+                            "imperial",
+                            "weight"
+                        ],
                         "type": "string",
-                        "description": "Source ID",
-                        "name": "source",
+                        "description": "Convert amounts and temperatures to a system of units; the stored recipe is not changed. weight converts volumes of ingredients with a known density, e.g., cups of flour, and all weights to g or kg",
+                        "name": "units",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Recipe ID",
+                        "name": "recipe",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a cached recipe",
+                        "name": "If-None-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/recipes.Recipe"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the recipe and hash of the returned document"
+                            }
+                        }
+                    },
+                    "304": {
                         "description": ""
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "A specific recipe is updated. The recipe's version has to be the version that was read; otherwise the recipe has been modified in the meantime and 409 is returned.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Recipes"
+                ],
+                "summary": "Update a specific Recipe",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Recipe ID",
+                        "name": "recipe",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Recipe",
+                        "name": "message",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/recipes.Recipe"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "",
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "ETag of the updated recipe"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Archives a recipe by id, i.e., the recipe is no longer listed but can be restored. With hard=true the recipe is removed permanently.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Recipes"
+                ],
+                "summary": "Delete a Recipe",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Recipe ID",
+                        "name": "recipe",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Remove the recipe permanently instead of archiving it",
+                        "name": "hard",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": ""
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipes/r/{recipe}/cook": {
+            "get": {
+                "description": "Returns a specific recipe prepared for a guided cooking screen, i.e., its scaled ingredients and its steps in order.\nEach step lists the ingredients its text mentions by name and its timer. Recipes without structured steps are cooked line by line of their description.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Recipes"
+                ],
+                "summary": "Cook a Recipe step by step",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Recipe ID",
+                        "name": "recipe",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of Servings",
+                        "name": "servings",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "metric",
+                            "imperial",
+                            "weight"
+                        ],
+                        "type": "string",
+                        "description": "Convert amounts and temperatures to a system of units; weight converts volumes of ingredients with a known density to g or kg",
+                        "name": "units",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/recipes.CookMode"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipes/r/{recipe}/copy": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Creates a copy of a recipe, including its pictures, e.g., to build a variant of a dish. The name of the copy is marked with '(copy)'.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Recipes"
+                ],
+                "summary": "Copy a Recipe",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Recipe ID",
+                        "name": "recipe",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/recipes.Recipe"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "Path of the new recipe"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipes/r/{recipe}/export": {
+            "get": {
+                "description": "A specific recipe is rendered in the requested format",
+                "produces": [
+                    "application/json",
+                    "text/markdown",
+                    "text/plain",
+                    "application/pdf"
+                ],
+                "tags": [
+                    "Recipes"
+                ],
+                "summary": "Export a specific Recipe",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Recipe ID",
+                        "name": "recipe",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "markdown",
+                            "plaintext",
+                            "pdf"
+                        ],
+                        "type": "string",
+                        "description": "Format of the recipe (default json)",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of Servings",
+                        "name": "servings",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipes/r/{recipe}/favorite": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "The recipe is added to the user's favorites; the new state is returned",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Favorites"
+                ],
+                "summary": "Mark a Recipe as Favorite",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Recipe ID",
+                        "name": "recipe",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/recipes.FavoriteState"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "The recipe is removed from the user's favorites; the new state is returned",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Favorites"
+                ],
+                "summary": "Remove a Recipe from the Favorites",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Recipe ID",
+                        "name": "recipe",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/recipes.FavoriteState"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipes/r/{recipe}/ingredients": {
+            "get": {
+                "description": "Returns only the ingredients of a specific recipe, scaled and converted like the recipe itself, e.g., for a shopping widget which does not need the description and the pictures.",
+                "produces": [
+                    "application/json",
+                    "application/yaml"
+                ],
+                "tags": [
+                    "Recipes"
+                ],
+                "summary": "Get the Ingredients of a Recipe",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Recipe ID",
+                        "name": "recipe",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of Servings",
+                        "name": "servings",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "metric",
+                            "imperial",
+                            "weight"
+                        ],
+                        "type": "string",
+                        "description": "Convert amounts to a system of units; weight converts volumes of ingredients with a known density to g or kg",
+                        "name": "units",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/recipes.Ingredients"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipes/r/{recipe}/notes": {
+            "get": {
+                "description": "The notes on a specific recipe are returned, the newest note first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Recipes"
+                ],
+                "summary": "Get the Notes on a Recipe",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Recipe ID",
+                        "name": "recipe",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/recipes.Note"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Adds a personal note to a specific recipe. Notes are kept when the recipe is updated and removed with the recipe.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Recipes"
+                ],
+                "summary": "Add a Note to a Recipe",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Recipe ID",
+                        "name": "recipe",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Note",
+                        "name": "message",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/recipes.NoteInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/recipes.Note"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipes/r/{recipe}/pictures": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "A picture is added to a specific recipe. The picture's name is derived from the uploaded file's name.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Recipes"
+                ],
+                "summary": "Upload a picture of a recipe",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Recipe ID",
+                        "name": "recipe",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Picture",
+                        "name": "picture",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/recipes.PictureUploadResult"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "Path of the new picture"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipes/r/{recipe}/pictures/order": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Rearranges the pictures of a specific recipe. The primary picture is listed first in the recipe's picture links and is shown as the recipe's thumbnail; without a primary picture, the first named picture becomes the primary one.\nPictures which are not named keep their order and follow the named ones. The new picture links are returned.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Recipes"
+                ],
+                "summary": "Order the pictures of a recipe",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Recipe ID",
+                        "name": "recipe",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Names of the pictures in the desired order and the primary picture",
+                        "name": "message",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/recipes.PictureOrder"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipes/r/{recipe}/pictures/{name}": {
+            "get": {
+                "description": "A specific picture of a specific recipe is returned",
+                "produces": [
+                    "application/json",
+                    "image/jpeg",
+                    "image/png"
+                ],
+                "tags": [
+                    "Recipes"
+                ],
+                "summary": "Get a picture of a",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Recipe ID",
+                        "name": "recipe",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Name of Picture",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Return the picture itself instead of a base64 encoded picture wrapped in JSON",
+                        "name": "raw",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "thumb"
+                        ],
+                        "type": "string",
+                        "description": "Return the thumbnail of the picture instead of the original picture",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/recipes.RecipePicture"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "A specific picture of a specific recipe is removed",
+                "tags": [
+                    "Recipes"
+                ],
+                "summary": "Delete a picture of a recipe",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Recipe ID",
+                        "name": "recipe",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Name of Picture",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": ""
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipes/r/{recipe}/print": {
+            "get": {
+                "description": "A specific recipe is rendered as a self-contained HTML page to be printed from a browser",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "Recipes"
+                ],
+                "summary": "Print a specific Recipe",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Recipe ID",
+                        "name": "recipe",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of Servings",
+                        "name": "servings",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipes/r/{recipe}/rating": {
+            "post": {
+                "description": "Adds a rating between 1 and 5 to a recipe and returns the recipe's new average rating",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Recipes"
+                ],
+                "summary": "Rate a Recipe",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Recipe ID",
+                        "name": "recipe",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Rating",
+                        "name": "message",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/recipes.RatingInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/recipes.RatingResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipes/r/{recipe}/raw": {
+            "get": {
+                "description": "A specific recipe is returned exactly as it is stored, i.e., it is neither scaled to recipes.defaultServings nor are its units converted or translated.\nEditors read recipes this way, so that saving an edited recipe does not accumulate rounding errors of scaled amounts.",
+                "produces": [
+                    "application/json",
+                    "application/yaml"
+                ],
+                "tags": [
+                    "Recipes"
+                ],
+                "summary": "Get a specific Recipe as stored",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Recipe ID",
+                        "name": "recipe",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a cached recipe",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/recipes.Recipe"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the recipe and hash of the returned document"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": ""
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipes/r/{recipe}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Restores an archived recipe by id, i.e., the recipe is listed again",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Recipes"
+                ],
+                "summary": "Restore a Recipe",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Recipe ID",
+                        "name": "recipe",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/recipes.Recipe"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipes/r/{recipe}/similar": {
+            "get": {
+                "description": "Lists recipes whose ingredients overlap with the ingredients of a specific recipe, e.g., to find duplicates. The similarity is the Jaccard similarity of the recipes' ingredient names; the most similar recipes are listed first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Recipes"
+                ],
+                "summary": "Similar Recipes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Recipe ID",
+                        "name": "recipe",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimal similarity between 0 and 1 (default 0.5)",
+                        "name": "threshold",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/recipes.SimilarRecipe"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipes/r/{recipe}/substitutions": {
+            "get": {
+                "description": "Suggests substitutes for each ingredient of a specific recipe. Ingredients without a known substitute have an empty list.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Recipes"
+                ],
+                "summary": "Substitutes of Ingredients",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Recipe ID",
+                        "name": "recipe",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/recipes.IngredientSubstitutions"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipes/r/{recipe}/timers": {
+            "get": {
+                "description": "Lists a cooking timer for each step of a specific recipe that takes some time. Recipes without such steps have no timers.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Recipes"
+                ],
+                "summary": "Timers of a Recipe",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Recipe ID",
+                        "name": "recipe",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/recipes.RecipeTimer"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipes/rand": {
+            "get": {
+                "description": "A random recipe is returned. Filters restrict the choice to the matching recipes.\nThe same seed and the same collection of recipes always yield the same recipe.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Recipes"
+                ],
+                "summary": "Get a Random Recipe",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Number of Servings",
+                        "name": "servings",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Seed for a reproducible choice of the recipe",
+                        "name": "seed",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only pick recipes with a specific name",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only pick recipes with a specific term in their description",
+                        "name": "description",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only pick recipes with a specific ingredient",
+                        "name": "ingredient",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only pick recipes with this tag",
+                        "name": "tag",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/recipes.Recipe"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipes/search": {
+            "get": {
+                "description": "Recipes whose name, description, or ingredients contain all terms of the query are returned, the most relevant recipes first.\nPhrases can be quoted, e.g., \"olive oil\".",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Recipes"
+                ],
+                "summary": "Search Recipes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search query",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/recipes.RecipeSearchResult"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipes/shopping-list": {
+            "post": {
+                "description": "The ingredients of all given recipes are scaled to the requested servings and merged to a shopping list",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Recipes"
+                ],
+                "summary": "Get a shopping list",
+                "parameters": [
+                    {
+                        "description": "Recipes and their servings",
+                        "name": "message",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/recipes.ShoppingListRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/recipes.Ingredients"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipes/stats": {
+            "get": {
+                "description": "Aggregated numbers of all recipes, e.g., for a dashboard. The statistics are cached for a short time, i.e., recent changes may be missing.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Recipes"
+                ],
+                "summary": "Statistics of Recipes",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/recipes.RecipeStats"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipes/tags": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Removes tags from and then adds tags to many recipes at once, e.g., to reorganize recipes after an import. Tags are compared ignoring the case.\nThe result tells for each id if the recipe was found; missing recipes do not stop the others from being tagged.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Recipes"
+                ],
+                "summary": "Tag many Recipes",
+                "parameters": [
+                    {
+                        "description": "Recipes and the tags to add and to remove",
+                        "name": "message",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/recipes.BulkTagRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "boolean"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipes/validate": {
+            "post": {
+                "description": "Validates a recipe and normalizes its units like it is done before a recipe is saved, but does not save it, e.g., to preview an imported recipe.\nInvalid recipes are answered with 200 as well; the result lists the errors which prevent the recipe from being saved and warnings about fields which are likely not intended.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Recipes"
+                ],
+                "summary": "Validate a Recipe",
+                "parameters": [
+                    {
+                        "description": "Recipe",
+                        "name": "message",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/recipes.Recipe"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/recipes.RecipeValidation"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/sources": {
+            "get": {
+                "description": "List sources",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sources"
+                ],
+                "summary": "List sources",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/sources.SourceResponse"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/sources/web/import": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "The recipe is read from the schema.org/Recipe structured data (JSON-LD) of the web page, including the recipe's image.\nPages without a complete recipe, i.e., with a name and ingredients, are rejected.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sources"
+                ],
+                "summary": "Import a Recipe from a Web Page",
+                "parameters": [
+                    {
+                        "description": "Web page of the recipe",
+                        "name": "message",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/sources.WebImportRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/recipes.Recipe"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "Path of the new recipe"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/sources/{source}/connect": {
+            "get": {
+                "description": "Trigger the oauth process",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sources"
+                ],
+                "summary": "Trigger the oauth process",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Source ID",
+                        "name": "source",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/sources.SourceOAuthConnectResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sources/{source}/oauth": {
+            "get": {
+                "description": "Handles Tokens. Typically not directly called.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sources"
+                ],
+                "summary": "Handles Tokens",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Source ID",
+                        "name": "source",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "301": {
+                        "description": "Moved Permanently",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/sources/{source}/recipes": {
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Download recipes from a source",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sources"
+                ],
+                "summary": "Download Recipes from a Source",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Source ID",
+                        "name": "source",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": ""
+                    },
+                    "409": {
+                        "description": "Source is not connected",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/tags": {
+            "get": {
+                "description": "All distinct tags in lower case and the number of recipes carrying them, the most used tag first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Recipes"
+                ],
+                "summary": "List Tags",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/recipes.TagCount"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/tags/suggest": {
+            "get": {
+                "description": "Tags which start with the query, ignoring the case, e.g., for a type-ahead. The most used tag comes first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Recipes"
+                ],
+                "summary": "Suggest Tags",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Beginning of the tag",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/recipes.TagCount"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/core.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/version": {
+            "get": {
+                "description": "get the current version",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get the curent version",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/core.Version"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "core.APIError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Code is the HTTP status code of the response",
+                    "type": "integer"
+                },
+                "detail": {
+                    "description": "Detail explains the cause of the error, i.e., the id of a missing recipe",
+                    "type": "string"
+                },
+                "error": {
+                    "description": "ErrorCode is a machine-readable code of the error, i.e., not_found; unlike the message it is not translated",
+                    "type": "string"
+                },
+                "fields": {
+                    "description": "Fields lists the invalid fields of a rejected input",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/core.FieldError"
+                    }
+                },
+                "message": {
+                    "description": "Message is a short description of the error",
+                    "type": "string"
+                },
+                "requestId": {
+                    "description": "RequestID correlates an unexpected error with the log entries of the request",
+                    "type": "string"
+                }
+            }
+        },
+        "core.FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "description": "Field is the path of the field in the JSON input, i.e., components[0].name",
+                    "type": "string"
+                },
+                "message": {
+                    "description": "Message explains what is wrong with the field",
+                    "type": "string"
+                }
+            }
+        },
+        "core.Status": {
+            "type": "object",
+            "properties": {
+                "failed": {
+                    "description": "Failed lists the names of all failed checks",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "status": {
+                    "description": "Status is either ok or unavailable",
+                    "type": "string"
+                }
+            }
+        },
+        "core.Version": {
+            "type": "object",
+            "properties": {
+                "api": {
+                    "description": "API is the MAJOR API Version supported by the app",
+                    "type": "string"
+                },
+                "app": {
+                    "description": "APP is the version of the current app",
+                    "type": "string"
+                }
+            }
+        },
+        "recipes.BulkTagRequest": {
+            "type": "object",
+            "properties": {
+                "add": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "remove": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "recipes.CSVImportResult": {
+            "type": "object",
+            "properties": {
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/recipes.CSVRowError"
+                    }
+                },
+                "imported": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "recipes.CSVRowError": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                },
+                "recipe": {
+                    "description": "Recipe is the name of the recipe the row belongs to; empty if the row could not be read",
+                    "type": "string"
+                },
+                "row": {
+                    "description": "Row is the number of the record in the file, starting with 1 for the header",
+                    "type": "integer"
+                }
+            }
+        },
+        "recipes.CookMode": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "ingredients": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/recipes.Ingredients"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "servings": {
+                    "type": "integer"
+                },
+                "steps": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/recipes.CookStep"
+                    }
+                },
+                "totalSeconds": {
+                    "description": "TotalSeconds of all timers",
+                    "type": "integer"
+                }
+            }
+        },
+        "recipes.CookStep": {
+            "type": "object",
+            "properties": {
+                "ingredients": {
+                    "description": "Ingredients which are mentioned in the step's text",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/recipes.Ingredients"
+                    }
+                },
+                "number": {
+                    "description": "Number of the step, starting with 1",
+                    "type": "integer"
+                },
+                "text": {
+                    "type": "string"
+                },
+                "timer": {
+                    "description": "Timer of the step; nil if the step does not take some time",
+                    "$ref": "#/definitions/recipes.RecipeTimer"
+                }
+            }
+        },
+        "recipes.FavoriteState": {
+            "type": "object",
+            "properties": {
+                "favorite": {
+                    "type": "boolean"
+                },
+                "recipe": {
+                    "type": "string"
+                }
+            }
+        },
+        "recipes.IngredientCount": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "recipes.IngredientSubstitutions": {
+            "type": "object",
+            "properties": {
+                "ingredient": {
+                    "type": "string"
+                },
+                "substitutes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "recipes.Ingredients": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "Amount needed in a recipe of an ingredient",
+                    "type": "number"
+                },
+                "amountMax": {
+                    "description": "AmountMax is the upper end of a range of amounts, e.g., 3 for '2-3 cloves'; 0 if the amount is no range",
+                    "type": "number"
+                },
+                "group": {
+                    "description": "Group of the ingredient, e.g., 'For the sauce'; ingredients without a group are listed first",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Name of the ingredient",
+                    "type": "string"
+                },
+                "unit": {
+                    "description": "Unit of the Amount",
+                    "type": "string"
+                }
+            }
+        },
+        "recipes.MealPlan": {
+            "type": "object",
+            "properties": {
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/recipes.MealPlanEntry"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "recipes.MealPlanEntry": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "description": "Date in the format of DateLayout",
+                    "type": "string"
+                },
+                "recipe": {
+                    "type": "string"
+                },
+                "servings": {
+                    "description": "Servings the recipe is scaled to; the recipe's own servings are used if no servings are given",
+                    "type": "integer"
+                }
+            }
+        },
+        "recipes.MealPlanList": {
+            "type": "object",
+            "properties": {
+                "mealPlans": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
                     }
                 }
             }
         },
-        "/version": {
-            "get": {
-                "description": "get the current version",
-                "produces": [
-                    "application/json"
-                ],
-                "summary": "Get the curent version",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/core.Version"
-                        }
+        "recipes.MergeRequest": {
+            "type": "object",
+            "properties": {
+                "duplicates": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
                     }
+                },
+                "primary": {
+                    "type": "string"
                 }
             }
-        }
-    },
-    "definitions": {
-        "core.Version": {
+        },
+        "recipes.Note": {
             "type": "object",
             "properties": {
-                "api": {
-                    "description": "API is the MAJOR API Version supported by the app",
+                "createdAt": {
+                    "description": "CreatedAt is set by the database when the note is added",
                     "type": "string"
                 },
-                "app": {
-                    "description": "APP is the version of the current app",
+                "id": {
+                    "type": "string"
+                },
+                "recipeID": {
+                    "type": "string"
+                },
+                "text": {
                     "type": "string"
                 }
             }
         },
-        "recipes.Ingredients": {
+        "recipes.NoteInput": {
             "type": "object",
             "properties": {
-                "amount": {
-                    "description": "Amount needed in a recipe of an ingredient",
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "recipes.Nutrition": {
+            "type": "object",
+            "properties": {
+                "calories": {
+                    "description": "Calories in kcal",
                     "type": "number"
                 },
-                "name": {
-                    "description": "Name of the ingredient",
+                "carbs": {
+                    "description": "Carbs in g",
+                    "type": "number"
+                },
+                "fat": {
+                    "description": "Fat in g",
+                    "type": "number"
+                },
+                "protein": {
+                    "description": "Protein in g",
+                    "type": "number"
+                }
+            }
+        },
+        "recipes.PictureOrder": {
+            "type": "object",
+            "properties": {
+                "names": {
+                    "description": "Names of pictures in the desired order; pictures which are not named keep their order and follow the named ones",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "primary": {
+                    "description": "Primary picture of the recipe; the first of the names if it is empty",
                     "type": "string"
+                }
+            }
+        },
+        "recipes.PictureUploadResult": {
+            "type": "object",
+            "properties": {
+                "height": {
+                    "type": "integer"
                 },
-                "unit": {
-                    "description": "Unit of the Amount",
+                "name": {
                     "type": "string"
+                },
+                "width": {
+                    "description": "Width and Height of the stored picture in pixels; omitted if the picture's format is unknown, e.g., for webp pictures",
+                    "type": "integer"
+                }
+            }
+        },
+        "recipes.RatingInput": {
+            "type": "object",
+            "properties": {
+                "rating": {
+                    "type": "integer"
+                }
+            }
+        },
+        "recipes.RatingResult": {
+            "type": "object",
+            "properties": {
+                "rating": {
+                    "type": "number"
                 }
             }
         },
         "recipes.Recipe": {
             "type": "object",
             "properties": {
+                "archived": {
+                    "description": "Archived recipes are excluded from listings until they are restored; updates keep the archived state",
+                    "type": "boolean"
+                },
                 "components": {
                     "type": "array",
                     "items": {
                         "$ref": "#/definitions/recipes.Ingredients"
                     }
                 },
+                "cookMinutes": {
+                    "description": "CookMinutes is the time to cook the recipe; 0 if unknown",
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "description": "CreatedAt is set by the database when the recipe is inserted",
+                    "type": "string"
+                },
                 "description": {
                     "type": "string"
                 },
+                "difficulty": {
+                    "description": "Difficulty of the recipe, i.e., DifficultyEasy, DifficultyMedium, or DifficultyHard; empty if unknown",
+                    "type": "string",
+                    "enum": [
+                        "easy",
+                        "medium",
+                        "hard"
+                    ]
+                },
                 "id": {
                     "type": "string"
                 },
                 "name": {
                     "type": "string"
                 },
+                "nutrition": {
+                    "$ref": "#/definitions/recipes.Nutrition"
+                },
+                "originalUnitSystem": {
+                    "description": "OriginalUnitSystem is the system of units the recipe was written in, i.e., metric or imperial; empty if unknown.\nRecipes are stored in their original units and converted when they are read.",
+                    "type": "string",
+                    "enum": [
+                        "metric",
+                        "imperial"
+                    ]
+                },
                 "pictureLink": {
                     "type": "array",
                     "items": {
                         "type": "string"
                     }
                 },
+                "prepMinutes": {
+                    "description": "PrepMinutes is the time to prepare the recipe before cooking; 0 if unknown",
+                    "type": "integer"
+                },
+                "rating": {
+                    "type": "number"
+                },
+                "ratingCount": {
+                    "type": "integer"
+                },
                 "servings": {
                     "type": "integer"
+                },
+                "steps": {
+                    "description": "Steps are the structured instructions of the recipe; Description keeps the instructions of clients that do not know steps",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/recipes.RecipeStep"
+                    }
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "updatedAt": {
+                    "description": "UpdatedAt is set by the database whenever the recipe changes, e.g., when it is updated or rated",
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Version is incremented with each update, so that concurrent updates do not overwrite each other",
+                    "type": "integer"
+                }
+            }
+        },
+        "recipes.RecipeBatch": {
+            "type": "object",
+            "properties": {
+                "notFound": {
+                    "description": "NotFound lists the requested ids of recipes that do not exist",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "recipes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/recipes.Recipe"
+                    }
+                }
+            }
+        },
+        "recipes.RecipeEvent": {
+            "type": "object",
+            "properties": {
+                "at": {
+                    "description": "At is the time of the change",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "ID of the changed recipe",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "Type of the change, e.g., RecipeCreatedEvent",
+                    "type": "string",
+                    "enum": [
+                        "recipe.created",
+                        "recipe.updated",
+                        "recipe.deleted"
+                    ]
                 }
             }
         },
@@ -489,6 +3144,152 @@ var doc = `{
                 }
             }
         },
+        "recipes.RecipeSearchResult": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "score": {
+                    "description": "Score is the relevance of the recipe for the search; higher scores are more relevant",
+                    "type": "number"
+                }
+            }
+        },
+        "recipes.RecipeStats": {
+            "type": "object",
+            "properties": {
+                "averageServings": {
+                    "description": "AverageServings of all recipes; 0 if there are no recipes",
+                    "type": "number"
+                },
+                "tags": {
+                    "description": "Tags and the number of recipes carrying them, the most used tag first; tags are compared ignoring the case",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/recipes.TagCount"
+                    }
+                },
+                "topIngredients": {
+                    "description": "TopIngredients are the ingredients used by most recipes, the most used ingredient first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/recipes.IngredientCount"
+                    }
+                },
+                "total": {
+                    "description": "Total number of recipes",
+                    "type": "integer"
+                }
+            }
+        },
+        "recipes.RecipeStep": {
+            "type": "object",
+            "properties": {
+                "minutes": {
+                    "description": "Minutes the step takes, e.g., to let a dough rest; 0 if unknown",
+                    "type": "integer"
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "recipes.RecipeTimer": {
+            "type": "object",
+            "properties": {
+                "label": {
+                    "description": "Label is the text of the step",
+                    "type": "string"
+                },
+                "seconds": {
+                    "type": "integer"
+                },
+                "step": {
+                    "description": "Step is the number of the step, starting with 1",
+                    "type": "integer"
+                }
+            }
+        },
+        "recipes.RecipeValidation": {
+            "type": "object",
+            "properties": {
+                "errors": {
+                    "description": "Errors lists the invalid fields which prevent the recipe from being saved",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/core.FieldError"
+                    }
+                },
+                "recipe": {
+                    "description": "Recipe as it would be saved, e.g., with normalized units",
+                    "$ref": "#/definitions/recipes.Recipe"
+                },
+                "valid": {
+                    "description": "Valid is true if the recipe can be saved, i.e., there are no errors",
+                    "type": "boolean"
+                },
+                "warnings": {
+                    "description": "Warnings lists fields which can be saved, but are likely not intended, e.g., unknown units",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/core.FieldError"
+                    }
+                }
+            }
+        },
+        "recipes.ShoppingListItem": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "servings": {
+                    "description": "Servings the recipe is scaled to; the recipe's own servings are used if no servings are given",
+                    "type": "integer"
+                }
+            }
+        },
+        "recipes.ShoppingListRequest": {
+            "type": "object",
+            "properties": {
+                "recipes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/recipes.ShoppingListItem"
+                    }
+                }
+            }
+        },
+        "recipes.SimilarRecipe": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "score": {
+                    "description": "Score is the Jaccard similarity of the recipes' ingredient names, between 0 (no common ingredient) and 1 (the same ingredients)",
+                    "type": "number"
+                }
+            }
+        },
+        "recipes.TagCount": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "tag": {
+                    "type": "string"
+                }
+            }
+        },
         "sources.SourceOAuthConnectResponse": {
             "type": "object",
             "properties": {
@@ -503,6 +3304,13 @@ var doc = `{
         "sources.SourceResponse": {
             "type": "object",
             "properties": {
+                "capabilities": {
+                    "description": "Capabilities tell clients how to use the source, e.g., 'oauth' sources have to be connected before recipes can be downloaded",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "connected": {
                     "type": "boolean"
                 },
@@ -516,6 +3324,21 @@ var doc = `{
                     "type": "string"
                 }
             }
+        },
+        "sources.WebImportRequest": {
+            "type": "object",
+            "properties": {
+                "url": {
+                    "type": "string"
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "ApiKeyAuth": {
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
@@ -25,6 +25,10 @@
 package main
 
 import (
+	"context"
+	"io"
+
+	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/ottenwbe/recipes-manager/core"
@@ -44,44 +48,75 @@ func init() {
 // @license.url https://github.com/ottenwbe/recipes-manager/blob/master/LICENSE
 
 // @BasePath /api/v1
+
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name Authorization
 func main() {
 
-	// configure the cooking app
-	recipesDB := newCloseableDatabase()
+	// configure the cooking app; the changes of recipes are published to the events, which are streamed by the api
+	events := recipes.NewRecipeEventsFromConfig()
+	recipesDB := newCloseableDatabase(events)
 	defer closeDatabase(recipesDB)
 
+	mealPlanDB := newCloseableMealPlanDatabase()
+	defer closeDatabase(mealPlanDB)
+
+	favoritesDB := newCloseableFavoritesDatabase()
+	defer closeDatabase(favoritesDB)
+
 	srcRepository := newSources()
 
-	server := newServer(recipesDB, srcRepository)
+	server := newServer(recipesDB, events, mealPlanDB, favoritesDB, srcRepository)
 
-	// start the application
-	waitForStop := server.Run()
-	waitForStop.Wait()
+	// start the application and wait for it to be stopped
+	err := server.RunAndWait(context.Background())
+	logOnError(err, "Server stopped unexpectedly ...")
 	log.Info("Stopping Application")
 }
 
-func newCloseableDatabase() recipes.RecipeDB {
-	recipesDB, err := recipes.NewDatabaseClient()
+func newCloseableDatabase(events *recipes.RecipeEvents) recipes.RecipeDB {
+	recipesDB, err := recipes.NewDatabaseClient(events)
 	failOnError(err)
 	return recipesDB
 }
 
-func closeDatabase(recipesDB recipes.RecipeDB) {
-	err := recipesDB.Close()
+func newCloseableMealPlanDatabase() recipes.MealPlanDB {
+	mealPlanDB, err := recipes.NewMealPlanDatabaseClient()
+	failOnError(err)
+	return mealPlanDB
+}
+
+func newCloseableFavoritesDatabase() recipes.FavoritesDB {
+	favoritesDB, err := recipes.NewFavoritesDatabaseClient()
+	failOnError(err)
+	return favoritesDB
+}
+
+func closeDatabase(db io.Closer) {
+	err := db.Close()
 	logOnError(err, "Could not close database ...")
 }
 
-func newServer(recipesDB recipes.RecipeDB, srcRepository sources.Sources) core.Server {
+func newServer(recipesDB recipes.RecipeDB, events *recipes.RecipeEvents, mealPlanDB recipes.MealPlanDB, favoritesDB recipes.FavoritesDB, srcRepository sources.Sources) core.Server {
 	handler := core.NewHandler()
+	handler.AddReadinessCheck("recipeDB", recipesDB.Ping)
+	handler.AddReadinessCheck("mealPlanDB", mealPlanDB.Ping)
+	handler.AddReadinessCheck("favoritesDB", favoritesDB.Ping)
+	if collector, ok := recipesDB.(prometheus.Collector); ok {
+		warnOnError(handler.RegisterMetrics(collector), "Could not register metrics of the recipes database")
+	}
 	server := core.NewServerH(handler)
 
-	addAPIsToServer(handler, recipesDB, srcRepository)
+	addAPIsToServer(handler, recipesDB, events, mealPlanDB, favoritesDB, srcRepository)
 
 	return server
 }
 
-func addAPIsToServer(handler core.Handler, recipesDB recipes.RecipeDB, srcRepository sources.Sources) {
-	recipes.AddRecipesAPIToHandler(handler, recipesDB)
+func addAPIsToServer(handler core.Handler, recipesDB recipes.RecipeDB, events *recipes.RecipeEvents, mealPlanDB recipes.MealPlanDB, favoritesDB recipes.FavoritesDB, srcRepository sources.Sources) {
+	recipes.AddRecipesAPIToHandler(handler, recipesDB, events)
+	recipes.AddMealPlanAPIToHandler(handler, mealPlanDB, recipesDB)
+	recipes.AddFavoritesAPIToHandler(handler, favoritesDB, recipesDB)
 	sourcesAPI := sources.NewSourceAPI(srcRepository, recipesDB)
 	sourcesAPI.PrepareAPI(handler, srcRepository, recipesDB)
 	core.AddCoreAPIToHandler(handler)
@@ -103,6 +138,15 @@ func newSources() sources.Sources {
 		warnOnError(err, "Could not add source")
 	}
 
+	paprika := sources.OpenPaprikaArchive()
+	if paprika.Connected() {
+		err = srcRepository.Add(
+			sources.NewSourceDescription(paprika.ID(), paprika.Name(), paprika.Version(), nil),
+			paprika,
+		)
+		warnOnError(err, "Could not add source")
+	}
+
 	return srcRepository
 }
 
@@ -0,0 +1,150 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+//CookStep is a step of a recipe in cook mode
+type CookStep struct {
+	//Number of the step, starting with 1
+	Number int    `json:"number"`
+	Text   string `json:"text"`
+	//Ingredients which are mentioned in the step's text
+	Ingredients []Ingredients `json:"ingredients"`
+	//Timer of the step; nil if the step does not take some time
+	Timer *RecipeTimer `json:"timer,omitempty"`
+}
+
+//CookMode is a recipe prepared for a guided cooking screen, i.e., its ingredients and its steps in the order they are cooked
+type CookMode struct {
+	ID          RecipeID      `json:"id"`
+	Name        string        `json:"name"`
+	Servings    int8          `json:"servings"`
+	Ingredients []Ingredients `json:"ingredients"`
+	Steps       []CookStep    `json:"steps"`
+	//TotalSeconds of all timers
+	TotalSeconds int `json:"totalSeconds"`
+}
+
+//CookMode returns the recipe's steps with the ingredients each step mentions and the steps' timers.
+//Recipes without structured steps are cooked line by line of their description; these steps have no timers.
+//Scale the recipe before, so that the steps list the scaled ingredients.
+func (r *Recipe) CookMode() *CookMode {
+	cook := &CookMode{
+		ID:          r.ID,
+		Name:        r.Name,
+		Servings:    r.Servings,
+		Ingredients: append(make([]Ingredients, 0, len(r.Ingredients)), r.Ingredients...),
+		Steps:       make([]CookStep, 0, len(r.Steps)),
+	}
+
+	texts := make([]string, 0, len(r.Steps))
+	for _, step := range r.Steps {
+		texts = append(texts, strings.TrimSpace(step.Text))
+	}
+	if len(texts) == 0 {
+		texts = instructionSteps(r.Description)
+	}
+
+	timers := make(map[int]RecipeTimer)
+	for _, timer := range r.Timers() {
+		timers[timer.Step] = timer
+	}
+
+	for i, text := range texts {
+		step := CookStep{Number: i + 1, Text: text, Ingredients: make([]Ingredients, 0)}
+		for _, ingredient := range cook.Ingredients {
+			if mentions(text, ingredient.Name) {
+				step.Ingredients = append(step.Ingredients, ingredient)
+			}
+		}
+		if timer, ok := timers[step.Number]; ok {
+			step.Timer = &timer
+			cook.TotalSeconds += timer.Seconds
+		}
+		cook.Steps = append(cook.Steps, step)
+	}
+	return cook
+}
+
+//mentions checks if a text contains the name of an ingredient as whole words, ignoring the case and plurals ending with 's' or 'es',
+//e.g., 'Peel the apples' mentions 'Apple' and 'Add an egg' mentions 'Eggs'
+func mentions(text string, name string) bool {
+	text = strings.ToLower(text)
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return false
+	}
+
+	names := []string{name}
+	if singular := strings.TrimSuffix(name, "es"); singular != name && singular != "" {
+		names = append(names, singular)
+	}
+	if singular := strings.TrimSuffix(name, "s"); singular != name && singular != "" {
+		names = append(names, singular)
+	}
+
+	for _, name := range names {
+		for start := 0; start < len(text); {
+			i := strings.Index(text[start:], name)
+			if i < 0 {
+				break
+			}
+			begin, end := start+i, start+i+len(name)
+			if wordBoundaryBefore(text, begin) && pluralBoundaryAfter(text, end) {
+				return true
+			}
+			start = begin + 1
+		}
+	}
+	return false
+}
+
+func wordBoundaryBefore(text string, i int) bool {
+	r, _ := utf8.DecodeLastRuneInString(text[:i])
+	return i == 0 || !isWordRune(r)
+}
+
+//pluralBoundaryAfter checks if a word ends at i or after a plural suffix at i
+func pluralBoundaryAfter(text string, i int) bool {
+	for _, suffix := range []string{"", "s", "es"} {
+		if !strings.HasPrefix(text[i:], suffix) {
+			continue
+		}
+		r, _ := utf8.DecodeRuneInString(text[i+len(suffix):])
+		if i+len(suffix) == len(text) || !isWordRune(r) {
+			return true
+		}
+	}
+	return false
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
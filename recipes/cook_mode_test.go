@@ -0,0 +1,72 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("cook mode", func() {
+
+	It("lists the ingredients mentioned by each step", func() {
+		recipe := NewRecipe(NewRecipeID())
+		recipe.Ingredients = []Ingredients{{Name: "Apple", Amount: 3}, {Name: "Eggs", Amount: 2}, {Name: "Sugar", Amount: 100, Unit: "g"}}
+		recipe.Steps = []RecipeStep{{Text: "Peel the apples."}, {Text: "Beat an egg with the sugar.", Minutes: 2}, {Text: "Serve with pineapple sugarcane."}}
+
+		cook := recipe.CookMode()
+
+		Expect(cook.Ingredients).To(Equal(recipe.Ingredients))
+		Expect(cook.Steps).To(Equal([]CookStep{
+			{Number: 1, Text: "Peel the apples.", Ingredients: []Ingredients{{Name: "Apple", Amount: 3}}},
+			{Number: 2, Text: "Beat an egg with the sugar.", Ingredients: []Ingredients{{Name: "Eggs", Amount: 2}, {Name: "Sugar", Amount: 100, Unit: "g"}},
+				Timer: &RecipeTimer{Step: 2, Label: "Beat an egg with the sugar.", Seconds: 120}},
+			{Number: 3, Text: "Serve with pineapple sugarcane.", Ingredients: []Ingredients{}},
+		}))
+		Expect(cook.TotalSeconds).To(Equal(120))
+	})
+
+	It("cooks recipes without steps line by line of their description", func() {
+		recipe := NewRecipe(NewRecipeID())
+		recipe.Ingredients = []Ingredients{{Name: "Äpfel", Amount: 3}}
+		recipe.Description = "Äpfel schälen\n\nBacken"
+
+		cook := recipe.CookMode()
+
+		Expect(cook.Steps).To(HaveLen(2))
+		Expect(cook.Steps[0].Ingredients).To(HaveLen(1))
+		Expect(cook.Steps[1].Text).To(Equal("Backen"))
+		Expect(cook.Steps[1].Timer).To(BeNil())
+	})
+
+	It("matches ingredients as whole words ignoring the case", func() {
+		Expect(mentions("Add the Olive Oil", "olive oil")).To(BeTrue())
+		Expect(mentions("Add tomatoes", "Tomato")).To(BeTrue())
+		Expect(mentions("Add a tomato", "Tomatoes")).To(BeTrue())
+		Expect(mentions("Add the oil", "Olive oil")).To(BeFalse())
+		Expect(mentions("Add the boiled water", "oil")).To(BeFalse())
+		Expect(mentions("Add salt", " ")).To(BeFalse())
+	})
+})
@@ -0,0 +1,212 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/ottenwbe/recipes-manager/core"
+	"github.com/ottenwbe/recipes-manager/utils"
+)
+
+const (
+	// eventsBufferCfg is the configuration key for the number of recent events that are replayed to reconnecting clients
+	eventsBufferCfg = "recipes.events.buffer"
+	// eventsKeepAliveCfg is the configuration key for the interval of comments that keep idle event streams open
+	eventsKeepAliveCfg = "recipes.events.keepAlive"
+)
+
+const (
+	//RecipeCreatedEvent is published when a recipe is created or restored
+	RecipeCreatedEvent = "recipe.created"
+	//RecipeUpdatedEvent is published when a recipe is updated, e.g., tagged or merged with its duplicates
+	RecipeUpdatedEvent = "recipe.updated"
+	//RecipeDeletedEvent is published when a recipe is archived or removed
+	RecipeDeletedEvent = "recipe.deleted"
+)
+
+// defaultEventsKeepAlive is clearly shorter than the default write timeout of the server, see html.timeouts.write
+const defaultEventsKeepAlive = 10 * time.Second
+
+// eventSubscriberQueueSize is the number of events buffered for each subscriber; slower subscribers are disconnected
+const eventSubscriberQueueSize = 32
+
+var eventsKeepAlive time.Duration
+
+func init() {
+	utils.Config.SetDefault(eventsBufferCfg, 100)
+	utils.Config.SetDefault(eventsKeepAliveCfg, defaultEventsKeepAlive.String())
+	eventsKeepAlive = validKeepAlive(utils.Config.GetDuration(eventsKeepAliveCfg), core.DefaultServerConfig("").WriteTimeout)
+}
+
+//validKeepAlive rejects intervals which are not shorter than the write timeout of the server,
+//since streams that cannot take over their connection, i.e., HTTP/2 streams, are closed after the write timeout
+func validKeepAlive(keepAlive time.Duration, writeTimeout time.Duration) time.Duration {
+	if keepAlive > 0 && (writeTimeout <= 0 || keepAlive < writeTimeout) {
+		return keepAlive
+	}
+	fallback := defaultEventsKeepAlive
+	if writeTimeout > 0 && fallback >= writeTimeout {
+		fallback = writeTimeout / 2
+	}
+	log.WithField("keepAlive", keepAlive).WithField("writeTimeout", writeTimeout).
+		Warnf("Invalid %v, it has to be shorter than the write timeout, falling back to %v", eventsKeepAliveCfg, fallback)
+	return fallback
+}
+
+//RecipeEvent informs about a change of a recipe
+type RecipeEvent struct {
+	//Type of the change, e.g., RecipeCreatedEvent
+	Type string `json:"type" enums:"recipe.created,recipe.updated,recipe.deleted"`
+	//ID of the changed recipe
+	ID RecipeID `json:"id"`
+	//At is the time of the change
+	At time.Time `json:"at"`
+}
+
+//RecipeNotifier is notified about changes of recipes
+type RecipeNotifier interface {
+	Notify(eventType string, id RecipeID)
+}
+
+//sequencedEvent is a RecipeEvent with its position in the stream of events, i.e., the id of the server-sent event
+type sequencedEvent struct {
+	RecipeEvent
+	seq uint64
+}
+
+//RecipeEvents is an in-process publish/subscribe of recipe events. The most recent events are kept in a ring buffer,
+//so that subscribers can catch up on the events they missed.
+type RecipeEvents struct {
+	mtx         sync.Mutex
+	seq         uint64
+	recent      []sequencedEvent
+	next        int
+	subscribers map[chan sequencedEvent]struct{}
+}
+
+//NewRecipeEventsFromConfig keeps as many recent events for replays as configured by recipes.events.buffer.
+//The events are published by a database client, see NewDatabaseClient, and streamed to the clients of an API, see AddRecipesAPIToHandler.
+func NewRecipeEventsFromConfig() *RecipeEvents {
+	return NewRecipeEvents(int(utils.Config.GetInt64(eventsBufferCfg)))
+}
+
+//NewRecipeEvents keeps up to size recent events for replays
+func NewRecipeEvents(size int) *RecipeEvents {
+	if size < 1 {
+		size = 1
+	}
+	return &RecipeEvents{
+		recent:      make([]sequencedEvent, 0, size),
+		subscribers: make(map[chan sequencedEvent]struct{}),
+	}
+}
+
+//Notify publishes an event to all subscribers without waiting for them.
+//Subscribers that cannot keep up are disconnected; they can resume with the id of the last event they received.
+func (e *RecipeEvents) Notify(eventType string, id RecipeID) {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+
+	e.seq++
+	event := sequencedEvent{RecipeEvent: RecipeEvent{Type: eventType, ID: id, At: changeTime()}, seq: e.seq}
+	if len(e.recent) < cap(e.recent) {
+		e.recent = append(e.recent, event)
+	} else {
+		e.recent[e.next] = event
+	}
+	e.next = (e.next + 1) % cap(e.recent)
+
+	for subscriber := range e.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+			delete(e.subscribers, subscriber)
+			close(subscriber)
+		}
+	}
+}
+
+//subscribe to events. If lastEventID is the id of a recent event, the events after it are returned for a replay;
+//if it is older than all recent events, all recent events are returned. The events are closed when the subscriber
+//is disconnected; call unsubscribe when the subscriber is done.
+func (e *RecipeEvents) subscribe(lastEventID string) (replay []sequencedEvent, events <-chan sequencedEvent, unsubscribe func()) {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+
+	if last, err := strconv.ParseUint(lastEventID, 10, 64); err == nil {
+		replay = e.since(last)
+	}
+
+	subscriber := make(chan sequencedEvent, eventSubscriberQueueSize)
+	e.subscribers[subscriber] = struct{}{}
+
+	unsubscribe = func() {
+		e.mtx.Lock()
+		defer e.mtx.Unlock()
+		if _, ok := e.subscribers[subscriber]; ok {
+			delete(e.subscribers, subscriber)
+			close(subscriber)
+		}
+	}
+	return replay, subscriber, unsubscribe
+}
+
+//since returns the recent events after the event with the sequence number last, ordered from old to new.
+//All recent events are returned if last is unknown, e.g., since it was sent before a restart.
+func (e *RecipeEvents) since(last uint64) []sequencedEvent {
+	if last > e.seq {
+		last = 0
+	}
+	events := make([]sequencedEvent, 0)
+	oldest := 0
+	if len(e.recent) == cap(e.recent) {
+		oldest = e.next
+	}
+	for i := 0; i < len(e.recent); i++ {
+		event := e.recent[(oldest+i)%len(e.recent)]
+		if event.seq > last {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+//writeServerSentEvent writes an event with its sequence number as id, its type as event type, and the json encoded event as data
+func writeServerSentEvent(w io.Writer, event sequencedEvent) error {
+	data, err := json.Marshal(event.RecipeEvent)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.seq, event.Type, data)
+	return err
+}
@@ -0,0 +1,143 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	"context"
+	"io"
+)
+
+//EventDB wraps a RecipeDB and notifies RecipeNotifiers, e.g., Webhooks, about the recipes that were created, updated, or deleted.
+//Archived recipes are reported as deleted and restored recipes as created, since archived recipes are not listed.
+type EventDB struct {
+	RecipeDB
+	notifiers []RecipeNotifier
+}
+
+//NewEventDB notifies the notifiers about changes of the inner database
+func NewEventDB(inner RecipeDB, notifiers ...RecipeNotifier) *EventDB {
+	return &EventDB{
+		RecipeDB:  inner,
+		notifiers: notifiers,
+	}
+}
+
+//WithContext returns a view of the database whose operations on the inner database are cancelled with the context
+func (e *EventDB) WithContext(ctx context.Context) RecipeDB {
+	return NewEventDB(e.RecipeDB.WithContext(ctx), e.notifiers...)
+}
+
+func (e *EventDB) notify(eventType string, id RecipeID) {
+	for _, notifier := range e.notifiers {
+		notifier.Notify(eventType, id)
+	}
+}
+
+//Insert a recipe and notify the notifiers
+func (e *EventDB) Insert(recipe *Recipe) error {
+	err := e.RecipeDB.Insert(recipe)
+	if err == nil {
+		e.notify(RecipeCreatedEvent, recipe.ID)
+	}
+	return err
+}
+
+//Update a recipe and notify the notifiers
+func (e *EventDB) Update(id RecipeID, recipe *Recipe) error {
+	err := e.RecipeDB.Update(id, recipe)
+	if err == nil {
+		e.notify(RecipeUpdatedEvent, id)
+	}
+	return err
+}
+
+//Remove a recipe and notify the notifiers
+func (e *EventDB) Remove(id RecipeID) error {
+	err := e.RecipeDB.Remove(id)
+	if err == nil {
+		e.notify(RecipeDeletedEvent, id)
+	}
+	return err
+}
+
+//RemoveByName removes a recipe and notifies the notifiers
+func (e *EventDB) RemoveByName(name string) error {
+	recipe, err := e.RecipeDB.GetByName(name)
+	if err != nil {
+		return e.RecipeDB.RemoveByName(name)
+	}
+	return e.Remove(recipe.ID)
+}
+
+//Archive a recipe and notify the notifiers
+func (e *EventDB) Archive(id RecipeID) error {
+	err := e.RecipeDB.Archive(id)
+	if err == nil {
+		e.notify(RecipeDeletedEvent, id)
+	}
+	return err
+}
+
+//Restore a recipe and notify the notifiers
+func (e *EventDB) Restore(id RecipeID) error {
+	err := e.RecipeDB.Restore(id)
+	if err == nil {
+		e.notify(RecipeCreatedEvent, id)
+	}
+	return err
+}
+
+//BulkTag tags recipes and notifies the notifiers about the existing recipes
+func (e *EventDB) BulkTag(ids []RecipeID, add []string, remove []string) (map[RecipeID]bool, error) {
+	results, err := e.RecipeDB.BulkTag(ids, add, remove)
+	for id, exists := range results {
+		if exists {
+			e.notify(RecipeUpdatedEvent, id)
+		}
+	}
+	return results, err
+}
+
+//Merge duplicates into a recipe and notify the notifiers about the updated recipe and the deleted duplicates
+func (e *EventDB) Merge(primary RecipeID, duplicates []RecipeID) (*Recipe, error) {
+	merged, err := e.RecipeDB.Merge(primary, duplicates)
+	if err == nil {
+		e.notify(RecipeUpdatedEvent, primary)
+		for _, duplicate := range duplicates {
+			e.notify(RecipeDeletedEvent, duplicate)
+		}
+	}
+	return merged, err
+}
+
+//Close the notifiers that need to be closed, e.g., Webhooks, and the inner database
+func (e *EventDB) Close() error {
+	for _, notifier := range e.notifiers {
+		if closer, ok := notifier.(io.Closer); ok {
+			_ = closer.Close()
+		}
+	}
+	return e.RecipeDB.Close()
+}
@@ -0,0 +1,158 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	"bytes"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ottenwbe/recipes-manager/core"
+)
+
+var _ = Describe("recipe events", func() {
+
+	var events *RecipeEvents
+
+	BeforeEach(func() {
+		events = NewRecipeEvents(3)
+	})
+
+	ids := func(events []sequencedEvent) []RecipeID {
+		result := make([]RecipeID, len(events))
+		for i, event := range events {
+			result[i] = event.ID
+		}
+		return result
+	}
+
+	It("publishes events to subscribers", func() {
+		_, received, unsubscribe := events.subscribe("")
+		defer unsubscribe()
+		id := NewRecipeID()
+
+		events.Notify(RecipeCreatedEvent, id)
+
+		var event sequencedEvent
+		Expect(received).To(Receive(&event))
+		Expect(event.Type).To(Equal(RecipeCreatedEvent))
+		Expect(event.ID).To(Equal(id))
+		Expect(event.seq).To(Equal(uint64(1)))
+	})
+
+	It("replays the events after the last event id", func() {
+		first, second, third := NewRecipeID(), NewRecipeID(), NewRecipeID()
+		events.Notify(RecipeCreatedEvent, first)
+		events.Notify(RecipeUpdatedEvent, second)
+		events.Notify(RecipeDeletedEvent, third)
+
+		replay, _, unsubscribe := events.subscribe("1")
+		defer unsubscribe()
+
+		Expect(ids(replay)).To(Equal([]RecipeID{second, third}))
+	})
+
+	It("replays the recent events only", func() {
+		recipeIDs := make([]RecipeID, 5)
+		for i := range recipeIDs {
+			recipeIDs[i] = NewRecipeID()
+			events.Notify(RecipeUpdatedEvent, recipeIDs[i])
+		}
+
+		replay, _, unsubscribe := events.subscribe("1")
+		defer unsubscribe()
+
+		Expect(ids(replay)).To(Equal(recipeIDs[2:]))
+	})
+
+	It("replays all recent events for unknown event ids", func() {
+		id := NewRecipeID()
+		events.Notify(RecipeCreatedEvent, id)
+
+		replay, _, unsubscribe := events.subscribe("42")
+		defer unsubscribe()
+
+		Expect(ids(replay)).To(Equal([]RecipeID{id}))
+	})
+
+	It("does not replay events without a last event id", func() {
+		events.Notify(RecipeCreatedEvent, NewRecipeID())
+
+		replay, _, unsubscribe := events.subscribe("")
+		defer unsubscribe()
+
+		Expect(replay).To(BeEmpty())
+	})
+
+	It("disconnects subscribers that cannot keep up", func() {
+		_, received, unsubscribe := events.subscribe("")
+		defer unsubscribe()
+
+		for i := 0; i <= eventSubscriberQueueSize; i++ {
+			events.Notify(RecipeUpdatedEvent, NewRecipeID())
+		}
+
+		Eventually(received).Should(BeClosed())
+	})
+
+	It("stops publishing to unsubscribed subscribers", func() {
+		_, received, unsubscribe := events.subscribe("")
+		unsubscribe()
+		unsubscribe()
+
+		events.Notify(RecipeUpdatedEvent, NewRecipeID())
+
+		Expect(received).To(BeClosed())
+	})
+
+	It("writes server-sent events", func() {
+		id := NewRecipeID()
+		events.Notify(RecipeDeletedEvent, id)
+		replay, _, unsubscribe := events.subscribe("0")
+		defer unsubscribe()
+
+		var buffer bytes.Buffer
+		Expect(writeServerSentEvent(&buffer, replay[0])).To(Succeed())
+
+		Expect(buffer.String()).To(HavePrefix("id: " + strconv.Itoa(1) + "\nevent: recipe.deleted\ndata: {\"type\":\"recipe.deleted\",\"id\":\"" + id.String() + "\""))
+		Expect(buffer.String()).To(HaveSuffix("}\n\n"))
+	})
+
+	It("keeps idle streams open within the default timeouts of the server", func() {
+		Expect(eventsKeepAlive).To(Equal(defaultEventsKeepAlive))
+		Expect(eventsKeepAlive).To(BeNumerically("<", core.DefaultServerConfig("").WriteTimeout))
+	})
+
+	It("rejects keep-alive intervals which are not shorter than the write timeout", func() {
+		Expect(validKeepAlive(5*time.Second, 15*time.Second)).To(Equal(5 * time.Second))
+		Expect(validKeepAlive(time.Minute, 0)).To(Equal(time.Minute))
+		Expect(validKeepAlive(15*time.Second, 15*time.Second)).To(Equal(defaultEventsKeepAlive))
+		Expect(validKeepAlive(0, 15*time.Second)).To(Equal(defaultEventsKeepAlive))
+		Expect(validKeepAlive(15*time.Second, 8*time.Second)).To(Equal(4 * time.Second))
+	})
+})
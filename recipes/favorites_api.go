@@ -0,0 +1,175 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	"net/http"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/ottenwbe/recipes-manager/core"
+)
+
+//FavoritesAPI for the favorite recipes of users
+type FavoritesAPI struct {
+	handler   core.Handler
+	favorites FavoritesDB
+	recipes   Recipes
+}
+
+// AddFavoritesAPIToHandler constructs an API for favorites
+func AddFavoritesAPIToHandler(handler core.Handler, favorites FavoritesDB, recipes Recipes) {
+	fAPI := &FavoritesAPI{
+		handler,
+		favorites,
+		recipes,
+	}
+
+	fAPI.prepareV1API()
+}
+
+func (fAPI *FavoritesAPI) prepareV1API() {
+
+	if fAPI.handler == nil {
+		log.WithField("Component", "Favorites API").Fatal("No handler defined")
+		return
+	}
+
+	if fAPI.favorites == nil || fAPI.recipes == nil {
+		log.WithField("Component", "Favorites API").Fatal("No persistence defined")
+		return
+	}
+
+	// favorites belong to the user of the api key
+	secured := fAPI.handler.API(1).Secured()
+
+	//GET the favorite recipes of the user
+	secured.GET("/recipes/favorites", fAPI.getFavorites)
+
+	//PUT marks a specific recipe as favorite
+	secured.PUT("/recipes/r/:recipe/favorite", fAPI.putFavorite)
+
+	//DELETE removes a specific recipe from the favorites
+	secured.DELETE("/recipes/r/:recipe/favorite", fAPI.deleteFavorite)
+}
+
+// getFavorites example
+// @Summary Get Favorite Recipes
+// @Description A list of ids of the user's favorite recipes is returned in the order they were marked as favorite
+// @Tags Favorites
+// @Param limit query int false "Maximal number of returned ids (default 50, max 500)"
+// @Param offset query int false "Number of ids to skip"
+// @Produce json
+// @Produce application/yaml
+// @Success 200 {object} RecipeList
+// @Header 200 {integer} X-Total-Count "Number of favorite recipes"
+// @Failure 400 {object} core.APIError
+// @Security ApiKeyAuth
+// @Router /recipes/favorites [get]
+func (fAPI *FavoritesAPI) getFavorites(c *core.APICallContext) {
+	offset, limit, err := extractPaging(c.Request.URL.Query())
+	if err != nil {
+		core.AbortWithAPIError(c, http.StatusBadRequest, "Invalid paging parameters", err.Error())
+		return
+	}
+
+	// recipes may have been removed after they were marked as favorite
+	favorites := make([]string, 0)
+	for _, id := range fAPI.favorites.Favorites(core.AuthenticatedUser(c)).Recipes {
+		if fAPI.recipes.Get(RecipeID(id)).ID != InvalidRecipeID() {
+			favorites = append(favorites, id)
+		}
+	}
+
+	c.Header(totalCountHeader, strconv.Itoa(len(favorites)))
+	core.Respond(c, http.StatusOK, RecipeList{Recipes: pageOf(favorites, offset, limit)})
+}
+
+// putFavorite example
+// @Summary Mark a Recipe as Favorite
+// @Description The recipe is added to the user's favorites; the new state is returned
+// @Tags Favorites
+// @Param recipe path string true "Recipe ID"
+// @Produce json
+// @Success 200 {object} FavoriteState
+// @Failure 400 {object} core.APIError
+// @Failure 404 {object} core.APIError
+// @Security ApiKeyAuth
+// @Router /recipes/r/{recipe}/favorite [put]
+func (fAPI *FavoritesAPI) putFavorite(c *core.APICallContext) {
+	recipeID, ok := recipeIDParam(c)
+	if !ok {
+		return
+	}
+
+	if fAPI.recipes.Get(recipeID).ID == InvalidRecipeID() {
+		core.AbortWithAPIError(c, http.StatusNotFound, "No such recipe", c.Param(RECIPE))
+		return
+	}
+
+	if err := fAPI.favorites.Add(core.AuthenticatedUser(c), recipeID); err != nil {
+		core.AbortWithAPIError(c, http.StatusInternalServerError, "Could not add favorite", "")
+		return
+	}
+
+	core.RespondJSON(c, http.StatusOK, FavoriteState{Recipe: recipeID, Favorite: true})
+}
+
+// deleteFavorite example
+// @Summary Remove a Recipe from the Favorites
+// @Description The recipe is removed from the user's favorites; the new state is returned
+// @Tags Favorites
+// @Param recipe path string true "Recipe ID"
+// @Produce json
+// @Success 200 {object} FavoriteState
+// @Failure 400 {object} core.APIError
+// @Security ApiKeyAuth
+// @Router /recipes/r/{recipe}/favorite [delete]
+func (fAPI *FavoritesAPI) deleteFavorite(c *core.APICallContext) {
+	recipeID, ok := recipeIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := fAPI.favorites.Remove(core.AuthenticatedUser(c), recipeID); err != nil {
+		core.AbortWithAPIError(c, http.StatusInternalServerError, "Could not remove favorite", "")
+		return
+	}
+
+	core.RespondJSON(c, http.StatusOK, FavoriteState{Recipe: recipeID, Favorite: false})
+}
+
+//pageOf returns the ids between offset and offset+limit
+func pageOf(ids []string, offset int64, limit int64) []string {
+	if offset >= int64(len(ids)) {
+		return make([]string, 0)
+	}
+	end := offset + limit
+	if end > int64(len(ids)) {
+		end = int64(len(ids))
+	}
+	return ids[offset:end]
+}
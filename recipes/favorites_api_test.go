@@ -0,0 +1,134 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ottenwbe/recipes-manager/core"
+)
+
+var _ = Describe("favoritesAPI", func() {
+
+	var (
+		handler   core.Handler
+		favorites FavoritesDB
+		recipeDB  RecipeDB
+		call      func(method string, path string) *httptest.ResponseRecorder
+	)
+
+	BeforeEach(func() {
+		favorites = NewInMemoryFavoritesDB()
+		recipeDB = NewInMemoryDB()
+		handler = core.NewHandler()
+		AddFavoritesAPIToHandler(handler, favorites, recipeDB)
+
+		call = func(method string, path string) *httptest.ResponseRecorder {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(method, "/api/v1"+path, nil))
+			return w
+		}
+	})
+
+	AfterEach(func() {
+		favorites.Clear()
+		favorites.Close()
+		recipeDB.Close()
+	})
+
+	favoriteState := func(w *httptest.ResponseRecorder) FavoriteState {
+		var state FavoriteState
+		Expect(json.NewDecoder(w.Body).Decode(&state)).To(Succeed())
+		return state
+	}
+
+	favoriteList := func() RecipeList {
+		w := call(http.MethodGet, "/recipes/favorites")
+		Expect(w.Code).To(Equal(http.StatusOK))
+		var list RecipeList
+		Expect(json.NewDecoder(w.Body).Decode(&list)).To(Succeed())
+		return list
+	}
+
+	It("marks recipes as favorite and lists them", func() {
+		first := createAndPersistDefaultRecipe(recipeDB)
+		second := createAndPersistDefaultRecipe(recipeDB)
+
+		w := call(http.MethodPut, "/recipes/r/"+second.String()+"/favorite")
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(favoriteState(w)).To(Equal(FavoriteState{Recipe: second, Favorite: true}))
+		call(http.MethodPut, "/recipes/r/"+first.String()+"/favorite")
+		call(http.MethodPut, "/recipes/r/"+second.String()+"/favorite")
+
+		Expect(favoriteList().Recipes).To(Equal([]string{second.String(), first.String()}))
+	})
+
+	It("removes recipes from the favorites", func() {
+		id := createAndPersistDefaultRecipe(recipeDB)
+		call(http.MethodPut, "/recipes/r/"+id.String()+"/favorite")
+
+		w := call(http.MethodDelete, "/recipes/r/"+id.String()+"/favorite")
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(favoriteState(w)).To(Equal(FavoriteState{Recipe: id, Favorite: false}))
+
+		Expect(favoriteList().Recipes).To(BeEmpty())
+	})
+
+	It("pages the favorites and counts them", func() {
+		for i := 0; i < 3; i++ {
+			call(http.MethodPut, "/recipes/r/"+createAndPersistDefaultRecipe(recipeDB).String()+"/favorite")
+		}
+
+		w := call(http.MethodGet, "/recipes/favorites?offset=1&limit=1")
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Header().Get("X-Total-Count")).To(Equal("3"))
+		var list RecipeList
+		Expect(json.NewDecoder(w.Body).Decode(&list)).To(Succeed())
+		Expect(list.Recipes).To(HaveLen(1))
+	})
+
+	It("does not list removed recipes", func() {
+		id := createAndPersistDefaultRecipe(recipeDB)
+		call(http.MethodPut, "/recipes/r/"+id.String()+"/favorite")
+		recipeDB.Remove(id)
+
+		Expect(favoriteList().Recipes).To(BeEmpty())
+	})
+
+	It("returns 404 for unknown recipes", func() {
+		w := call(http.MethodPut, "/recipes/r/"+NewRecipeID().String()+"/favorite")
+		Expect(w.Code).To(Equal(http.StatusNotFound))
+	})
+
+	It("returns 400 for invalid recipe ids", func() {
+		w := call(http.MethodDelete, "/recipes/r/in_valid/favorite")
+		Expect(w.Code).To(Equal(http.StatusBadRequest))
+	})
+})
@@ -0,0 +1,48 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	"io"
+)
+
+//FavoriteState tells if a recipe is a favorite of the authenticated user
+type FavoriteState struct {
+	Recipe   RecipeID `json:"recipe"`
+	Favorite bool     `json:"favorite"`
+}
+
+//FavoritesDB is the interface that all DB implementations for favorite recipes of users have to expose
+type FavoritesDB interface {
+	io.Closer
+	//Favorites lists the ids of a user's favorite recipes in the order they were added
+	Favorites(user string) RecipeList
+	//Add a recipe to a user's favorites; adding a favorite twice has no effect
+	Add(user string, id RecipeID) error
+	//Remove a recipe from a user's favorites; removing a recipe which is no favorite has no effect
+	Remove(user string, id RecipeID) error
+	Ping() error
+	Clear()
+}
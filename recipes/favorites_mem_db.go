@@ -0,0 +1,101 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	"sync"
+)
+
+//InMemoryFavoritesDB implements the FavoritesDB interface without a database. It is safe for concurrent use.
+type InMemoryFavoritesDB struct {
+	mtx       sync.RWMutex
+	favorites map[string][]RecipeID
+}
+
+//NewInMemoryFavoritesDB returns an empty in-memory database for favorites
+func NewInMemoryFavoritesDB() *InMemoryFavoritesDB {
+	return &InMemoryFavoritesDB{
+		favorites: make(map[string][]RecipeID),
+	}
+}
+
+//Favorites lists the ids of a user's favorite recipes in the order they were added
+func (m *InMemoryFavoritesDB) Favorites(user string) RecipeList {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	result := make([]string, 0, len(m.favorites[user]))
+	for _, id := range m.favorites[user] {
+		result = append(result, id.String())
+	}
+	return RecipeList{Recipes: result}
+}
+
+//Add a recipe to a user's favorites
+func (m *InMemoryFavoritesDB) Add(user string, id RecipeID) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	for _, favorite := range m.favorites[user] {
+		if favorite == id {
+			return nil
+		}
+	}
+	m.favorites[user] = append(m.favorites[user], id)
+	return nil
+}
+
+//Remove a recipe from a user's favorites
+func (m *InMemoryFavoritesDB) Remove(user string, id RecipeID) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	favorites := m.favorites[user]
+	for i, favorite := range favorites {
+		if favorite == id {
+			m.favorites[user] = append(favorites[:i:i], favorites[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+//Ping always succeeds
+func (m *InMemoryFavoritesDB) Ping() error {
+	return nil
+}
+
+//Close does nothing, since there is no connection to close
+func (m *InMemoryFavoritesDB) Close() error {
+	return nil
+}
+
+//Clear removes all favorites of all users
+func (m *InMemoryFavoritesDB) Clear() {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	m.favorites = make(map[string][]RecipeID)
+}
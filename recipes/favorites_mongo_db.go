@@ -0,0 +1,191 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	"errors"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+const (
+	//FAVORITES index
+	FAVORITES = "favorites"
+)
+
+//favorite is a document in the favorites collection
+type favorite struct {
+	User   string   `bson:"user"`
+	Recipe RecipeID `bson:"recipe"`
+}
+
+//MongoFavoritesDB implements the FavoritesDB interface to read and write favorites to and from a Mongo DB
+type MongoFavoritesDB struct {
+	mongoClient *mongo.Client
+	//mtx avoids race conditions while connecting to the database and while closing the connection
+	mtx sync.Mutex
+}
+
+//Clear drops all favorites
+func (m *MongoFavoritesDB) Clear() {
+	if err := m.getFavoritesCollection().Drop(ctx()); err != nil {
+		log.WithError(err).Error("Could not drop favorites from MongoDB")
+	}
+}
+
+//Favorites lists the ids of a user's favorite recipes in the order they were added
+func (m *MongoFavoritesDB) Favorites(user string) RecipeList {
+
+	collection := m.getFavoritesCollection()
+
+	favorites := make([]*favorite, 0)
+	result := make([]string, 0)
+
+	findOptions := options.Find().SetProjection(bson.M{"recipe": 1}).SetSort(bson.M{"_id": 1})
+	cursor, err := collection.Find(ctx(), bson.M{"user": user}, findOptions)
+	if err != nil {
+		log.WithError(err).Info("Error while finding favorites")
+		return RecipeList{Recipes: result}
+	}
+	defer func() { _ = cursor.Close(ctx()) }()
+
+	if err = cursor.All(ctx(), &favorites); err != nil {
+		log.WithError(err).Info("Error while finding favorites")
+	}
+
+	for _, f := range favorites {
+		result = append(result, f.Recipe.String())
+	}
+
+	return RecipeList{Recipes: result}
+}
+
+//Add a recipe to a user's favorites
+func (m *MongoFavoritesDB) Add(user string, id RecipeID) error {
+
+	collection := m.getFavoritesCollection()
+
+	f := favorite{User: user, Recipe: id}
+	_, err := collection.UpdateOne(ctx(), f, bson.M{"$setOnInsert": f}, options.Update().SetUpsert(true))
+	if err != nil {
+		log.WithError(err).Error("Could not add favorite")
+		return err
+	}
+
+	return nil
+}
+
+//Remove a recipe from a user's favorites
+func (m *MongoFavoritesDB) Remove(user string, id RecipeID) error {
+
+	collection := m.getFavoritesCollection()
+
+	_, err := collection.DeleteOne(ctx(), favorite{User: user, Recipe: id})
+	if err != nil {
+		log.WithError(err).Error("Could not remove favorite")
+		return err
+	}
+
+	return nil
+}
+
+//Ping MongoDB
+func (m *MongoFavoritesDB) Ping() error {
+	return m.mongoClient.Ping(ctx(), readpref.Primary())
+}
+
+//Close the connection to the database
+func (m *MongoFavoritesDB) Close() error {
+	return m.StopDB()
+}
+
+//StartDB initializes the database connection
+func (m *MongoFavoritesDB) StartDB() error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if m.mongoClient != nil {
+		return errors.New("database is already running")
+	}
+
+	if err := m.connectToDB(); err != nil {
+		log.WithError(err).Error("Database is not connected")
+		return errors.New("database is not connected")
+	}
+
+	return nil
+}
+
+//StopDB closes the connection to the db
+func (m *MongoFavoritesDB) StopDB() (err error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if m.mongoClient != nil {
+		err = m.mongoClient.Disconnect(ctx())
+	}
+	m.mongoClient = nil
+
+	return
+}
+
+func (m *MongoFavoritesDB) connectToDB() (err error) {
+	log.WithField("addr", mongoAddress).Info("Connecting to DB")
+	m.mongoClient, err = mongo.NewClient(options.Client().ApplyURI(mongoAddress))
+	if err != nil {
+		log.WithError(err).Info("Could not create MongoDB client")
+		return
+	}
+	if err = m.mongoClient.Connect(ctx()); err != nil {
+		log.WithError(err).Info("Could not connect to MongoDB")
+		return
+	}
+	if err = m.Ping(); err != nil {
+		log.WithError(err).Info("Could not ping MongoDB")
+		return
+	}
+	if err = m.ensureFavoritesIndex(); err != nil {
+		log.WithError(err).Info("Could not create mongo db favorites index")
+	}
+	return
+}
+
+func (m *MongoFavoritesDB) ensureFavoritesIndex() error {
+	index := mongo.IndexModel{
+		Keys:    bson.D{{Key: "user", Value: 1}, {Key: "recipe", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}
+	_, err := m.getFavoritesCollection().Indexes().CreateOne(ctx(), index)
+	return err
+}
+
+func (m *MongoFavoritesDB) getFavoritesCollection() *mongo.Collection {
+	return m.mongoClient.Database(DATABASE).Collection(FAVORITES)
+}
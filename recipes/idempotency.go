@@ -0,0 +1,163 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/ottenwbe/recipes-manager/utils"
+)
+
+const (
+	// idempotencyKeyHeader is sent by clients that retry the creation of a recipe
+	idempotencyKeyHeader = "Idempotency-Key"
+	// idempotentReplayedHeader tells clients that a response was replayed for a repeated Idempotency-Key
+	idempotentReplayedHeader = "Idempotent-Replayed"
+	// maxIdempotencyKeyLength is the maximal length of an Idempotency-Key
+	maxIdempotencyKeyLength = 255
+	// idempotencyTTLCfg is the configuration key for the time an Idempotency-Key is remembered, e.g., 24h
+	idempotencyTTLCfg = "recipes.idempotency.ttl"
+	// idempotencyCleanupInterval defines how often expired keys are dropped
+	idempotencyCleanupInterval = time.Minute
+)
+
+var idempotencyTTL time.Duration
+
+func init() {
+	utils.Config.SetDefault(idempotencyTTLCfg, "24h")
+	idempotencyTTL = utils.Config.GetDuration(idempotencyTTLCfg)
+}
+
+//IdempotencyState is the outcome of reserving an Idempotency-Key
+type IdempotencyState int
+
+const (
+	//IdempotencyNew means the key was not used before and is now reserved for the request
+	IdempotencyNew IdempotencyState = iota
+	//IdempotencyDone means a recipe was already created with the key and the same request
+	IdempotencyDone
+	//IdempotencyInProgress means another request with the key has not finished yet
+	IdempotencyInProgress
+	//IdempotencyMismatch means the key was used for a different request
+	IdempotencyMismatch
+)
+
+//idempotencyEntry remembers the request and the created recipe of a key; the id is invalid while the request is in progress
+type idempotencyEntry struct {
+	fingerprint string
+	id          RecipeID
+	expires     time.Time
+}
+
+//IdempotencyStore remembers Idempotency-Keys for a ttl, so that repeated requests do not create recipes again
+type IdempotencyStore struct {
+	ttl         time.Duration
+	entries     map[string]*idempotencyEntry
+	lastCleanup time.Time
+	now         func() time.Time
+	mtx         sync.Mutex
+}
+
+//NewIdempotencyStore remembers keys for ttl
+func NewIdempotencyStore(ttl time.Duration) *IdempotencyStore {
+	return &IdempotencyStore{
+		ttl:     ttl,
+		entries: make(map[string]*idempotencyEntry),
+		now:     time.Now,
+	}
+}
+
+//Reserve the key for a request identified by the fingerprint.
+//If a recipe was already created with the key and the same fingerprint, its id is returned with IdempotencyDone.
+func (s *IdempotencyStore) Reserve(key string, fingerprint string) (RecipeID, IdempotencyState) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	now := s.now()
+	s.cleanup(now)
+
+	entry, ok := s.entries[key]
+	if !ok || now.After(entry.expires) {
+		s.entries[key] = &idempotencyEntry{fingerprint: fingerprint, id: InvalidRecipeID(), expires: now.Add(s.ttl)}
+		return InvalidRecipeID(), IdempotencyNew
+	}
+	if entry.fingerprint != fingerprint {
+		return InvalidRecipeID(), IdempotencyMismatch
+	}
+	if entry.id == InvalidRecipeID() {
+		return InvalidRecipeID(), IdempotencyInProgress
+	}
+	return entry.id, IdempotencyDone
+}
+
+//Complete the request of a reserved key with the id of the created recipe
+func (s *IdempotencyStore) Complete(key string, id RecipeID) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if entry, ok := s.entries[key]; ok {
+		entry.id = id
+		entry.expires = s.now().Add(s.ttl)
+	}
+}
+
+//Release a reserved key whose request failed, so that the request can be retried
+func (s *IdempotencyStore) Release(key string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if entry, ok := s.entries[key]; ok && entry.id == InvalidRecipeID() {
+		delete(s.entries, key)
+	}
+}
+
+//cleanup drops expired keys
+func (s *IdempotencyStore) cleanup(now time.Time) {
+	if now.Sub(s.lastCleanup) < idempotencyCleanupInterval {
+		return
+	}
+	s.lastCleanup = now
+	for key, entry := range s.entries {
+		if now.After(entry.expires) {
+			delete(s.entries, key)
+		}
+	}
+}
+
+//idempotencyStoreKey scopes the Idempotency-Key of a request by the authenticated user, so that clients cannot replay or block the requests of others
+func idempotencyStoreKey(user string, key string) string {
+	return user + ":" + key
+}
+
+//idempotencyFingerprint identifies the recipe of a request; the id is ignored, since it is generated by the backend
+func idempotencyFingerprint(recipe Recipe) string {
+	recipe.ID = InvalidRecipeID()
+	recipe.Version = 0
+	hash := sha256.Sum256(recipe.JSON())
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,122 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("idempotency", func() {
+
+	var (
+		store *IdempotencyStore
+		now   time.Time
+	)
+
+	BeforeEach(func() {
+		now = time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+		store = NewIdempotencyStore(time.Hour)
+		store.now = func() time.Time { return now }
+	})
+
+	It("reserves new keys", func() {
+		_, state := store.Reserve("key", "soup")
+		Expect(state).To(Equal(IdempotencyNew))
+	})
+
+	It("returns the recipe created with a key", func() {
+		id := NewRecipeID()
+		store.Reserve("key", "soup")
+		store.Complete("key", id)
+
+		replayed, state := store.Reserve("key", "soup")
+		Expect(state).To(Equal(IdempotencyDone))
+		Expect(replayed).To(Equal(id))
+	})
+
+	It("detects requests in progress", func() {
+		store.Reserve("key", "soup")
+		_, state := store.Reserve("key", "soup")
+		Expect(state).To(Equal(IdempotencyInProgress))
+	})
+
+	It("detects keys used for different requests", func() {
+		store.Reserve("key", "soup")
+		store.Complete("key", NewRecipeID())
+		_, state := store.Reserve("key", "stew")
+		Expect(state).To(Equal(IdempotencyMismatch))
+	})
+
+	It("scopes keys by the user", func() {
+		store.Reserve(idempotencyStoreKey("alice", "key"), "soup")
+		_, state := store.Reserve(idempotencyStoreKey("bob", "key"), "stew")
+		Expect(state).To(Equal(IdempotencyNew))
+	})
+
+	It("allows to retry released keys", func() {
+		store.Reserve("key", "soup")
+		store.Release("key")
+		_, state := store.Reserve("key", "stew")
+		Expect(state).To(Equal(IdempotencyNew))
+	})
+
+	It("does not release completed keys", func() {
+		store.Reserve("key", "soup")
+		store.Complete("key", NewRecipeID())
+		store.Release("key")
+		_, state := store.Reserve("key", "soup")
+		Expect(state).To(Equal(IdempotencyDone))
+	})
+
+	It("forgets keys after the ttl", func() {
+		store.Reserve("key", "soup")
+		store.Complete("key", NewRecipeID())
+
+		now = now.Add(time.Hour + time.Second)
+		_, state := store.Reserve("key", "soup")
+		Expect(state).To(Equal(IdempotencyNew))
+	})
+
+	It("drops expired keys", func() {
+		store.Reserve("key", "soup")
+		now = now.Add(2 * time.Hour)
+		store.Reserve("other", "soup")
+		Expect(store.entries).To(HaveLen(1))
+		Expect(store.entries).To(HaveKey("other"))
+	})
+
+	It("ignores the id of recipes when comparing requests", func() {
+		recipe := Recipe{ID: NewRecipeID(), Name: "soup", Servings: 2}
+		retried := recipe
+		retried.ID = NewRecipeID()
+		Expect(idempotencyFingerprint(retried)).To(Equal(idempotencyFingerprint(recipe)))
+
+		retried.Servings = 4
+		Expect(idempotencyFingerprint(retried)).ToNot(Equal(idempotencyFingerprint(recipe)))
+	})
+})
@@ -0,0 +1,187 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/ottenwbe/recipes-manager/core"
+)
+
+const (
+	// MEALPLAN keyword used as part of the url
+	MEALPLAN = "mealplan"
+)
+
+//MealPlanAPI for meal plans
+type MealPlanAPI struct {
+	handler   core.Handler
+	mealPlans MealPlanDB
+	recipes   Recipes
+}
+
+// AddMealPlanAPIToHandler constructs an API for meal plans
+func AddMealPlanAPIToHandler(handler core.Handler, mealPlans MealPlanDB, recipes Recipes) {
+	mAPI := &MealPlanAPI{
+		handler,
+		mealPlans,
+		recipes,
+	}
+
+	mAPI.prepareV1API()
+}
+
+func (mAPI *MealPlanAPI) prepareV1API() {
+
+	if mAPI.handler == nil {
+		log.WithField("Component", "Meal Plan API").Fatal("No handler defined")
+		return
+	}
+
+	if mAPI.mealPlans == nil || mAPI.recipes == nil {
+		log.WithField("Component", "Meal Plan API").Fatal("No persistence defined")
+		return
+	}
+
+	v1 := mAPI.handler.API(1)
+
+	//GET the list of meal plans
+	v1.GET("/meal-plans", mAPI.getMealPlans)
+
+	//POST a new meal plan
+	v1.Secured().POST("/meal-plans", mAPI.postMealPlan)
+
+	//GET a specific meal plan
+	v1.GET("/meal-plans/:mealplan", mAPI.getMealPlan)
+
+	//GET the shopping list of a specific meal plan
+	v1.GET("/meal-plans/:mealplan/shopping-list", mAPI.getMealPlanShoppingList)
+}
+
+// getMealPlans example
+// @Summary Get Meal Plans
+// @Description A list of ids of meal plans is returned
+// @Tags MealPlans
+// @Produce json
+// @Success 200 {object} MealPlanList
+// @Router /meal-plans [get]
+func (mAPI *MealPlanAPI) getMealPlans(c *core.APICallContext) {
+	core.RespondJSON(c, http.StatusOK, mAPI.mealPlans.IDs())
+}
+
+// postMealPlan example
+// @Summary Add a new Meal Plan
+// @Description Adds a new meal plan, the id will automatically overriden by the backend. All entries have to reference existing recipes.
+// @Tags MealPlans
+// @Param message body MealPlan true "Meal Plan"
+// @Accept json
+// @Produce json
+// @Success 201
+// @Header 201 {string} Location "Path of the new meal plan"
+// @Failure 400 {object} core.APIError
+// @Security ApiKeyAuth
+// @Router /meal-plans [post]
+func (mAPI *MealPlanAPI) postMealPlan(c *core.APICallContext) {
+	var mealPlan MealPlan
+	if err := c.ShouldBindJSON(&mealPlan); err != nil {
+		core.AbortWithAPIError(c, http.StatusBadRequest, "Could not read JSON input", err.Error())
+		return
+	}
+	if err := mealPlan.Validate(); err != nil {
+		core.AbortWithAPIError(c, http.StatusBadRequest, "Invalid meal plan", err.Error())
+		return
+	}
+	for _, entry := range mealPlan.Entries {
+		if mAPI.recipes.Get(entry.Recipe).ID == InvalidRecipeID() {
+			core.AbortWithAPIError(c, http.StatusBadRequest, "No such recipe", entry.Recipe.String())
+			return
+		}
+	}
+	if mealPlan.Entries == nil {
+		mealPlan.Entries = make([]MealPlanEntry, 0)
+	}
+
+	mealPlan.ID = NewMealPlanID()
+	if err := mAPI.mealPlans.Insert(&mealPlan); err != nil {
+		core.AbortWithAPIError(c, http.StatusInternalServerError, "Could not persist meal plan", "")
+		return
+	}
+
+	c.Header("Location", fmt.Sprintf("%v/meal-plans/%v", mAPI.handler.API(1).Path(), mealPlan.ID))
+	c.Status(http.StatusCreated)
+}
+
+// getMealPlan example
+// @Summary Get a specific Meal Plan
+// @Description A specific meal plan is returned
+// @Tags MealPlans
+// @Param mealplan path string true "Meal Plan ID"
+// @Produce json
+// @Success 200 {object} MealPlan
+// @Failure 404 {object} core.APIError
+// @Router /meal-plans/{mealplan} [get]
+func (mAPI *MealPlanAPI) getMealPlan(c *core.APICallContext) {
+	mealPlanIDS := c.Param(MEALPLAN)
+
+	mealPlan := mAPI.mealPlans.Get(NewMealPlanIDFromString(mealPlanIDS))
+	if mealPlan.ID == InvalidMealPlanID() {
+		core.AbortWithAPIError(c, http.StatusNotFound, "No such meal plan", mealPlanIDS)
+	} else {
+		core.RespondJSON(c, http.StatusOK, mealPlan)
+	}
+}
+
+// getMealPlanShoppingList example
+// @Summary Get the shopping list of a Meal Plan
+// @Description The ingredients of all recipes of the meal plan are scaled and merged to a shopping list
+// @Tags MealPlans
+// @Param mealplan path string true "Meal Plan ID"
+// @Produce json
+// @Success 200 {array} Ingredients
+// @Failure 404 {object} core.APIError
+// @Router /meal-plans/{mealplan}/shopping-list [get]
+func (mAPI *MealPlanAPI) getMealPlanShoppingList(c *core.APICallContext) {
+	mealPlanIDS := c.Param(MEALPLAN)
+
+	mealPlan := mAPI.mealPlans.Get(NewMealPlanIDFromString(mealPlanIDS))
+	if mealPlan.ID == InvalidMealPlanID() {
+		core.AbortWithAPIError(c, http.StatusNotFound, "No such meal plan", mealPlanIDS)
+		return
+	}
+
+	ingredients, err := ShoppingList(mAPI.recipes, mealPlan.ShoppingListItems())
+	if errors.Is(err, ErrRecipeNotFound) {
+		// recipes may have been removed after the meal plan was created
+		core.AbortWithAPIError(c, http.StatusNotFound, "No such recipe", err.Error())
+	} else if err != nil {
+		core.AbortWithAPIError(c, http.StatusInternalServerError, "Could not create shopping list", "")
+	} else {
+		core.RespondJSON(c, http.StatusOK, ingredients)
+	}
+}
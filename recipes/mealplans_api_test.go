@@ -0,0 +1,108 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ottenwbe/recipes-manager/core"
+)
+
+var _ = Describe("mealPlanAPI", func() {
+
+	var (
+		handler   core.Handler
+		mealPlans MealPlanDB
+		recipeDB  RecipeDB
+		call      func(method string, path string, body interface{}) *httptest.ResponseRecorder
+	)
+
+	BeforeEach(func() {
+		mealPlans, _ = NewMealPlanDatabaseClient()
+		recipeDB, _ = NewDatabaseClient(NewRecipeEventsFromConfig())
+		handler = core.NewHandler()
+		AddMealPlanAPIToHandler(handler, mealPlans, recipeDB)
+
+		call = func(method string, path string, body interface{}) *httptest.ResponseRecorder {
+			encoded, _ := json.Marshal(body)
+			w := httptest.NewRecorder()
+			request := httptest.NewRequest(method, "/api/v1"+path, bytes.NewBuffer(encoded))
+			request.Header.Set("Content-Type", "application/json")
+			handler.ServeHTTP(w, request)
+			return w
+		}
+	})
+
+	AfterEach(func() {
+		mealPlans.Clear()
+		mealPlans.Close()
+		recipeDB.Close()
+	})
+
+	It("creates a meal plan and returns its shopping list", func() {
+		id := createAndPersistDefaultRecipe(recipeDB)
+		defer recipeDB.Remove(id)
+
+		w := call(http.MethodPost, "/meal-plans", MealPlan{Name: "week", Entries: []MealPlanEntry{
+			{Date: "2021-07-26", Recipe: id, Servings: 2},
+			{Date: "2021-07-27", Recipe: id},
+		}})
+		Expect(w.Code).To(Equal(http.StatusCreated))
+		location := w.Header().Get("Location")
+
+		w = call(http.MethodGet, location[len("/api/v1"):], nil)
+		Expect(w.Code).To(Equal(http.StatusOK))
+		var mealPlan MealPlan
+		Expect(json.NewDecoder(w.Body).Decode(&mealPlan)).To(Succeed())
+		Expect(mealPlan.Entries).To(HaveLen(2))
+
+		w = call(http.MethodGet, location[len("/api/v1"):]+"/shopping-list", nil)
+		Expect(w.Code).To(Equal(http.StatusOK))
+		var ingredients []Ingredients
+		Expect(json.NewDecoder(w.Body).Decode(&ingredients)).To(Succeed())
+		Expect(ingredients).To(Equal([]Ingredients{{Name: "Test", Amount: 300, Unit: "g"}}))
+
+		w = call(http.MethodGet, "/meal-plans", nil)
+		var list MealPlanList
+		Expect(json.NewDecoder(w.Body).Decode(&list)).To(Succeed())
+		Expect(list.MealPlans).To(ContainElement(mealPlan.ID.String()))
+	})
+
+	It("rejects meal plans with unknown recipes", func() {
+		w := call(http.MethodPost, "/meal-plans", MealPlan{Entries: []MealPlanEntry{{Date: "2021-07-26", Recipe: NewRecipeID()}}})
+		Expect(w.Code).To(Equal(http.StatusBadRequest))
+	})
+
+	It("returns 404 for unknown meal plans", func() {
+		w := call(http.MethodGet, "/meal-plans/"+NewMealPlanID().String(), nil)
+		Expect(w.Code).To(Equal(http.StatusNotFound))
+	})
+})
@@ -0,0 +1,109 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	"errors"
+	"sync"
+)
+
+//ErrMealPlanExists is returned when a meal plan with the same id is inserted twice
+var ErrMealPlanExists = errors.New("meal plan already exists")
+
+//InMemoryMealPlanDB implements the MealPlanDB interface without a database. It is safe for concurrent use.
+type InMemoryMealPlanDB struct {
+	mtx       sync.RWMutex
+	order     []MealPlanID
+	mealPlans map[MealPlanID]*MealPlan
+}
+
+//NewInMemoryMealPlanDB returns an empty in-memory database for meal plans
+func NewInMemoryMealPlanDB() *InMemoryMealPlanDB {
+	return &InMemoryMealPlanDB{
+		order:     make([]MealPlanID, 0),
+		mealPlans: make(map[MealPlanID]*MealPlan),
+	}
+}
+
+//IDs lists the ids of all meal plans in the order of their insertion
+func (m *InMemoryMealPlanDB) IDs() MealPlanList {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	result := make([]string, 0, len(m.order))
+	for _, id := range m.order {
+		result = append(result, id.String())
+	}
+	return MealPlanList{MealPlans: result}
+}
+
+//Get a meal plan by id; the returned meal plan's id is InvalidMealPlanID if it does not exist
+func (m *InMemoryMealPlanDB) Get(id MealPlanID) *MealPlan {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	if mealPlan, ok := m.mealPlans[id]; ok {
+		return copyMealPlan(mealPlan)
+	}
+	return NewInvalidMealPlan()
+}
+
+//Insert a new meal plan
+func (m *InMemoryMealPlanDB) Insert(mealPlan *MealPlan) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if _, ok := m.mealPlans[mealPlan.ID]; ok {
+		return ErrMealPlanExists
+	}
+	m.mealPlans[mealPlan.ID] = copyMealPlan(mealPlan)
+	m.order = append(m.order, mealPlan.ID)
+	return nil
+}
+
+//Ping always succeeds
+func (m *InMemoryMealPlanDB) Ping() error {
+	return nil
+}
+
+//Close does nothing, since there is no connection to close
+func (m *InMemoryMealPlanDB) Close() error {
+	return nil
+}
+
+//Clear removes all meal plans
+func (m *InMemoryMealPlanDB) Clear() {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	m.order = make([]MealPlanID, 0)
+	m.mealPlans = make(map[MealPlanID]*MealPlan)
+}
+
+func copyMealPlan(mealPlan *MealPlan) *MealPlan {
+	c := *mealPlan
+	c.Entries = append(make([]MealPlanEntry, 0, len(mealPlan.Entries)), mealPlan.Entries...)
+	return &c
+}
@@ -0,0 +1,114 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/satori/go.uuid"
+)
+
+//DateLayout is the layout of dates like 2021-07-31, e.g., of meal plan entries or the recipe of the day
+const DateLayout = "2006-01-02"
+
+//MealPlanID is a data type that provides a unique id for each meal plan
+type MealPlanID string
+
+//String converts a MealPlanID to string
+func (m MealPlanID) String() string {
+	return string(m)
+}
+
+//InvalidMealPlanID should not be used for any valid MealPlan
+func InvalidMealPlanID() MealPlanID {
+	return MealPlanID(uuid.Nil.String())
+}
+
+//NewMealPlanID returns a random meal plan id
+func NewMealPlanID() MealPlanID {
+	return MealPlanID(uuid.NewV4().String())
+}
+
+//NewMealPlanIDFromString converts a string to a meal plan id.
+//Returns the InvalidMealPlanID iff the meal plan id cannot be converted
+func NewMealPlanIDFromString(mealPlanID string) MealPlanID {
+	tmp, err := uuid.FromString(mealPlanID)
+	if err != nil {
+		return InvalidMealPlanID()
+	}
+	return MealPlanID(tmp.String())
+}
+
+//MealPlanEntry plans to cook a recipe on a specific date
+type MealPlanEntry struct {
+	//Date in the format of DateLayout
+	Date   string   `json:"date"`
+	Recipe RecipeID `json:"recipe"`
+	//Servings the recipe is scaled to; the recipe's own servings are used if no servings are given
+	Servings int8 `json:"servings"`
+}
+
+//MealPlan model
+type MealPlan struct {
+	ID      MealPlanID      `json:"id"`
+	Name    string          `json:"name"`
+	Entries []MealPlanEntry `json:"entries"`
+}
+
+//MealPlanList models a list of meal plans by ID
+type MealPlanList struct {
+	MealPlans []string `json:"mealPlans"`
+}
+
+//NewInvalidMealPlan returns an empty MealPlan object. The ID of the returned MealPlan is InvalidMealPlanID.
+func NewInvalidMealPlan() *MealPlan {
+	return &MealPlan{
+		ID:      InvalidMealPlanID(),
+		Entries: make([]MealPlanEntry, 0),
+	}
+}
+
+//Validate checks that all entries have a valid date and reference a recipe
+func (m *MealPlan) Validate() error {
+	for i, entry := range m.Entries {
+		if _, err := time.Parse(DateLayout, entry.Date); err != nil {
+			return fmt.Errorf("entry %v has an invalid date '%v', expected a date like %v", i, entry.Date, DateLayout)
+		}
+		if id, err := NewRecipeIDFromString(entry.Recipe.String()); err != nil || id == InvalidRecipeID() {
+			return fmt.Errorf("entry %v has an invalid recipe id '%v'", i, entry.Recipe)
+		}
+	}
+	return nil
+}
+
+//ShoppingListItems returns the recipes of all entries, see ShoppingList
+func (m *MealPlan) ShoppingListItems() []ShoppingListItem {
+	items := make([]ShoppingListItem, len(m.Entries))
+	for i, entry := range m.Entries {
+		items[i] = ShoppingListItem{ID: entry.Recipe, Servings: entry.Servings}
+	}
+	return items
+}
@@ -0,0 +1,67 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("meal plans", func() {
+
+	Context("id", func() {
+		It("can be converted from a valid string", func() {
+			id := NewMealPlanID()
+			Expect(NewMealPlanIDFromString(id.String())).To(Equal(id))
+		})
+
+		It("is invalid if the string is not an id", func() {
+			Expect(NewMealPlanIDFromString("no id")).To(Equal(InvalidMealPlanID()))
+		})
+	})
+
+	Context("validation", func() {
+		It("accepts entries with a date and a recipe", func() {
+			mealPlan := MealPlan{Entries: []MealPlanEntry{{Date: "2021-07-31", Recipe: NewRecipeID()}}}
+			Expect(mealPlan.Validate()).To(Succeed())
+		})
+
+		It("rejects entries with an invalid date", func() {
+			mealPlan := MealPlan{Entries: []MealPlanEntry{{Date: "31.07.2021", Recipe: NewRecipeID()}}}
+			Expect(mealPlan.Validate()).ToNot(Succeed())
+		})
+
+		It("rejects entries without a recipe", func() {
+			mealPlan := MealPlan{Entries: []MealPlanEntry{{Date: "2021-07-31"}}}
+			Expect(mealPlan.Validate()).ToNot(Succeed())
+		})
+	})
+
+	It("lists the recipes of all entries for a shopping list", func() {
+		id := NewRecipeID()
+		mealPlan := MealPlan{Entries: []MealPlanEntry{{Date: "2021-07-31", Recipe: id, Servings: 4}}}
+		Expect(mealPlan.ShoppingListItems()).To(Equal([]ShoppingListItem{{ID: id, Servings: 4}}))
+	})
+})
@@ -0,0 +1,188 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	"errors"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+const (
+	//MEALPLANS index
+	MEALPLANS = "mealplans"
+)
+
+//MongoMealPlanDB implements the MealPlanDB interface to read and write meal plans to and from a Mongo DB
+type MongoMealPlanDB struct {
+	mongoClient *mongo.Client
+	//mtx avoids race conditions while connecting to the database and while closing the connection
+	mtx sync.Mutex
+}
+
+//Clear drops all meal plans
+func (m *MongoMealPlanDB) Clear() {
+	if err := m.getMealPlanCollection().Drop(ctx()); err != nil {
+		log.WithError(err).Error("Could not drop meal plans from MongoDB")
+	}
+}
+
+//IDs lists the ids of all meal plans
+func (m *MongoMealPlanDB) IDs() MealPlanList {
+
+	collection := m.getMealPlanCollection()
+
+	mealPlans := make([]*MealPlan, 0)
+	result := make([]string, 0)
+
+	findOptions := options.Find().SetProjection(bson.M{"id": 1}).SetSort(bson.M{"_id": 1})
+	cursor, err := collection.Find(ctx(), bson.M{}, findOptions)
+	if err != nil {
+		log.WithError(err).Info("Error while finding meal plans")
+		return MealPlanList{MealPlans: result}
+	}
+	defer func() { _ = cursor.Close(ctx()) }()
+
+	if err = cursor.All(ctx(), &mealPlans); err != nil {
+		log.WithError(err).Info("Error while finding meal plans")
+	}
+
+	for _, mealPlan := range mealPlans {
+		result = append(result, mealPlan.ID.String())
+	}
+
+	return MealPlanList{MealPlans: result}
+}
+
+//Get a meal plan by ID
+func (m *MongoMealPlanDB) Get(id MealPlanID) *MealPlan {
+
+	collection := m.getMealPlanCollection()
+
+	mealPlan := NewInvalidMealPlan()
+	result := collection.FindOne(ctx(), bson.M{"id": id})
+
+	if err := result.Decode(mealPlan); err != nil {
+		log.WithError(err).Info("Error while finding meal plan")
+		return NewInvalidMealPlan()
+	}
+
+	return mealPlan
+}
+
+//Insert a meal plan into the database
+func (m *MongoMealPlanDB) Insert(mealPlan *MealPlan) error {
+
+	collection := m.getMealPlanCollection()
+
+	_, err := collection.InsertOne(ctx(), *mealPlan)
+	if err != nil {
+		log.WithError(err).Error("Could not insert meal plan")
+		return err
+	}
+
+	return nil
+}
+
+//Ping MongoDB
+func (m *MongoMealPlanDB) Ping() error {
+	return m.mongoClient.Ping(ctx(), readpref.Primary())
+}
+
+//Close the connection to the database
+func (m *MongoMealPlanDB) Close() error {
+	return m.StopDB()
+}
+
+//StartDB initializes the database connection
+func (m *MongoMealPlanDB) StartDB() error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if m.mongoClient != nil {
+		return errors.New("database is already running")
+	}
+
+	if err := m.connectToDB(); err != nil {
+		log.WithError(err).Error("Database is not connected")
+		return errors.New("database is not connected")
+	}
+
+	return nil
+}
+
+//StopDB closes the connection to the db
+func (m *MongoMealPlanDB) StopDB() (err error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if m.mongoClient != nil {
+		err = m.mongoClient.Disconnect(ctx())
+	}
+	m.mongoClient = nil
+
+	return
+}
+
+func (m *MongoMealPlanDB) connectToDB() (err error) {
+	log.WithField("addr", mongoAddress).Info("Connecting to DB")
+	m.mongoClient, err = mongo.NewClient(options.Client().ApplyURI(mongoAddress))
+	if err != nil {
+		log.WithError(err).Info("Could not create MongoDB client")
+		return
+	}
+	if err = m.mongoClient.Connect(ctx()); err != nil {
+		log.WithError(err).Info("Could not connect to MongoDB")
+		return
+	}
+	if err = m.Ping(); err != nil {
+		log.WithError(err).Info("Could not ping MongoDB")
+		return
+	}
+	if err = m.ensureMealPlanIndex(); err != nil {
+		log.WithError(err).Info("Could not create mongo db meal plan index")
+	}
+	return
+}
+
+func (m *MongoMealPlanDB) ensureMealPlanIndex() error {
+	index := mongo.IndexModel{
+		Keys: bson.M{ // index in ascending order
+			"id": 1,
+		},
+		Options: options.Index().SetUnique(true).SetSparse(true),
+	}
+	_, err := m.getMealPlanCollection().Indexes().CreateOne(ctx(), index)
+	return err
+}
+
+func (m *MongoMealPlanDB) getMealPlanCollection() *mongo.Collection {
+	return m.mongoClient.Database(DATABASE).Collection(MEALPLANS)
+}
@@ -0,0 +1,69 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	"errors"
+	"fmt"
+)
+
+//MergeRequest names the recipe that is kept and its duplicates, which are merged into it
+type MergeRequest struct {
+	Primary    RecipeID   `json:"primary"`
+	Duplicates []RecipeID `json:"duplicates"`
+}
+
+//Validate ensures that all ids are valid, that there is at least one duplicate, and that no recipe is named twice
+func (m *MergeRequest) Validate() error {
+	if len(m.Duplicates) == 0 {
+		return errors.New("at least one duplicate is required")
+	}
+
+	seen := make(map[RecipeID]bool)
+	for _, id := range append([]RecipeID{m.Primary}, m.Duplicates...) {
+		if _, err := NewRecipeIDFromString(id.String()); err != nil {
+			return err
+		}
+		if seen[id] {
+			return fmt.Errorf("recipe '%v' is named twice", id)
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+//mergeRatings returns the average and the count of all ratings of the recipes, i.e., averages are weighted by their counts
+func mergeRatings(recipes []*Recipe) (float32, int) {
+	sum := 0.0
+	count := 0
+	for _, recipe := range recipes {
+		sum += float64(recipe.Rating) * float64(recipe.RatingCount)
+		count += recipe.RatingCount
+	}
+	if count == 0 {
+		return 0, 0
+	}
+	return float32(sum / float64(count)), count
+}
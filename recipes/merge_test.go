@@ -0,0 +1,65 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("merging recipes", func() {
+
+	Context("validation", func() {
+		It("accepts a primary recipe with duplicates", func() {
+			request := MergeRequest{Primary: NewRecipeID(), Duplicates: []RecipeID{NewRecipeID(), NewRecipeID()}}
+			Expect(request.Validate()).To(Succeed())
+		})
+		It("requires a duplicate", func() {
+			request := MergeRequest{Primary: NewRecipeID()}
+			Expect(request.Validate()).ToNot(Succeed())
+		})
+		It("rejects invalid ids", func() {
+			request := MergeRequest{Primary: NewRecipeID(), Duplicates: []RecipeID{"Soup!"}}
+			Expect(request.Validate()).ToNot(Succeed())
+		})
+		It("rejects recipes which are named twice", func() {
+			id := NewRecipeID()
+			Expect((&MergeRequest{Primary: id, Duplicates: []RecipeID{id}}).Validate()).ToNot(Succeed())
+			Expect((&MergeRequest{Primary: NewRecipeID(), Duplicates: []RecipeID{id, id}}).Validate()).ToNot(Succeed())
+		})
+	})
+
+	It("weights the average ratings by their counts", func() {
+		rating, count := mergeRatings([]*Recipe{{Rating: 5, RatingCount: 1}, {Rating: 3, RatingCount: 3}, {}})
+		Expect(rating).To(Equal(float32(3.5)))
+		Expect(count).To(Equal(4))
+	})
+
+	It("keeps recipes without ratings unrated", func() {
+		rating, count := mergeRatings([]*Recipe{{}, {}})
+		Expect(rating).To(BeZero())
+		Expect(count).To(BeZero())
+	})
+})
@@ -0,0 +1,76 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/satori/go.uuid"
+)
+
+//NoteID is a data type that provides a unique id for each note
+type NoteID string
+
+//String converts a NoteID to string
+func (n NoteID) String() string {
+	return string(n)
+}
+
+//NewNoteID returns a random note id
+func NewNoteID() NoteID {
+	return NoteID(uuid.NewV4().String())
+}
+
+//Note is a personal remark on a recipe. Notes are kept apart from the recipe, so that updates of the recipe keep its notes.
+type Note struct {
+	ID       NoteID   `json:"id"`
+	RecipeID RecipeID `json:"recipeID"`
+	Text     string   `json:"text"`
+	//CreatedAt is set by the database when the note is added
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+//NoteInput models the text of a new note
+type NoteInput struct {
+	Text string `json:"text"`
+}
+
+//Validate checks that the note has a text
+func (n *Note) Validate() error {
+	if strings.TrimSpace(n.Text) == "" {
+		return errors.New("text of a note must not be empty")
+	}
+	return nil
+}
+
+//NotesDB is the interface for the notes on recipes. Notes of a recipe are removed together with the recipe.
+type NotesDB interface {
+	//Notes of a recipe, the newest note first
+	Notes(id RecipeID) []*Note
+	//AddNote to a recipe and set its creation time; ErrRecipeNotFound is returned if the recipe does not exist
+	AddNote(note *Note) error
+}
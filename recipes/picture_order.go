@@ -0,0 +1,83 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	"errors"
+	"fmt"
+)
+
+//PictureOrder rearranges the pictures of a recipe. The primary picture is listed first and shown as the recipe's thumbnail.
+type PictureOrder struct {
+	//Names of pictures in the desired order; pictures which are not named keep their order and follow the named ones
+	Names []string `json:"names"`
+	//Primary picture of the recipe; the first of the names if it is empty
+	Primary string `json:"primary"`
+}
+
+//Validate ensures that the order names at least one picture and no picture twice
+func (o *PictureOrder) Validate() error {
+	if len(o.Names) == 0 && o.Primary == "" {
+		return errors.New("at least one name or a primary picture is required")
+	}
+	named := make(map[string]bool, len(o.Names))
+	for _, name := range o.Names {
+		if name == "" {
+			return errors.New("names must not be empty")
+		}
+		if named[name] {
+			return fmt.Errorf("picture '%v' is named twice", name)
+		}
+		named[name] = true
+	}
+	return nil
+}
+
+//orderPictures rearranges the picture links of a recipe: the primary picture first, followed by the other named pictures
+//and the remaining links in their previous order. An error wrapping ErrPictureNotFound is returned if a named picture is not linked.
+func orderPictures(links []string, names []string, primary string) ([]string, error) {
+	linked := make(map[string]bool, len(links))
+	for _, link := range links {
+		linked[link] = true
+	}
+	if primary == "" && len(names) > 0 {
+		primary = names[0]
+	}
+	for _, name := range append([]string{primary}, names...) {
+		if name != "" && !linked[name] {
+			return nil, fmt.Errorf("%w: %v", ErrPictureNotFound, name)
+		}
+	}
+
+	ordered := make([]string, 0, len(links))
+	placed := make(map[string]bool, len(links))
+	for _, name := range append(append([]string{primary}, names...), links...) {
+		if name != "" && !placed[name] {
+			ordered = append(ordered, name)
+			placed[name] = true
+		}
+	}
+	return ordered, nil
+}
@@ -0,0 +1,69 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("picture order", func() {
+
+	Context("validation", func() {
+		It("should accept names or a primary picture", func() {
+			Expect((&PictureOrder{Names: []string{"a", "b"}}).Validate()).To(Succeed())
+			Expect((&PictureOrder{Primary: "a"}).Validate()).To(Succeed())
+		})
+
+		It("should reject empty orders, empty names, and names which are named twice", func() {
+			Expect((&PictureOrder{}).Validate()).ToNot(Succeed())
+			Expect((&PictureOrder{Names: []string{""}}).Validate()).ToNot(Succeed())
+			Expect((&PictureOrder{Names: []string{"a", "a"}}).Validate()).ToNot(Succeed())
+		})
+	})
+
+	Context("ordering", func() {
+		links := []string{"a", "b", "c", "d"}
+
+		It("should list the named pictures first and keep the order of the others", func() {
+			Expect(orderPictures(links, []string{"c", "a"}, "")).To(Equal([]string{"c", "a", "b", "d"}))
+		})
+
+		It("should list the primary picture first", func() {
+			Expect(orderPictures(links, []string{"c", "a"}, "d")).To(Equal([]string{"d", "c", "a", "b"}))
+			Expect(orderPictures(links, []string{"c", "a"}, "a")).To(Equal([]string{"a", "c", "b", "d"}))
+			Expect(orderPictures(links, nil, "b")).To(Equal([]string{"b", "a", "c", "d"}))
+		})
+
+		It("should reject pictures which are not linked", func() {
+			_, err := orderPictures(links, []string{"a", "e"}, "")
+			Expect(errors.Is(err, ErrPictureNotFound)).To(BeTrue())
+			_, err = orderPictures(links, nil, "e")
+			Expect(errors.Is(err, ErrPictureNotFound)).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,173 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ottenwbe/recipes-manager/utils"
+)
+
+const (
+	// picturesBackendCfg is the configuration key for the storage of pictures, see the PictureBackend constants
+	picturesBackendCfg = "recipes.pictures.backend"
+	// picturesDirCfg is the configuration key for the directory of the PictureBackendFS
+	picturesDirCfg = "recipes.pictures.fs.dir"
+	// picturesS3EndpointCfg is the configuration key for the url of an S3 compatible service, e.g., https://s3.eu-central-1.amazonaws.com
+	picturesS3EndpointCfg = "recipes.pictures.s3.endpoint"
+	// picturesS3BucketCfg is the configuration key for the bucket of the pictures
+	picturesS3BucketCfg = "recipes.pictures.s3.bucket"
+	// picturesS3RegionCfg is the configuration key for the region of the bucket
+	picturesS3RegionCfg = "recipes.pictures.s3.region"
+	// picturesS3AccessKeyCfg is the configuration key for the access key id
+	picturesS3AccessKeyCfg = "recipes.pictures.s3.accessKey"
+	// picturesS3SecretKeyCfg is the configuration key for the secret access key
+	picturesS3SecretKeyCfg = "recipes.pictures.s3.secretKey"
+)
+
+const (
+	//PictureBackendDB keeps pictures in the RecipeDB
+	PictureBackendDB = "db"
+	//PictureBackendFS keeps pictures in a directory
+	PictureBackendFS = "fs"
+	//PictureBackendS3 keeps pictures in a bucket of an S3 compatible service
+	PictureBackendS3 = "s3"
+)
+
+func init() {
+	utils.Config.SetDefault(picturesBackendCfg, PictureBackendDB)
+	utils.Config.SetDefault(picturesDirCfg, "pictures")
+	utils.Config.SetDefault(picturesS3EndpointCfg, "")
+	utils.Config.SetDefault(picturesS3BucketCfg, "")
+	utils.Config.SetDefault(picturesS3RegionCfg, "us-east-1")
+	utils.Config.SetDefault(picturesS3AccessKeyCfg, "")
+	utils.Config.SetDefault(picturesS3SecretKeyCfg, "")
+}
+
+//ErrInvalidPictureKey is returned for keys that cannot be stored safely, i.e., keys with empty or relative segments
+var ErrInvalidPictureKey = errors.New("invalid picture key")
+
+//PictureStore keeps the bytes of pictures by key, i.e., <recipe id>/<picture name>, apart from the RecipeDB
+type PictureStore interface {
+	//Put stores a picture, replacing a picture with the same key
+	Put(key string, picture []byte) error
+	//Get a picture; ErrPictureNotFound is returned if there is no picture with the key
+	Get(key string) ([]byte, error)
+	//Delete a picture; deleting a missing picture succeeds
+	Delete(key string) error
+}
+
+//NewPictureStore creates the PictureStore configured by recipes.pictures.backend.
+//No store is returned for the PictureBackendDB, since the RecipeDB keeps the pictures itself.
+func NewPictureStore() (PictureStore, error) {
+	switch backend := utils.Config.GetString(picturesBackendCfg); backend {
+	case PictureBackendDB, "":
+		return nil, nil
+	case PictureBackendFS:
+		return NewFilePictureStore(utils.Config.GetString(picturesDirCfg))
+	case PictureBackendS3:
+		return NewS3PictureStore(
+			utils.Config.GetString(picturesS3EndpointCfg),
+			utils.Config.GetString(picturesS3BucketCfg),
+			utils.Config.GetString(picturesS3RegionCfg),
+			utils.Config.GetString(picturesS3AccessKeyCfg),
+			utils.Config.GetString(picturesS3SecretKeyCfg),
+		)
+	default:
+		return nil, fmt.Errorf("unknown %v '%v'", picturesBackendCfg, backend)
+	}
+}
+
+//validPictureKey rejects keys which could address files outside of a store
+func validPictureKey(key string) bool {
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." || strings.ContainsAny(segment, `\`) {
+			return false
+		}
+	}
+	return true
+}
+
+//FilePictureStore keeps pictures as files in a directory
+type FilePictureStore struct {
+	dir string
+}
+
+//NewFilePictureStore creates the directory if it does not exist
+func NewFilePictureStore(dir string) (*FilePictureStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &FilePictureStore{dir: dir}, nil
+}
+
+//Put writes a picture to the file of the key
+func (f *FilePictureStore) Put(key string, picture []byte) error {
+	path, err := f.path(key)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, picture, 0600)
+}
+
+//Get reads a picture from the file of the key
+func (f *FilePictureStore) Get(key string) ([]byte, error) {
+	path, err := f.path(key)
+	if err != nil {
+		return nil, err
+	}
+	picture, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, ErrPictureNotFound
+	}
+	return picture, err
+}
+
+//Delete removes the file of the key
+func (f *FilePictureStore) Delete(key string) error {
+	path, err := f.path(key)
+	if err != nil {
+		return err
+	}
+	if err = os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (f *FilePictureStore) path(key string) (string, error) {
+	if !validPictureKey(key) {
+		return "", ErrInvalidPictureKey
+	}
+	return filepath.Join(f.dir, filepath.FromSlash(key)), nil
+}
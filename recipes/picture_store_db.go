@@ -0,0 +1,229 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/ottenwbe/recipes-manager/utils"
+)
+
+//pictureReferencePrefix marks pictures in the RecipeDB whose bytes are kept in a PictureStore.
+//Pictures without the prefix are base64 encoded and were added before the PictureStore was configured.
+const pictureReferencePrefix = "ref:"
+
+//PictureStoreDB wraps a RecipeDB and keeps the bytes of pictures in a PictureStore. The RecipeDB only keeps references to the pictures.
+type PictureStoreDB struct {
+	RecipeDB
+	store PictureStore
+}
+
+//NewPictureStoreDB keeps pictures added to the inner database in the store
+func NewPictureStoreDB(inner RecipeDB, store PictureStore) *PictureStoreDB {
+	return &PictureStoreDB{
+		RecipeDB: inner,
+		store:    store,
+	}
+}
+
+//WithContext returns a view of the database whose operations on the inner database are cancelled with the context
+func (p *PictureStoreDB) WithContext(ctx context.Context) RecipeDB {
+	return NewPictureStoreDB(p.RecipeDB.WithContext(ctx), p.store)
+}
+
+//thumbnailKeyPrefix separates the thumbnails from the pictures in a PictureStore
+const thumbnailKeyPrefix = "thumbs/"
+
+//pictureKey of a recipe's picture in a PictureStore, i.e., <recipe id>/<escaped name>
+func pictureKey(id RecipeID, name string) string {
+	return id.String() + "/" + url.PathEscape(name)
+}
+
+//AddPicture to the store and a reference to the picture to the database
+func (p *PictureStoreDB) AddPicture(pic *RecipePicture) error {
+	img, err := utils.Base64ToIMG(pic.Picture)
+	if err != nil {
+		return err
+	}
+
+	key := pictureKey(pic.ID, pic.Name)
+	if err = p.store.Put(key, img); err != nil {
+		log.WithError(err).WithField("key", key).Error("Could not store picture")
+		return err
+	}
+
+	reference := *pic
+	reference.Picture = pictureReferencePrefix + key
+	if pic.Thumbnail != "" {
+		if reference.Thumbnail, err = p.putThumbnail(thumbnailKeyPrefix+key, pic.Thumbnail); err != nil {
+			p.deleteFromStore(reference.Picture)
+			return err
+		}
+	}
+	if err = p.RecipeDB.AddPicture(&reference); err != nil {
+		p.deleteFromStore(reference.Picture)
+		p.deleteFromStore(reference.Thumbnail)
+		return err
+	}
+	return nil
+}
+
+//putThumbnail into the store and return the reference to the thumbnail
+func (p *PictureStoreDB) putThumbnail(key string, thumbnail string) (string, error) {
+	img, err := utils.Base64ToIMG(thumbnail)
+	if err != nil {
+		return "", err
+	}
+	if err = p.store.Put(key, img); err != nil {
+		log.WithError(err).WithField("key", key).Error("Could not store thumbnail")
+		return "", err
+	}
+	return pictureReferencePrefix + key, nil
+}
+
+//Picture of a recipe with the bytes read from the store
+func (p *PictureStoreDB) Picture(id RecipeID, name string) *RecipePicture {
+	return p.resolve(p.RecipeDB.Picture(id, name))
+}
+
+//Pictures of a recipe with the bytes read from the store
+func (p *PictureStoreDB) Pictures(id RecipeID) map[string]*RecipePicture {
+	result := make(map[string]*RecipePicture)
+	for name, pic := range p.RecipeDB.Pictures(id) {
+		if resolved := p.resolve(pic); resolved.ID != InvalidRecipeID() {
+			result[name] = resolved
+		}
+	}
+	return result
+}
+
+//DeletePicture of a recipe from the database and the store
+func (p *PictureStoreDB) DeletePicture(id RecipeID, name string) error {
+	reference := p.RecipeDB.Picture(id, name)
+	if err := p.RecipeDB.DeletePicture(id, name); err != nil {
+		return err
+	}
+	p.deleteFromStore(reference.Picture)
+	p.deleteFromStore(reference.Thumbnail)
+	return nil
+}
+
+//Remove a recipe and its pictures in the store
+func (p *PictureStoreDB) Remove(id RecipeID) error {
+	pictures := p.RecipeDB.Pictures(id)
+	if err := p.RecipeDB.Remove(id); err != nil {
+		return err
+	}
+	for _, pic := range pictures {
+		p.deleteFromStore(pic.Picture)
+		p.deleteFromStore(pic.Thumbnail)
+	}
+	return nil
+}
+
+//RemoveByName removes the first recipe with the given name and its pictures in the store
+func (p *PictureStoreDB) RemoveByName(name string) error {
+	recipe, err := p.RecipeDB.GetByName(name)
+	if err != nil || recipe.ID == InvalidRecipeID() {
+		return p.RecipeDB.RemoveByName(name)
+	}
+	return p.Remove(recipe.ID)
+}
+
+//Merge duplicates into the primary recipe and delete the pictures of the duplicates which are dropped from the store
+func (p *PictureStoreDB) Merge(primary RecipeID, duplicates []RecipeID) (*Recipe, error) {
+	taken := p.RecipeDB.Pictures(primary)
+	dropped := make([]*RecipePicture, 0)
+	for _, id := range duplicates {
+		for name, pic := range p.RecipeDB.Pictures(id) {
+			if _, ok := taken[name]; ok {
+				dropped = append(dropped, pic)
+			} else {
+				taken[name] = pic
+			}
+		}
+	}
+
+	recipe, err := p.RecipeDB.Merge(primary, duplicates)
+	if err != nil {
+		return recipe, err
+	}
+	for _, pic := range dropped {
+		p.deleteFromStore(pic.Picture)
+		p.deleteFromStore(pic.Thumbnail)
+	}
+	return recipe, nil
+}
+
+//resolve replaces the references of a picture and its thumbnail by the base64 encoded pictures from the store
+func (p *PictureStoreDB) resolve(pic *RecipePicture) *RecipePicture {
+	if pic.ID == InvalidRecipeID() {
+		return pic
+	}
+
+	resolved := *pic
+	if strings.HasPrefix(pic.Picture, pictureReferencePrefix) {
+		img, err := p.fromStore(pic.Picture)
+		if err != nil {
+			log.WithError(err).WithField("reference", pic.Picture).Error("Could not read picture from store")
+			return NewInvalidRecipePicture()
+		}
+		resolved.Picture = img
+	}
+	if strings.HasPrefix(pic.Thumbnail, pictureReferencePrefix) {
+		// the original picture can still be served without its thumbnail
+		img, err := p.fromStore(pic.Thumbnail)
+		if err != nil {
+			log.WithError(err).WithField("reference", pic.Thumbnail).Warn("Could not read thumbnail from store")
+		}
+		resolved.Thumbnail = img
+	}
+	return &resolved
+}
+
+//fromStore reads a referenced picture from the store and encodes it in base64
+func (p *PictureStoreDB) fromStore(reference string) (string, error) {
+	img, err := p.store.Get(strings.TrimPrefix(reference, pictureReferencePrefix))
+	if err != nil {
+		return "", err
+	}
+	return utils.IMGToBase64(http.DetectContentType(img), img), nil
+}
+
+//deleteFromStore removes a referenced picture from the store; pictures which are kept in the database are ignored
+func (p *PictureStoreDB) deleteFromStore(reference string) {
+	if !strings.HasPrefix(reference, pictureReferencePrefix) {
+		return
+	}
+	key := strings.TrimPrefix(reference, pictureReferencePrefix)
+	if err := p.store.Delete(key); err != nil {
+		log.WithError(err).WithField("key", key).Warn("Could not delete picture from store")
+	}
+}
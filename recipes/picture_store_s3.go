@@ -0,0 +1,142 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+)
+
+//s3Timeout limits the duration of a single request to the S3 compatible service
+const s3Timeout = 30 * time.Second
+
+//S3PictureStore keeps pictures as objects in a bucket of an S3 compatible service, e.g., AWS S3 or MinIO.
+//Objects are addressed path-style, i.e., <endpoint>/<bucket>/<key>, which all S3 compatible services support.
+type S3PictureStore struct {
+	client   *http.Client
+	endpoint string
+	bucket   string
+	region   string
+	signer   *v4.Signer
+}
+
+//NewS3PictureStore signs requests to the bucket with the given keys
+func NewS3PictureStore(endpoint string, bucket string, region string, accessKey string, secretKey string) (*S3PictureStore, error) {
+	if _, err := url.ParseRequestURI(endpoint); err != nil || bucket == "" {
+		return nil, fmt.Errorf("an S3 endpoint and bucket are required to store pictures, got '%v' and '%v'", endpoint, bucket)
+	}
+	return &S3PictureStore{
+		client:   &http.Client{Timeout: s3Timeout},
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		bucket:   bucket,
+		region:   region,
+		signer: v4.NewSigner(credentials.NewStaticCredentials(accessKey, secretKey, ""), func(s *v4.Signer) {
+			// S3 expects the path to be escaped only once
+			s.DisableURIPathEscaping = true
+		}),
+	}, nil
+}
+
+//Put uploads a picture as object of the key
+func (s *S3PictureStore) Put(key string, picture []byte) error {
+	resp, err := s.do(http.MethodPut, key, picture)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	return s3Error(resp, http.StatusOK)
+}
+
+//Get downloads the object of the key
+func (s *S3PictureStore) Get(key string) ([]byte, error) {
+	resp, err := s.do(http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrPictureNotFound
+	}
+	if err = s3Error(resp, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+//Delete removes the object of the key
+func (s *S3PictureStore) Delete(key string) error {
+	resp, err := s.do(http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	return s3Error(resp, http.StatusNoContent, http.StatusOK, http.StatusNotFound)
+}
+
+func (s *S3PictureStore) do(method string, key string, body []byte) (*http.Response, error) {
+	if !validPictureKey(key) {
+		return nil, ErrInvalidPictureKey
+	}
+
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	request, err := http.NewRequest(method, fmt.Sprintf("%v/%v/%v", s.endpoint, url.PathEscape(s.bucket), strings.Join(segments, "/")), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var reader io.ReadSeeker
+	if body != nil {
+		reader = bytes.NewReader(body)
+		request.ContentLength = int64(len(body))
+		request.Header.Set("Content-Type", http.DetectContentType(body))
+	}
+	if _, err = s.signer.Sign(request, reader, "s3", s.region, time.Now()); err != nil {
+		return nil, err
+	}
+	return s.client.Do(request)
+}
+
+//s3Error returns an error with the response of the service, unless the response has one of the expected status codes
+func s3Error(resp *http.Response, expected ...int) error {
+	for _, code := range expected {
+		if resp.StatusCode == code {
+			return nil
+		}
+	}
+	msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("S3 responded with %v: %v", resp.Status, strings.TrimSpace(string(msg)))
+}
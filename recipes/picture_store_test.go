@@ -0,0 +1,251 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ottenwbe/recipes-manager/utils"
+)
+
+var _ = Describe("picture store", func() {
+
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "pictures")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		_ = os.RemoveAll(dir)
+	})
+
+	It("keeps pictures in the database by default", func() {
+		store, err := NewPictureStore()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(store).To(BeNil())
+	})
+
+	Context("files", func() {
+		It("puts, gets, and deletes pictures", func() {
+			store, err := NewFilePictureStore(dir)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(store.Put("recipe/soup.png", pngHeader)).To(Succeed())
+			Expect(ioutil.ReadFile(filepath.Join(dir, "recipe", "soup.png"))).To(Equal(pngHeader))
+			Expect(store.Get("recipe/soup.png")).To(Equal(pngHeader))
+
+			Expect(store.Delete("recipe/soup.png")).To(Succeed())
+			_, err = store.Get("recipe/soup.png")
+			Expect(err).To(Equal(ErrPictureNotFound))
+			Expect(store.Delete("recipe/soup.png")).To(Succeed())
+		})
+
+		It("rejects keys outside of the directory", func() {
+			store, err := NewFilePictureStore(dir)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(store.Put("../soup.png", pngHeader)).To(Equal(ErrInvalidPictureKey))
+			Expect(store.Put("/soup.png", pngHeader)).To(Equal(ErrInvalidPictureKey))
+			_, err = store.Get("recipe/..")
+			Expect(err).To(Equal(ErrInvalidPictureKey))
+		})
+	})
+
+	Context("S3", func() {
+		var (
+			server  *httptest.Server
+			mtx     sync.Mutex
+			objects map[string][]byte
+			auth    []string
+		)
+
+		BeforeEach(func() {
+			objects = make(map[string][]byte)
+			auth = make([]string, 0)
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mtx.Lock()
+				defer mtx.Unlock()
+				auth = append(auth, r.Header.Get("Authorization"))
+				switch r.Method {
+				case http.MethodPut:
+					objects[r.URL.EscapedPath()], _ = ioutil.ReadAll(r.Body)
+				case http.MethodGet:
+					if object, ok := objects[r.URL.EscapedPath()]; ok {
+						_, _ = w.Write(object)
+					} else {
+						w.WriteHeader(http.StatusNotFound)
+					}
+				case http.MethodDelete:
+					delete(objects, r.URL.EscapedPath())
+					w.WriteHeader(http.StatusNoContent)
+				}
+			}))
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("requires an endpoint and a bucket", func() {
+			_, err := NewS3PictureStore("", "pictures", "us-east-1", "key", "secret")
+			Expect(err).To(HaveOccurred())
+			_, err = NewS3PictureStore(server.URL, "", "us-east-1", "key", "secret")
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("puts, gets, and deletes signed objects in the bucket", func() {
+			store, err := NewS3PictureStore(server.URL+"/", "pictures", "eu-central-1", "key", "secret")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(store.Put("recipe/my%20soup.png", pngHeader)).To(Succeed())
+			Expect(objects).To(HaveKeyWithValue("/pictures/recipe/my%2520soup.png", pngHeader))
+			Expect(store.Get("recipe/my%20soup.png")).To(Equal(pngHeader))
+
+			Expect(store.Delete("recipe/my%20soup.png")).To(Succeed())
+			_, err = store.Get("recipe/my%20soup.png")
+			Expect(err).To(Equal(ErrPictureNotFound))
+
+			for _, header := range auth {
+				Expect(header).To(HavePrefix("AWS4-HMAC-SHA256 Credential=key/"))
+				Expect(header).To(ContainSubstring("/eu-central-1/s3/aws4_request"))
+			}
+		})
+
+		It("reports errors of the service", func() {
+			server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "AccessDenied", http.StatusForbidden)
+			})
+			store, err := NewS3PictureStore(server.URL, "pictures", "us-east-1", "key", "wrong")
+			Expect(err).ToNot(HaveOccurred())
+
+			err = store.Put("recipe/soup.png", pngHeader)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("AccessDenied"))
+		})
+	})
+
+	Context("database", func() {
+		var (
+			inner *InMemoryDB
+			db    *PictureStoreDB
+			id    RecipeID
+		)
+
+		BeforeEach(func() {
+			store, err := NewFilePictureStore(dir)
+			Expect(err).ToNot(HaveOccurred())
+			inner = NewInMemoryDB()
+			db = NewPictureStoreDB(inner, store)
+			id = NewRecipeID()
+			Expect(db.Insert(&Recipe{ID: id, Name: "soup"})).To(Succeed())
+		})
+
+		It("keeps only a reference in the database", func() {
+			picture := utils.IMGToBase64("image/png", pngHeader)
+			Expect(db.AddPicture(&RecipePicture{ID: id, Name: "soup.png", Picture: picture})).To(Succeed())
+
+			Expect(inner.Picture(id, "soup.png").Picture).To(Equal("ref:" + id.String() + "/soup.png"))
+			Expect(db.Picture(id, "soup.png").Picture).To(Equal(picture))
+			Expect(db.Pictures(id)).To(HaveKey("soup.png"))
+			Expect(db.Get(id).PictureLink).To(Equal([]string{"soup.png"}))
+		})
+
+		It("keeps thumbnails next to the pictures", func() {
+			picture := utils.IMGToBase64("image/png", pngHeader)
+			Expect(db.AddPicture(&RecipePicture{ID: id, Name: "soup.png", Picture: picture, Thumbnail: picture})).To(Succeed())
+
+			Expect(inner.Picture(id, "soup.png").Thumbnail).To(Equal("ref:thumbs/" + id.String() + "/soup.png"))
+			Expect(db.Picture(id, "soup.png").Thumbnail).To(Equal(picture))
+
+			Expect(db.DeletePicture(id, "soup.png")).To(Succeed())
+			_, err := os.Stat(filepath.Join(dir, "thumbs", id.String(), "soup.png"))
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+
+		It("reads pictures which were kept in the database before", func() {
+			picture := utils.IMGToBase64("image/png", pngHeader)
+			Expect(inner.AddPicture(&RecipePicture{ID: id, Name: "old.png", Picture: picture})).To(Succeed())
+
+			Expect(db.Picture(id, "old.png").Picture).To(Equal(picture))
+			Expect(db.DeletePicture(id, "old.png")).To(Succeed())
+		})
+
+		It("deletes pictures from the store", func() {
+			Expect(db.AddPicture(&RecipePicture{ID: id, Name: "soup.png", Picture: utils.IMGToBase64("image/png", pngHeader)})).To(Succeed())
+			Expect(db.DeletePicture(id, "soup.png")).To(Succeed())
+
+			_, err := os.Stat(filepath.Join(dir, id.String(), "soup.png"))
+			Expect(os.IsNotExist(err)).To(BeTrue())
+			Expect(db.DeletePicture(id, "soup.png")).To(Equal(ErrPictureNotFound))
+		})
+
+		It("deletes the pictures of removed recipes from the store", func() {
+			Expect(db.AddPicture(&RecipePicture{ID: id, Name: "soup.png", Picture: utils.IMGToBase64("image/png", pngHeader)})).To(Succeed())
+			Expect(db.RemoveByName("soup")).To(Succeed())
+
+			_, err := os.Stat(filepath.Join(dir, id.String(), "soup.png"))
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+
+		It("deletes the pictures which are dropped by a merge from the store", func() {
+			duplicate := NewRecipeID()
+			Expect(db.Insert(&Recipe{ID: duplicate, Name: "soup"})).To(Succeed())
+			picture := utils.IMGToBase64("image/png", pngHeader)
+			Expect(db.AddPicture(&RecipePicture{ID: id, Name: "soup.png", Picture: picture})).To(Succeed())
+			Expect(db.AddPicture(&RecipePicture{ID: duplicate, Name: "soup.png", Picture: picture})).To(Succeed())
+			Expect(db.AddPicture(&RecipePicture{ID: duplicate, Name: "bowl.png", Picture: picture})).To(Succeed())
+
+			_, err := db.Merge(id, []RecipeID{duplicate})
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = os.Stat(filepath.Join(dir, duplicate.String(), "soup.png"))
+			Expect(os.IsNotExist(err)).To(BeTrue())
+			Expect(db.Picture(id, "soup.png").Picture).To(Equal(picture))
+			Expect(db.Picture(id, "bowl.png").Picture).To(Equal(picture))
+		})
+
+		It("does not keep pictures of recipes that do not exist", func() {
+			missing := NewRecipeID()
+			err := db.AddPicture(&RecipePicture{ID: missing, Name: "soup.png", Picture: utils.IMGToBase64("image/png", pngHeader)})
+			Expect(err).To(Equal(ErrRecipeNotFound))
+
+			files, _ := ioutil.ReadDir(filepath.Join(dir, missing.String()))
+			Expect(files).To(BeEmpty())
+			Expect(strings.HasPrefix(db.Picture(missing, "soup.png").Picture, "ref:")).To(BeFalse())
+		})
+	})
+})
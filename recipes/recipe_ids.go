@@ -0,0 +1,188 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/ottenwbe/recipes-manager/utils"
+)
+
+const (
+	// idStrategyCfg is the configuration key for the strategy which generates the ids of new recipes
+	idStrategyCfg = "recipes.idStrategy"
+)
+
+const (
+	//UUIDStrategy generates random uuids, e.g., 40ac4297-d5b3-435e-9f42-e5e3479d0ae8
+	UUIDStrategy = "uuid"
+	//SlugStrategy derives ids from the names of recipes, e.g., apple-pie, or apple-pie-2 if apple-pie is taken
+	SlugStrategy = "slug"
+	//SequentialStrategy numbers recipes, e.g., 42
+	SequentialStrategy = "sequential"
+)
+
+const (
+	// maxSlugLength limits the length of slugs without their collision suffix
+	maxSlugLength = 64
+	// maxSlugSuffixLength is the maximal length of a collision suffix, e.g., -2
+	maxSlugSuffixLength = 20
+	// maxIDAttempts limits how often a new id is generated for a recipe whose id was taken by a recipe created at the same time
+	maxIDAttempts = 10
+)
+
+func init() {
+	utils.Config.SetDefault(idStrategyCfg, UUIDStrategy)
+}
+
+//RecipeIDGenerator generates the ids of new recipes
+type RecipeIDGenerator interface {
+	//Generate an id for the recipe which is not used by any of the recipes yet.
+	//The id is not reserved, i.e., a recipe created at the same time may take it first, see insertWithGeneratedID.
+	Generate(recipe *Recipe, recipes Recipes) RecipeID
+}
+
+//insertWithGeneratedID inserts the recipe with an id of the generator. If a recipe created at the same time took the id,
+//the next id is generated; ErrRecipeExists is only returned if the ids of all attempts were taken.
+func insertWithGeneratedID(generator RecipeIDGenerator, recipe *Recipe, recipes Recipes) error {
+	var err error
+	for attempt := 0; attempt < maxIDAttempts; attempt++ {
+		recipe.ID = generator.Generate(recipe, recipes)
+		if err = recipes.Insert(recipe); !errors.Is(err, ErrRecipeExists) {
+			return err
+		}
+	}
+	return err
+}
+
+//NewRecipeIDGenerator returns the generator of the strategy, e.g., SlugStrategy
+func NewRecipeIDGenerator(strategy string) (RecipeIDGenerator, error) {
+	switch strategy {
+	case UUIDStrategy:
+		return uuidGenerator{}, nil
+	case SlugStrategy:
+		return &slugGenerator{}, nil
+	case SequentialStrategy:
+		return &sequentialGenerator{}, nil
+	default:
+		return nil, fmt.Errorf("unknown id strategy '%v', expected %v, %v, or %v", strategy, UUIDStrategy, SlugStrategy, SequentialStrategy)
+	}
+}
+
+//configuredRecipeIDGenerator returns the generator configured by recipes.idStrategy; uuids are generated if the strategy is unknown
+func configuredRecipeIDGenerator() RecipeIDGenerator {
+	generator, err := NewRecipeIDGenerator(utils.Config.GetString(idStrategyCfg))
+	if err != nil {
+		log.WithError(err).Error("Could not configure the ids of recipes, uuids are generated")
+		return uuidGenerator{}
+	}
+	return generator
+}
+
+type uuidGenerator struct{}
+
+func (uuidGenerator) Generate(_ *Recipe, _ Recipes) RecipeID {
+	return NewRecipeID()
+}
+
+//slugGenerator derives ids from the names of recipes. Ids are generated one after the other, but they are only taken
+//when the recipes are inserted, i.e., recipes with the same name which are created at the same time may get the same id.
+type slugGenerator struct {
+	mtx sync.Mutex
+}
+
+func (g *slugGenerator) Generate(recipe *Recipe, recipes Recipes) RecipeID {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+
+	slug := Slugify(recipe.Name)
+	id := RecipeID(slug)
+	for i := 2; exists(recipes, id); i++ {
+		id = RecipeID(slug + "-" + strconv.Itoa(i))
+	}
+	return id
+}
+
+//sequentialGenerator numbers recipes, starting after the number of existing recipes
+type sequentialGenerator struct {
+	mtx  sync.Mutex
+	next int64
+}
+
+func (g *sequentialGenerator) Generate(_ *Recipe, recipes Recipes) RecipeID {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+
+	if g.next == 0 {
+		g.next = recipes.Num() + 1
+	}
+	id := RecipeID(strconv.FormatInt(g.next, 10))
+	for exists(recipes, id) {
+		g.next++
+		id = RecipeID(strconv.FormatInt(g.next, 10))
+	}
+	g.next++
+	return id
+}
+
+func exists(recipes Recipes, id RecipeID) bool {
+	return recipes.Get(id).ID != InvalidRecipeID()
+}
+
+//slugReplacements transliterates letters which are common in the names of recipes
+var slugReplacements = strings.NewReplacer("ä", "ae", "ö", "oe", "ü", "ue", "ß", "ss", "é", "e", "è", "e", "ê", "e", "ë", "e", "à", "a", "â", "a", "á", "a", "î", "i", "ï", "i", "í", "i", "ô", "o", "ó", "o", "û", "u", "ù", "u", "ú", "u", "ç", "c", "ñ", "n", "ø", "o", "å", "a", "æ", "ae", "&", " and ")
+
+//Slugify converts a name to a URL-safe id, i.e., lower case letters and digits separated by single dashes, e.g., 'Äpfel & Birnen!' becomes 'aepfel-and-birnen'.
+//Names without letters or digits result in 'recipe'.
+func Slugify(name string) string {
+	var slug strings.Builder
+	dash := false
+	for _, r := range slugReplacements.Replace(strings.ToLower(name)) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			if dash && slug.Len() > 0 {
+				slug.WriteByte('-')
+			}
+			dash = false
+			slug.WriteRune(r)
+		default:
+			dash = true
+		}
+		if slug.Len() >= maxSlugLength {
+			break
+		}
+	}
+
+	if slug.Len() == 0 {
+		return "recipe"
+	}
+	return slug.String()
+}
@@ -0,0 +1,159 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	"strings"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("recipe ids", func() {
+
+	var db *InMemoryDB
+
+	BeforeEach(func() {
+		db = NewInMemoryDB()
+	})
+
+	insert := func(generator RecipeIDGenerator, name string) RecipeID {
+		recipe := NewRecipe(InvalidRecipeID())
+		recipe.Name = name
+		recipe.ID = generator.Generate(recipe, db)
+		Expect(db.Insert(recipe)).To(Succeed())
+		return recipe.ID
+	}
+
+	It("rejects unknown strategies", func() {
+		_, err := NewRecipeIDGenerator("random")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("generates uuids by default", func() {
+		generator := configuredRecipeIDGenerator()
+		id := insert(generator, "Apple Pie")
+
+		Expect(NewRecipeIDFromString(id.String())).To(Equal(id))
+		Expect(id.String()).To(HaveLen(36))
+	})
+
+	It("derives unique slugs from names", func() {
+		generator, err := NewRecipeIDGenerator(SlugStrategy)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(insert(generator, "Apple Pie")).To(Equal(RecipeID("apple-pie")))
+		Expect(insert(generator, "apple  pie!")).To(Equal(RecipeID("apple-pie-2")))
+		Expect(insert(generator, "Apple Pie")).To(Equal(RecipeID("apple-pie-3")))
+		Expect(insert(generator, "Apple Pie 2")).To(Equal(RecipeID("apple-pie-2-2")))
+	})
+
+	It("generates ids which can be parsed", func() {
+		generator, _ := NewRecipeIDGenerator(SlugStrategy)
+		for _, name := range []string{"Crème brûlée", "Äpfel & Birnen", "???", strings.Repeat("long ", 50)} {
+			id := insert(generator, name)
+			Expect(NewRecipeIDFromString(id.String())).To(Equal(id), name)
+		}
+	})
+
+	It("numbers recipes", func() {
+		generator, err := NewRecipeIDGenerator(SequentialStrategy)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(insert(generator, "Apple Pie")).To(Equal(RecipeID("1")))
+		Expect(insert(generator, "Apple Pie")).To(Equal(RecipeID("2")))
+	})
+
+	It("skips numbers which are taken", func() {
+		taken := NewRecipe(RecipeID("2"))
+		taken.Name = "Soup"
+		Expect(db.Insert(taken)).To(Succeed())
+		generator, _ := NewRecipeIDGenerator(SequentialStrategy)
+
+		Expect(insert(generator, "Apple Pie")).To(Equal(RecipeID("3")))
+		Expect(insert(generator, "Apple Pie")).To(Equal(RecipeID("4")))
+	})
+
+	It("generates the next id if a recipe created at the same time took the id", func() {
+		generator, _ := NewRecipeIDGenerator(SlugStrategy)
+		recipes := &racingRecipes{InMemoryDB: db}
+		recipe := NewRecipe(InvalidRecipeID())
+		recipe.Name = "Apple Pie"
+
+		Expect(insertWithGeneratedID(generator, recipe, recipes)).To(Succeed())
+		Expect(recipe.ID).To(Equal(RecipeID("apple-pie-2")))
+		Expect(db.Num()).To(Equal(int64(2)))
+	})
+
+	It("creates recipes with the same name at the same time", func() {
+		for _, strategy := range []string{SlugStrategy, SequentialStrategy} {
+			db = NewInMemoryDB()
+			generator, _ := NewRecipeIDGenerator(strategy)
+
+			var wg sync.WaitGroup
+			errs := make(chan error, 20)
+			for i := 0; i < 20; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					recipe := NewRecipe(InvalidRecipeID())
+					recipe.Name = "Apple Pie"
+					errs <- insertWithGeneratedID(generator, recipe, db)
+				}()
+			}
+			wg.Wait()
+			close(errs)
+
+			for err := range errs {
+				Expect(err).ToNot(HaveOccurred(), strategy)
+			}
+			Expect(db.Num()).To(Equal(int64(20)), strategy)
+		}
+	})
+
+	It("converts names to slugs", func() {
+		Expect(Slugify("Äpfel & Birnen!")).To(Equal("aepfel-and-birnen"))
+		Expect(Slugify("  Crème Brûlée ")).To(Equal("creme-brulee"))
+		Expect(Slugify("???")).To(Equal("recipe"))
+		Expect(Slugify(strings.Repeat("a", 100))).To(HaveLen(maxSlugLength))
+	})
+})
+
+//racingRecipes inserts a rival recipe with the id of the first inserted recipe right before it, like a request which creates a recipe at the same time
+type racingRecipes struct {
+	*InMemoryDB
+	raced bool
+}
+
+func (r *racingRecipes) Insert(recipe *Recipe) error {
+	if !r.raced {
+		r.raced = true
+		rival := NewRecipe(recipe.ID)
+		rival.Name = recipe.Name
+		Expect(r.InMemoryDB.Insert(rival)).To(Succeed())
+	}
+	return r.InMemoryDB.Insert(recipe)
+}
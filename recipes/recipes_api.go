@@ -25,13 +25,24 @@
 package recipes
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"hash/fnv"
+	"io/ioutil"
+	"math"
 	"net/http"
 	"net/url"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/ottenwbe/recipes-manager/core"
+	"github.com/ottenwbe/recipes-manager/units"
+	"github.com/ottenwbe/recipes-manager/utils"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -46,26 +57,153 @@ const (
 	INGREDIENT = "ingredient"
 	// DESCRIPTION keyword used as part of the url
 	DESCRIPTION = "description"
+	// TAG keyword used as part of the url
+	TAG = "tag"
+	// LIMIT keyword used as part of the url
+	LIMIT = "limit"
+	// OFFSET keyword used as part of the url
+	OFFSET = "offset"
+	// UNITS keyword used as part of the url
+	UNITS = "units"
+	// SORT keyword used as part of the url
+	SORT = "sort"
+	// ORDER keyword used as part of the url
+	ORDER = "order"
+	// SINCE keyword used as part of the url
+	SINCE = "since"
+	// MAXTOTALTIME keyword used as part of the url
+	MAXTOTALTIME = "maxTotalTime"
+	// PICTURE is the name of the form field used to upload pictures
+	PICTURE = "picture"
+	// RAW keyword used as part of the url
+	RAW = "raw"
+	// SIZE keyword used as part of the url
+	SIZE = "size"
+	// FORMAT keyword used as part of the url
+	FORMAT = "format"
+	// SEED keyword used as part of the url
+	SEED = "seed"
+	// DATE keyword used as part of the url
+	DATE = "date"
+	// QUERY keyword used as part of the url
+	QUERY = "q"
+	// IDS keyword used as part of the url
+	IDS = "ids"
+	// THRESHOLD keyword used as part of the url
+	THRESHOLD = "threshold"
+	// HARD keyword used as part of the url
+	HARD = "hard"
+	// EXPAND keyword used as part of the url
+	EXPAND = "expand"
 )
 
-//API for recipes
-type API struct {
-	handler core.Handler
-	recipes RecipeDB
-}
+const (
+	// defaultLimit is the number of recipe ids returned when no limit is requested
+	defaultLimit = 50
+	// maxLimit is the maximal number of recipe ids returned with one request
+	maxLimit = 500
+	// totalCountHeader informs clients about the number of recipes that match a query
+	totalCountHeader = "X-Total-Count"
+	// lastEventIDHeader is sent by clients that reconnect to a stream of server-sent events
+	lastEventIDHeader = "Last-Event-ID"
+	// picturesMaxBytesCfg is the configuration key for the maximal size of uploaded pictures
+	picturesMaxBytesCfg = "recipes.pictures.maxBytes"
+	// pictureCacheControl allows browsers to cache raw pictures
+	pictureCacheControl = "public, max-age=3600"
+	// picturesThumbSizeCfg is the configuration key for the length in pixels of the longer side of thumbnails
+	picturesThumbSizeCfg = "recipes.pictures.thumbSize"
+	// picturesMaxDimensionCfg is the configuration key for the maximal width and height in pixels of stored pictures
+	picturesMaxDimensionCfg = "recipes.pictures.maxDimension"
+	// pictureSizeThumb requests the thumbnail of a picture
+	pictureSizeThumb = "thumb"
+	// defaultSimilarityThreshold is the minimal similarity of recipes when no threshold is requested
+	defaultSimilarityThreshold = 0.5
+	// defaultServingsCfg is the configuration key for the servings recipes are scaled to when no servings are requested
+	defaultServingsCfg = "recipes.defaultServings"
+	// maxServingsCfg is the configuration key for the maximal servings recipes can be scaled to
+	maxServingsCfg = "recipes.maxServings"
+	// statsTTLCfg is the configuration key for the time the statistics of the recipes are cached, e.g., 30s
+	statsTTLCfg = "recipes.stats.ttl"
+	// suggestLimitCfg is the configuration key for the maximal number of suggested ingredients
+	suggestLimitCfg = "recipes.suggest.limit"
+	// eventsFeature is the name of the feature flag of the stream of changes of recipes, i.e., features.events
+	eventsFeature = "events"
+	// csvFeature is the name of the feature flag of the CSV export and import, i.e., features.csv
+	csvFeature = "csv"
+	// cookModeFeature is the name of the feature flag of the cook mode, i.e., features.cookMode
+	cookModeFeature = "cookMode"
+)
 
 var (
-	api *API
+	maxPictureBytes     int64
+	thumbSize           int
+	maxPictureDimension int
+	defaultServings     int8
+	maxServings         int8
+	statsTTL            time.Duration
+	suggestLimit        int
 )
 
-// AddRecipesAPIToHandler constructs an API for recipes
-func AddRecipesAPIToHandler(handler core.Handler, recipes RecipeDB) {
-	api = &API{
+func init() {
+	utils.Config.SetDefault(picturesMaxBytesCfg, 5<<20)
+	maxPictureBytes = utils.Config.GetInt64(picturesMaxBytesCfg)
+	utils.Config.SetDefault(picturesThumbSizeCfg, 256)
+	thumbSize = int(utils.Config.GetInt64(picturesThumbSizeCfg))
+	utils.Config.SetDefault(picturesMaxDimensionCfg, 2048)
+	maxPictureDimension = int(utils.Config.GetInt64(picturesMaxDimensionCfg))
+	utils.Config.SetDefault(maxServingsCfg, 100)
+	maxServings = int8(clampInt64(utils.Config.GetInt64(maxServingsCfg), 1, math.MaxInt8))
+	utils.Config.SetDefault(defaultServingsCfg, 0)
+	defaultServings = int8(clampInt64(utils.Config.GetInt64(defaultServingsCfg), 0, int64(maxServings)))
+	utils.Config.SetDefault(statsTTLCfg, "30s")
+	statsTTL = utils.Config.GetDuration(statsTTLCfg)
+	utils.Config.SetDefault(suggestLimitCfg, 10)
+	suggestLimit = int(utils.Config.GetInt64(suggestLimitCfg))
+}
+
+func clampInt64(value, min, max int64) int64 {
+	if value < min {
+		return min
+	}
+	if value > max {
+		return max
+	}
+	return value
+}
+
+//API for recipes
+type API struct {
+	handler       core.Handler
+	recipes       RecipeDB
+	substitutions Substitutions
+	stats         *statsCache
+	events        *RecipeEvents
+	ids           RecipeIDGenerator
+	idempotency   *IdempotencyStore
+}
+
+// AddRecipesAPIToHandler constructs an API for recipes and registers its endpoints at the handler.
+// The API streams the events, which have to be the events the recipes are published to, see NewDatabaseClient.
+// Each call returns a new API, so that handlers in one process do not share state.
+func AddRecipesAPIToHandler(handler core.Handler, recipes RecipeDB, events *RecipeEvents) *API {
+	rAPI := &API{
 		handler,
 		recipes,
+		configuredSubstitutions(),
+		newStatsCache(statsTTL),
+		events,
+		configuredRecipeIDGenerator(),
+		NewIdempotencyStore(idempotencyTTL),
 	}
 
-	api.prepareAPI()
+	rAPI.prepareAPI()
+	return rAPI
+}
+
+//reader returns the recipes whose reads are cancelled when the call is cancelled or times out.
+//Writes are not cancelled, so that changes are not left half-done.
+func (rAPI *API) reader(c *core.APICallContext) RecipeDB {
+	return rAPI.recipes.WithContext(c.Request.Context())
 }
 
 //prepareAPI registers all api endpoints for recipes
@@ -86,31 +224,117 @@ func (rAPI *API) prepareV1API() {
 	}
 
 	v1 := rAPI.handler.API(1)
+	secured := v1.Secured()
 
 	//GET the list of recipes
 	v1.GET("/recipes", rAPI.getRecipes)
 
+	//GET the list of archived recipes
+	v1.GET("/recipes/archived", rAPI.getArchivedRecipes)
+
+	//GET a stream of changes of recipes
+	v1.FeatureGate(eventsFeature).Stream("/recipes/events", rAPI.getRecipeEvents)
+
+	//GET aggregated numbers of all recipes
+	v1.GET("/recipes/stats", rAPI.getStats)
+
+	//GET names of ingredients for a type-ahead
+	v1.GET("/ingredients/suggest", rAPI.suggestIngredients)
+
+	//GET all tags and tags for a type-ahead
+	v1.GET("/tags", rAPI.getTags)
+	v1.GET("/tags/suggest", rAPI.suggestTags)
+
 	//POST a new recipe
-	v1.POST("/recipes", rAPI.postRecipes)
+	secured.POST("/recipes", rAPI.postRecipes)
+
+	//GET all recipes as CSV and POST recipes from a CSV file
+	v1.FeatureGate(csvFeature).GET("/recipes/export.csv", rAPI.exportRecipesCSV)
+	v1.FeatureGate(csvFeature).Secured().POST("/recipes/import.csv", rAPI.importRecipesCSV)
+
+	//POST a recipe to preview how it would be saved
+	v1.POST("/recipes/validate", rAPI.validateRecipe)
+
+	//POST merges duplicates into a recipe
+	secured.POST("/recipes/merge", rAPI.mergeRecipes)
+
+	//POST adds and removes tags of many recipes
+	secured.POST("/recipes/tags", rAPI.bulkTagRecipes)
+
+	//POST recipes to receive a list of their ingredients
+	v1.POST("/recipes/shopping-list", rAPI.postShoppingList)
 
 	//GET a random recipe
 	v1.GET("/recipes/rand", rAPI.getRandomRecipe)
 
+	//GET recipes matching a full-text search
+	v1.GET("/recipes/search", rAPI.searchRecipes)
+
+	//GET the recipe of the day
+	v1.GET("/recipes/daily", rAPI.getDailyRecipe)
+
+	//GET multiple recipes at once
+	v1.GET("/recipes/batch", rAPI.getRecipeBatch)
+
 	//GET the number of recipe
 	v1.GET("/recipes/num", rAPI.getNumberOfRecipes)
 
 	//GET a specific recipe
 	v1.GET("/recipes/r/:recipe", rAPI.getRecipe)
 
-	//PUT updates a specific recipe
-	v1.PUT("/recipes/r/:recipe", rAPI.putRecipe)
+	//GET a specific recipe exactly as it is stored, e.g., to edit it
+	v1.GET("/recipes/r/:recipe/raw", rAPI.getRawRecipe)
 
 	//PUT updates a specific recipe
-	v1.DELETE("/recipes/r/:recipe", rAPI.deleteRecipe)
+	secured.PUT("/recipes/r/:recipe", rAPI.putRecipe)
+
+	//GET a specific recipe in a specific format
+	v1.GET("/recipes/r/:recipe/export", rAPI.exportRecipe)
+	v1.GET("/recipes/r/:recipe/print", rAPI.printRecipe)
+	v1.GET("/recipes/r/:recipe/substitutions", rAPI.getSubstitutions)
+
+	//GET the cooking timers of a specific recipe's steps
+	v1.GET("/recipes/r/:recipe/timers", rAPI.getTimers)
+
+	//GET only the ingredients of a specific recipe
+	v1.GET("/recipes/r/:recipe/ingredients", rAPI.getIngredients)
+
+	//GET a specific recipe step by step for a guided cooking screen
+	v1.FeatureGate(cookModeFeature).GET("/recipes/r/:recipe/cook", rAPI.getCookMode)
+
+	//GET recipes with similar ingredients, e.g., duplicates of a specific recipe
+	v1.GET("/recipes/r/:recipe/similar", rAPI.getSimilarRecipes)
+
+	//POST creates a copy of a specific recipe
+	secured.POST("/recipes/r/:recipe/copy", rAPI.copyRecipe)
+
+	//DELETE archives or removes a specific recipe
+	secured.DELETE("/recipes/r/:recipe", rAPI.deleteRecipe)
+
+	//POST restores a specific archived recipe
+	secured.POST("/recipes/r/:recipe/restore", rAPI.restoreRecipe)
+
+	//GET the notes on a specific recipe
+	v1.GET("/recipes/r/:recipe/notes", rAPI.getNotes)
+
+	//POST a new note on a specific recipe
+	secured.POST("/recipes/r/:recipe/notes", rAPI.postNote)
+
+	//POST a rating for a specific recipe
+	v1.POST("/recipes/r/:recipe/rating", rAPI.postRating)
 
 	//GET a specific recipe's picture
 	v1.GET("/recipes/r/:recipe/pictures/:name", rAPI.getRecipePicture)
 
+	//POST a new picture for a specific recipe
+	secured.POST("/recipes/r/:recipe/pictures", rAPI.postRecipePicture)
+
+	//DELETE removes a specific recipe's picture
+	secured.DELETE("/recipes/r/:recipe/pictures/:name", rAPI.deleteRecipePicture)
+
+	//PUT rearranges a specific recipe's pictures and selects its primary picture
+	secured.PUT("/recipes/r/:recipe/pictures/order", rAPI.putPictureOrder)
+
 }
 
 // getNumberOfRecipes example
@@ -121,8 +345,8 @@ func (rAPI *API) prepareV1API() {
 // @Success 200 {integer} number
 // @Router /recipes/num [get]
 func (rAPI *API) getNumberOfRecipes(c *core.APICallContext) {
-	num := rAPI.recipes.Num()
-	log.Debugf("Number of Recipes %v", num)
+	num := rAPI.reader(c).Num()
+	core.LoggerFrom(c).Debugf("Number of Recipes %v", num)
 	c.String(http.StatusOK, fmt.Sprintf("%v", num))
 }
 
@@ -132,184 +356,1763 @@ func (rAPI *API) getNumberOfRecipes(c *core.APICallContext) {
 // @Description A specific picture of a specific recipe is returned
 // @Param recipe path string true "Recipe ID"
 // @Param name path string true "Name of Picture"
+// @Param raw query bool false "Return the picture itself instead of a base64 encoded picture wrapped in JSON"
+// @Param size query string false "Return the thumbnail of the picture instead of the original picture" Enums(thumb)
 // @Produce json
+// @Produce image/jpeg
+// @Produce image/png
 // @Success 200 {object} RecipePicture
+// @Failure 400 {object} core.APIError
+// @Failure 404 {object} core.APIError
 // @Router /recipes/r/{recipe}/pictures/{name} [get]
 func (rAPI *API) getRecipePicture(c *core.APICallContext) {
-	recipeID := NewRecipeIDFromString(c.Param(RECIPE))
+	recipeID, ok := recipeIDParam(c)
+	if !ok {
+		return
+	}
 	name := c.Param(NAME)
-	picture := rAPI.recipes.Picture(recipeID, name)
+	raw, _ := strconv.ParseBool(c.Query(RAW))
+	size := c.Query(SIZE)
+	if size != "" && size != pictureSizeThumb {
+		core.AbortWithAPIError(c, http.StatusBadRequest, "Unknown size", fmt.Sprintf("size has to be '%v'", pictureSizeThumb))
+		return
+	}
+
+	picture := rAPI.reader(c).Picture(recipeID, name)
+	if size == pictureSizeThumb && picture.Thumbnail != "" {
+		// pictures uploaded before thumbnails were generated are served in their original size
+		thumbnail := *picture
+		thumbnail.Picture = picture.Thumbnail
+		picture = &thumbnail
+	}
+
 	if picture.ID == InvalidRecipeID() {
-		c.String(http.StatusNotFound, "No such picture")
+		core.AbortWithAPIError(c, http.StatusNotFound, "No such picture", name)
+	} else if raw {
+		writeRawPicture(c, picture)
 	} else {
-		c.JSON(http.StatusOK, picture)
+		core.RespondJSON(c, http.StatusOK, picture)
 	}
 }
 
-// getRandomRecipe example
-// @Summary Get a Random Recipe
-// @Description A specific picture of a specific recipe is returned
+//writeRawPicture decodes the stored picture and writes it with the content type detected from the picture itself
+func writeRawPicture(c *core.APICallContext, picture *RecipePicture) {
+	img, err := utils.Base64ToIMG(picture.Picture)
+	if err != nil {
+		core.LoggerFrom(c).WithError(err).WithField("picture", picture.Name).Error("Could not decode picture")
+		core.AbortWithAPIError(c, http.StatusInternalServerError, "Could not decode picture", "")
+		return
+	}
+
+	c.Header("Cache-Control", pictureCacheControl)
+	c.Data(http.StatusOK, http.DetectContentType(img), img)
+}
+
+// postRecipePicture example
+// @Summary Upload a picture of a recipe
 // @Tags Recipes
-// @Param servings query int false "Number of Servings"
+// @Description A picture is added to a specific recipe. The picture's name is derived from the uploaded file's name.
+// @Param recipe path string true "Recipe ID"
+// @Param picture formData file true "Picture"
+// @Accept multipart/form-data
 // @Produce json
-// @Success 200 {object} Recipe
-// @Router /recipes/rand [get]
-func (rAPI *API) getRandomRecipe(c *core.APICallContext) {
-	query := c.Request.URL.Query()
-	servings := extractServings(query)
+// @Success 201 {object} PictureUploadResult
+// @Header 201 {string} Location "Path of the new picture"
+// @Failure 400 {object} core.APIError
+// @Failure 404 {object} core.APIError
+// @Failure 409 {object} core.APIError
+// @Failure 413 {object} core.APIError
+// @Failure 415 {object} core.APIError
+// @Security ApiKeyAuth
+// @Router /recipes/r/{recipe}/pictures [post]
+func (rAPI *API) postRecipePicture(c *core.APICallContext) {
+	recipeIDS := c.Param(RECIPE)
+	recipeID, ok := recipeIDParam(c)
+	if !ok {
+		return
+	}
+
+	if rAPI.reader(c).Get(recipeID).ID == InvalidRecipeID() {
+		core.AbortWithAPIError(c, http.StatusNotFound, "No such recipe", recipeIDS)
+		return
+	}
 
-	recipe := rAPI.recipes.Random()
+	file, header, err := c.Request.FormFile(PICTURE)
+	if err != nil {
+		core.AbortWithBodyError(c, "Could not read picture", err)
+		return
+	}
+	defer func() { _ = file.Close() }()
 
-	if servings > 0 {
-		recipe.ScaleTo(servings)
+	if header.Size > maxPictureBytes {
+		core.AbortWithAPIError(c, http.StatusRequestEntityTooLarge, "Picture too large", fmt.Sprintf("pictures must not be larger than %v bytes", maxPictureBytes))
+		return
 	}
 
-	if recipe.ID == InvalidRecipeID() {
-		c.String(http.StatusNotFound, "No such recipe")
+	name := filepath.Base(header.Filename)
+	if name == "." || name == ".." || name == string(filepath.Separator) {
+		core.AbortWithAPIError(c, http.StatusBadRequest, "Pictures need a file name", "")
+		return
+	}
+	if rAPI.reader(c).Picture(recipeID, name).ID != InvalidRecipeID() {
+		core.AbortWithAPIError(c, http.StatusConflict, "Picture already exists", name)
+		return
+	}
+
+	img, err := ioutil.ReadAll(file)
+	if err != nil {
+		core.AbortWithBodyError(c, "Could not read picture", err)
+		return
+	}
+
+	// do not trust the client's content type, inspect the picture instead
+	contentType := http.DetectContentType(img)
+	if !strings.HasPrefix(contentType, "image/") {
+		core.AbortWithAPIError(c, http.StatusUnsupportedMediaType, "Not an image", contentType)
+		return
+	}
+
+	// photos of phones are often stored sideways and carry private metadata like the location
+	img, err = utils.UprightJPEG(img)
+	if err == utils.ErrImageTooLarge {
+		abortWithTooManyPixels(c)
+		return
+	} else if err != nil {
+		core.AbortWithBodyError(c, "Could not read picture", err)
+		return
+	}
+
+	// very large pictures waste storage; pictures which cannot be decoded, e.g., webp pictures, are stored as they are
+	result := PictureUploadResult{Name: name}
+	if scaled, size, err := utils.Downscale(img, maxPictureDimension); err == utils.ErrImageTooLarge {
+		abortWithTooManyPixels(c)
+		return
+	} else if err != nil {
+		core.LoggerFrom(c).WithError(err).Warn("Could not downscale picture")
 	} else {
-		c.JSON(http.StatusOK, recipe)
+		img = scaled
+		contentType = http.DetectContentType(img)
+		result.Width, result.Height = size.X, size.Y
 	}
-}
 
-// getRecipes example
-// @Summary Get Recipes
-// @Description A list of ids of recipes is returned
-// @Tags Recipes
-// @Param name query string false "Search for a specific name"
-// @Param description query string false "Search for a specific term in a description"
-// @Param ingredient query string false "Search for a specific ingredient"
-// @Produce json
-// @Success 200 {object} RecipeList
-// @Router /recipes [get]
-func (rAPI *API) getRecipes(c *core.APICallContext) {
+	err = rAPI.recipes.AddPicture(&RecipePicture{
+		ID:        recipeID,
+		Name:      name,
+		Picture:   utils.IMGToBase64(contentType, img),
+		Thumbnail: thumbnailOf(c, img),
+	})
+	if err == ErrRecipeNotFound {
+		core.AbortWithAPIError(c, http.StatusNotFound, "No such recipe", recipeIDS)
+	} else if err != nil {
+		core.AbortWithAPIError(c, http.StatusInternalServerError, "Could not persist picture", "")
+	} else {
+		c.Header("Location", rAPI.pictureLocation(recipeID, name))
+		core.RespondJSON(c, http.StatusCreated, result)
+	}
+}
 
-	query := c.Request.URL.Query()
+//abortWithTooManyPixels rejects pictures which would exhaust the memory when they are decoded, e.g., decompression bombs
+func abortWithTooManyPixels(c *core.APICallContext) {
+	core.AbortWithAPIError(c, http.StatusRequestEntityTooLarge, "Picture too large", fmt.Sprintf("pictures must not have more than %v pixels", utils.MaxImagePixels))
+}
 
-	searchFilter := extractSearchFilter(query)
+//thumbnailOf an uploaded picture encoded in base64; pictures which cannot be decoded, e.g., webp pictures, have no thumbnail
+func thumbnailOf(c *core.APICallContext, img []byte) string {
+	thumb, err := utils.Thumbnail(img, thumbSize)
+	if err != nil {
+		core.LoggerFrom(c).WithError(err).Warn("Could not create thumbnail")
+		return ""
+	}
+	return utils.IMGToBase64(http.DetectContentType(thumb), thumb)
+}
 
-	debugFilterJSON, _ := json.Marshal(searchFilter)
-	log.WithField("json", string(debugFilterJSON)).Debug("Get Recipes")
+// deleteRecipePicture example
+// @Summary Delete a picture of a recipe
+// @Tags Recipes
+// @Description A specific picture of a specific recipe is removed
+// @Param recipe path string true "Recipe ID"
+// @Param name path string true "Name of Picture"
+// @Success 204
+// @Failure 400 {object} core.APIError
+// @Failure 404 {object} core.APIError
+// @Security ApiKeyAuth
+// @Router /recipes/r/{recipe}/pictures/{name} [delete]
+func (rAPI *API) deleteRecipePicture(c *core.APICallContext) {
+	recipeID, ok := recipeIDParam(c)
+	if !ok {
+		return
+	}
+	name := c.Param(NAME)
 
-	c.JSON(http.StatusOK, rAPI.recipes.IDs(searchFilter))
+	err := rAPI.recipes.DeletePicture(recipeID, name)
+	if err == ErrPictureNotFound {
+		core.AbortWithAPIError(c, http.StatusNotFound, "No such picture", name)
+	} else if err != nil {
+		core.AbortWithAPIError(c, http.StatusInternalServerError, "Could not delete picture", "")
+	} else {
+		c.Status(http.StatusNoContent)
+	}
 }
 
-// getRecipe documentation
-// @Summary Get a specific Recipe
-// @Description A specific recipe is returned
+// putPictureOrder example
+// @Summary Order the pictures of a recipe
 // @Tags Recipes
-// @Param servings query int false "Number of Servings"
+// @Description Rearranges the pictures of a specific recipe. The primary picture is listed first in the recipe's picture links and is shown as the recipe's thumbnail; without a primary picture, the first named picture becomes the primary one.
+// @Description Pictures which are not named keep their order and follow the named ones. The new picture links are returned.
 // @Param recipe path string true "Recipe ID"
+// @Param message body PictureOrder true "Names of the pictures in the desired order and the primary picture"
+// @Accept json
 // @Produce json
-// @Success 200 {object} Recipe
-// @Router /recipes/r/{recipe} [get]
-func (rAPI *API) getRecipe(c *core.APICallContext) {
+// @Success 200 {array} string
+// @Failure 400 {object} core.APIError
+// @Failure 404 {object} core.APIError
+// @Failure 409 {object} core.APIError
+// @Security ApiKeyAuth
+// @Router /recipes/r/{recipe}/pictures/order [put]
+func (rAPI *API) putPictureOrder(c *core.APICallContext) {
 	recipeIDS := c.Param(RECIPE)
-	recipeID := NewRecipeIDFromString(recipeIDS)
+	recipeID, ok := recipeIDParam(c)
+	if !ok {
+		return
+	}
 
-	query := c.Request.URL.Query()
-	servings := extractServings(query)
+	var order PictureOrder
+	if err := c.ShouldBindJSON(&order); err != nil {
+		core.AbortWithBodyError(c, "Could not read JSON input", err)
+		return
+	}
+	if err := order.Validate(); err != nil {
+		core.AbortWithAPIError(c, http.StatusBadRequest, "Invalid picture order", err.Error())
+		return
+	}
 
-	recipe := rAPI.recipes.Get(recipeID)
+	links, err := rAPI.recipes.SetPictureOrder(recipeID, order.Names, order.Primary)
+	switch {
+	case err == ErrRecipeNotFound:
+		core.AbortWithAPIError(c, http.StatusNotFound, "No such recipe", recipeIDS)
+	case errors.Is(err, ErrPictureNotFound):
+		core.AbortWithAPIError(c, http.StatusNotFound, "No such picture", err.Error())
+	case err == ErrVersionConflict:
+		core.AbortWithAPIError(c, http.StatusConflict, "Recipe was modified in the meantime", "the recipe's pictures changed")
+	case err != nil:
+		core.LoggerFrom(c).WithError(err).Error("Could not order pictures")
+		core.AbortWithAPIError(c, http.StatusInternalServerError, "Could not order pictures", "")
+	default:
+		core.RespondJSON(c, http.StatusOK, links)
+	}
+}
 
-	if servings > 0 {
-		recipe.ScaleTo(servings)
+// postShoppingList example
+// @Summary Get a shopping list
+// @Description The ingredients of all given recipes are scaled to the requested servings and merged to a shopping list
+// @Tags Recipes
+// @Param message body ShoppingListRequest true "Recipes and their servings"
+// @Accept json
+// @Produce json
+// @Success 200 {array} Ingredients
+// @Failure 400 {object} core.APIError
+// @Failure 404 {object} core.APIError
+// @Router /recipes/shopping-list [post]
+func (rAPI *API) postShoppingList(c *core.APICallContext) {
+	var request ShoppingListRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		core.AbortWithAPIError(c, http.StatusBadRequest, "Could not read JSON input", err.Error())
+		return
+	}
+	for _, item := range request.Recipes {
+		if err := validateServings(int64(item.Servings)); item.Servings != 0 && err != nil {
+			core.AbortWithAPIError(c, http.StatusBadRequest, "Invalid servings", err.Error())
+			return
+		}
 	}
 
-	if recipe.ID == InvalidRecipeID() {
-		c.String(http.StatusNotFound, "No such recipe: %v", recipeIDS)
+	ingredients, err := ShoppingList(rAPI.reader(c), request.Recipes)
+	if errors.Is(err, ErrRecipeNotFound) {
+		core.AbortWithAPIError(c, http.StatusNotFound, "No such recipe", err.Error())
+	} else if err != nil {
+		core.AbortWithAPIError(c, http.StatusInternalServerError, "Could not create shopping list", "")
 	} else {
-		c.JSON(http.StatusOK, recipe)
+		translateUnits(c, ingredients)
+		core.RespondJSON(c, http.StatusOK, ingredients)
 	}
 }
 
-// putRecipe example
-// @Summary Update a specific Recipe
-// @Description A specific recipe is updates
+// mergeRecipes example
+// @Summary Merge Recipes
+// @Description Merges duplicates into a primary recipe and deletes the duplicates, e.g., after finding them with the similar recipes. The pictures, notes, and ratings of the duplicates are moved to the primary recipe. Pictures whose names are already taken by the primary recipe or a preceding duplicate are dropped.
 // @Tags Recipes
-// @Param recipe path string true "Recipe ID"
-// @Param message body Recipe true "Recipe"
+// @Param message body MergeRequest true "Primary recipe and its duplicates"
 // @Accept json
 // @Produce json
-// @Success 200
-// @Router /recipes/r/{recipe} [put]
-func (rAPI *API) putRecipe(c *core.APICallContext) {
+// @Success 200 {object} Recipe
+// @Failure 400 {object} core.APIError
+// @Failure 404 {object} core.APIError
+// @Failure 413 {object} core.APIError
+// @Failure 500 {object} core.APIError
+// @Security ApiKeyAuth
+// @Router /recipes/merge [post]
+func (rAPI *API) mergeRecipes(c *core.APICallContext) {
+	var request MergeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		core.AbortWithBodyError(c, "Could not read JSON input", err)
+		return
+	}
+	if err := request.Validate(); err != nil {
+		core.AbortWithAPIError(c, http.StatusBadRequest, "Invalid merge request", err.Error())
+		return
+	}
+
+	recipe, err := rAPI.recipes.Merge(request.Primary, request.Duplicates)
+	if errors.Is(err, ErrRecipeNotFound) {
+		core.AbortWithAPIError(c, http.StatusNotFound, "No such recipe", err.Error())
+	} else if err != nil {
+		core.LoggerFrom(c).WithError(err).Error("Could not merge recipes")
+		core.AbortWithAPIError(c, http.StatusInternalServerError, "Could not merge recipes", "")
+	} else {
+		core.RespondJSON(c, http.StatusOK, recipe)
+	}
+}
+
+// bulkTagRecipes example
+// @Summary Tag many Recipes
+// @Description Removes tags from and then adds tags to many recipes at once, e.g., to reorganize recipes after an import. Tags are compared ignoring the case.
+// @Description The result tells for each id if the recipe was found; missing recipes do not stop the others from being tagged.
+// @Tags Recipes
+// @Param message body BulkTagRequest true "Recipes and the tags to add and to remove"
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]bool
+// @Failure 400 {object} core.APIError
+// @Failure 413 {object} core.APIError
+// @Security ApiKeyAuth
+// @Router /recipes/tags [post]
+func (rAPI *API) bulkTagRecipes(c *core.APICallContext) {
+	var request BulkTagRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		core.AbortWithBodyError(c, "Could not read JSON input", err)
+		return
+	}
+	if err := request.Validate(); err != nil {
+		core.AbortWithAPIError(c, http.StatusBadRequest, "Invalid tag request", err.Error())
+		return
+	}
 
+	results, err := rAPI.recipes.BulkTag(request.IDs, request.Add, request.Remove)
+	if err != nil {
+		core.LoggerFrom(c).WithError(err).Error("Could not tag recipes")
+		core.AbortWithAPIError(c, http.StatusInternalServerError, "Could not tag recipes", "")
+		return
+	}
+	core.RespondJSON(c, http.StatusOK, results)
+}
+
+// copyRecipe example
+// @Summary Copy a Recipe
+// @Description Creates a copy of a recipe, including its pictures, e.g., to build a variant of a dish. The name of the copy is marked with '(copy)'.
+// @Tags Recipes
+// @Param recipe path string true "Recipe ID"
+// @Produce json
+// @Success 201 {object} Recipe
+// @Header 201 {string} Location "Path of the new recipe"
+// @Failure 400 {object} core.APIError
+// @Failure 404 {object} core.APIError
+// @Failure 500 {object} core.APIError
+// @Security ApiKeyAuth
+// @Router /recipes/r/{recipe}/copy [post]
+func (rAPI *API) copyRecipe(c *core.APICallContext) {
 	recipeIDS := c.Param(RECIPE)
-	recipeID := NewRecipeIDFromString(recipeIDS)
+	recipeID, ok := recipeIDParam(c)
+	if !ok {
+		return
+	}
+	recipe := rAPI.reader(c).Get(recipeID)
+	if recipe.ID == InvalidRecipeID() {
+		core.AbortWithAPIError(c, http.StatusNotFound, "No such recipe", recipeIDS)
+		return
+	}
 
-	log.Error("Put Recipes called")
+	recipeCopy := recipe.Copy()
+	if err := insertWithGeneratedID(rAPI.ids, recipeCopy, rAPI.recipes); err != nil {
+		core.LoggerFrom(c).WithError(err).Error("Could not persist copy of recipe")
+		core.AbortWithAPIError(c, http.StatusInternalServerError, "Could not persist Recipe", "")
+		return
+	}
 
-	var recipe Recipe
-	err := c.BindJSON(&recipe)
-	if err != nil || rAPI.recipes.Get(recipeID).ID == InvalidRecipeID() {
-		c.String(http.StatusBadRequest, "Could not read JSON input")
-	} else {
-		recipe.ID = recipeID
-		err = rAPI.recipes.Update(recipeID, &recipe)
-		if err != nil {
-			c.String(http.StatusInternalServerError, "Could not persist Recipe")
-		} else {
-			c.Status(http.StatusNoContent)
+	for _, pic := range rAPI.reader(c).Pictures(recipe.ID) {
+		picCopy := *pic
+		picCopy.ID = recipeCopy.ID
+		if err := rAPI.recipes.AddPicture(&picCopy); err != nil {
+			core.LoggerFrom(c).WithError(err).WithField("picture", pic.Name).Warn("Could not copy picture of recipe")
 		}
 	}
+
+	c.Header("Location", rAPI.recipeLocation(recipeCopy.ID))
+	core.RespondJSON(c, http.StatusCreated, recipeCopy)
 }
 
-// postRecipes example
-// @Summary Add a new Recipe
-// @Description Adds a new recipe, the id will automatically overriden by the backend
+// getRandomRecipe example
+// @Summary Get a Random Recipe
+// @Description A random recipe is returned. Filters restrict the choice to the matching recipes.
+// @Description The same seed and the same collection of recipes always yield the same recipe.
 // @Tags Recipes
-// @Param message body Recipe true "Recipe"
-// @Accept json
+// @Param servings query int false "Number of Servings"
+// @Param seed query int false "Seed for a reproducible choice of the recipe"
+// @Param name query string false "Only pick recipes with a specific name"
+// @Param description query string false "Only pick recipes with a specific term in their description"
+// @Param ingredient query string false "Only pick recipes with a specific ingredient"
+// @Param tag query string false "Only pick recipes with this tag"
 // @Produce json
-// @Success 201
-// @Router /recipes [post]
-func (rAPI *API) postRecipes(c *core.APICallContext) {
-	var recipe Recipe
-	err := c.BindJSON(&recipe)
+// @Success 200 {object} Recipe
+// @Failure 400 {object} core.APIError
+// @Failure 404 {object} core.APIError
+// @Router /recipes/rand [get]
+func (rAPI *API) getRandomRecipe(c *core.APICallContext) {
+	query := c.Request.URL.Query()
+	servings, err := extractServings(query)
 	if err != nil {
-		c.String(http.StatusBadRequest, "Could not read JSON input")
-	} else {
-		recipe.ID = NewRecipeID()
-		err = rAPI.recipes.Insert(&recipe)
+		core.AbortWithAPIError(c, http.StatusBadRequest, "Invalid servings", err.Error())
+		return
+	}
+
+	var recipe *Recipe
+	if len(query[SEED]) > 0 {
+		seed, err := strconv.ParseInt(query[SEED][0], 10, 64)
 		if err != nil {
-			c.String(http.StatusInternalServerError, "Could not persist Recipe")
-		} else {
-			c.Status(http.StatusCreated)
+			core.AbortWithAPIError(c, http.StatusBadRequest, "Invalid seed", err.Error())
+			return
 		}
+		recipe = rAPI.reader(c).RandomSeeded(extractSearchFilter(query), seed)
+	} else {
+		recipe = rAPI.reader(c).RandomFiltered(extractSearchFilter(query))
+	}
+
+	if servings > 0 {
+		recipe.ScaleTo(servings)
+	}
+
+	if recipe.ID == InvalidRecipeID() {
+		core.AbortWithAPIError(c, http.StatusNotFound, "No such recipe", "")
+	} else {
+		core.RespondJSON(c, http.StatusOK, recipe)
 	}
 }
 
-// deleteRecipe example
-// @Summary Delete a Recipe
-// @Description Deletes a recipe by id
+// searchRecipes example
+// @Summary Search Recipes
+// @Description Recipes whose name, description, or ingredients contain all terms of the query are returned, the most relevant recipes first.
+// @Description Phrases can be quoted, e.g., "olive oil".
 // @Tags Recipes
-// @Param recipe path string true "Recipe ID"
-// @Accept json
+// @Param q query string true "Search query"
 // @Produce json
-// @Success 200
-// @Router /recipes/r/{recipe} [delete]
-func (rAPI *API) deleteRecipe(c *core.APICallContext) {
-	recipeIDS := c.Param(RECIPE)
-	recipeID := NewRecipeIDFromString(recipeIDS)
-	if err := rAPI.recipes.Remove(recipeID); err != nil {
-		c.String(http.StatusNotFound, "Recipe not found")
-		log.WithError(err).Debug("Could not Delete Recipe")
-	} else {
-		c.Status(http.StatusOK)
+// @Success 200 {array} RecipeSearchResult
+// @Failure 400 {object} core.APIError
+// @Router /recipes/search [get]
+func (rAPI *API) searchRecipes(c *core.APICallContext) {
+	query := extractSearchString(c.Request.URL.Query(), QUERY)
+	if len(SearchTerms(query)) == 0 {
+		core.AbortWithAPIError(c, http.StatusBadRequest, "Missing search query", "")
+		return
 	}
+
+	core.RespondJSON(c, http.StatusOK, rAPI.reader(c).Search(query))
 }
 
-func extractServings(query url.Values) int8 {
-	var servings int64 = -1
-	if len(query[SERVINGS]) > 0 {
-		servingsS := query[SERVINGS][0]
-		if num, err := strconv.ParseInt(servingsS, 10, 8); err == nil {
-			servings = num
-		} else {
-			log.WithError(err).Error("Could not convert the amount of servings requested")
+// getRecipeBatch example
+// @Summary Get multiple Recipes
+// @Description All existing recipes out of a comma separated list of ids are returned at once.
+// @Description Ids of recipes that do not exist are listed as notFound.
+// @Tags Recipes
+// @Param ids query string true "Comma separated list of Recipe IDs"
+// @Param servings query int false "Number of Servings"
+// @Produce json
+// @Success 200 {object} RecipeBatch
+// @Failure 400 {object} core.APIError
+// @Router /recipes/batch [get]
+func (rAPI *API) getRecipeBatch(c *core.APICallContext) {
+	query := c.Request.URL.Query()
+	servings, err := extractServings(query)
+	if err != nil {
+		core.AbortWithAPIError(c, http.StatusBadRequest, "Invalid servings", err.Error())
+		return
+	}
+
+	ids := make([]RecipeID, 0)
+	seen := make(map[RecipeID]bool)
+	for _, idS := range strings.Split(query.Get(IDS), ",") {
+		if idS = strings.TrimSpace(idS); idS == "" {
+			continue
+		}
+		id, err := NewRecipeIDFromString(idS)
+		if err != nil {
+			core.AbortWithAPIError(c, http.StatusBadRequest, "Invalid recipe id", idS)
+			return
 		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	if len(ids) == 0 {
+		core.AbortWithAPIError(c, http.StatusBadRequest, "Missing recipe ids", "")
+		return
+	}
+	if len(ids) > maxLimit {
+		core.AbortWithAPIError(c, http.StatusBadRequest, "Too many recipe ids", fmt.Sprintf("at most %v ids are allowed", maxLimit))
+		return
+	}
+
+	found := make(map[RecipeID]*Recipe)
+	for _, recipe := range rAPI.reader(c).GetMany(ids) {
+		found[recipe.ID] = recipe
+	}
+
+	batch := RecipeBatch{Recipes: make([]*Recipe, 0, len(found)), NotFound: make([]string, 0)}
+	for _, id := range ids {
+		recipe, ok := found[id]
+		if !ok {
+			batch.NotFound = append(batch.NotFound, id.String())
+			continue
+		}
+		if servings > 0 {
+			recipe.ScaleTo(servings)
+		}
+		batch.Recipes = append(batch.Recipes, recipe)
+	}
+
+	core.RespondJSON(c, http.StatusOK, batch)
+}
+
+// getDailyRecipe example
+// @Summary Get the Recipe of the Day
+// @Description The recipe of the day is chosen by the date, i.e., all clients see the same recipe for the whole day
+// @Tags Recipes
+// @Param date query string false "Preview the recipe of another day (format YYYY-MM-DD)"
+// @Param servings query int false "Number of Servings"
+// @Produce json
+// @Success 200 {object} Recipe
+// @Failure 400 {object} core.APIError
+// @Failure 404 {object} core.APIError
+// @Router /recipes/daily [get]
+func (rAPI *API) getDailyRecipe(c *core.APICallContext) {
+	query := c.Request.URL.Query()
+	servings, err := extractServings(query)
+	if err != nil {
+		core.AbortWithAPIError(c, http.StatusBadRequest, "Invalid servings", err.Error())
+		return
+	}
+
+	date := time.Now().Format(DateLayout)
+	if len(query[DATE]) > 0 {
+		day, err := time.Parse(DateLayout, query[DATE][0])
+		if err != nil {
+			core.AbortWithAPIError(c, http.StatusBadRequest, "Invalid date", err.Error())
+			return
+		}
+		date = day.Format(DateLayout)
+	}
+
+	ids := rAPI.reader(c).IDs(&RecipeSearchFilter{}).Recipes
+	if len(ids) == 0 {
+		core.AbortWithAPIError(c, http.StatusNotFound, "No such recipe", "")
+		return
+	}
+
+	recipe := rAPI.reader(c).Get(RecipeID(ids[dailyIndex(date, len(ids))]))
+	if recipe.ID == InvalidRecipeID() {
+		core.AbortWithAPIError(c, http.StatusNotFound, "No such recipe", "")
+		return
+	}
+
+	if servings > 0 {
+		recipe.ScaleTo(servings)
+	}
+	core.RespondJSON(c, http.StatusOK, recipe)
+}
+
+//dailyIndex hashes the date into an index of a list with num elements
+func dailyIndex(date string, num int) int {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(date))
+	return int(h.Sum64() % uint64(num))
+}
+
+// getRecipes example
+// @Summary Get Recipes
+// @Description A list of ids of recipes is returned. With expand=true a list of RecipeSummary, i.e., the id, name, primary picture, tags, and rating of each recipe, is returned instead of ids.
+// @Tags Recipes
+// @Param name query string false "Search for a specific name"
+// @Param description query string false "Search for a specific term in a description"
+// @Param ingredient query string false "Search for a specific ingredient"
+// @Param tag query string false "Only return recipes with this tag"
+// @Param since query string false "Only return recipes changed after this time (RFC3339), e.g., to synchronize clients"
+// @Param maxTotalTime query int false "Only return recipes which can be prepared and cooked in this many minutes"
+// @Param limit query int false "Maximal number of returned ids (default 50, max 500)"
+// @Param offset query int false "Number of ids to skip"
+// @Param sort query string false "Field to sort the ids by (default name)" Enums(name, created, rating, calories)
+// @Param order query string false "Direction of the sort (default asc, desc for rating)" Enums(asc, desc)
+// @Param expand query bool false "Return summaries of the recipes instead of their ids (default false)"
+// @Param If-Modified-Since header string false "Last-Modified of a cached list"
+// @Produce json
+// @Produce application/yaml
+// @Success 200 {object} RecipeList
+// @Header 200 {integer} X-Total-Count "Number of recipes matching the search"
+// @Header 200 {string} Last-Modified "Time of the latest change of any recipe"
+// @Success 304
+// @Failure 400 {object} core.APIError
+// @Router /recipes [get]
+func (rAPI *API) getRecipes(c *core.APICallContext) {
+
+	query := c.Request.URL.Query()
+
+	searchFilter := extractSearchFilter(query)
+	if !validSort(searchFilter.Sort) {
+		core.AbortWithAPIError(c, http.StatusBadRequest, "Invalid sort field", searchFilter.Sort)
+		return
+	}
+	if !validOrder(searchFilter.Order) {
+		core.AbortWithAPIError(c, http.StatusBadRequest, "Invalid sort order", searchFilter.Order)
+		return
+	}
+	if searchFilter.Sort == "" {
+		searchFilter.Sort = SortByName
+	}
+	if since := query.Get(SINCE); since != "" {
+		changedSince, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			core.AbortWithAPIError(c, http.StatusBadRequest, "Invalid since parameter", err.Error())
+			return
+		}
+		searchFilter.ChangedSince = changedSince
+	}
+	if maxTotalTime := query.Get(MAXTOTALTIME); maxTotalTime != "" {
+		minutes, err := strconv.Atoi(maxTotalTime)
+		if err != nil || minutes <= 0 {
+			core.AbortWithAPIError(c, http.StatusBadRequest, "Invalid maxTotalTime parameter", maxTotalTime)
+			return
+		}
+		searchFilter.MaxTotalTime = minutes
+	}
+
+	offset, limit, err := extractPaging(query)
+	if err != nil {
+		core.AbortWithAPIError(c, http.StatusBadRequest, "Invalid paging parameters", err.Error())
+		return
+	}
+
+	expand := false
+	if expandParam := query.Get(EXPAND); expandParam != "" {
+		if expand, err = strconv.ParseBool(expandParam); err != nil {
+			core.AbortWithAPIError(c, http.StatusBadRequest, "Invalid expand parameter", expandParam)
+			return
+		}
+	}
+
+	debugFilterJSON, _ := json.Marshal(searchFilter)
+	core.LoggerFrom(c).WithField("json", string(debugFilterJSON)).Debug("Get Recipes")
+
+	if lastModified := rAPI.reader(c).LastModified(); !lastModified.IsZero() {
+		c.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+		if notModifiedSince(c.GetHeader("If-Modified-Since"), lastModified) {
+			c.Status(http.StatusNotModified)
+			return
+		}
+	}
+
+	c.Header(totalCountHeader, strconv.FormatInt(rAPI.reader(c).Count(searchFilter), 10))
+	if expand {
+		core.Respond(c, http.StatusOK, rAPI.reader(c).Summaries(searchFilter, offset, limit))
+		return
+	}
+	core.Respond(c, http.StatusOK, rAPI.reader(c).IDsPaged(searchFilter, offset, limit))
+}
+
+// getArchivedRecipes example
+// @Summary Get archived Recipes
+// @Description A list of ids of archived recipes is returned, ordered by name. Archived recipes can be restored or removed permanently.
+// @Tags Recipes
+// @Param limit query int false "Maximal number of returned ids (default 50, max 500)"
+// @Param offset query int false "Number of ids to skip"
+// @Produce json
+// @Produce application/yaml
+// @Success 200 {object} RecipeList
+// @Header 200 {integer} X-Total-Count "Number of archived recipes"
+// @Failure 400 {object} core.APIError
+// @Router /recipes/archived [get]
+func (rAPI *API) getArchivedRecipes(c *core.APICallContext) {
+	offset, limit, err := extractPaging(c.Request.URL.Query())
+	if err != nil {
+		core.AbortWithAPIError(c, http.StatusBadRequest, "Invalid paging parameters", err.Error())
+		return
+	}
+
+	searchFilter := &RecipeSearchFilter{Archived: true, Sort: SortByName}
+	c.Header(totalCountHeader, strconv.FormatInt(rAPI.reader(c).Count(searchFilter), 10))
+	core.Respond(c, http.StatusOK, rAPI.reader(c).IDsPaged(searchFilter, offset, limit))
+}
+
+// getRecipeEvents example
+// @Summary Stream changes of Recipes
+// @Description Server-sent events about created, updated, and deleted recipes, e.g., to update a list of recipes without polling. The type of a server-sent event is the type of the change and its data is a RecipeEvent.
+// @Description Clients that reconnect with the id of the last received event receive the recent events they missed. Idle streams receive comments to keep the connection open.
+// @Tags Recipes
+// @Param Last-Event-ID header string false "Id of the last received event"
+// @Produce text/event-stream
+// @Success 200 {object} RecipeEvent
+// @Router /recipes/events [get]
+func (rAPI *API) getRecipeEvents(c *core.APICallContext) {
+	replay, events, unsubscribe := rAPI.events.subscribe(c.GetHeader(lastEventIDHeader))
+	defer unsubscribe()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	stream := core.OpenStream(c)
+	defer stream.Close()
+
+	for _, event := range replay {
+		if err := writeServerSentEvent(stream, event); err != nil {
+			return
+		}
+	}
+	if err := stream.Flush(); err != nil {
+		return
+	}
+
+	keepAlive := time.NewTicker(eventsKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		var err error
+		select {
+		case event, ok := <-events:
+			if !ok {
+				core.LoggerFrom(c).Debug("Event stream is closed, since the client cannot keep up")
+				return
+			}
+			err = writeServerSentEvent(stream, event)
+		case <-keepAlive.C:
+			_, err = stream.WriteString(": keep-alive\n\n")
+		case <-stream.Done():
+			return
+		}
+		if err == nil {
+			err = stream.Flush()
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// getStats example
+// @Summary Statistics of Recipes
+// @Description Aggregated numbers of all recipes, e.g., for a dashboard. The statistics are cached for a short time, i.e., recent changes may be missing.
+// @Tags Recipes
+// @Produce json
+// @Success 200 {object} RecipeStats
+// @Failure 500 {object} core.APIError
+// @Router /recipes/stats [get]
+func (rAPI *API) getStats(c *core.APICallContext) {
+	stats, err := rAPI.stats.get(rAPI.reader(c).Stats)
+	if err != nil {
+		core.LoggerFrom(c).WithError(err).Error("Could not compute statistics of recipes")
+		core.AbortWithAPIError(c, http.StatusInternalServerError, "Could not compute statistics", "")
+		return
+	}
+	core.RespondJSON(c, http.StatusOK, stats)
+}
+
+// suggestIngredients example
+// @Summary Suggest Ingredients
+// @Description Distinct names of ingredients of all recipes which start with the query, ignoring the case, e.g., for a type-ahead. The names are in lower case.
+// @Tags Recipes
+// @Param q query string true "Beginning of the ingredient's name"
+// @Produce json
+// @Success 200 {array} string
+// @Failure 400 {object} core.APIError
+// @Router /ingredients/suggest [get]
+func (rAPI *API) suggestIngredients(c *core.APICallContext) {
+	prefix := strings.TrimSpace(c.Query(QUERY))
+	if prefix == "" {
+		core.AbortWithAPIError(c, http.StatusBadRequest, "Missing search query", "")
+		return
+	}
+
+	core.RespondJSON(c, http.StatusOK, rAPI.reader(c).SuggestIngredients(prefix, suggestLimit))
+}
+
+// getTags example
+// @Summary List Tags
+// @Description All distinct tags in lower case and the number of recipes carrying them, the most used tag first
+// @Tags Recipes
+// @Produce json
+// @Success 200 {array} TagCount
+// @Failure 500 {object} core.APIError
+// @Router /tags [get]
+func (rAPI *API) getTags(c *core.APICallContext) {
+	tags, err := rAPI.reader(c).Tags()
+	if err != nil {
+		core.LoggerFrom(c).WithError(err).Error("Could not list tags")
+		core.AbortWithAPIError(c, http.StatusInternalServerError, "Could not list tags", "")
+		return
+	}
+	core.RespondJSON(c, http.StatusOK, tags)
+}
+
+// suggestTags example
+// @Summary Suggest Tags
+// @Description Tags which start with the query, ignoring the case, e.g., for a type-ahead. The most used tag comes first.
+// @Tags Recipes
+// @Param q query string true "Beginning of the tag"
+// @Produce json
+// @Success 200 {array} TagCount
+// @Failure 400 {object} core.APIError
+// @Failure 500 {object} core.APIError
+// @Router /tags/suggest [get]
+func (rAPI *API) suggestTags(c *core.APICallContext) {
+	prefix := normalizeTag(c.Query(QUERY))
+	if prefix == "" {
+		core.AbortWithAPIError(c, http.StatusBadRequest, "Missing search query", "")
+		return
+	}
+
+	tags, err := rAPI.reader(c).Tags()
+	if err != nil {
+		core.LoggerFrom(c).WithError(err).Error("Could not list tags")
+		core.AbortWithAPIError(c, http.StatusInternalServerError, "Could not list tags", "")
+		return
+	}
+
+	suggestions := make([]TagCount, 0)
+	for _, tag := range tags {
+		if len(suggestions) < suggestLimit && strings.HasPrefix(tag.Tag, prefix) {
+			suggestions = append(suggestions, tag)
+		}
+	}
+	core.RespondJSON(c, http.StatusOK, suggestions)
+}
+
+// getRecipe documentation
+// @Summary Get a specific Recipe
+// @Description A specific recipe is returned
+// @Tags Recipes
+// @Param servings query int false "Number of Servings"
+// @Param units query string false "Convert amounts and temperatures to a system of units; the stored recipe is not changed. weight converts volumes of ingredients with a known density, e.g., cups of flour, and all weights to g or kg" Enums(metric, imperial, weight)
+// @Param recipe path string true "Recipe ID"
+// @Param If-None-Match header string false "ETag of a cached recipe"
+// @Produce json
+// @Produce application/yaml
+// @Success 200 {object} Recipe
+// @Header 200 {string} ETag "Version of the recipe and hash of the returned document"
+// @Success 304
+// @Failure 400 {object} core.APIError
+// @Failure 404 {object} core.APIError
+// @Router /recipes/r/{recipe} [get]
+func (rAPI *API) getRecipe(c *core.APICallContext) {
+	recipeIDS := c.Param(RECIPE)
+	recipeID, ok := recipeIDParam(c)
+	if !ok {
+		return
+	}
+
+	logger := core.LoggerFrom(c).WithField("recipe", recipeIDS)
+	logger.Debug("Get Recipe")
+
+	query := c.Request.URL.Query()
+	servings, err := extractServings(query)
+	if err != nil {
+		core.AbortWithAPIError(c, http.StatusBadRequest, "Invalid servings", err.Error())
+		return
+	}
+
+	system, err := extractUnitSystem(query)
+	if err != nil {
+		core.AbortWithAPIError(c, http.StatusBadRequest, "Invalid units", query.Get(UNITS))
+		return
+	}
+
+	recipe := rAPI.reader(c).Get(recipeID)
+
+	if servings > 0 {
+		recipe.ScaleTo(servings)
+	}
+	if system != "" {
+		recipe.ConvertUnits(system)
+	}
+	translateUnits(c, recipe.Ingredients)
+
+	if recipe.ID == InvalidRecipeID() {
+		logger.Debug("Recipe not found")
+		core.AbortWithAPIError(c, http.StatusNotFound, "No such recipe", recipeIDS)
+	} else {
+		etag := recipeETag(recipe)
+		c.Header("ETag", etag)
+		if matchesETag(c.GetHeader("If-None-Match"), etag) {
+			c.Status(http.StatusNotModified)
+			return
+		}
+		core.Respond(c, http.StatusOK, recipe)
+	}
+}
+
+// getRawRecipe documentation
+// @Summary Get a specific Recipe as stored
+// @Description A specific recipe is returned exactly as it is stored, i.e., it is neither scaled to recipes.defaultServings nor are its units converted or translated.
+// @Description Editors read recipes this way, so that saving an edited recipe does not accumulate rounding errors of scaled amounts.
+// @Tags Recipes
+// @Param recipe path string true "Recipe ID"
+// @Param If-None-Match header string false "ETag of a cached recipe"
+// @Produce json
+// @Produce application/yaml
+// @Success 200 {object} Recipe
+// @Header 200 {string} ETag "Version of the recipe and hash of the returned document"
+// @Success 304
+// @Failure 400 {object} core.APIError
+// @Failure 404 {object} core.APIError
+// @Router /recipes/r/{recipe}/raw [get]
+func (rAPI *API) getRawRecipe(c *core.APICallContext) {
+	recipeID, ok := recipeIDParam(c)
+	if !ok {
+		return
+	}
+
+	recipe := rAPI.reader(c).Get(recipeID)
+	if recipe.ID == InvalidRecipeID() {
+		core.AbortWithAPIError(c, http.StatusNotFound, "No such recipe", c.Param(RECIPE))
+		return
+	}
+
+	etag := recipeETag(recipe)
+	c.Header("ETag", etag)
+	if matchesETag(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	core.Respond(c, http.StatusOK, recipe)
+}
+
+// exportRecipe example
+// @Summary Export a specific Recipe
+// @Description A specific recipe is rendered in the requested format
+// @Tags Recipes
+// @Param recipe path string true "Recipe ID"
+// @Param format query string false "Format of the recipe (default json)" Enums(json, markdown, plaintext, pdf)
+// @Param servings query int false "Number of Servings"
+// @Produce json
+// @Produce text/markdown
+// @Produce plain
+// @Produce application/pdf
+// @Success 200 {string} string
+// @Failure 400 {object} core.APIError
+// @Failure 404 {object} core.APIError
+// @Router /recipes/r/{recipe}/export [get]
+func (rAPI *API) exportRecipe(c *core.APICallContext) {
+	recipeIDS := c.Param(RECIPE)
+	recipeID, ok := recipeIDParam(c)
+	if !ok {
+		return
+	}
+	format := c.Query(FORMAT)
+	servings, err := extractServings(c.Request.URL.Query())
+	if err != nil {
+		core.AbortWithAPIError(c, http.StatusBadRequest, "Invalid servings", err.Error())
+		return
+	}
+
+	recipe := rAPI.reader(c).Get(recipeID)
+	if recipe.ID == InvalidRecipeID() {
+		core.AbortWithAPIError(c, http.StatusNotFound, "No such recipe", recipeIDS)
+		return
+	}
+
+	if servings > 0 {
+		recipe.ScaleTo(servings)
+	}
+
+	result, contentType, err := Render(*recipe, format)
+	if err == ErrUnknownFormat {
+		core.AbortWithAPIError(c, http.StatusBadRequest, "Unknown format", format)
+	} else if err != nil {
+		core.LoggerFrom(c).WithError(err).Error("Could not render recipe")
+		core.AbortWithAPIError(c, http.StatusInternalServerError, "Could not render recipe", "")
+	} else {
+		if format == FormatPDF {
+			c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", exportFileName(recipe.Name, "pdf")))
+		}
+		c.Data(http.StatusOK, contentType, result)
+	}
+}
+
+// printRecipe example
+// @Summary Print a specific Recipe
+// @Description A specific recipe is rendered as a self-contained HTML page to be printed from a browser
+// @Tags Recipes
+// @Param recipe path string true "Recipe ID"
+// @Param servings query int false "Number of Servings"
+// @Produce html
+// @Success 200 {string} string
+// @Failure 400 {object} core.APIError
+// @Failure 404 {object} core.APIError
+// @Router /recipes/r/{recipe}/print [get]
+func (rAPI *API) printRecipe(c *core.APICallContext) {
+	recipeIDS := c.Param(RECIPE)
+	recipeID, ok := recipeIDParam(c)
+	if !ok {
+		return
+	}
+	servings, err := extractServings(c.Request.URL.Query())
+	if err != nil {
+		core.AbortWithAPIError(c, http.StatusBadRequest, "Invalid servings", err.Error())
+		return
+	}
+
+	recipe := rAPI.reader(c).Get(recipeID)
+	if recipe.ID == InvalidRecipeID() {
+		core.AbortWithAPIError(c, http.StatusNotFound, "No such recipe", recipeIDS)
+		return
+	}
+
+	if servings > 0 {
+		recipe.ScaleTo(servings)
+	}
+
+	result, err := renderHTML(*recipe)
+	if err != nil {
+		core.LoggerFrom(c).WithError(err).Error("Could not render recipe")
+		core.AbortWithAPIError(c, http.StatusInternalServerError, "Could not render recipe", "")
+		return
+	}
+	c.Data(http.StatusOK, "text/html; charset=utf-8", result)
+}
+
+// getSubstitutions example
+// @Summary Substitutes of Ingredients
+// @Description Suggests substitutes for each ingredient of a specific recipe. Ingredients without a known substitute have an empty list.
+// @Tags Recipes
+// @Param recipe path string true "Recipe ID"
+// @Produce json
+// @Success 200 {array} recipes.IngredientSubstitutions
+// @Failure 400 {object} core.APIError
+// @Failure 404 {object} core.APIError
+// @Router /recipes/r/{recipe}/substitutions [get]
+func (rAPI *API) getSubstitutions(c *core.APICallContext) {
+	recipeID, ok := recipeIDParam(c)
+	if !ok {
+		return
+	}
+
+	recipe := rAPI.reader(c).Get(recipeID)
+	if recipe.ID == InvalidRecipeID() {
+		core.AbortWithAPIError(c, http.StatusNotFound, "No such recipe", c.Param(RECIPE))
+		return
+	}
+
+	core.RespondJSON(c, http.StatusOK, rAPI.substitutions.Suggest(recipe))
+}
+
+// getTimers example
+// @Summary Timers of a Recipe
+// @Description Lists a cooking timer for each step of a specific recipe that takes some time. Recipes without such steps have no timers.
+// @Tags Recipes
+// @Param recipe path string true "Recipe ID"
+// @Produce json
+// @Success 200 {array} recipes.RecipeTimer
+// @Failure 400 {object} core.APIError
+// @Failure 404 {object} core.APIError
+// @Router /recipes/r/{recipe}/timers [get]
+func (rAPI *API) getTimers(c *core.APICallContext) {
+	recipeID, ok := recipeIDParam(c)
+	if !ok {
+		return
+	}
+
+	recipe := rAPI.reader(c).Get(recipeID)
+	if recipe.ID == InvalidRecipeID() {
+		core.AbortWithAPIError(c, http.StatusNotFound, "No such recipe", c.Param(RECIPE))
+		return
+	}
+
+	core.RespondJSON(c, http.StatusOK, recipe.Timers())
+}
+
+// getIngredients example
+// @Summary Get the Ingredients of a Recipe
+// @Description Returns only the ingredients of a specific recipe, scaled and converted like the recipe itself, e.g., for a shopping widget which does not need the description and the pictures.
+// @Tags Recipes
+// @Param recipe path string true "Recipe ID"
+// @Param servings query int false "Number of Servings"
+// @Param units query string false "Convert amounts to a system of units; weight converts volumes of ingredients with a known density to g or kg" Enums(metric, imperial, weight)
+// @Produce json
+// @Produce application/yaml
+// @Success 200 {array} recipes.Ingredients
+// @Failure 400 {object} core.APIError
+// @Failure 404 {object} core.APIError
+// @Router /recipes/r/{recipe}/ingredients [get]
+func (rAPI *API) getIngredients(c *core.APICallContext) {
+	recipeID, ok := recipeIDParam(c)
+	if !ok {
+		return
+	}
+
+	query := c.Request.URL.Query()
+	servings, err := extractServings(query)
+	if err != nil {
+		core.AbortWithAPIError(c, http.StatusBadRequest, "Invalid servings", err.Error())
+		return
+	}
+	system, err := extractUnitSystem(query)
+	if err != nil {
+		core.AbortWithAPIError(c, http.StatusBadRequest, "Invalid units", query.Get(UNITS))
+		return
+	}
+
+	recipe := rAPI.reader(c).Get(recipeID)
+	if recipe.ID == InvalidRecipeID() {
+		core.AbortWithAPIError(c, http.StatusNotFound, "No such recipe", c.Param(RECIPE))
+		return
+	}
+
+	if servings > 0 {
+		recipe.ScaleTo(servings)
+	}
+	if system != "" {
+		recipe.ConvertUnits(system)
+	}
+	translateUnits(c, recipe.Ingredients)
+
+	if recipe.Ingredients == nil {
+		// clients expect a list, even for recipes without ingredients
+		recipe.Ingredients = make([]Ingredients, 0)
+	}
+	core.Respond(c, http.StatusOK, recipe.Ingredients)
+}
+
+// getCookMode example
+// @Summary Cook a Recipe step by step
+// @Description Returns a specific recipe prepared for a guided cooking screen, i.e., its scaled ingredients and its steps in order.
+// @Description Each step lists the ingredients its text mentions by name and its timer. Recipes without structured steps are cooked line by line of their description.
+// @Tags Recipes
+// @Param recipe path string true "Recipe ID"
+// @Param servings query int false "Number of Servings"
+// @Param units query string false "Convert amounts and temperatures to a system of units; weight converts volumes of ingredients with a known density to g or kg" Enums(metric, imperial, weight)
+// @Produce json
+// @Success 200 {object} CookMode
+// @Failure 400 {object} core.APIError
+// @Failure 404 {object} core.APIError
+// @Router /recipes/r/{recipe}/cook [get]
+func (rAPI *API) getCookMode(c *core.APICallContext) {
+	recipeID, ok := recipeIDParam(c)
+	if !ok {
+		return
+	}
+
+	query := c.Request.URL.Query()
+	servings, err := extractServings(query)
+	if err != nil {
+		core.AbortWithAPIError(c, http.StatusBadRequest, "Invalid servings", err.Error())
+		return
+	}
+	system, err := extractUnitSystem(query)
+	if err != nil {
+		core.AbortWithAPIError(c, http.StatusBadRequest, "Invalid units", query.Get(UNITS))
+		return
+	}
+
+	recipe := rAPI.reader(c).Get(recipeID)
+	if recipe.ID == InvalidRecipeID() {
+		core.AbortWithAPIError(c, http.StatusNotFound, "No such recipe", c.Param(RECIPE))
+		return
+	}
+
+	if servings > 0 {
+		recipe.ScaleTo(servings)
+	}
+	if system != "" {
+		recipe.ConvertUnits(system)
+	}
+	cook := recipe.CookMode()
+	translateUnits(c, cook.Ingredients)
+	for i := range cook.Steps {
+		translateUnits(c, cook.Steps[i].Ingredients)
+	}
+
+	core.RespondJSON(c, http.StatusOK, cook)
+}
+
+// getSimilarRecipes example
+// @Summary Similar Recipes
+// @Description Lists recipes whose ingredients overlap with the ingredients of a specific recipe, e.g., to find duplicates. The similarity is the Jaccard similarity of the recipes' ingredient names; the most similar recipes are listed first.
+// @Tags Recipes
+// @Param recipe path string true "Recipe ID"
+// @Param threshold query number false "Minimal similarity between 0 and 1 (default 0.5)"
+// @Produce json
+// @Success 200 {array} recipes.SimilarRecipe
+// @Failure 400 {object} core.APIError
+// @Failure 404 {object} core.APIError
+// @Router /recipes/r/{recipe}/similar [get]
+func (rAPI *API) getSimilarRecipes(c *core.APICallContext) {
+	recipeID, ok := recipeIDParam(c)
+	if !ok {
+		return
+	}
+
+	threshold := defaultSimilarityThreshold
+	if thresholdS := c.Query(THRESHOLD); thresholdS != "" {
+		var err error
+		threshold, err = strconv.ParseFloat(thresholdS, 64)
+		if err != nil || threshold < 0 || threshold > 1 {
+			core.AbortWithAPIError(c, http.StatusBadRequest, "Invalid threshold parameter", "threshold has to be a number between 0 and 1")
+			return
+		}
+	}
+
+	similar, err := rAPI.reader(c).FindSimilar(recipeID, threshold)
+	if err == ErrRecipeNotFound {
+		core.AbortWithAPIError(c, http.StatusNotFound, "No such recipe", c.Param(RECIPE))
+	} else if err != nil {
+		core.AbortWithAPIError(c, http.StatusInternalServerError, "Could not find similar recipes", "")
+	} else {
+		core.RespondJSON(c, http.StatusOK, similar)
+	}
+}
+
+//translateUnits of ingredients into the language of the client, e.g., tbsp into EL for German clients
+func translateUnits(c *core.APICallContext, ingredients []Ingredients) {
+	core.Localized(c)
+	for i := range ingredients {
+		ingredients[i].Unit = core.T(c, ingredients[i].Unit)
+	}
+}
+
+//untranslateUnits reads the units of ingredients in the language of the client, so that only canonical units are stored
+func untranslateUnits(c *core.APICallContext, ingredients []Ingredients) {
+	for i := range ingredients {
+		ingredients[i].Unit = core.Untranslate(c, ingredients[i].Unit)
+	}
+}
+
+//exportFileName derives a file name from a recipe's name, i.e., 'Apple Pie' results in 'Apple-Pie.pdf'
+func exportFileName(name string, extension string) string {
+	fileName := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		case r == ' ':
+			return '-'
+		default:
+			return -1
+		}
+	}, strings.TrimSpace(name))
+	if fileName == "" {
+		fileName = "recipe"
+	}
+	return fileName + "." + extension
+}
+
+// putRecipe example
+// @Summary Update a specific Recipe
+// @Description A specific recipe is updated. The recipe's version has to be the version that was read; otherwise the recipe has been modified in the meantime and 409 is returned.
+// @Tags Recipes
+// @Param recipe path string true "Recipe ID"
+// @Param message body Recipe true "Recipe"
+// @Accept json
+// @Produce json
+// @Success 204
+// @Header 204 {string} ETag "ETag of the updated recipe"
+// @Failure 400 {object} core.APIError
+// @Failure 404 {object} core.APIError
+// @Failure 409 {object} core.APIError
+// @Failure 413 {object} core.APIError
+// @Security ApiKeyAuth
+// @Router /recipes/r/{recipe} [put]
+func (rAPI *API) putRecipe(c *core.APICallContext) {
+
+	recipeIDS := c.Param(RECIPE)
+	recipeID, ok := recipeIDParam(c)
+	if !ok {
+		return
+	}
+
+	core.LoggerFrom(c).WithField("recipe", recipeIDS).Debug("Put Recipe")
+
+	var recipe Recipe
+	err := c.ShouldBindJSON(&recipe)
+	untranslateUnits(c, recipe.Ingredients)
+	if err != nil {
+		core.AbortWithBodyError(c, "Could not read JSON input", err)
+	} else if err = recipe.Validate(); err != nil {
+		abortWithValidationError(c, err)
+	} else if rAPI.reader(c).Get(recipeID).ID == InvalidRecipeID() {
+		core.AbortWithAPIError(c, http.StatusNotFound, "No such recipe", recipeIDS)
+	} else {
+		recipe.ID = recipeID
+		err = rAPI.recipes.Update(recipeID, &recipe)
+		if errors.Is(err, ErrVersionConflict) {
+			core.AbortWithAPIError(c, http.StatusConflict, "Recipe was modified in the meantime", fmt.Sprintf("version %v is outdated", recipe.Version))
+		} else if err != nil {
+			core.AbortWithAPIError(c, http.StatusInternalServerError, "Could not persist Recipe", "")
+		} else {
+			c.Header("ETag", recipeETag(&recipe))
+			c.Status(http.StatusNoContent)
+		}
+	}
+}
+
+// postRecipes example
+// @Summary Add a new Recipe
+// @Description Adds a new recipe, the id will automatically overriden by the backend
+// @Description Clients that retry the request, e.g., on flaky networks, send the same Idempotency-Key, e.g., a UUID, with each attempt. A repeated request is answered like the original one without creating the recipe again; the response has the header Idempotent-Replayed. Keys of different api keys do not collide.
+// @Tags Recipes
+// @Param message body Recipe true "Recipe"
+// @Param Idempotency-Key header string false "Key of the request, at most 255 characters, which is remembered for recipes.idempotency.ttl (default 24h)"
+// @Accept json
+// @Produce json
+// @Success 201
+// @Header 201 {string} Location "Path of the new recipe"
+// @Header 201 {string} Idempotent-Replayed "true if the recipe was created by an earlier request with the same Idempotency-Key"
+// @Failure 400 {object} core.APIError
+// @Failure 409 {object} core.APIError "A request with the same Idempotency-Key is in progress"
+// @Failure 413 {object} core.APIError
+// @Failure 422 {object} core.APIError "The Idempotency-Key was used for a different recipe"
+// @Security ApiKeyAuth
+// @Router /recipes [post]
+func (rAPI *API) postRecipes(c *core.APICallContext) {
+	key := c.GetHeader(idempotencyKeyHeader)
+	if len(key) > maxIdempotencyKeyLength {
+		core.AbortWithAPIError(c, http.StatusBadRequest, "Invalid Idempotency-Key", fmt.Sprintf("keys must not be longer than %v characters", maxIdempotencyKeyLength))
+		return
+	}
+
+	var recipe Recipe
+	err := c.ShouldBindJSON(&recipe)
+	untranslateUnits(c, recipe.Ingredients)
+	if err != nil {
+		core.AbortWithBodyError(c, "Could not read JSON input", err)
+		return
+	} else if err = recipe.Validate(); err != nil {
+		abortWithValidationError(c, err)
+		return
+	}
+
+	created := false
+	if key != "" {
+		storeKey := idempotencyStoreKey(core.AuthenticatedUser(c), key)
+		if !rAPI.reserveIdempotencyKey(c, storeKey, key, &recipe) {
+			return
+		}
+		// the key is released unless the recipe was created, even if persisting the recipe panics
+		defer func() {
+			if created {
+				rAPI.idempotency.Complete(storeKey, recipe.ID)
+			} else {
+				rAPI.idempotency.Release(storeKey)
+			}
+		}()
+	}
+
+	recipe.Version = 0
+	err = insertWithGeneratedID(rAPI.ids, &recipe, rAPI.recipes)
+	if err != nil {
+		core.AbortWithAPIError(c, http.StatusInternalServerError, "Could not persist Recipe", "")
+	} else {
+		created = true
+		c.Header("Location", rAPI.recipeLocation(recipe.ID))
+		c.Status(http.StatusCreated)
+	}
+}
+
+//reserveIdempotencyKey of the client, i.e., the storeKey, for the creation of the recipe. If the key was used before, the request is answered and false is returned.
+func (rAPI *API) reserveIdempotencyKey(c *core.APICallContext, storeKey string, key string, recipe *Recipe) bool {
+	id, state := rAPI.idempotency.Reserve(storeKey, idempotencyFingerprint(*recipe))
+	switch state {
+	case IdempotencyDone:
+		core.LoggerFrom(c).WithField("recipe", id).Debug("Replay creation of recipe")
+		c.Header("Location", rAPI.recipeLocation(id))
+		c.Header(idempotentReplayedHeader, "true")
+		c.Status(http.StatusCreated)
+		return false
+	case IdempotencyInProgress:
+		core.AbortWithAPIError(c, http.StatusConflict, "Request with this Idempotency-Key is in progress", key)
+		return false
+	case IdempotencyMismatch:
+		core.AbortWithAPIError(c, http.StatusUnprocessableEntity, "Idempotency-Key was used for a different recipe", key)
+		return false
+	default:
+		return true
+	}
+}
+
+// validateRecipe example
+// @Summary Validate a Recipe
+// @Description Validates a recipe and normalizes its units like it is done before a recipe is saved, but does not save it, e.g., to preview an imported recipe.
+// @Description Invalid recipes are answered with 200 as well; the result lists the errors which prevent the recipe from being saved and warnings about fields which are likely not intended.
+// @Tags Recipes
+// @Param message body Recipe true "Recipe"
+// @Accept json
+// @Produce json
+// @Success 200 {object} RecipeValidation
+// @Failure 400 {object} core.APIError
+// @Failure 413 {object} core.APIError
+// @Router /recipes/validate [post]
+func (rAPI *API) validateRecipe(c *core.APICallContext) {
+	var recipe Recipe
+	if err := c.ShouldBindJSON(&recipe); err != nil {
+		core.AbortWithBodyError(c, "Could not read JSON input", err)
+		return
+	}
+	untranslateUnits(c, recipe.Ingredients)
+
+	core.RespondJSON(c, http.StatusOK, ValidateRecipe(&recipe))
+}
+
+// exportRecipesCSV example
+// @Summary Export all Recipes as CSV
+// @Description All recipes which are not archived are exported as CSV with one row per ingredient, e.g., to edit them in a spreadsheet.
+// @Description The columns are name, servings, prepMinutes, cookMinutes, difficulty, tags (separated by ';'), description, steps (separated by line breaks), ingredient, amount (empty if the ingredient has no amount), amountMax, unit, and group.
+// @Description All columns but the ingredient's are repeated in each row of a recipe; a recipe without ingredients has one row with empty ingredient columns.
+// @Tags Recipes
+// @Produce text/csv
+// @Success 200 {string} string
+// @Failure 500 {object} core.APIError
+// @Router /recipes/export.csv [get]
+func (rAPI *API) exportRecipesCSV(c *core.APICallContext) {
+	recipes := make([]*Recipe, 0)
+	for _, recipe := range rAPI.reader(c).List() {
+		if !recipe.Archived {
+			recipes = append(recipes, recipe)
+		}
+	}
+
+	var buffer bytes.Buffer
+	if err := WriteRecipesCSV(&buffer, recipes); err != nil {
+		core.LoggerFrom(c).WithError(err).Error("Could not export recipes")
+		core.AbortWithAPIError(c, http.StatusInternalServerError, "Could not export recipes", "")
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="recipes.csv"`)
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", buffer.Bytes())
+}
+
+// importRecipesCSV example
+// @Summary Import Recipes from CSV
+// @Description Adds the recipes of a CSV file in the layout of /recipes/export.csv. Rows are grouped into recipes by their name; the other columns of a recipe are taken from its first row.
+// @Description Columns may be in any order and only name and ingredient are mandatory. Amounts may use a decimal comma.
+// @Description Recipes with invalid rows are not imported, the result lists the rejected rows, starting with 1 for the header. The other recipes are imported with new ids.
+// @Tags Recipes
+// @Accept text/csv
+// @Produce json
+// @Success 200 {object} CSVImportResult
+// @Failure 400 {object} core.APIError
+// @Failure 413 {object} core.APIError
+// @Security ApiKeyAuth
+// @Router /recipes/import.csv [post]
+func (rAPI *API) importRecipesCSV(c *core.APICallContext) {
+	recipes, rowErrors, err := ReadRecipesCSV(c.Request.Body)
+	if err != nil {
+		core.AbortWithBodyError(c, "Could not read CSV input", err)
+		return
+	}
+
+	result := CSVImportResult{Imported: make([]RecipeID, 0, len(recipes)), Errors: rowErrors}
+	for _, imported := range recipes {
+		recipe := imported.Recipe
+		if err := insertWithGeneratedID(rAPI.ids, recipe, rAPI.recipes); err != nil {
+			core.LoggerFrom(c).WithError(err).WithField("recipe", recipe.Name).Error("Could not import recipe")
+			result.Errors = append(result.Errors, CSVRowError{Row: imported.Row, Recipe: recipe.Name, Message: "could not persist recipe"})
+			continue
+		}
+		result.Imported = append(result.Imported, recipe.ID)
+	}
+	core.RespondJSON(c, http.StatusOK, result)
+}
+
+// postRating example
+// @Summary Rate a Recipe
+// @Description Adds a rating between 1 and 5 to a recipe and returns the recipe's new average rating
+// @Tags Recipes
+// @Param recipe path string true "Recipe ID"
+// @Param message body RatingInput true "Rating"
+// @Accept json
+// @Produce json
+// @Success 200 {object} RatingResult
+// @Failure 400 {object} core.APIError
+// @Failure 404 {object} core.APIError
+// @Router /recipes/r/{recipe}/rating [post]
+func (rAPI *API) postRating(c *core.APICallContext) {
+	recipeIDS := c.Param(RECIPE)
+	recipeID, ok := recipeIDParam(c)
+	if !ok {
+		return
+	}
+
+	var rating RatingInput
+	if err := c.ShouldBindJSON(&rating); err != nil {
+		core.AbortWithAPIError(c, http.StatusBadRequest, "Could not read JSON input", err.Error())
+		return
+	}
+	if rating.Rating < MinRating || rating.Rating > MaxRating {
+		core.AbortWithAPIError(c, http.StatusBadRequest, "Invalid rating", fmt.Sprintf("rating must be between %v and %v", MinRating, MaxRating))
+		return
+	}
+
+	average, err := rAPI.recipes.AddRating(recipeID, rating.Rating)
+	if err == ErrRecipeNotFound {
+		core.AbortWithAPIError(c, http.StatusNotFound, "No such recipe", recipeIDS)
+	} else if err != nil {
+		core.AbortWithAPIError(c, http.StatusInternalServerError, "Could not persist rating", "")
+	} else {
+		core.RespondJSON(c, http.StatusOK, RatingResult{Rating: average})
+	}
+}
+
+// getNotes example
+// @Summary Get the Notes on a Recipe
+// @Description The notes on a specific recipe are returned, the newest note first
+// @Tags Recipes
+// @Param recipe path string true "Recipe ID"
+// @Produce json
+// @Success 200 {array} Note
+// @Failure 400 {object} core.APIError
+// @Failure 404 {object} core.APIError
+// @Router /recipes/r/{recipe}/notes [get]
+func (rAPI *API) getNotes(c *core.APICallContext) {
+	recipeID, ok := recipeIDParam(c)
+	if !ok {
+		return
+	}
+
+	if rAPI.reader(c).Get(recipeID).ID == InvalidRecipeID() {
+		core.AbortWithAPIError(c, http.StatusNotFound, "No such recipe", c.Param(RECIPE))
+		return
+	}
+
+	core.RespondJSON(c, http.StatusOK, rAPI.reader(c).Notes(recipeID))
+}
+
+// postNote example
+// @Summary Add a Note to a Recipe
+// @Description Adds a personal note to a specific recipe. Notes are kept when the recipe is updated and removed with the recipe.
+// @Tags Recipes
+// @Param recipe path string true "Recipe ID"
+// @Param message body NoteInput true "Note"
+// @Accept json
+// @Produce json
+// @Success 201 {object} Note
+// @Failure 400 {object} core.APIError
+// @Failure 404 {object} core.APIError
+// @Failure 413 {object} core.APIError
+// @Security ApiKeyAuth
+// @Router /recipes/r/{recipe}/notes [post]
+func (rAPI *API) postNote(c *core.APICallContext) {
+	recipeID, ok := recipeIDParam(c)
+	if !ok {
+		return
+	}
+
+	var input NoteInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		core.AbortWithBodyError(c, "Could not read JSON input", err)
+		return
+	}
+	note := Note{ID: NewNoteID(), RecipeID: recipeID, Text: input.Text}
+	if err := note.Validate(); err != nil {
+		core.AbortWithAPIError(c, http.StatusBadRequest, "Invalid note", err.Error())
+		return
+	}
+
+	err := rAPI.recipes.AddNote(&note)
+	if err == ErrRecipeNotFound {
+		core.AbortWithAPIError(c, http.StatusNotFound, "No such recipe", c.Param(RECIPE))
+	} else if err != nil {
+		core.AbortWithAPIError(c, http.StatusInternalServerError, "Could not persist note", "")
+	} else {
+		core.RespondJSON(c, http.StatusCreated, note)
+	}
+}
+
+//recipeLocation returns the path under which a recipe can be retrieved
+func (rAPI *API) recipeLocation(id RecipeID) string {
+	return fmt.Sprintf("%v/recipes/r/%v", rAPI.handler.API(1).Path(), id)
+}
+
+//pictureLocation returns the path under which a recipe's picture can be retrieved
+func (rAPI *API) pictureLocation(id RecipeID, name string) string {
+	return fmt.Sprintf("%v/pictures/%v", rAPI.recipeLocation(id), url.PathEscape(name))
+}
+
+// deleteRecipe example
+// @Summary Delete a Recipe
+// @Description Archives a recipe by id, i.e., the recipe is no longer listed but can be restored. With hard=true the recipe is removed permanently.
+// @Tags Recipes
+// @Param recipe path string true "Recipe ID"
+// @Param hard query bool false "Remove the recipe permanently instead of archiving it"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Failure 400 {object} core.APIError
+// @Failure 404 {object} core.APIError
+// @Security ApiKeyAuth
+// @Router /recipes/r/{recipe} [delete]
+func (rAPI *API) deleteRecipe(c *core.APICallContext) {
+	recipeIDS := c.Param(RECIPE)
+	recipeID, ok := recipeIDParam(c)
+	if !ok {
+		return
+	}
+	hard, _ := strconv.ParseBool(c.Query(HARD))
+
+	var err error
+	if hard {
+		err = rAPI.recipes.Remove(recipeID)
+	} else {
+		err = rAPI.recipes.Archive(recipeID)
+	}
+	if err != nil {
+		core.AbortWithAPIError(c, http.StatusNotFound, "No such recipe", recipeIDS)
+		core.LoggerFrom(c).WithError(err).Debug("Could not Delete Recipe")
+	} else {
+		c.Status(http.StatusOK)
+	}
+}
+
+// restoreRecipe example
+// @Summary Restore a Recipe
+// @Description Restores an archived recipe by id, i.e., the recipe is listed again
+// @Tags Recipes
+// @Param recipe path string true "Recipe ID"
+// @Produce json
+// @Success 200 {object} Recipe
+// @Failure 400 {object} core.APIError
+// @Failure 404 {object} core.APIError
+// @Failure 500 {object} core.APIError
+// @Security ApiKeyAuth
+// @Router /recipes/r/{recipe}/restore [post]
+func (rAPI *API) restoreRecipe(c *core.APICallContext) {
+	recipeID, ok := recipeIDParam(c)
+	if !ok {
+		return
+	}
+	if err := rAPI.recipes.Restore(recipeID); errors.Is(err, ErrRecipeNotFound) {
+		core.AbortWithAPIError(c, http.StatusNotFound, "No such recipe", recipeID.String())
+		return
+	} else if err != nil {
+		core.LoggerFrom(c).WithError(err).Error("Could not restore recipe")
+		core.AbortWithAPIError(c, http.StatusInternalServerError, "Could not restore recipe", "")
+		return
+	}
+	core.Respond(c, http.StatusOK, rAPI.reader(c).Get(recipeID))
+}
+
+//recipeIDParam parses the recipe id of the url; it responds with 400 and returns false if the id is malformed
+func recipeIDParam(c *core.APICallContext) (RecipeID, bool) {
+	recipeID, err := NewRecipeIDFromString(c.Param(RECIPE))
+	if err != nil {
+		core.AbortWithAPIError(c, http.StatusBadRequest, "Invalid recipe id", err.Error())
+		return recipeID, false
+	}
+	return recipeID, true
+}
+
+//recipeETag identifies the returned document; it includes the recipe's version and changes with scaling or unit conversion
+func recipeETag(recipe *Recipe) string {
+	hash := sha256.Sum256(recipe.JSON())
+	return fmt.Sprintf(`"%v-%x"`, recipe.Version, hash[:8])
+}
+
+//matchesETag checks if the If-None-Match header contains the etag; weak etags match as well
+func matchesETag(ifNoneMatch string, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+//notModifiedSince checks if nothing was modified after the time in the If-Modified-Since header; HTTP dates have a precision of seconds
+func notModifiedSince(ifModifiedSince string, lastModified time.Time) bool {
+	if ifModifiedSince == "" {
+		return false
+	}
+	since, err := http.ParseTime(ifModifiedSince)
+	if err != nil {
+		return false
+	}
+	return !lastModified.Truncate(time.Second).After(since)
+}
+
+//abortWithValidationError responds with the invalid fields of a recipe
+func abortWithValidationError(c *core.APICallContext, err error) {
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		core.AbortWithFieldErrors(c, http.StatusBadRequest, "Invalid recipe", validationErr.Fields)
+	} else {
+		core.AbortWithAPIError(c, http.StatusBadRequest, "Invalid recipe", err.Error())
+	}
+}
+
+//extractServings returns the requested servings or, if no servings are requested, the configured default servings.
+//A result of 0 means that the recipe's own servings are kept.
+func extractServings(query url.Values) (int8, error) {
+	if len(query[SERVINGS]) == 0 {
+		return defaultServings, nil
+	}
+	servings, err := strconv.ParseInt(query[SERVINGS][0], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%v must be a number", SERVINGS)
+	}
+	if err = validateServings(servings); err != nil {
+		return 0, err
+	}
+	return int8(servings), nil
+}
+
+//extractUnitSystem returns the requested system of units; the result is empty if no units are requested, i.e., amounts are not converted
+func extractUnitSystem(query url.Values) (units.System, error) {
+	if unitsParam := query.Get(UNITS); unitsParam != "" {
+		return units.ParseSystem(unitsParam)
+	}
+	return "", nil
+}
+
+//validateServings checks that servings are positive and do not exceed maxServings
+func validateServings(servings int64) error {
+	if servings < 1 {
+		return fmt.Errorf("%v must be positive", SERVINGS)
+	}
+	if servings > int64(maxServings) {
+		return fmt.Errorf("%v must not exceed %v", SERVINGS, maxServings)
+	}
+	return nil
+}
+
+//extractPaging returns the requested offset and limit; the limit is capped at maxLimit
+func extractPaging(query url.Values) (offset int64, limit int64, err error) {
+	offset, err = extractNonNegativeInt(query, OFFSET, 0)
+	if err != nil {
+		return
+	}
+	limit, err = extractNonNegativeInt(query, LIMIT, defaultLimit)
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+	return
+}
+
+func extractNonNegativeInt(query url.Values, param string, defaultValue int64) (int64, error) {
+	if len(query[param]) == 0 {
+		return defaultValue, nil
+	}
+	num, err := strconv.ParseInt(query[param][0], 10, 64)
+	if err != nil {
+		return defaultValue, err
+	}
+	if num < 0 {
+		return defaultValue, fmt.Errorf("%v must not be negative", param)
 	}
-	return int8(servings)
+	return num, nil
 }
 
 func extractSearchString(query url.Values, param string) string {
@@ -331,5 +2134,8 @@ func extractSearchFilter(query url.Values) *RecipeSearchFilter {
 		Ingredient:  extractIngredientSearchArray(query),
 		Name:        extractSearchString(query, NAME),
 		Description: extractSearchString(query, DESCRIPTION),
+		Tag:         extractSearchString(query, TAG),
+		Sort:        extractSearchString(query, SORT),
+		Order:       extractSearchString(query, ORDER),
 	}
 }
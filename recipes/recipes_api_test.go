@@ -25,14 +25,25 @@
 package recipes
 
 import (
+	"bufio"
 	"bytes"
+	"encoding/binary"
 	"encoding/json"
 	"fmt"
+	"hash/crc32"
+	"image"
+	"image/png"
 	"io/ioutil"
+	"mime/multipart"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/ottenwbe/recipes-manager/core"
+	"github.com/ottenwbe/recipes-manager/units"
+	"github.com/ottenwbe/recipes-manager/utils"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -43,12 +54,14 @@ var _ = Describe("recipesAPI", func() {
 	var (
 		server  core.Server
 		recipes RecipeDB
+		rAPI    *API
 	)
 
 	BeforeSuite(func() {
 		handler := core.NewHandler()
-		recipes, _ = NewDatabaseClient()
-		AddRecipesAPIToHandler(handler, recipes)
+		events := NewRecipeEventsFromConfig()
+		recipes, _ = NewDatabaseClient(events)
+		rAPI = AddRecipesAPIToHandler(handler, recipes, events)
 		server = core.NewServerA(":8080", handler)
 		server.Run()
 		time.Sleep(500 * time.Millisecond)
@@ -71,9 +84,99 @@ var _ = Describe("recipesAPI", func() {
 			Expect(err).ToNot(HaveOccurred())
 			Expect(resp.StatusCode).To(Equal(200))
 		})
+
+		It("should not share state between instances", func() {
+			firstEvents, secondEvents := NewRecipeEvents(10), NewRecipeEvents(10)
+			first, second := NewEventDB(NewInMemoryDB(), firstEvents), NewEventDB(NewInMemoryDB(), secondEvents)
+			defer first.Close()
+			defer second.Close()
+			id := createAndPersistDefaultRecipe(first)
+
+			firstHandler, secondHandler := core.NewHandler(), core.NewHandler()
+			firstAPI := AddRecipesAPIToHandler(firstHandler, first, firstEvents)
+			secondAPI := AddRecipesAPIToHandler(secondHandler, second, secondEvents)
+			Expect(firstAPI).ToNot(BeIdenticalTo(secondAPI))
+			Expect(firstAPI.recipes).To(BeIdenticalTo(first))
+			Expect(firstAPI.events).To(BeIdenticalTo(firstEvents))
+			Expect(secondAPI.events).To(BeIdenticalTo(secondEvents))
+			Expect(firstEvents.since(0)).To(HaveLen(1))
+			Expect(firstEvents.since(0)[0].ID).To(Equal(id))
+			Expect(secondEvents.since(0)).To(BeEmpty())
+
+			num := func(handler core.Handler) string {
+				w := httptest.NewRecorder()
+				handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/recipes/num", nil))
+				return w.Body.String()
+			}
+			Expect(num(firstHandler)).To(Equal("1"))
+			Expect(num(secondHandler)).To(Equal("0"))
+		})
 	})
 
 	Context("List Recipes", func() {
+		It("should answer conditional requests with the time of the last modification", func() {
+			id := createAndPersistDefaultRecipe(recipes)
+			defer recipes.Remove(id)
+
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			lastModified := resp.Header.Get("Last-Modified")
+			Expect(http.ParseTime(lastModified)).To(Equal(recipes.LastModified().Truncate(time.Second)))
+
+			request, _ := http.NewRequest(http.MethodGet, "http://localhost:8080/api/v1/recipes", nil)
+			request.Header.Set("If-Modified-Since", lastModified)
+			resp, err = http.DefaultClient.Do(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusNotModified))
+
+			request.Header.Set("If-Modified-Since", recipes.LastModified().Add(-time.Hour).Format(http.TimeFormat))
+			resp, err = http.DefaultClient.Do(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		})
+
+		It("should return summaries of the recipes when expanded", func() {
+			createRandomRecipes(5, recipes)
+			expectedID := createAndPersistDefaultRecipe(recipes)
+			defer recipes.Remove(expectedID)
+			_, err := uploadPicture(expectedID, "primary.png", pngHeader)
+			Expect(err).ToNot(HaveOccurred())
+
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes?name=retrieve&expand=true")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(resp.Header.Get("X-Total-Count")).To(Equal("1"))
+
+			var summaries RecipeSummaries
+			Expect(json.NewDecoder(resp.Body).Decode(&summaries)).To(Succeed())
+			Expect(summaries.Recipes).To(HaveLen(1))
+			Expect(summaries.Recipes[0].ID).To(Equal(expectedID))
+			Expect(summaries.Recipes[0].Name).To(Equal("retrieve recipe"))
+			Expect(summaries.Recipes[0].Picture).To(Equal("primary.png"))
+			Expect(summaries.Recipes[0].Tags).ToNot(BeNil())
+		})
+
+		It("should return ids unless expanded", func() {
+			expectedID := createAndPersistDefaultRecipe(recipes)
+			defer recipes.Remove(expectedID)
+
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes?name=retrieve&expand=false")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			var recipeIDs RecipeList
+			Expect(json.NewDecoder(resp.Body).Decode(&recipeIDs)).To(Succeed())
+			Expect(recipeIDs.Recipes).To(Equal([]string{expectedID.String()}))
+		})
+
+		It("should reject an invalid expand parameter", func() {
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes?expand=maybe")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+		})
+
 		It("should be able to filter by name", func() {
 
 			createRandomRecipes(5, recipes)
@@ -93,6 +196,44 @@ var _ = Describe("recipesAPI", func() {
 			Expect(expectedID.String()).To(Equal(recipeIDs.Recipes[0]))
 		})
 
+		It("should filter by name case-insensitively", func() {
+
+			createRandomRecipes(5, recipes)
+			expectedID := createAndPersistDefaultRecipe(recipes)
+			defer func() { //delete recently created recipe
+				recipes.Remove(expectedID)
+			}()
+
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes?name=RETRIEVE")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(200))
+
+			var recipeIDs RecipeList
+			err = json.NewDecoder(resp.Body).Decode(&recipeIDs)
+			Expect(len(recipeIDs.Recipes)).To(Equal(1))
+			Expect(expectedID.String()).To(Equal(recipeIDs.Recipes[0]))
+		})
+
+		It("should be able to filter by ingredient", func() {
+
+			createRandomRecipes(5, recipes)
+			expectedID := createAndPersistNewRecipe("search", "none", Ingredients{Name: "Tomato"}, recipes)
+			defer func() { //delete recently created recipe
+				recipes.Remove(expectedID)
+			}()
+
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes?ingredient=tomato")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(200))
+
+			var recipeIDs RecipeList
+			err = json.NewDecoder(resp.Body).Decode(&recipeIDs)
+			Expect(len(recipeIDs.Recipes)).To(Equal(1))
+			Expect(expectedID.String()).To(Equal(recipeIDs.Recipes[0]))
+		})
+
 		It("should be able to filter by description", func() {
 
 			createRandomRecipes(5, recipes)
@@ -136,205 +277,1909 @@ var _ = Describe("recipesAPI", func() {
 			Expect(recipeIDs.Recipes).To(ContainElement(expectedID2.String()))
 		})
 
-		It("should not return elements that do not match the search query", func() {
-
-			createRandomRecipes(5, recipes) //add noise
-			expectedID := createAndPersistNewRecipe("search", "test", Ingredients{Name: "hi"}, recipes)
-			defer func() { //delete recently created recipe
-				recipes.Remove(expectedID)
-			}()
+		It("should limit the number of returned ids and report the total count", func() {
+			recipes.Clear()
+			createRandomRecipes(10, recipes)
 
-			resp, err := http.Get("http://localhost:8080/api/v1/recipes?description=find")
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes?limit=3&offset=8")
 
 			Expect(err).ToNot(HaveOccurred())
 			Expect(resp.StatusCode).To(Equal(200))
+			Expect(resp.Header.Get("X-Total-Count")).To(Equal("10"))
 
-			var recipeIDs []string
+			var recipeIDs RecipeList
 			err = json.NewDecoder(resp.Body).Decode(&recipeIDs)
-			Expect(len(recipeIDs)).To(Equal(0))
+			Expect(recipeIDs.Recipes).To(HaveLen(2))
 		})
-	})
 
-	Context("Get Recipes", func() {
-		It("can retrieve an recipe by id", func() {
-			expectedRecipe, _ := createRandomRecipes(1, recipes) //recipes
+		It("should reject negative paging parameters", func() {
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes?limit=-1")
 
-			resp, err := http.Get("http://localhost:8080/api/v1/recipes/r/" + expectedRecipe[0].ID.String())
 			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+		})
+
+		It("should sort recipes by their calories", func() {
+			recipes.Clear()
+			calories := []float64{800, 250, 500}
+			ids := make([]RecipeID, len(calories))
+			for i := range calories {
+				recipe := NewRecipe(NewRecipeID())
+				recipe.Nutrition = &Nutrition{Calories: &calories[i]}
+				Expect(recipes.Insert(recipe)).To(Succeed())
+				ids[i] = recipe.ID
+			}
+
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes?sort=calories")
 
+			Expect(err).ToNot(HaveOccurred())
 			Expect(resp.StatusCode).To(Equal(200))
 
-			var recipe Recipe
-			err = json.NewDecoder(resp.Body).Decode(&recipe)
-			Expect(recipe).To(Equal(*expectedRecipe[0]))
+			var recipeIDs RecipeList
+			err = json.NewDecoder(resp.Body).Decode(&recipeIDs)
+			Expect(recipeIDs.Recipes).To(Equal([]string{ids[1].String(), ids[2].String(), ids[0].String()}))
 		})
 
-		It("can retrieve an recipe by id and scale the recipe", func() {
-			id := createAndPersistDefaultRecipe(recipes)
+		It("should sort recipes by their name by default", func() {
+			recipes.Clear()
+			stewID := createAndPersistNewRecipe("stew", "", Ingredients{Name: "beans"}, recipes)
+			appleID := createAndPersistNewRecipe("Apple pie", "", Ingredients{Name: "apple"}, recipes)
+			soupID := createAndPersistNewRecipe("soup", "", Ingredients{Name: "water"}, recipes)
 
-			resp, err := http.Get(fmt.Sprintf("http://localhost:8080/api/v1/recipes/r/%v?servings=2", id.String()))
-			Expect(err).ToNot(HaveOccurred())
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes")
 
+			Expect(err).ToNot(HaveOccurred())
 			Expect(resp.StatusCode).To(Equal(200))
 
-			var recipe Recipe
-			err = json.NewDecoder(resp.Body).Decode(&recipe)
-			Expect(len(recipe.Ingredients)).ToNot(Equal(0))
-			Expect(recipe.Ingredients[0].Amount).To(Equal(200.0))
+			var recipeIDs RecipeList
+			err = json.NewDecoder(resp.Body).Decode(&recipeIDs)
+			Expect(recipeIDs.Recipes).To(Equal([]string{appleID.String(), soupID.String(), stewID.String()}))
 		})
-	})
 
-	Context("Randomly getting recipes", func() {
-		It("returns a 404 when no recipe exists ", func() {
+		It("should sort recipes by their creation in descending order", func() {
 			recipes.Clear()
+			firstID := createAndPersistNewRecipe("b", "", Ingredients{Name: "water"}, recipes)
+			secondID := createAndPersistNewRecipe("a", "", Ingredients{Name: "water"}, recipes)
+
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes?sort=created&order=desc")
 
-			resp, err := http.Get("http://localhost:8080/api/v1/recipes/rand")
 			Expect(err).ToNot(HaveOccurred())
-			Expect(resp.StatusCode).To(Equal(404))
+			Expect(resp.StatusCode).To(Equal(200))
+
+			var recipeIDs RecipeList
+			err = json.NewDecoder(resp.Body).Decode(&recipeIDs)
+			Expect(recipeIDs.Recipes).To(Equal([]string{secondID.String(), firstID.String()}))
 		})
 
-		It("returns a valid random recipe", func() {
+		It("should only return recipes changed after a given time", func() {
 			recipes.Clear()
-			_, expectedRecipesIDs := createRandomRecipes(10, recipes) //recipes
+			createAndPersistNewRecipe("soup", "", Ingredients{Name: "water"}, recipes)
+			time.Sleep(2 * time.Millisecond)
+			since := time.Now().UTC()
+			time.Sleep(2 * time.Millisecond)
+			stewID := createAndPersistNewRecipe("stew", "", Ingredients{Name: "beans"}, recipes)
+
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes?since=" + url.QueryEscape(since.Format(time.RFC3339Nano)))
 
-			resp, err := http.Get("http://localhost:8080/api/v1/recipes/rand")
 			Expect(err).ToNot(HaveOccurred())
 			Expect(resp.StatusCode).To(Equal(200))
 
-			var recipe Recipe
-			err = json.NewDecoder(resp.Body).Decode(&recipe)
-
-			Expect(err).ToNot(HaveOccurred())
-			Expect(recipe.ID).To(BeElementOf(expectedRecipesIDs))
+			var recipeIDs RecipeList
+			err = json.NewDecoder(resp.Body).Decode(&recipeIDs)
+			Expect(recipeIDs.Recipes).To(Equal([]string{stewID.String()}))
 		})
 
-		It("can retrieve a random recipe and scale the recipe", func() {
+		It("should only return recipes which can be made in the given time", func() {
 			recipes.Clear()
+			quick := NewRecipe(NewRecipeID())
+			quick.Name, quick.PrepMinutes, quick.CookMinutes = "salad", 15, 0
+			slow := NewRecipe(NewRecipeID())
+			slow.Name, slow.PrepMinutes, slow.CookMinutes = "stew", 20, 120
+			createAndPersistNewRecipe("soup", "", Ingredients{Name: "water"}, recipes)
+			Expect(recipes.Insert(quick)).To(Succeed())
+			Expect(recipes.Insert(slow)).To(Succeed())
 
-			_ = createAndPersistDefaultRecipe(recipes)
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes?maxTotalTime=30")
 
-			resp, err := http.Get("http://localhost:8080/api/v1/recipes/rand?servings=2")
 			Expect(err).ToNot(HaveOccurred())
-
 			Expect(resp.StatusCode).To(Equal(200))
 
-			var recipe Recipe
-			err = json.NewDecoder(resp.Body).Decode(&recipe)
-			Expect(len(recipe.Ingredients)).ToNot(Equal(0))
-			Expect(recipe.Ingredients[0].Amount).To(Equal(200.0))
+			var recipeIDs RecipeList
+			err = json.NewDecoder(resp.Body).Decode(&recipeIDs)
+			Expect(recipeIDs.Recipes).To(Equal([]string{quick.ID.String()}))
 		})
-	})
 
-	Context("Counting Recipes", func() {
-		It("returns 0 when no recipes are persisted", func() {
-			recipes.Clear()
+		It("should reject invalid maximal total times", func() {
+			for _, maxTotalTime := range []string{"0", "-5", "quick"} {
+				resp, err := http.Get("http://localhost:8080/api/v1/recipes?maxTotalTime=" + maxTotalTime)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+			}
+		})
 
-			_, _ = createRandomRecipes(10, recipes)
+		It("should reject invalid times", func() {
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes?since=yesterday")
 
-			resp, err := http.Get("http://localhost:8080/api/v1/recipes/num")
 			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+		})
 
-			result, err := ioutil.ReadAll(resp.Body)
-			Expect(err).ToNot(HaveOccurred())
+		It("should reject unknown sort fields", func() {
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes?sort=color")
 
-			Expect(resp.StatusCode).To(Equal(200))
-			Expect(string(result)).To(Equal("10"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
 		})
 
-		It("returns the amount of persisted recipes", func() {
-			recipes.Clear()
+		It("should reject unknown sort orders", func() {
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes?sort=name&order=up")
 
-			resp, err := http.Get("http://localhost:8080/api/v1/recipes/num")
 			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+		})
 
-			result, err := ioutil.ReadAll(resp.Body)
-			Expect(err).ToNot(HaveOccurred())
+		It("should not return elements that do not match the search query", func() {
+
+			createRandomRecipes(5, recipes) //add noise
+			expectedID := createAndPersistNewRecipe("search", "test", Ingredients{Name: "hi"}, recipes)
+			defer func() { //delete recently created recipe
+				recipes.Remove(expectedID)
+			}()
 
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes?description=find")
+
+			Expect(err).ToNot(HaveOccurred())
 			Expect(resp.StatusCode).To(Equal(200))
-			Expect(string(result)).To(Equal("0"))
+
+			var recipeIDs []string
+			err = json.NewDecoder(resp.Body).Decode(&recipeIDs)
+			Expect(len(recipeIDs)).To(Equal(0))
 		})
 	})
 
-	Context("Posting Recipes", func() {
-		It("is not possible with malformed documents", func() {
-			recipes.Clear()
+	Context("Ingredient Suggestions", func() {
+		It("suggests the names of ingredients starting with the query", func() {
+			id := createAndPersistDefaultRecipe(recipes)
+			defer recipes.Remove(id)
 
-			resp, err := http.Post("http://localhost:8080/api/v1/recipes", "application/json", bytes.NewBuffer(nil))
+			resp, err := http.Get("http://localhost:8080/api/v1/ingredients/suggest?q=TE")
 			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
 
-			Expect(resp.StatusCode).To(Equal(400))
+			var suggestions []string
+			Expect(json.NewDecoder(resp.Body).Decode(&suggestions)).To(Succeed())
+			Expect(suggestions).To(ContainElement("test"))
+			Expect(len(suggestions)).To(BeNumerically("<=", suggestLimit))
 		})
 
-		It("persists a new recipe", func() {
-			recipes.Clear()
+		It("requires a query", func() {
+			resp, err := http.Get("http://localhost:8080/api/v1/ingredients/suggest?q=+")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+		})
+	})
 
-			const POSTTEST = "PostTest"
-			const POSTDESCRIPTION = "Test \n 123"
+	Context("Tags", func() {
+		var id RecipeID
 
-			recipe := Recipe{Servings: 2, Name: POSTTEST, Description: POSTDESCRIPTION}
-			recipeJSON, _ := json.Marshal(recipe)
+		BeforeEach(func() {
+			recipe := NewRecipe(NewRecipeID())
+			recipe.Name = "tagged"
+			recipe.Tags = []string{"Tagging", "tagged"}
+			Expect(recipes.Insert(recipe)).To(Succeed())
+			id = recipe.ID
+		})
 
-			resp, err := http.Post("http://localhost:8080/api/v1/recipes", "application/json", bytes.NewBuffer(recipeJSON))
+		AfterEach(func() {
+			recipes.Remove(id)
+		})
+
+		It("lists all tags with their counts", func() {
+			resp, err := http.Get("http://localhost:8080/api/v1/tags")
 			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
 
-			retrievedRecipe, err := recipes.GetByName(POSTTEST)
+			var tags []TagCount
+			Expect(json.NewDecoder(resp.Body).Decode(&tags)).To(Succeed())
+			Expect(tags).To(ContainElement(TagCount{Tag: "tagging", Count: 1}))
+		})
+
+		It("suggests tags starting with the query", func() {
+			resp, err := http.Get("http://localhost:8080/api/v1/tags/suggest?q=TAGG")
 			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
 
-			Expect(resp.StatusCode).To(Equal(201))
-			Expect(retrievedRecipe.Servings).To(Equal(recipe.Servings))
-			Expect(retrievedRecipe.Description).To(Equal(recipe.Description))
+			var tags []TagCount
+			Expect(json.NewDecoder(resp.Body).Decode(&tags)).To(Succeed())
+			Expect(tags).To(ConsistOf(TagCount{Tag: "tagged", Count: 1}, TagCount{Tag: "tagging", Count: 1}))
+		})
+
+		It("requires a query for suggestions", func() {
+			resp, err := http.Get("http://localhost:8080/api/v1/tags/suggest")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
 		})
 	})
 
-	Context("DELETE Recipes", func() {
+	Context("Statistics", func() {
+		It("returns the aggregated numbers of all recipes", func() {
+			recipe := NewRecipe(NewRecipeID())
+			recipe.Name = "stats"
+			recipe.Servings = 2
+			recipe.Tags = []string{"statistics"}
+			Expect(recipes.Insert(recipe)).To(Succeed())
+			defer recipes.Remove(recipe.ID)
 
-		It("removes a persisted recipe", func() {
-			id := createAndPersistDefaultRecipe(recipes)
-			client := &http.Client{}
-			request, err := http.NewRequest(http.MethodDelete, "http://localhost:8080/api/v1/recipes/r/"+id.String(), bytes.NewBuffer(nil))
-			response, err := client.Do(request)
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes/stats")
 			Expect(err).ToNot(HaveOccurred())
-			Expect(response.StatusCode).To(Equal(http.StatusOK))
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			var stats RecipeStats
+			Expect(json.NewDecoder(resp.Body).Decode(&stats)).To(Succeed())
+			Expect(stats.Total).To(Equal(recipes.Num()))
+			Expect(stats.Tags).To(ContainElement(TagCount{Tag: "statistics", Count: 1}))
 		})
 	})
 
-	Context("PUT Recipes", func() {
+	Context("Get Recipes", func() {
+		It("can retrieve an recipe by id", func() {
+			expectedRecipe, _ := createRandomRecipes(1, recipes) //recipes
 
-		It("persists a change to a recipe", func() {
-			recipes.Clear()
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes/r/" + expectedRecipe[0].ID.String())
+			Expect(err).ToNot(HaveOccurred())
 
-			const POSTTEST = "PostTest"
-			const POSTDESCRIPTION = "Test \n 123"
+			Expect(resp.StatusCode).To(Equal(200))
 
-			recipe := Recipe{Servings: 2, Name: POSTTEST, Description: POSTDESCRIPTION}
-			recipeJSON, _ := json.Marshal(recipe)
+			var recipe Recipe
+			err = json.NewDecoder(resp.Body).Decode(&recipe)
+			Expect(recipe).To(Equal(*expectedRecipe[0]))
+			Expect(resp.Header.Get("ETag")).To(HavePrefix(`"0-`))
+		})
 
-			_, err := http.Post("http://localhost:8080/api/v1/recipes", "application/json", bytes.NewBuffer(recipeJSON))
+		It("can retrieve a recipe as YAML", func() {
+			id := createAndPersistDefaultRecipe(recipes)
+
+			request, _ := http.NewRequest(http.MethodGet, "http://localhost:8080/api/v1/recipes/r/"+id.String(), nil)
+			request.Header.Set("Accept", core.MIMEYAML)
+			resp, err := http.DefaultClient.Do(request)
 			Expect(err).ToNot(HaveOccurred())
 
-			// update recipe
-			recipe.Servings = 3
-			recipe.Description = "updated"
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(resp.Header.Get("Content-Type")).To(HavePrefix(core.MIMEYAML))
+			body, _ := ioutil.ReadAll(resp.Body)
+			Expect(string(body)).To(ContainSubstring("id: " + id.String()))
+		})
 
-			// post updated recipe
-			client := &http.Client{}
-			recipeJSON, _ = json.Marshal(recipe)
-			retrievedRecipe, _ := recipes.GetByName(POSTTEST)
-			request, err := http.NewRequest(http.MethodPut, "http://localhost:8080/api/v1/recipes/r/"+retrievedRecipe.ID.String(), bytes.NewBuffer(recipeJSON))
-			request.Header.Set("Content-Type", "application/json")
-			resp, err := client.Do(request)
+		It("returns 304 when the client's ETag matches", func() {
+			id := createAndPersistDefaultRecipe(recipes)
+			url := "http://localhost:8080/api/v1/recipes/r/" + id.String()
+
+			resp, err := http.Get(url)
 			Expect(err).ToNot(HaveOccurred())
+			etag := resp.Header.Get("ETag")
+			Expect(etag).ToNot(BeEmpty())
 
-			retrievedRecipe, err = recipes.GetByName(POSTTEST)
+			request, _ := http.NewRequest(http.MethodGet, url, nil)
+			request.Header.Set("If-None-Match", etag)
+			resp, err = http.DefaultClient.Do(request)
 			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusNotModified))
+			body, _ := ioutil.ReadAll(resp.Body)
+			Expect(body).To(BeEmpty())
 
-			Expect(resp.StatusCode).To(Equal(204))
-			Expect(retrievedRecipe.Servings).To(Equal(recipe.Servings))
-			Expect(retrievedRecipe.Description).To(Equal(recipe.Description))
+			request, _ = http.NewRequest(http.MethodGet, url+"?servings=4", nil)
+			request.Header.Set("If-None-Match", etag)
+			resp, err = http.DefaultClient.Do(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(resp.Header.Get("ETag")).ToNot(Equal(etag))
 		})
-	})
 
-})
+		It("matches weak and listed ETags", func() {
+			Expect(matchesETag(`W/"0-ab"`, `"0-ab"`)).To(BeTrue())
+			Expect(matchesETag(`"1-cd", "0-ab"`, `"0-ab"`)).To(BeTrue())
+			Expect(matchesETag(`*`, `"0-ab"`)).To(BeTrue())
+			Expect(matchesETag(``, `"0-ab"`)).To(BeFalse())
+			Expect(matchesETag(`"1-ab"`, `"0-ab"`)).To(BeFalse())
+		})
+
+		It("returns 400 when the recipe id is malformed", func() {
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes/r/not_a_uuid")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+
+			var apiError core.APIError
+			Expect(json.NewDecoder(resp.Body).Decode(&apiError)).To(Succeed())
+			Expect(apiError.Message).To(Equal("Invalid recipe id"))
+		})
+
+		It("returns a JSON error when the recipe does not exist", func() {
+			id := NewRecipeID()
+
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes/r/" + id.String())
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+
+			var apiError core.APIError
+			err = json.NewDecoder(resp.Body).Decode(&apiError)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(apiError.Code).To(Equal(http.StatusNotFound))
+			Expect(apiError.Detail).To(Equal(id.String()))
+		})
+
+		It("can retrieve an recipe by id and scale the recipe", func() {
+			id := createAndPersistDefaultRecipe(recipes)
+
+			resp, err := http.Get(fmt.Sprintf("http://localhost:8080/api/v1/recipes/r/%v?servings=2", id.String()))
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(resp.StatusCode).To(Equal(200))
+
+			var recipe Recipe
+			err = json.NewDecoder(resp.Body).Decode(&recipe)
+			Expect(len(recipe.Ingredients)).ToNot(Equal(0))
+			Expect(recipe.Ingredients[0].Amount).To(Equal(200.0))
+		})
+
+		It("varies by the language of the client, since the units are translated", func() {
+			id := createAndPersistDefaultRecipe(recipes)
+			defer recipes.Remove(id)
+
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes/r/" + id.String())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(200))
+			Expect(resp.Header["Vary"]).To(ContainElement("Accept-Language"))
+		})
+
+		It("keeps the nutrition per serving when scaling the servings", func() {
+			calories := 420.0
+			recipe := NewRecipe(NewRecipeID())
+			recipe.Servings = 2
+			recipe.Nutrition = &Nutrition{Calories: &calories}
+			Expect(recipes.Insert(recipe)).To(Succeed())
+			defer recipes.Remove(recipe.ID)
+
+			resp, err := http.Get(fmt.Sprintf("http://localhost:8080/api/v1/recipes/r/%v?servings=4", recipe.ID.String()))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(200))
+
+			var scaled Recipe
+			Expect(json.NewDecoder(resp.Body).Decode(&scaled)).To(Succeed())
+			Expect(scaled.Servings).To(Equal(int8(4)))
+			Expect(*scaled.Nutrition.Calories).To(Equal(420.0))
+		})
+
+		It("rejects servings beyond the limit", func() {
+			id := createAndPersistDefaultRecipe(recipes)
+			defer recipes.Remove(id)
+
+			resp, err := http.Get(fmt.Sprintf("http://localhost:8080/api/v1/recipes/r/%v?servings=100000", id.String()))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+
+			var apiError core.APIError
+			Expect(json.NewDecoder(resp.Body).Decode(&apiError)).To(Succeed())
+			Expect(apiError.Detail).To(Equal(fmt.Sprintf("servings must not exceed %v", maxServings)))
+		})
+
+		It("rejects negative servings", func() {
+			id := createAndPersistDefaultRecipe(recipes)
+			defer recipes.Remove(id)
+
+			resp, err := http.Get(fmt.Sprintf("http://localhost:8080/api/v1/recipes/r/%v?servings=-2", id.String()))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+		})
+
+		It("converts a recipe to another system of units without changing the stored recipe", func() {
+			recipe := NewRecipe(NewRecipeID())
+			recipe.Name = "Pancakes"
+			recipe.Servings = 2
+			recipe.OriginalUnitSystem = string(units.Imperial)
+			recipe.Ingredients = []Ingredients{{Name: "flour", Amount: 1, Unit: "lb"}}
+			recipe.Steps = []RecipeStep{{Text: "Bake at 350°F"}}
+			Expect(recipes.Insert(recipe)).To(Succeed())
+			defer recipes.Remove(recipe.ID)
+
+			resp, err := http.Get(fmt.Sprintf("http://localhost:8080/api/v1/recipes/r/%v?units=metric", recipe.ID.String()))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			var converted Recipe
+			Expect(json.NewDecoder(resp.Body).Decode(&converted)).To(Succeed())
+			Expect(converted.Ingredients[0].Unit).To(Equal("g"))
+			Expect(converted.Steps[0].Text).To(Equal("Bake at 175°C"))
+			Expect(converted.OriginalUnitSystem).To(Equal("imperial"))
+			Expect(recipes.Get(recipe.ID).Ingredients[0].Unit).To(Equal("lb"))
+		})
+
+		It("weighs the ingredients of a recipe whose density is known", func() {
+			recipe := NewRecipe(NewRecipeID())
+			recipe.Name = "Shortbread"
+			recipe.Servings = 2
+			recipe.Ingredients = []Ingredients{
+				{Name: "all-purpose flour", Amount: 2, Unit: "cup"},
+				{Name: "butter", Amount: 4, Unit: "oz"},
+				{Name: "saffron", Amount: 1, Unit: "tsp"},
+			}
+			Expect(recipes.Insert(recipe)).To(Succeed())
+			defer recipes.Remove(recipe.ID)
+
+			resp, err := http.Get(fmt.Sprintf("http://localhost:8080/api/v1/recipes/r/%v?units=weight", recipe.ID.String()))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			var converted Recipe
+			Expect(json.NewDecoder(resp.Body).Decode(&converted)).To(Succeed())
+			Expect(converted.Ingredients).To(Equal([]Ingredients{
+				{Name: "all-purpose flour", Amount: 241.32, Unit: "g"},
+				{Name: "butter", Amount: 113.4, Unit: "g"},
+				{Name: "saffron", Amount: 1, Unit: "tsp"},
+			}))
+			Expect(recipes.Get(recipe.ID).Ingredients[0].Unit).To(Equal("cup"))
+		})
+
+		It("rejects an unknown system of units", func() {
+			id := createAndPersistDefaultRecipe(recipes)
+
+			resp, err := http.Get(fmt.Sprintf("http://localhost:8080/api/v1/recipes/r/%v?units=nautical", id.String()))
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(resp.StatusCode).To(Equal(400))
+		})
+	})
+
+	Context("Raw Recipes", func() {
+		It("returns the recipe exactly as stored", func() {
+			recipe := NewRecipe(NewRecipeID())
+			recipe.Name = "Raw Soup"
+			recipe.Servings = 3
+			recipe.Ingredients = []Ingredients{{Name: "oil", Amount: 1, Unit: "tbsp"}}
+			Expect(recipes.Insert(recipe)).To(Succeed())
+			defer recipes.Remove(recipe.ID)
+
+			request, _ := http.NewRequest(http.MethodGet, "http://localhost:8080/api/v1/recipes/r/"+recipe.ID.String()+"/raw?servings=7&units=metric", nil)
+			request.Header.Set("Accept-Language", "de")
+			resp, err := http.DefaultClient.Do(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			var raw Recipe
+			Expect(json.NewDecoder(resp.Body).Decode(&raw)).To(Succeed())
+			Expect(raw.Servings).To(Equal(int8(3)))
+			Expect(raw.Ingredients).To(Equal([]Ingredients{{Name: "oil", Amount: 1, Unit: "tbsp"}}))
+
+			request.Header.Set("If-None-Match", resp.Header.Get("ETag"))
+			resp, err = http.DefaultClient.Do(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusNotModified))
+		})
+
+		It("returns 404 when the recipe does not exist", func() {
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes/r/" + NewRecipeID().String() + "/raw")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Context("Exporting Recipes", func() {
+		It("renders a recipe in the requested format", func() {
+			id := createAndPersistDefaultRecipe(recipes)
+			defer recipes.Remove(id)
+
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes/r/" + id.String() + "/export?format=markdown")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(resp.Header.Get("Content-Type")).To(HavePrefix("text/markdown"))
+
+			body, _ := ioutil.ReadAll(resp.Body)
+			Expect(string(body)).To(HavePrefix("# retrieve recipe\n"))
+		})
+
+		It("renders a scaled recipe as PDF attachment", func() {
+			id := createAndPersistDefaultRecipe(recipes)
+			defer recipes.Remove(id)
+
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes/r/" + id.String() + "/export?format=pdf&servings=2")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(resp.Header.Get("Content-Type")).To(Equal("application/pdf"))
+			Expect(resp.Header.Get("Content-Disposition")).To(Equal(`attachment; filename="retrieve-recipe.pdf"`))
+		})
+
+		It("rejects unknown formats", func() {
+			id := createAndPersistDefaultRecipe(recipes)
+			defer recipes.Remove(id)
+
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes/r/" + id.String() + "/export?format=docx")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Context("Printing Recipes", func() {
+		It("renders a scaled recipe as HTML page", func() {
+			id := createAndPersistDefaultRecipe(recipes)
+			defer recipes.Remove(id)
+
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes/r/" + id.String() + "/print?servings=2")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(resp.Header.Get("Content-Type")).To(HavePrefix("text/html"))
+
+			body, _ := ioutil.ReadAll(resp.Body)
+			Expect(string(body)).To(ContainSubstring("<h1>retrieve recipe</h1>"))
+			Expect(string(body)).To(ContainSubstring("<li>200 g Test</li>"))
+		})
+
+		It("returns 404 when the recipe does not exist", func() {
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes/r/" + NewRecipeID().String() + "/print")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Context("Substitutions", func() {
+		AfterEach(func() {
+			rAPI.substitutions = NewSubstitutions(nil)
+		})
+
+		It("suggests substitutes for each ingredient", func() {
+			rAPI.substitutions = NewSubstitutions(map[string][]string{"test": {"Mock"}})
+			id := createAndPersistDefaultRecipe(recipes)
+			defer recipes.Remove(id)
+
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes/r/" + id.String() + "/substitutions")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			var suggestions []IngredientSubstitutions
+			err = json.NewDecoder(resp.Body).Decode(&suggestions)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(suggestions).To(Equal([]IngredientSubstitutions{{Ingredient: "Test", Substitutes: []string{"Mock"}}}))
+		})
+
+		It("returns an empty list for ingredients without substitutes", func() {
+			id := createAndPersistDefaultRecipe(recipes)
+			defer recipes.Remove(id)
+
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes/r/" + id.String() + "/substitutions")
+			Expect(err).ToNot(HaveOccurred())
+
+			body, _ := ioutil.ReadAll(resp.Body)
+			Expect(string(body)).To(Equal(`[{"ingredient":"Test","substitutes":[]}]`))
+		})
+
+		It("returns 404 when the recipe does not exist", func() {
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes/r/" + NewRecipeID().String() + "/substitutions")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Context("Timers", func() {
+		It("lists the timers of the steps", func() {
+			recipe := NewRecipe(NewRecipeID())
+			recipe.Name = "bread"
+			recipe.Steps = []RecipeStep{{Text: "Knead."}, {Text: "Bake.", Minutes: 45}}
+			Expect(recipes.Insert(recipe)).To(Succeed())
+			defer recipes.Remove(recipe.ID)
+
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes/r/" + recipe.ID.String() + "/timers")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			var timers []RecipeTimer
+			Expect(json.NewDecoder(resp.Body).Decode(&timers)).To(Succeed())
+			Expect(timers).To(Equal([]RecipeTimer{{Step: 2, Label: "Bake.", Seconds: 2700}}))
+		})
+
+		It("returns an empty list for recipes without timers", func() {
+			id := createAndPersistDefaultRecipe(recipes)
+			defer recipes.Remove(id)
+
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes/r/" + id.String() + "/timers")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			body, _ := ioutil.ReadAll(resp.Body)
+			Expect(string(body)).To(Equal("[]"))
+		})
+
+		It("returns 404 when the recipe does not exist", func() {
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes/r/" + NewRecipeID().String() + "/timers")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Context("Ingredients", func() {
+		It("returns the scaled ingredients", func() {
+			id := createAndPersistDefaultRecipe(recipes)
+			defer recipes.Remove(id)
+
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes/r/" + id.String() + "/ingredients?servings=3")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			var ingredients []Ingredients
+			Expect(json.NewDecoder(resp.Body).Decode(&ingredients)).To(Succeed())
+			Expect(ingredients).To(Equal([]Ingredients{{Name: "Test", Amount: 300, Unit: "g"}}))
+		})
+
+		It("converts the ingredients to the requested units", func() {
+			id := createAndPersistDefaultRecipe(recipes)
+			defer recipes.Remove(id)
+
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes/r/" + id.String() + "/ingredients?units=imperial")
+			Expect(err).ToNot(HaveOccurred())
+
+			var ingredients []Ingredients
+			Expect(json.NewDecoder(resp.Body).Decode(&ingredients)).To(Succeed())
+			Expect(ingredients[0].Unit).To(Equal("oz"))
+		})
+
+		It("rejects invalid servings and units", func() {
+			for _, query := range []string{"servings=0", "servings=1000", "units=parsecs"} {
+				resp, err := http.Get("http://localhost:8080/api/v1/recipes/r/" + NewRecipeID().String() + "/ingredients?" + query)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(resp.StatusCode).To(Equal(http.StatusBadRequest), query)
+			}
+		})
+
+		It("returns 404 when the recipe does not exist", func() {
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes/r/" + NewRecipeID().String() + "/ingredients")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Context("Cook Mode", func() {
+		It("lists the steps with their scaled ingredients and timers", func() {
+			recipe := NewRecipe(NewRecipeID())
+			recipe.Name = "bread"
+			recipe.Servings = 1
+			recipe.Ingredients = []Ingredients{{Name: "Flour", Amount: 500, Unit: "g"}, {Name: "Water", Amount: 300, Unit: "ml"}}
+			recipe.Steps = []RecipeStep{{Text: "Knead the flour with the water."}, {Text: "Bake.", Minutes: 45}}
+			Expect(recipes.Insert(recipe)).To(Succeed())
+			defer recipes.Remove(recipe.ID)
+
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes/r/" + recipe.ID.String() + "/cook?servings=2")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			var cook CookMode
+			Expect(json.NewDecoder(resp.Body).Decode(&cook)).To(Succeed())
+			Expect(cook.Servings).To(Equal(int8(2)))
+			Expect(cook.Steps).To(HaveLen(2))
+			Expect(cook.Steps[0].Ingredients).To(Equal([]Ingredients{{Name: "Flour", Amount: 1000, Unit: "g"}, {Name: "Water", Amount: 600, Unit: "ml"}}))
+			Expect(cook.Steps[1].Ingredients).To(BeEmpty())
+			Expect(cook.Steps[1].Timer).To(Equal(&RecipeTimer{Step: 2, Label: "Bake.", Seconds: 2700}))
+		})
+
+		It("rejects invalid servings", func() {
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes/r/" + NewRecipeID().String() + "/cook?servings=many")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+		})
+
+		It("returns 404 when the recipe does not exist", func() {
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes/r/" + NewRecipeID().String() + "/cook")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Context("Similar Recipes", func() {
+		It("lists recipes with similar ingredients", func() {
+			id := createAndPersistDefaultRecipe(recipes)
+			defer recipes.Remove(id)
+			duplicate := createAndPersistDefaultRecipe(recipes)
+			defer recipes.Remove(duplicate)
+
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes/r/" + id.String() + "/similar?threshold=1")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			var similar []SimilarRecipe
+			err = json.NewDecoder(resp.Body).Decode(&similar)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(similar).To(ContainElement(SimilarRecipe{ID: duplicate, Name: "retrieve recipe", Score: 1}))
+			for _, recipe := range similar {
+				Expect(recipe.ID).ToNot(Equal(id))
+			}
+		})
+
+		It("rejects invalid thresholds", func() {
+			id := createAndPersistDefaultRecipe(recipes)
+			defer recipes.Remove(id)
+
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes/r/" + id.String() + "/similar?threshold=2")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+		})
+
+		It("returns 404 when the recipe does not exist", func() {
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes/r/" + NewRecipeID().String() + "/similar")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Context("Shopping Lists", func() {
+		It("merges the scaled ingredients of all recipes", func() {
+			first := createAndPersistDefaultRecipe(recipes)
+			defer recipes.Remove(first)
+			second := createAndPersistDefaultRecipe(recipes)
+			defer recipes.Remove(second)
+
+			request, _ := json.Marshal(ShoppingListRequest{Recipes: []ShoppingListItem{{ID: first, Servings: 2}, {ID: second}}})
+			resp, err := http.Post("http://localhost:8080/api/v1/recipes/shopping-list", "application/json", bytes.NewBuffer(request))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			var ingredients []Ingredients
+			err = json.NewDecoder(resp.Body).Decode(&ingredients)
+			Expect(ingredients).To(Equal([]Ingredients{{Name: "Test", Amount: 300, Unit: "g"}}))
+		})
+
+		It("rejects negative servings", func() {
+			id := createAndPersistDefaultRecipe(recipes)
+			defer recipes.Remove(id)
+
+			request, _ := json.Marshal(ShoppingListRequest{Recipes: []ShoppingListItem{{ID: id, Servings: -1}}})
+			resp, err := http.Post("http://localhost:8080/api/v1/recipes/shopping-list", "application/json", bytes.NewBuffer(request))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+		})
+
+		It("returns 404 when a recipe does not exist", func() {
+			request, _ := json.Marshal(ShoppingListRequest{Recipes: []ShoppingListItem{{ID: NewRecipeID()}}})
+			resp, err := http.Post("http://localhost:8080/api/v1/recipes/shopping-list", "application/json", bytes.NewBuffer(request))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Context("Randomly getting recipes", func() {
+		It("returns a 404 when no recipe exists ", func() {
+			recipes.Clear()
+
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes/rand")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(404))
+		})
+
+		It("returns a valid random recipe", func() {
+			recipes.Clear()
+			_, expectedRecipesIDs := createRandomRecipes(10, recipes) //recipes
+
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes/rand")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(200))
+
+			var recipe Recipe
+			err = json.NewDecoder(resp.Body).Decode(&recipe)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(recipe.ID).To(BeElementOf(expectedRecipesIDs))
+		})
+
+		It("picks a random recipe out of the filtered recipes", func() {
+			recipes.Clear()
+			createRandomRecipes(10, recipes)
+			expectedID := createAndPersistDefaultRecipe(recipes)
+
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes/rand?name=retrieve")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(200))
+
+			var recipe Recipe
+			err = json.NewDecoder(resp.Body).Decode(&recipe)
+			Expect(recipe.ID).To(Equal(expectedID))
+		})
+
+		It("picks the same recipe for the same seed", func() {
+			recipes.Clear()
+			createRandomRecipes(10, recipes)
+
+			var first, second Recipe
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes/rand?seed=42")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(200))
+			err = json.NewDecoder(resp.Body).Decode(&first)
+
+			resp, err = http.Get("http://localhost:8080/api/v1/recipes/rand?seed=42")
+			Expect(err).ToNot(HaveOccurred())
+			err = json.NewDecoder(resp.Body).Decode(&second)
+
+			Expect(first.ID).ToNot(Equal(InvalidRecipeID()))
+			Expect(second.ID).To(Equal(first.ID))
+		})
+
+		It("rejects a seed that is not a number", func() {
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes/rand?seed=abc")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(400))
+		})
+
+		It("returns a 404 when no recipe matches the filter", func() {
+			recipes.Clear()
+			createRandomRecipes(10, recipes)
+
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes/rand?tag=vegan")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(404))
+		})
+
+		It("can retrieve a random recipe and scale the recipe", func() {
+			recipes.Clear()
+
+			_ = createAndPersistDefaultRecipe(recipes)
+
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes/rand?servings=2")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(resp.StatusCode).To(Equal(200))
+
+			var recipe Recipe
+			err = json.NewDecoder(resp.Body).Decode(&recipe)
+			Expect(len(recipe.Ingredients)).ToNot(Equal(0))
+			Expect(recipe.Ingredients[0].Amount).To(Equal(200.0))
+		})
+	})
+
+	Context("Searching Recipes", func() {
+		It("returns the recipes matching all terms, the most relevant first", func() {
+			recipes.Clear()
+			createRandomRecipes(5, recipes)
+			bestID := createAndPersistNewRecipe("tomato soup", "a soup with tomato", Ingredients{Name: "tomato"}, recipes)
+			otherID := createAndPersistNewRecipe("soup", "a soup with a hint of tomato", Ingredients{Name: "water"}, recipes)
+			createAndPersistNewRecipe("tomato salad", "fresh", Ingredients{Name: "tomato"}, recipes)
+
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes/search?q=tomato+soup")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(200))
+
+			var results []RecipeSearchResult
+			err = json.NewDecoder(resp.Body).Decode(&results)
+			Expect(results).To(HaveLen(2))
+			Expect(results[0].ID).To(Equal(bestID))
+			Expect(results[1].ID).To(Equal(otherID))
+		})
+
+		It("returns an empty list when nothing matches", func() {
+			recipes.Clear()
+			createRandomRecipes(5, recipes)
+
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes/search?q=%22olive+oil%22")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(200))
+
+			var results []RecipeSearchResult
+			err = json.NewDecoder(resp.Body).Decode(&results)
+			Expect(results).ToNot(BeNil())
+			Expect(results).To(BeEmpty())
+		})
+
+		It("rejects an empty query", func() {
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes/search?q=")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(400))
+		})
+	})
+
+	Context("Batch of Recipes", func() {
+		It("returns all found recipes and lists the missing ids", func() {
+			recipes.Clear()
+			soupID := createAndPersistNewRecipe("soup", "hot", Ingredients{Name: "water", Amount: 2}, recipes)
+			stewID := createAndPersistNewRecipe("stew", "hearty", Ingredients{Name: "beans", Amount: 2}, recipes)
+			missingID := NewRecipeID()
+
+			resp, err := http.Get(fmt.Sprintf("http://localhost:8080/api/v1/recipes/batch?ids=%v,%v,%v&servings=2", stewID, missingID, soupID))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(200))
+
+			var batch RecipeBatch
+			err = json.NewDecoder(resp.Body).Decode(&batch)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(batch.Recipes).To(HaveLen(2))
+			Expect(batch.Recipes[0].ID).To(Equal(stewID))
+			Expect(batch.Recipes[1].ID).To(Equal(soupID))
+			Expect(batch.Recipes[0].Servings).To(Equal(int8(2)))
+			Expect(batch.Recipes[0].Ingredients[0].Amount).To(Equal(4.0))
+			Expect(batch.NotFound).To(Equal([]string{missingID.String()}))
+		})
+
+		It("rejects malformed ids", func() {
+			resp, err := http.Get(fmt.Sprintf("http://localhost:8080/api/v1/recipes/batch?ids=%v,not_an_id", NewRecipeID()))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(400))
+		})
+
+		It("rejects requests without ids", func() {
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes/batch")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(400))
+		})
+	})
+
+	Context("Recipe of the day", func() {
+		It("returns the same recipe for the same date", func() {
+			recipes.Clear()
+			createRandomRecipes(10, recipes)
+
+			var first, second Recipe
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes/daily?date=2021-07-31")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(200))
+			err = json.NewDecoder(resp.Body).Decode(&first)
+
+			resp, err = http.Get("http://localhost:8080/api/v1/recipes/daily?date=2021-07-31")
+			Expect(err).ToNot(HaveOccurred())
+			err = json.NewDecoder(resp.Body).Decode(&second)
+
+			Expect(first.ID).ToNot(Equal(InvalidRecipeID()))
+			Expect(second.ID).To(Equal(first.ID))
+		})
+
+		It("returns a 404 when there are no recipes", func() {
+			recipes.Clear()
+
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes/daily")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(404))
+		})
+
+		It("rejects invalid dates", func() {
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes/daily?date=31.07.2021")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(400))
+		})
+
+		It("hashes dates into the range of recipes", func() {
+			for day := 1; day <= 31; day++ {
+				index := dailyIndex(fmt.Sprintf("2021-07-%02d", day), 7)
+				Expect(index).To(BeNumerically(">=", 0))
+				Expect(index).To(BeNumerically("<", 7))
+			}
+			Expect(dailyIndex("2021-07-31", 7)).To(Equal(dailyIndex("2021-07-31", 7)))
+		})
+	})
+
+	Context("Servings", func() {
+		It("uses the default servings when no servings are requested", func() {
+			servings, err := extractServings(url.Values{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(servings).To(Equal(defaultServings))
+		})
+
+		It("accepts servings up to the limit", func() {
+			servings, err := extractServings(url.Values{SERVINGS: []string{fmt.Sprint(maxServings)}})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(servings).To(Equal(maxServings))
+		})
+
+		It("rejects invalid servings", func() {
+			for _, value := range []string{"0", "-1", "abc", fmt.Sprint(int(maxServings) + 1)} {
+				_, err := extractServings(url.Values{SERVINGS: []string{value}})
+				Expect(err).To(HaveOccurred(), value)
+			}
+		})
+	})
+
+	Context("Counting Recipes", func() {
+		It("returns 0 when no recipes are persisted", func() {
+			recipes.Clear()
+
+			_, _ = createRandomRecipes(10, recipes)
+
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes/num")
+			Expect(err).ToNot(HaveOccurred())
+
+			result, err := ioutil.ReadAll(resp.Body)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(resp.StatusCode).To(Equal(200))
+			Expect(string(result)).To(Equal("10"))
+		})
+
+		It("returns the amount of persisted recipes", func() {
+			recipes.Clear()
+
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes/num")
+			Expect(err).ToNot(HaveOccurred())
+
+			result, err := ioutil.ReadAll(resp.Body)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(resp.StatusCode).To(Equal(200))
+			Expect(string(result)).To(Equal("0"))
+		})
+	})
+
+	Context("Posting Recipes", func() {
+		It("is not possible with malformed documents", func() {
+			recipes.Clear()
+
+			resp, err := http.Post("http://localhost:8080/api/v1/recipes", "application/json", bytes.NewBuffer(nil))
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(resp.StatusCode).To(Equal(400))
+		})
+
+		It("rejects invalid recipes and lists the invalid fields", func() {
+			recipes.Clear()
+
+			recipe := Recipe{Servings: 2, Name: ""}
+			recipeJSON, _ := json.Marshal(recipe)
+
+			resp, err := http.Post("http://localhost:8080/api/v1/recipes", "application/json", bytes.NewBuffer(recipeJSON))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+
+			var apiError core.APIError
+			Expect(json.NewDecoder(resp.Body).Decode(&apiError)).To(Succeed())
+			Expect(apiError.Fields).To(Equal([]core.FieldError{{Field: "name", Message: "must not be empty"}}))
+			Expect(recipes.Num()).To(BeZero())
+		})
+
+		It("persists a new recipe", func() {
+			recipes.Clear()
+
+			const POSTTEST = "PostTest"
+			const POSTDESCRIPTION = "Test \n 123"
+
+			recipe := Recipe{Servings: 2, Name: POSTTEST, Description: POSTDESCRIPTION}
+			recipeJSON, _ := json.Marshal(recipe)
+
+			resp, err := http.Post("http://localhost:8080/api/v1/recipes", "application/json", bytes.NewBuffer(recipeJSON))
+			Expect(err).ToNot(HaveOccurred())
+
+			retrievedRecipe, err := recipes.GetByName(POSTTEST)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(resp.StatusCode).To(Equal(201))
+			Expect(retrievedRecipe.Servings).To(Equal(recipe.Servings))
+			Expect(retrievedRecipe.Description).To(Equal(recipe.Description))
+		})
+
+		It("ignores the client's id and points to the new recipe", func() {
+			recipes.Clear()
+
+			const POSTTEST = "PostLocationTest"
+
+			clientID := NewRecipeID()
+			recipe := Recipe{ID: clientID, Servings: 2, Name: POSTTEST}
+			recipeJSON, _ := json.Marshal(recipe)
+
+			resp, err := http.Post("http://localhost:8080/api/v1/recipes", "application/json", bytes.NewBuffer(recipeJSON))
+			Expect(err).ToNot(HaveOccurred())
+
+			retrievedRecipe, err := recipes.GetByName(POSTTEST)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(resp.StatusCode).To(Equal(201))
+			Expect(retrievedRecipe.ID).ToNot(Equal(clientID))
+			Expect(resp.Header.Get("Location")).To(Equal("/api/v1/recipes/r/" + retrievedRecipe.ID.String()))
+		})
+	})
+
+	Context("Posting Recipes with an Idempotency-Key", func() {
+
+		post := func(key string, recipe Recipe) *http.Response {
+			recipeJSON, _ := json.Marshal(recipe)
+			request, _ := http.NewRequest(http.MethodPost, "http://localhost:8080/api/v1/recipes", bytes.NewBuffer(recipeJSON))
+			request.Header.Set("Content-Type", "application/json")
+			request.Header.Set("Idempotency-Key", key)
+			resp, err := http.DefaultClient.Do(request)
+			Expect(err).ToNot(HaveOccurred())
+			return resp
+		}
+
+		It("creates a recipe only once", func() {
+			recipes.Clear()
+			key := NewRecipeID().String()
+			recipe := Recipe{Servings: 2, Name: "Idempotent Soup"}
+
+			first := post(key, recipe)
+			Expect(first.StatusCode).To(Equal(http.StatusCreated))
+			Expect(first.Header.Get("Idempotent-Replayed")).To(BeEmpty())
+
+			retry := post(key, recipe)
+			Expect(retry.StatusCode).To(Equal(http.StatusCreated))
+			Expect(retry.Header.Get("Idempotent-Replayed")).To(Equal("true"))
+			Expect(retry.Header.Get("Location")).To(Equal(first.Header.Get("Location")))
+			Expect(recipes.Num()).To(Equal(int64(1)))
+		})
+
+		It("creates recipes with different keys", func() {
+			recipes.Clear()
+			recipe := Recipe{Servings: 2, Name: "Idempotent Stew"}
+
+			Expect(post(NewRecipeID().String(), recipe).StatusCode).To(Equal(http.StatusCreated))
+			Expect(post(NewRecipeID().String(), recipe).StatusCode).To(Equal(http.StatusCreated))
+			Expect(recipes.Num()).To(Equal(int64(2)))
+		})
+
+		It("rejects a key used for a different recipe", func() {
+			recipes.Clear()
+			key := NewRecipeID().String()
+
+			Expect(post(key, Recipe{Servings: 2, Name: "Idempotent Salad"}).StatusCode).To(Equal(http.StatusCreated))
+			Expect(post(key, Recipe{Servings: 4, Name: "Idempotent Salad"}).StatusCode).To(Equal(http.StatusUnprocessableEntity))
+			Expect(recipes.Num()).To(Equal(int64(1)))
+		})
+
+		It("does not remember keys of invalid recipes", func() {
+			recipes.Clear()
+			key := NewRecipeID().String()
+
+			Expect(post(key, Recipe{Servings: 2}).StatusCode).To(Equal(http.StatusBadRequest))
+			Expect(post(key, Recipe{Servings: 2, Name: "Idempotent Bread"}).StatusCode).To(Equal(http.StatusCreated))
+		})
+
+		It("releases the key when persisting the recipe panics", func() {
+			db := &panickingDB{InMemoryDB: NewInMemoryDB(), panics: true}
+			defer db.Close()
+			handler := core.NewHandler()
+			AddRecipesAPIToHandler(handler, db, NewRecipeEvents(1))
+			post := func() int {
+				w := httptest.NewRecorder()
+				request := httptest.NewRequest(http.MethodPost, "/api/v1/recipes", strings.NewReader(`{"name":"Idempotent Pie","servings":2}`))
+				request.Header.Set("Content-Type", "application/json")
+				request.Header.Set("Idempotency-Key", "pie")
+				handler.ServeHTTP(w, request)
+				return w.Code
+			}
+
+			Expect(post()).To(Equal(http.StatusInternalServerError))
+			db.panics = false
+			Expect(post()).To(Equal(http.StatusCreated))
+			Expect(db.Num()).To(Equal(int64(1)))
+		})
+
+		It("rejects too long keys", func() {
+			resp := post(strings.Repeat("k", 256), Recipe{Servings: 2, Name: "Idempotent Cake"})
+			Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Context("Copying Recipes", func() {
+
+		It("persists a copy of a recipe with a new id", func() {
+			id := createAndPersistDefaultRecipe(recipes)
+
+			resp, err := http.Post("http://localhost:8080/api/v1/recipes/r/"+id.String()+"/copy", "application/json", nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusCreated))
+
+			var recipeCopy Recipe
+			err = json.NewDecoder(resp.Body).Decode(&recipeCopy)
+			Expect(recipeCopy.ID).ToNot(Equal(id))
+			Expect(recipeCopy.Name).To(Equal("retrieve recipe (copy)"))
+			Expect(resp.Header.Get("Location")).To(HaveSuffix(recipeCopy.ID.String()))
+			Expect(recipes.Get(recipeCopy.ID).Ingredients).To(Equal(recipes.Get(id).Ingredients))
+		})
+
+		It("returns a 404 when the recipe does not exist", func() {
+			resp, err := http.Post("http://localhost:8080/api/v1/recipes/r/"+NewRecipeID().String()+"/copy", "application/json", nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Context("Tagging Recipes", func() {
+		It("tags many recipes and reports unknown ids", func() {
+			id := createAndPersistDefaultRecipe(recipes)
+			defer recipes.Remove(id)
+			missing := NewRecipeID()
+
+			resp, err := http.Post("http://localhost:8080/api/v1/recipes/tags", "application/json",
+				bytes.NewBufferString(fmt.Sprintf(`{"ids":[%q,%q],"add":["quick"]}`, id, missing)))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			var results map[RecipeID]bool
+			Expect(json.NewDecoder(resp.Body).Decode(&results)).To(Succeed())
+			Expect(results).To(Equal(map[RecipeID]bool{id: true, missing: false}))
+			Expect(recipes.Get(id).Tags).To(ContainElement("quick"))
+		})
+
+		It("rejects requests without tags", func() {
+			resp, err := http.Post("http://localhost:8080/api/v1/recipes/tags", "application/json",
+				bytes.NewBufferString(fmt.Sprintf(`{"ids":[%q]}`, NewRecipeID())))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Context("Validating Recipes", func() {
+
+		validate := func(request string) *http.Response {
+			resp, err := http.Post("http://localhost:8080/api/v1/recipes/validate", "application/json", bytes.NewBufferString(request))
+			Expect(err).ToNot(HaveOccurred())
+			return resp
+		}
+
+		It("returns the normalized recipe without saving it", func() {
+			num := recipes.Num()
+
+			resp := validate(`{"name":"Pancakes","servings":2,"description":"Mix and fry","components":[{"name":"Flour","amount":200,"unit":"Grams"}]}`)
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			var validation RecipeValidation
+			Expect(json.NewDecoder(resp.Body).Decode(&validation)).To(Succeed())
+			Expect(validation.Valid).To(BeTrue())
+			Expect(validation.Recipe.Ingredients[0].Unit).To(Equal("g"))
+			Expect(recipes.Num()).To(Equal(num))
+		})
+
+		It("returns the errors of invalid recipes with 200", func() {
+			resp := validate(`{"name":"","servings":2,"components":[{"name":"Flour","amount":200,"unit":"handfuls"}]}`)
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			var validation RecipeValidation
+			Expect(json.NewDecoder(resp.Body).Decode(&validation)).To(Succeed())
+			Expect(validation.Valid).To(BeFalse())
+			Expect(validation.Errors).To(ContainElement(core.FieldError{Field: "name", Message: "must not be empty"}))
+			Expect(validation.Warnings).To(ContainElement(core.FieldError{Field: "components[0].unit", Message: "is not a known unit and cannot be converted"}))
+		})
+
+		It("rejects malformed JSON", func() {
+			resp := validate(`{"name":`)
+			Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Context("CSV", func() {
+
+		importCSV := func(csv string) *http.Response {
+			resp, err := http.Post("http://localhost:8080/api/v1/recipes/import.csv", "text/csv", bytes.NewBufferString(csv))
+			Expect(err).ToNot(HaveOccurred())
+			return resp
+		}
+
+		It("exports recipes with one row per ingredient", func() {
+			id := createAndPersistNewRecipe("CSV, exported", "details", Ingredients{Name: "Flour", Amount: 100, Unit: "g"}, recipes)
+			defer recipes.Remove(id)
+
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes/export.csv")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(resp.Header.Get("Content-Type")).To(HavePrefix("text/csv"))
+			Expect(resp.Header.Get("Content-Disposition")).To(ContainSubstring("recipes.csv"))
+
+			body, _ := ioutil.ReadAll(resp.Body)
+			Expect(string(body)).To(HavePrefix("name,servings,"))
+			Expect(string(body)).To(ContainSubstring(`"CSV, exported",`))
+			Expect(string(body)).To(ContainSubstring(",Flour,100,,g,"))
+		})
+
+		It("imports recipes and reports rejected rows", func() {
+			resp := importCSV("name,servings,ingredient,amount,unit\nCSV Bread,2,Flour,500,g\nCSV Bread,2,Water,300,ml\nCSV Soup,2,Water,lots,ml\n")
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			var result CSVImportResult
+			Expect(json.NewDecoder(resp.Body).Decode(&result)).To(Succeed())
+			Expect(result.Imported).To(HaveLen(1))
+			defer recipes.Remove(result.Imported[0])
+			Expect(result.Errors).To(Equal([]CSVRowError{{Row: 4, Recipe: "CSV Soup", Message: "amount must be a number, not 'lots'"}}))
+
+			recipe := recipes.Get(result.Imported[0])
+			Expect(recipe.Name).To(Equal("CSV Bread"))
+			Expect(recipe.Ingredients).To(HaveLen(2))
+			_, err := recipes.GetByName("CSV Soup")
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("rejects files without a valid header", func() {
+			resp := importCSV("title,amount\nBread,1\n")
+			Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Context("Merging Recipes", func() {
+
+		merge := func(request string) *http.Response {
+			resp, err := http.Post("http://localhost:8080/api/v1/recipes/merge", "application/json", bytes.NewBufferString(request))
+			Expect(err).ToNot(HaveOccurred())
+			return resp
+		}
+
+		It("merges the ratings of duplicates and removes them", func() {
+			id := createAndPersistDefaultRecipe(recipes)
+			defer recipes.Remove(id)
+			duplicate := createAndPersistDefaultRecipe(recipes)
+			_, _ = recipes.AddRating(id, 4)
+			_, _ = recipes.AddRating(duplicate, 1)
+
+			resp := merge(fmt.Sprintf(`{"primary":%q,"duplicates":[%q]}`, id, duplicate))
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			var merged Recipe
+			Expect(json.NewDecoder(resp.Body).Decode(&merged)).To(Succeed())
+			Expect(merged.ID).To(Equal(id))
+			Expect(merged.Rating).To(Equal(float32(2.5)))
+			Expect(merged.RatingCount).To(Equal(2))
+			Expect(recipes.Get(duplicate).ID).To(Equal(InvalidRecipeID()))
+		})
+
+		It("rejects requests without duplicates", func() {
+			resp := merge(fmt.Sprintf(`{"primary":%q,"duplicates":[]}`, NewRecipeID()))
+			Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+		})
+
+		It("returns 404 when a recipe does not exist", func() {
+			id := createAndPersistDefaultRecipe(recipes)
+			defer recipes.Remove(id)
+
+			resp := merge(fmt.Sprintf(`{"primary":%q,"duplicates":[%q]}`, id, NewRecipeID()))
+			Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+			Expect(recipes.Get(id).ID).To(Equal(id))
+		})
+	})
+
+	Context("DELETE Recipes", func() {
+
+		It("returns 400 when the recipe id is malformed", func() {
+			request, err := http.NewRequest(http.MethodDelete, "http://localhost:8080/api/v1/recipes/r/not_a_uuid", nil)
+			Expect(err).ToNot(HaveOccurred())
+			response, err := http.DefaultClient.Do(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.StatusCode).To(Equal(http.StatusBadRequest))
+		})
+
+		It("archives a persisted recipe", func() {
+			id := createAndPersistDefaultRecipe(recipes)
+			defer recipes.Remove(id)
+			client := &http.Client{}
+			request, err := http.NewRequest(http.MethodDelete, "http://localhost:8080/api/v1/recipes/r/"+id.String(), bytes.NewBuffer(nil))
+			response, err := client.Do(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.StatusCode).To(Equal(http.StatusOK))
+			Expect(recipes.Get(id).Archived).To(BeTrue())
+			Expect(recipes.IDs(&RecipeSearchFilter{}).Recipes).ToNot(ContainElement(id.String()))
+		})
+
+		It("removes a persisted recipe permanently with hard=true", func() {
+			id := createAndPersistDefaultRecipe(recipes)
+			request, err := http.NewRequest(http.MethodDelete, "http://localhost:8080/api/v1/recipes/r/"+id.String()+"?hard=true", nil)
+			Expect(err).ToNot(HaveOccurred())
+			response, err := http.DefaultClient.Do(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.StatusCode).To(Equal(http.StatusOK))
+			Expect(recipes.Get(id).ID).To(Equal(InvalidRecipeID()))
+		})
+
+		It("returns 404 when archiving a recipe that does not exist", func() {
+			request, err := http.NewRequest(http.MethodDelete, "http://localhost:8080/api/v1/recipes/r/"+NewRecipeID().String(), nil)
+			Expect(err).ToNot(HaveOccurred())
+			response, err := http.DefaultClient.Do(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.StatusCode).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Context("Archived Recipes", func() {
+
+		It("lists archived recipes only", func() {
+			archived := createAndPersistDefaultRecipe(recipes)
+			defer recipes.Remove(archived)
+			listed := createAndPersistDefaultRecipe(recipes)
+			defer recipes.Remove(listed)
+			Expect(recipes.Archive(archived)).To(Succeed())
+
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes/archived")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(resp.Header.Get(totalCountHeader)).To(Equal("1"))
+
+			var list RecipeList
+			Expect(json.NewDecoder(resp.Body).Decode(&list)).To(Succeed())
+			Expect(list.Recipes).To(Equal([]string{archived.String()}))
+		})
+
+		It("restores an archived recipe", func() {
+			id := createAndPersistDefaultRecipe(recipes)
+			defer recipes.Remove(id)
+			Expect(recipes.Archive(id)).To(Succeed())
+
+			resp, err := http.Post("http://localhost:8080/api/v1/recipes/r/"+id.String()+"/restore", "application/json", nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			var recipe Recipe
+			Expect(json.NewDecoder(resp.Body).Decode(&recipe)).To(Succeed())
+			Expect(recipe.ID).To(Equal(id))
+			Expect(recipe.Archived).To(BeFalse())
+			Expect(recipes.IDs(&RecipeSearchFilter{}).Recipes).To(ContainElement(id.String()))
+		})
+
+		It("returns 404 when restoring a recipe that does not exist", func() {
+			resp, err := http.Post("http://localhost:8080/api/v1/recipes/r/"+NewRecipeID().String()+"/restore", "application/json", nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Context("Uploading Pictures", func() {
+
+		It("adds the picture to the recipe", func() {
+			id := createAndPersistDefaultRecipe(recipes)
+			defer recipes.Remove(id)
+
+			resp, err := uploadPicture(id, "dish.png", pngHeader)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusCreated))
+			Expect(resp.Header.Get("Location")).To(Equal("/api/v1/recipes/r/" + id.String() + "/pictures/dish.png"))
+
+			var result PictureUploadResult
+			err = json.NewDecoder(resp.Body).Decode(&result)
+			Expect(result.Name).To(Equal("dish.png"))
+			Expect(recipes.Get(id).PictureLink).To(ContainElement("dish.png"))
+		})
+
+		It("scales large pictures down and returns the stored dimensions", func() {
+			id := createAndPersistDefaultRecipe(recipes)
+			defer recipes.Remove(id)
+			var picture bytes.Buffer
+			Expect(png.Encode(&picture, image.NewRGBA(image.Rect(0, 0, 2*maxPictureDimension, 100)))).To(Succeed())
+
+			resp, err := uploadPicture(id, "dish.png", picture.Bytes())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusCreated))
+
+			var result PictureUploadResult
+			Expect(json.NewDecoder(resp.Body).Decode(&result)).To(Succeed())
+			Expect(result).To(Equal(PictureUploadResult{Name: "dish.png", Width: maxPictureDimension, Height: 50}))
+
+			stored, err := utils.Base64ToIMG(recipes.Picture(id, "dish.png").Picture)
+			Expect(err).ToNot(HaveOccurred())
+			config, _, err := image.DecodeConfig(bytes.NewReader(stored))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(config.Width).To(Equal(maxPictureDimension))
+			Expect(config.Height).To(Equal(50))
+		})
+
+		It("rejects pictures with too many pixels, e.g., decompression bombs", func() {
+			id := createAndPersistDefaultRecipe(recipes)
+			defer recipes.Remove(id)
+
+			resp, err := uploadPicture(id, "bomb.png", pngOfSize(100000, 100000))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusRequestEntityTooLarge))
+			Expect(recipes.Picture(id, "bomb.png").ID).To(Equal(InvalidRecipeID()))
+		})
+
+		It("rejects files that are not images", func() {
+			id := createAndPersistDefaultRecipe(recipes)
+			defer recipes.Remove(id)
+
+			resp, err := uploadPicture(id, "dish.png", []byte("no image"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusUnsupportedMediaType))
+		})
+
+		It("returns 404 when the recipe does not exist", func() {
+			resp, err := uploadPicture(NewRecipeID(), "dish.png", pngHeader)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Context("Getting Pictures", func() {
+
+		It("returns the raw picture with its content type", func() {
+			id := createAndPersistDefaultRecipe(recipes)
+			defer recipes.Remove(id)
+			_, err := uploadPicture(id, "dish.png", pngHeader)
+			Expect(err).ToNot(HaveOccurred())
+
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes/r/" + id.String() + "/pictures/dish.png?raw=true")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(resp.Header.Get("Content-Type")).To(Equal("image/png"))
+			Expect(resp.Header.Get("Cache-Control")).ToNot(BeEmpty())
+
+			body, _ := ioutil.ReadAll(resp.Body)
+			Expect(body).To(Equal(pngHeader))
+		})
+
+		It("returns the thumbnail of a picture", func() {
+			id := createAndPersistDefaultRecipe(recipes)
+			defer recipes.Remove(id)
+			var picture bytes.Buffer
+			Expect(png.Encode(&picture, image.NewRGBA(image.Rect(0, 0, 1024, 512)))).To(Succeed())
+			_, err := uploadPicture(id, "dish.png", picture.Bytes())
+			Expect(err).ToNot(HaveOccurred())
+
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes/r/" + id.String() + "/pictures/dish.png?raw=true&size=thumb")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(resp.Header.Get("Content-Type")).To(Equal("image/png"))
+
+			thumbnail, _, err := image.DecodeConfig(resp.Body)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(thumbnail.Width).To(Equal(256))
+			Expect(thumbnail.Height).To(Equal(128))
+		})
+
+		It("returns the original picture when there is no thumbnail", func() {
+			id := createAndPersistDefaultRecipe(recipes)
+			defer recipes.Remove(id)
+			_, err := uploadPicture(id, "dish.png", pngHeader)
+			Expect(err).ToNot(HaveOccurred())
+
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes/r/" + id.String() + "/pictures/dish.png?raw=true&size=thumb")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			body, _ := ioutil.ReadAll(resp.Body)
+			Expect(body).To(Equal(pngHeader))
+		})
+
+		It("rejects unknown sizes", func() {
+			id := createAndPersistDefaultRecipe(recipes)
+			defer recipes.Remove(id)
+			_, err := uploadPicture(id, "dish.png", pngHeader)
+			Expect(err).ToNot(HaveOccurred())
+
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes/r/" + id.String() + "/pictures/dish.png?size=huge")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Context("Deleting Pictures", func() {
+
+		It("removes the picture from the recipe", func() {
+			id := createAndPersistDefaultRecipe(recipes)
+			defer recipes.Remove(id)
+			_, err := uploadPicture(id, "dish.png", pngHeader)
+			Expect(err).ToNot(HaveOccurred())
+
+			request, _ := http.NewRequest(http.MethodDelete, "http://localhost:8080/api/v1/recipes/r/"+id.String()+"/pictures/dish.png", nil)
+			resp, err := http.DefaultClient.Do(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusNoContent))
+			Expect(recipes.Get(id).PictureLink).ToNot(ContainElement("dish.png"))
+			Expect(recipes.Picture(id, "dish.png").ID).To(Equal(InvalidRecipeID()))
+		})
+
+		It("returns 404 when the picture does not exist", func() {
+			id := createAndPersistDefaultRecipe(recipes)
+			defer recipes.Remove(id)
+
+			request, _ := http.NewRequest(http.MethodDelete, "http://localhost:8080/api/v1/recipes/r/"+id.String()+"/pictures/missing.png", nil)
+			resp, err := http.DefaultClient.Do(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Context("Ordering Pictures", func() {
+
+		order := func(id RecipeID, body string) *http.Response {
+			request, _ := http.NewRequest(http.MethodPut, "http://localhost:8080/api/v1/recipes/r/"+id.String()+"/pictures/order", bytes.NewBufferString(body))
+			request.Header.Set("Content-Type", "application/json")
+			resp, err := http.DefaultClient.Do(request)
+			Expect(err).ToNot(HaveOccurred())
+			return resp
+		}
+
+		It("lists the primary picture first", func() {
+			id := createAndPersistDefaultRecipe(recipes)
+			defer recipes.Remove(id)
+			for _, name := range []string{"a.png", "b.png", "c.png"} {
+				_, err := uploadPicture(id, name, pngHeader)
+				Expect(err).ToNot(HaveOccurred())
+			}
+
+			resp := order(id, `{"names":["b.png","a.png"],"primary":"c.png"}`)
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			var links []string
+			Expect(json.NewDecoder(resp.Body).Decode(&links)).To(Succeed())
+			Expect(links).To(Equal([]string{"c.png", "b.png", "a.png"}))
+
+			resp, err := http.Get("http://localhost:8080/api/v1/recipes/r/" + id.String())
+			Expect(err).ToNot(HaveOccurred())
+			var recipe Recipe
+			Expect(json.NewDecoder(resp.Body).Decode(&recipe)).To(Succeed())
+			Expect(recipe.PictureLink).To(Equal(links))
+		})
+
+		It("rejects invalid orders", func() {
+			id := createAndPersistDefaultRecipe(recipes)
+			defer recipes.Remove(id)
+
+			Expect(order(id, `{"names":["a.png","a.png"]}`).StatusCode).To(Equal(http.StatusBadRequest))
+			Expect(order(id, `{"names":`).StatusCode).To(Equal(http.StatusBadRequest))
+		})
+
+		It("returns 404 when a picture or the recipe does not exist", func() {
+			id := createAndPersistDefaultRecipe(recipes)
+			defer recipes.Remove(id)
+
+			Expect(order(id, `{"primary":"missing.png"}`).StatusCode).To(Equal(http.StatusNotFound))
+			Expect(order(NewRecipeID(), `{"primary":"a.png"}`).StatusCode).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Context("Rating Recipes", func() {
+
+		It("returns the new average rating", func() {
+			id := createAndPersistDefaultRecipe(recipes)
+			defer recipes.Remove(id)
+
+			_, err := http.Post("http://localhost:8080/api/v1/recipes/r/"+id.String()+"/rating", "application/json", bytes.NewBufferString(`{"rating":4}`))
+			Expect(err).ToNot(HaveOccurred())
+			resp, err := http.Post("http://localhost:8080/api/v1/recipes/r/"+id.String()+"/rating", "application/json", bytes.NewBufferString(`{"rating":1}`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			var result RatingResult
+			err = json.NewDecoder(resp.Body).Decode(&result)
+			Expect(result.Rating).To(Equal(float32(2.5)))
+		})
+
+		It("rejects ratings outside of the valid range", func() {
+			id := createAndPersistDefaultRecipe(recipes)
+			defer recipes.Remove(id)
+
+			resp, err := http.Post("http://localhost:8080/api/v1/recipes/r/"+id.String()+"/rating", "application/json", bytes.NewBufferString(`{"rating":6}`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+		})
+
+		It("returns 404 when the recipe does not exist", func() {
+			resp, err := http.Post("http://localhost:8080/api/v1/recipes/r/"+NewRecipeID().String()+"/rating", "application/json", bytes.NewBufferString(`{"rating":3}`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Context("Notes", func() {
+
+		It("adds notes and returns them newest first", func() {
+			id := createAndPersistDefaultRecipe(recipes)
+			defer recipes.Remove(id)
+
+			resp, err := http.Post("http://localhost:8080/api/v1/recipes/r/"+id.String()+"/notes", "application/json", bytes.NewBufferString(`{"text":"less salt"}`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusCreated))
+			var created Note
+			err = json.NewDecoder(resp.Body).Decode(&created)
+			Expect(created.RecipeID).To(Equal(id))
+			Expect(created.Text).To(Equal("less salt"))
+			Expect(created.CreatedAt).ToNot(BeZero())
+
+			_, err = http.Post("http://localhost:8080/api/v1/recipes/r/"+id.String()+"/notes", "application/json", bytes.NewBufferString(`{"text":"more pepper"}`))
+			Expect(err).ToNot(HaveOccurred())
+
+			resp, err = http.Get("http://localhost:8080/api/v1/recipes/r/" + id.String() + "/notes")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			var notes []Note
+			err = json.NewDecoder(resp.Body).Decode(&notes)
+			Expect(notes).To(HaveLen(2))
+			Expect(notes[0].Text).To(Equal("more pepper"))
+			Expect(notes[1].ID).To(Equal(created.ID))
+		})
+
+		It("rejects empty notes", func() {
+			id := createAndPersistDefaultRecipe(recipes)
+			defer recipes.Remove(id)
+
+			resp, err := http.Post("http://localhost:8080/api/v1/recipes/r/"+id.String()+"/notes", "application/json", bytes.NewBufferString(`{"text":" "}`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+		})
+
+		It("returns 404 when the recipe does not exist", func() {
+			resp, err := http.Post("http://localhost:8080/api/v1/recipes/r/"+NewRecipeID().String()+"/notes", "application/json", bytes.NewBufferString(`{"text":"less salt"}`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+
+			resp, err = http.Get("http://localhost:8080/api/v1/recipes/r/" + NewRecipeID().String() + "/notes")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Context("PUT Recipes", func() {
+
+		It("persists a change to a recipe", func() {
+			recipes.Clear()
+
+			const POSTTEST = "PostTest"
+			const POSTDESCRIPTION = "Test \n 123"
+
+			recipe := Recipe{Servings: 2, Name: POSTTEST, Description: POSTDESCRIPTION}
+			recipeJSON, _ := json.Marshal(recipe)
+
+			_, err := http.Post("http://localhost:8080/api/v1/recipes", "application/json", bytes.NewBuffer(recipeJSON))
+			Expect(err).ToNot(HaveOccurred())
+
+			// update recipe
+			recipe.Servings = 3
+			recipe.Description = "updated"
+
+			// post updated recipe
+			client := &http.Client{}
+			recipeJSON, _ = json.Marshal(recipe)
+			retrievedRecipe, _ := recipes.GetByName(POSTTEST)
+			request, err := http.NewRequest(http.MethodPut, "http://localhost:8080/api/v1/recipes/r/"+retrievedRecipe.ID.String(), bytes.NewBuffer(recipeJSON))
+			request.Header.Set("Content-Type", "application/json")
+			resp, err := client.Do(request)
+			Expect(err).ToNot(HaveOccurred())
+
+			retrievedRecipe, err = recipes.GetByName(POSTTEST)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(resp.StatusCode).To(Equal(204))
+			Expect(retrievedRecipe.Servings).To(Equal(recipe.Servings))
+			Expect(retrievedRecipe.Description).To(Equal(recipe.Description))
+		})
+
+		It("rejects changes based on an outdated version", func() {
+			id := createAndPersistDefaultRecipe(recipes)
+			recipe := recipes.Get(id)
+
+			put := func(recipe *Recipe) *http.Response {
+				recipeJSON, _ := json.Marshal(recipe)
+				request, err := http.NewRequest(http.MethodPut, "http://localhost:8080/api/v1/recipes/r/"+id.String(), bytes.NewBuffer(recipeJSON))
+				Expect(err).ToNot(HaveOccurred())
+				request.Header.Set("Content-Type", "application/json")
+				resp, err := (&http.Client{}).Do(request)
+				Expect(err).ToNot(HaveOccurred())
+				return resp
+			}
+
+			resp := put(recipe)
+			Expect(resp.StatusCode).To(Equal(http.StatusNoContent))
+			Expect(resp.Header.Get("ETag")).To(HavePrefix(`"1-`))
+
+			recipe.Description = "outdated"
+			resp = put(recipe)
+			Expect(resp.StatusCode).To(Equal(http.StatusConflict))
+			Expect(recipes.Get(id).Description).To(Equal("details"))
+			Expect(recipes.Get(id).Version).To(Equal(1))
+		})
+
+		It("rejects invalid recipes", func() {
+			id := createAndPersistDefaultRecipe(recipes)
+
+			recipe := Recipe{Servings: 0, Name: "PutTest"}
+			recipeJSON, _ := json.Marshal(recipe)
+
+			client := &http.Client{}
+			request, err := http.NewRequest(http.MethodPut, "http://localhost:8080/api/v1/recipes/r/"+id.String(), bytes.NewBuffer(recipeJSON))
+			request.Header.Set("Content-Type", "application/json")
+			resp, err := client.Do(request)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+			Expect(recipes.Get(id).Name).To(Equal("retrieve recipe"))
+		})
+
+		It("returns 404 when the recipe does not exist", func() {
+			recipes.Clear()
+
+			recipe := Recipe{Servings: 2, Name: "PutTest"}
+			recipeJSON, _ := json.Marshal(recipe)
+
+			client := &http.Client{}
+			request, err := http.NewRequest(http.MethodPut, "http://localhost:8080/api/v1/recipes/r/"+NewRecipeID().String(), bytes.NewBuffer(recipeJSON))
+			request.Header.Set("Content-Type", "application/json")
+			resp, err := client.Do(request)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Context("Recipe Events", func() {
+
+		streamEvents := func(lastEventID string) (*http.Response, *bufio.Reader) {
+			client := &http.Client{Timeout: 5 * time.Second}
+			request, _ := http.NewRequest(http.MethodGet, "http://localhost:8080/api/v1/recipes/events", nil)
+			request.Header.Set("Accept", "text/event-stream")
+			request.Header.Set("Accept-Encoding", "gzip")
+			if lastEventID != "" {
+				request.Header.Set("Last-Event-ID", lastEventID)
+			}
+			resp, err := client.Do(request)
+			Expect(err).ToNot(HaveOccurred())
+			return resp, bufio.NewReader(resp.Body)
+		}
+
+		It("should stream changes of recipes and replay missed changes", func() {
+			created, deleted := NewRecipeID(), NewRecipeID()
+
+			resp, reader := streamEvents("")
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(resp.Header.Get("Content-Type")).To(HavePrefix("text/event-stream"))
+
+			rAPI.events.Notify(RecipeCreatedEvent, created)
+			event := readServerSentEvent(reader)
+			resp.Body.Close()
+			Expect(event["event"]).To(Equal(RecipeCreatedEvent))
+			var data RecipeEvent
+			Expect(json.Unmarshal([]byte(event["data"]), &data)).To(Succeed())
+			Expect(data.ID).To(Equal(created))
+
+			rAPI.events.Notify(RecipeDeletedEvent, deleted)
+
+			resp, reader = streamEvents(event["id"])
+			defer resp.Body.Close()
+			missed := readServerSentEvent(reader)
+			Expect(missed["event"]).To(Equal(RecipeDeletedEvent))
+			Expect(missed["data"]).To(ContainSubstring(deleted.String()))
+		})
+	})
+
+})
+
+//readServerSentEvent reads the fields of the next event of a stream, skipping comments
+func readServerSentEvent(reader *bufio.Reader) map[string]string {
+	event := make(map[string]string)
+	for {
+		line, err := reader.ReadString('\n')
+		Expect(err).ToNot(HaveOccurred())
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" && len(event) > 0 {
+			return event
+		}
+		if line == "" || strings.HasPrefix(line, ":") {
+			continue
+		}
+		parts := strings.SplitN(line, ": ", 2)
+		Expect(parts).To(HaveLen(2))
+		event[parts[0]] = parts[1]
+	}
+}
+
+// pngHeader is sufficient to be detected as image/png
+//panickingDB panics when a recipe is inserted, e.g., like a driver with a bug
+type panickingDB struct {
+	*InMemoryDB
+	panics bool
+}
+
+func (p *panickingDB) Insert(recipe *Recipe) error {
+	if p.panics {
+		panic("insert failed")
+	}
+	return p.InMemoryDB.Insert(recipe)
+}
+
+var pngHeader = []byte("\x89PNG\x0D\x0A\x1A\x0A")
+
+// pngOfSize creates a png image which consists of its header only, which suffices to read the dimensions of the image
+func pngOfSize(width uint32, height uint32) []byte {
+	ihdr := []byte("IHDR\x00\x00\x00\x00\x00\x00\x00\x00\x08\x06\x00\x00\x00")
+	binary.BigEndian.PutUint32(ihdr[4:], width)
+	binary.BigEndian.PutUint32(ihdr[8:], height)
+	var buf bytes.Buffer
+	buf.WriteString("\x89PNG\x0D\x0A\x1A\x0A")
+	_ = binary.Write(&buf, binary.BigEndian, uint32(len(ihdr)-4))
+	buf.Write(ihdr)
+	_ = binary.Write(&buf, binary.BigEndian, crc32.ChecksumIEEE(ihdr))
+	return buf.Bytes()
+}
+
+func uploadPicture(id RecipeID, name string, picture []byte) (*http.Response, error) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, _ := writer.CreateFormFile(PICTURE, name)
+	part.Write(picture)
+	writer.Close()
+
+	return http.Post("http://localhost:8080/api/v1/recipes/r/"+id.String()+"/pictures", writer.FormDataContentType(), body)
+}
 
 func createAndPersistNewRecipe(name string, description string, ingredient Ingredients, recipes RecipeDB) RecipeID {
 	id := NewRecipeID()
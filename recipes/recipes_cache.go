@@ -0,0 +1,325 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ottenwbe/recipes-manager/core"
+	"github.com/ottenwbe/recipes-manager/utils"
+)
+
+const (
+	// cacheEnabledCfg is the configuration key to cache recipes in front of the database
+	cacheEnabledCfg = "recipes.cache.enabled"
+	// cacheSizeCfg is the configuration key for the maximal number of cached entries
+	cacheSizeCfg = "recipes.cache.size"
+	// cacheTTLCfg is the configuration key for the time entries are cached, e.g., 30s
+	cacheTTLCfg = "recipes.cache.ttl"
+)
+
+var cacheSize int
+
+func init() {
+	utils.Config.SetDefault(cacheEnabledCfg, false)
+	utils.Config.SetDefault(cacheSizeCfg, 1000)
+	utils.Config.SetDefault(cacheTTLCfg, "1m")
+	cacheSize = int(utils.Config.GetInt64(cacheSizeCfg))
+}
+
+//CachedDB wraps a RecipeDB and caches the results of Get, Num, and IDs in an LRU cache.
+//Entries expire after a ttl; all entries are invalidated when recipes are written.
+//CachedDB is a prometheus.Collector for the number of cache hits and misses.
+type CachedDB struct {
+	RecipeDB
+	ttl   time.Duration
+	cache *lruCache
+
+	hits   *prometheus.CounterVec
+	misses *prometheus.CounterVec
+
+	//context of a view created by WithContext
+	context context.Context
+}
+
+//NewCachedDB wraps the inner database with a cache of the configured size
+func NewCachedDB(inner RecipeDB, ttl time.Duration) *CachedDB {
+	return &CachedDB{
+		RecipeDB: inner,
+		ttl:      ttl,
+		cache:    newLRUCache(cacheSize),
+		hits: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: core.MetricsNamespace(),
+			Name:      "recipes_cache_hits_total",
+			Help:      "Number of reads answered by the recipes cache.",
+		}, []string{"operation"}),
+		misses: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: core.MetricsNamespace(),
+			Name:      "recipes_cache_misses_total",
+			Help:      "Number of reads passed to the database by the recipes cache.",
+		}, []string{"operation"}),
+	}
+}
+
+//WithContext returns a view of the database whose reads of the inner database are cancelled with the context; the view shares the cache
+func (c *CachedDB) WithContext(ctx context.Context) RecipeDB {
+	view := *c
+	view.RecipeDB = c.RecipeDB.WithContext(ctx)
+	view.context = ctx
+	return &view
+}
+
+//Describe the cache metrics
+func (c *CachedDB) Describe(ch chan<- *prometheus.Desc) {
+	c.hits.Describe(ch)
+	c.misses.Describe(ch)
+}
+
+//Collect the cache metrics
+func (c *CachedDB) Collect(ch chan<- prometheus.Metric) {
+	c.hits.Collect(ch)
+	c.misses.Collect(ch)
+}
+
+//Get a recipe from the cache or the database
+func (c *CachedDB) Get(id RecipeID) *Recipe {
+	value := c.cached("get", "get:"+id.String(), func() interface{} {
+		return c.RecipeDB.Get(id)
+	})
+	// callers modify recipes, e.g., when scaling them
+	return value.(*Recipe).clone()
+}
+
+//GetMany recipes from the cache and the missing ones from the database
+func (c *CachedDB) GetMany(ids []RecipeID) []*Recipe {
+	recipes := make([]*Recipe, 0, len(ids))
+	for _, id := range ids {
+		if recipe := c.Get(id); recipe.ID != InvalidRecipeID() {
+			recipes = append(recipes, recipe)
+		}
+	}
+	return recipes
+}
+
+//Num returns the number of recipes from the cache or the database
+func (c *CachedDB) Num() int64 {
+	return c.cached("num", "num", func() interface{} {
+		return c.RecipeDB.Num()
+	}).(int64)
+}
+
+//IDs of all recipes matching the filter from the cache or the database
+func (c *CachedDB) IDs(filterQuery *RecipeSearchFilter) RecipeList {
+	key, err := json.Marshal(filterQuery)
+	if err != nil {
+		return c.RecipeDB.IDs(filterQuery)
+	}
+	ids := c.cached("ids", "ids:"+string(key), func() interface{} {
+		return c.RecipeDB.IDs(filterQuery)
+	}).(RecipeList)
+	return RecipeList{Recipes: append(make([]string, 0, len(ids.Recipes)), ids.Recipes...)}
+}
+
+//Insert a recipe and invalidate the cache
+func (c *CachedDB) Insert(recipe *Recipe) error {
+	defer c.cache.purge()
+	return c.RecipeDB.Insert(recipe)
+}
+
+//Update a recipe and invalidate the cache
+func (c *CachedDB) Update(id RecipeID, recipe *Recipe) error {
+	defer c.cache.purge()
+	return c.RecipeDB.Update(id, recipe)
+}
+
+//Remove a recipe and invalidate the cache
+func (c *CachedDB) Remove(id RecipeID) error {
+	defer c.cache.purge()
+	return c.RecipeDB.Remove(id)
+}
+
+//BulkTag tags recipes and invalidates the cache
+func (c *CachedDB) BulkTag(ids []RecipeID, add []string, remove []string) (map[RecipeID]bool, error) {
+	defer c.cache.purge()
+	return c.RecipeDB.BulkTag(ids, add, remove)
+}
+
+//Archive a recipe and invalidate the cache
+func (c *CachedDB) Archive(id RecipeID) error {
+	defer c.cache.purge()
+	return c.RecipeDB.Archive(id)
+}
+
+//Restore a recipe and invalidate the cache
+func (c *CachedDB) Restore(id RecipeID) error {
+	defer c.cache.purge()
+	return c.RecipeDB.Restore(id)
+}
+
+//RemoveByName removes a recipe and invalidates the cache
+func (c *CachedDB) RemoveByName(name string) error {
+	defer c.cache.purge()
+	return c.RecipeDB.RemoveByName(name)
+}
+
+//AddRating to a recipe and invalidate the cache
+func (c *CachedDB) AddRating(id RecipeID, rating int) (float32, error) {
+	defer c.cache.purge()
+	return c.RecipeDB.AddRating(id, rating)
+}
+
+//Merge duplicates into a recipe and invalidate the cache
+func (c *CachedDB) Merge(primary RecipeID, duplicates []RecipeID) (*Recipe, error) {
+	defer c.cache.purge()
+	return c.RecipeDB.Merge(primary, duplicates)
+}
+
+//AddPicture to a recipe and invalidate the cache, since the recipe's picture links change
+func (c *CachedDB) AddPicture(pic *RecipePicture) error {
+	defer c.cache.purge()
+	return c.RecipeDB.AddPicture(pic)
+}
+
+//DeletePicture of a recipe and invalidate the cache, since the recipe's picture links change
+func (c *CachedDB) DeletePicture(id RecipeID, name string) error {
+	defer c.cache.purge()
+	return c.RecipeDB.DeletePicture(id, name)
+}
+
+//SetPictureOrder of a recipe and invalidate the cache, since the recipe's picture links change
+func (c *CachedDB) SetPictureOrder(id RecipeID, names []string, primary string) ([]string, error) {
+	defer c.cache.purge()
+	return c.RecipeDB.SetPictureOrder(id, names, primary)
+}
+
+//Clear the database and the cache
+func (c *CachedDB) Clear() {
+	defer c.cache.purge()
+	c.RecipeDB.Clear()
+}
+
+func (c *CachedDB) cached(operation string, key string, load func() interface{}) interface{} {
+	if value, ok := c.cache.get(key); ok {
+		c.hits.WithLabelValues(operation).Inc()
+		return value
+	}
+	c.misses.WithLabelValues(operation).Inc()
+
+	generation := c.cache.currentGeneration()
+	value := load()
+	if c.context != nil && c.context.Err() != nil {
+		// the read was cancelled, i.e., the value is incomplete
+		return value
+	}
+	if recipe, ok := value.(*Recipe); ok {
+		value = recipe.clone()
+	}
+	c.cache.add(generation, key, value, time.Now().Add(c.ttl))
+	return value
+}
+
+//lruCache evicts the least recently used entry when it is full. It is safe for concurrent use.
+type lruCache struct {
+	mtx     sync.Mutex
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+	//generation is incremented with each purge, so that values loaded before a purge are not added afterwards
+	generation uint64
+}
+
+type lruEntry struct {
+	key     string
+	value   interface{}
+	expires time.Time
+}
+
+func newLRUCache(size int) *lruCache {
+	return &lruCache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+func (l *lruCache) get(key string) (interface{}, bool) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	element, ok := l.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*lruEntry)
+	if time.Now().After(entry.expires) {
+		l.order.Remove(element)
+		delete(l.entries, key)
+		return nil, false
+	}
+	l.order.MoveToFront(element)
+	return entry.value, true
+}
+
+func (l *lruCache) currentGeneration() uint64 {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	return l.generation
+}
+
+func (l *lruCache) add(generation uint64, key string, value interface{}, expires time.Time) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	if l.size <= 0 || generation != l.generation {
+		return
+	}
+	if element, ok := l.entries[key]; ok {
+		element.Value = &lruEntry{key: key, value: value, expires: expires}
+		l.order.MoveToFront(element)
+		return
+	}
+	if l.order.Len() >= l.size {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.entries, oldest.Value.(*lruEntry).key)
+	}
+	l.entries[key] = l.order.PushFront(&lruEntry{key: key, value: value, expires: expires})
+}
+
+func (l *lruCache) purge() {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	l.order.Init()
+	l.entries = make(map[string]*list.Element)
+	l.generation++
+}
@@ -0,0 +1,153 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ottenwbe/recipes-manager/core"
+)
+
+var _ = Describe("recipes cache", func() {
+
+	var (
+		inner  *InMemoryDB
+		cached *CachedDB
+		recipe *Recipe
+	)
+
+	BeforeEach(func() {
+		inner = NewInMemoryDB()
+		cached = NewCachedDB(inner, time.Minute)
+		recipe = NewRecipe(NewRecipeID())
+		recipe.Name = "soup"
+		Expect(cached.Insert(recipe)).To(Succeed())
+	})
+
+	It("should answer repeated reads from the cache", func() {
+		Expect(cached.Get(recipe.ID).Name).To(Equal("soup"))
+		Expect(cached.Num()).To(Equal(int64(1)))
+
+		changed := recipe.clone()
+		changed.Name = "stew"
+		Expect(inner.Update(recipe.ID, changed)).To(Succeed())
+		Expect(inner.Insert(NewRecipe(NewRecipeID()))).To(Succeed())
+
+		Expect(cached.Get(recipe.ID).Name).To(Equal("soup"))
+		Expect(cached.Num()).To(Equal(int64(1)))
+	})
+
+	It("should invalidate the cache on writes", func() {
+		Expect(cached.IDs(&RecipeSearchFilter{}).Recipes).To(HaveLen(1))
+
+		Expect(cached.Insert(NewRecipe(NewRecipeID()))).To(Succeed())
+		Expect(cached.IDs(&RecipeSearchFilter{}).Recipes).To(HaveLen(2))
+
+		changed := recipe.clone()
+		changed.Name = "stew"
+		Expect(cached.Update(recipe.ID, changed)).To(Succeed())
+		Expect(cached.Get(recipe.ID).Name).To(Equal("stew"))
+
+		Expect(cached.Remove(recipe.ID)).To(Succeed())
+		Expect(cached.Get(recipe.ID).ID).To(Equal(InvalidRecipeID()))
+	})
+
+	It("should not share cached recipes with callers", func() {
+		cached.Get(recipe.ID).Name = "changed"
+		Expect(cached.Get(recipe.ID).Name).To(Equal("soup"))
+	})
+
+	It("should share the cache with views of a context", func() {
+		Expect(cached.WithContext(context.Background()).Num()).To(Equal(int64(1)))
+		Expect(inner.Insert(NewRecipe(NewRecipeID()))).To(Succeed())
+
+		Expect(cached.Num()).To(Equal(int64(1)))
+	})
+
+	It("should not cache reads of cancelled views", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		Expect(cached.WithContext(ctx).Num()).To(Equal(int64(1)))
+		Expect(inner.Insert(NewRecipe(NewRecipeID()))).To(Succeed())
+
+		Expect(cached.Num()).To(Equal(int64(2)))
+	})
+
+	It("should expire entries after the ttl", func() {
+		cached = NewCachedDB(inner, time.Millisecond)
+		Expect(cached.Num()).To(Equal(int64(1)))
+		Expect(inner.Insert(NewRecipe(NewRecipeID()))).To(Succeed())
+
+		time.Sleep(5 * time.Millisecond)
+		Expect(cached.Num()).To(Equal(int64(2)))
+	})
+
+	It("should evict the least recently used entry", func() {
+		cache := newLRUCache(2)
+		expires := time.Now().Add(time.Minute)
+		cache.add(0, "a", 1, expires)
+		cache.add(0, "b", 2, expires)
+		cache.get("a")
+		cache.add(0, "c", 3, expires)
+
+		_, ok := cache.get("b")
+		Expect(ok).To(BeFalse())
+		value, ok := cache.get("a")
+		Expect(ok).To(BeTrue())
+		Expect(value).To(Equal(1))
+		value, ok = cache.get("c")
+		Expect(ok).To(BeTrue())
+		Expect(value).To(Equal(3))
+	})
+
+	It("should not add values loaded before a purge", func() {
+		cache := newLRUCache(2)
+		generation := cache.currentGeneration()
+		cache.purge()
+		cache.add(generation, "a", 1, time.Now().Add(time.Minute))
+
+		_, ok := cache.get("a")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should count hits and misses", func() {
+		cached.Get(recipe.ID)
+		cached.Get(recipe.ID)
+
+		handler := core.NewHandler()
+		Expect(handler.RegisterMetrics(cached)).To(Succeed())
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+		Expect(w.Body.String()).To(ContainSubstring(`recipes_manager_recipes_cache_hits_total{operation="get"} 1`))
+		Expect(w.Body.String()).To(ContainSubstring(`recipes_manager_recipes_cache_misses_total{operation="get"} 1`))
+	})
+})
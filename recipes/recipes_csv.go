@@ -0,0 +1,409 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+//Columns of CSV files with recipes. Each row describes one ingredient of a recipe, the recipe's other fields are repeated in each of its rows.
+const (
+	CSVName        = "name"
+	CSVServings    = "servings"
+	CSVPrepMinutes = "prepMinutes"
+	CSVCookMinutes = "cookMinutes"
+	CSVDifficulty  = "difficulty"
+	//CSVTags are separated by semicolons
+	CSVTags        = "tags"
+	CSVDescription = "description"
+	//CSVSteps are the texts of the steps separated by line breaks; the minutes of steps are not exported
+	CSVSteps = "steps"
+	//CSVIngredient is empty in the only row of a recipe without ingredients
+	CSVIngredient = "ingredient"
+	//CSVAmount is empty for ingredients without an amount, i.e., NoAmountIngredient
+	CSVAmount    = "amount"
+	CSVAmountMax = "amountMax"
+	CSVUnit      = "unit"
+	CSVGroup     = "group"
+)
+
+//csvColumns in the order they are exported
+var csvColumns = []string{CSVName, CSVServings, CSVPrepMinutes, CSVCookMinutes, CSVDifficulty, CSVTags, CSVDescription, CSVSteps,
+	CSVIngredient, CSVAmount, CSVAmountMax, CSVUnit, CSVGroup}
+
+//csvTagSeparator separates the tags of a recipe in the tags column
+const csvTagSeparator = ";"
+
+//csvFormulaPrefixes start cells which spreadsheets evaluate as formulas
+const csvFormulaPrefixes = "=+-@\t\r"
+
+//csvFormulaGuard is written in front of cells which would be evaluated as formulas, so that spreadsheets show them as text
+const csvFormulaGuard = "'"
+
+//ErrInvalidCSVHeader is returned when the first row of a CSV file does not name the columns of recipes
+var ErrInvalidCSVHeader = errors.New("invalid CSV header")
+
+//CSVRowError describes why a row of an imported CSV file was rejected
+type CSVRowError struct {
+	//Row is the number of the record in the file, starting with 1 for the header
+	Row int `json:"row"`
+	//Recipe is the name of the recipe the row belongs to; empty if the row could not be read
+	Recipe  string `json:"recipe,omitempty"`
+	Message string `json:"message"`
+}
+
+//CSVRecipe is a recipe read from a CSV file
+type CSVRecipe struct {
+	//Row is the first row of the recipe
+	Row    int
+	Recipe *Recipe
+}
+
+//CSVImportResult lists the recipes that have been imported and the rows that have been rejected
+type CSVImportResult struct {
+	Imported []RecipeID    `json:"imported"`
+	Errors   []CSVRowError `json:"errors"`
+}
+
+//WriteRecipesCSV writes the recipes with one row per ingredient, starting with a header that names the columns.
+//Ids, pictures, ratings, and nutrition are not written, so that the file can be imported as new recipes.
+//Texts which spreadsheets would evaluate as formulas, e.g., =1+1, are prefixed with an apostrophe.
+func WriteRecipesCSV(w io.Writer, recipes []*Recipe) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvColumns); err != nil {
+		return err
+	}
+
+	for _, recipe := range recipes {
+		ingredients := recipe.Ingredients
+		if len(ingredients) == 0 {
+			// a recipe without ingredients still needs a row
+			ingredients = []Ingredients{{}}
+		}
+		for _, ingredient := range ingredients {
+			if err := writer.Write(csvRow(recipe, ingredient)); err != nil {
+				return err
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func csvRow(recipe *Recipe, ingredient Ingredients) []string {
+	steps := make([]string, len(recipe.Steps))
+	for i, step := range recipe.Steps {
+		steps[i] = step.Text
+	}
+
+	amount, amountMax := "", ""
+	if ingredient.Name != "" && ingredient.Amount != NoAmountIngredient {
+		amount = strconv.FormatFloat(ingredient.Amount, 'f', -1, 64)
+	}
+	if ingredient.AmountMax > 0 {
+		amountMax = strconv.FormatFloat(ingredient.AmountMax, 'f', -1, 64)
+	}
+
+	return []string{
+		csvText(recipe.Name),
+		strconv.Itoa(int(recipe.Servings)),
+		strconv.Itoa(recipe.PrepMinutes),
+		strconv.Itoa(recipe.CookMinutes),
+		csvText(recipe.Difficulty),
+		csvText(strings.Join(recipe.Tags, csvTagSeparator)),
+		csvText(recipe.Description),
+		csvText(strings.Join(steps, "\n")),
+		csvText(ingredient.Name),
+		amount,
+		amountMax,
+		csvText(ingredient.Unit),
+		csvText(ingredient.Group),
+	}
+}
+
+//csvText guards texts which spreadsheets would evaluate as formulas
+func csvText(text string) string {
+	if text != "" && strings.ContainsRune(csvFormulaPrefixes, rune(text[0])) {
+		return csvFormulaGuard + text
+	}
+	return text
+}
+
+//csvCell trims a cell and removes the guard of csvText
+func csvCell(value string) string {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, csvFormulaGuard) && len(value) > 1 && strings.ContainsRune(csvFormulaPrefixes, rune(value[1])) {
+		return strings.TrimSpace(value[1:])
+	}
+	return value
+}
+
+//ReadRecipesCSV reads recipes in the layout written by WriteRecipesCSV. Rows are grouped into recipes by the name column;
+//the other fields of a recipe are taken from its first row. Columns are identified by the header, may be in any order,
+//and only name and ingredient are mandatory; unknown columns are ignored. Amounts may use a decimal comma.
+//Recipes with an invalid row or which do not pass Validate are not returned, the reasons are returned as row errors instead.
+//Rows which are no valid CSV fail the recipe they belong to, if its name can be read at all.
+//An error is only returned if the header is invalid or the file cannot be read.
+func ReadRecipesCSV(r io.Reader) ([]CSVRecipe, []CSVRowError, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	reader := csv.NewReader(bytes.NewReader(data))
+	// spreadsheets tend to drop empty cells at the end of rows
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil, fmt.Errorf("%w: the file is empty", ErrInvalidCSVHeader)
+	} else if err != nil {
+		return nil, nil, csvReadError(err)
+	}
+	columns, err := csvHeader(header)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	recipes := make([]CSVRecipe, 0)
+	positions := make(map[string]int)
+	failed := make(map[string]bool)
+	rowErrors := make([]CSVRowError, 0)
+
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if parseErr, ok := err.(*csv.ParseError); ok {
+			// the rows of the recipe which can be read must not be imported as a partial recipe
+			name := csvRecordName(data, parseErr, columns)
+			if name != "" {
+				failed[name] = true
+			}
+			rowErrors = append(rowErrors, CSVRowError{Row: row, Recipe: name, Message: parseErr.Err.Error()})
+			continue
+		} else if err != nil {
+			return nil, nil, err
+		}
+
+		cell := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(record) {
+				return csvCell(record[i])
+			}
+			return ""
+		}
+		if blankRecord(record) {
+			continue
+		}
+
+		name := cell(CSVName)
+		if name == "" {
+			rowErrors = append(rowErrors, CSVRowError{Row: row, Message: "name must not be empty"})
+			continue
+		}
+
+		pos, ok := positions[name]
+		if !ok {
+			recipe, err := csvRecipe(cell)
+			if err != nil {
+				failed[name] = true
+				rowErrors = append(rowErrors, CSVRowError{Row: row, Recipe: name, Message: err.Error()})
+			}
+			pos = len(recipes)
+			positions[name] = pos
+			recipes = append(recipes, CSVRecipe{Row: row, Recipe: recipe})
+		}
+
+		ingredient, ok, err := csvIngredient(cell)
+		if err != nil {
+			failed[name] = true
+			rowErrors = append(rowErrors, CSVRowError{Row: row, Recipe: name, Message: err.Error()})
+		} else if ok {
+			recipes[pos].Recipe.Ingredients = append(recipes[pos].Recipe.Ingredients, ingredient)
+		}
+	}
+
+	valid := make([]CSVRecipe, 0, len(recipes))
+	for _, recipe := range recipes {
+		if failed[recipe.Recipe.Name] {
+			continue
+		}
+		if err := recipe.Recipe.Validate(); err != nil {
+			rowErrors = append(rowErrors, CSVRowError{Row: recipe.Row, Recipe: recipe.Recipe.Name, Message: err.Error()})
+			continue
+		}
+		valid = append(valid, recipe)
+	}
+	return valid, rowErrors, nil
+}
+
+//csvHeader maps the known columns to their positions, ignoring the case
+func csvHeader(header []string) (map[string]int, error) {
+	known := make(map[string]string, len(csvColumns))
+	for _, column := range csvColumns {
+		known[strings.ToLower(column)] = column
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, title := range header {
+		if i == 0 {
+			// spreadsheets may start files with a byte order mark
+			title = strings.TrimPrefix(title, "\ufeff")
+		}
+		column, ok := known[strings.ToLower(strings.TrimSpace(title))]
+		if !ok {
+			continue
+		}
+		if _, duplicate := columns[column]; duplicate {
+			return nil, fmt.Errorf("%w: column '%v' is named twice", ErrInvalidCSVHeader, column)
+		}
+		columns[column] = i
+	}
+
+	for _, column := range []string{CSVName, CSVIngredient} {
+		if _, ok := columns[column]; !ok {
+			return nil, fmt.Errorf("%w: column '%v' is missing", ErrInvalidCSVHeader, column)
+		}
+	}
+	return columns, nil
+}
+
+//csvReadError turns syntax errors in the header into ErrInvalidCSVHeader and passes errors of the reader through, e.g., a too large body
+func csvReadError(err error) error {
+	if parseErr, ok := err.(*csv.ParseError); ok {
+		return fmt.Errorf("%w: %v", ErrInvalidCSVHeader, parseErr.Err)
+	}
+	return err
+}
+
+//csvRecordName reads the name of a record which is no valid CSV from the lines of the record, tolerating quotes within fields
+func csvRecordName(data []byte, parseErr *csv.ParseError, columns map[string]int) string {
+	lines := bytes.Split(data, []byte("\n"))
+	if parseErr.StartLine < 1 || parseErr.StartLine > parseErr.Line || parseErr.Line > len(lines) {
+		return ""
+	}
+	reader := csv.NewReader(bytes.NewReader(bytes.Join(lines[parseErr.StartLine-1:parseErr.Line], []byte("\n"))))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	record, err := reader.Read()
+	if i := columns[CSVName]; err == nil && i < len(record) {
+		return csvCell(record[i])
+	}
+	return ""
+}
+
+func blankRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+//csvRecipe creates a recipe without ingredients from the cells of its first row
+func csvRecipe(cell func(string) string) (*Recipe, error) {
+	recipe := NewRecipe(InvalidRecipeID())
+	recipe.Name = cell(CSVName)
+	recipe.Difficulty = cell(CSVDifficulty)
+	recipe.Description = cell(CSVDescription)
+
+	if servings := cell(CSVServings); servings != "" {
+		value, err := strconv.ParseInt(servings, 10, 8)
+		if err != nil {
+			return recipe, fmt.Errorf("%v must be a number between 1 and 127, not '%v'", CSVServings, servings)
+		}
+		recipe.Servings = int8(value)
+	}
+	minutes := []struct {
+		column string
+		value  *int
+	}{{CSVPrepMinutes, &recipe.PrepMinutes}, {CSVCookMinutes, &recipe.CookMinutes}}
+	for _, m := range minutes {
+		if value := cell(m.column); value != "" {
+			number, err := strconv.Atoi(value)
+			if err != nil {
+				return recipe, fmt.Errorf("%v must be a number, not '%v'", m.column, value)
+			}
+			*m.value = number
+		}
+	}
+
+	for _, tag := range strings.Split(cell(CSVTags), csvTagSeparator) {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			recipe.Tags = append(recipe.Tags, tag)
+		}
+	}
+	for _, line := range strings.Split(cell(CSVSteps), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			recipe.Steps = append(recipe.Steps, RecipeStep{Text: line})
+		}
+	}
+	return recipe, nil
+}
+
+//csvIngredient reads the ingredient of a row; ok is false if the row has no ingredient
+func csvIngredient(cell func(string) string) (ingredient Ingredients, ok bool, err error) {
+	ingredient = Ingredients{
+		Name:   cell(CSVIngredient),
+		Amount: NoAmountIngredient,
+		Unit:   cell(CSVUnit),
+		Group:  cell(CSVGroup),
+	}
+	amount, amountMax := cell(CSVAmount), cell(CSVAmountMax)
+	if ingredient.Name == "" && amount == "" && amountMax == "" && ingredient.Unit == "" && ingredient.Group == "" {
+		return ingredient, false, nil
+	}
+	if ingredient.Name == "" {
+		return ingredient, false, fmt.Errorf("%v must not be empty", CSVIngredient)
+	}
+
+	if amount != "" {
+		if ingredient.Amount, err = parseCSVAmount(amount); err != nil {
+			return ingredient, false, fmt.Errorf("%v must be a number, not '%v'", CSVAmount, amount)
+		}
+	}
+	if amountMax != "" {
+		if ingredient.AmountMax, err = parseCSVAmount(amountMax); err != nil {
+			return ingredient, false, fmt.Errorf("%v must be a number, not '%v'", CSVAmountMax, amountMax)
+		}
+	}
+	return ingredient, true, nil
+}
+
+//parseCSVAmount accepts decimal points as well as decimal commas, e.g., '1.5' and '1,5'
+func parseCSVAmount(amount string) (float64, error) {
+	if !strings.Contains(amount, ".") {
+		amount = strings.Replace(amount, ",", ".", 1)
+	}
+	return strconv.ParseFloat(amount, 64)
+}
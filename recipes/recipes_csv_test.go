@@ -0,0 +1,148 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	"bytes"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("recipes csv", func() {
+
+	pie := func() *Recipe {
+		recipe := NewRecipe(NewRecipeID())
+		recipe.Name = `Apple "Grandma's" Pie, deluxe`
+		recipe.Servings = 8
+		recipe.PrepMinutes = 30
+		recipe.CookMinutes = 45
+		recipe.Difficulty = DifficultyMedium
+		recipe.Tags = []string{"baking", "sweet"}
+		recipe.Description = "Bake it,\nthen serve it"
+		recipe.Steps = []RecipeStep{{Text: "Peel the apples"}, {Text: "Bake, until golden"}}
+		recipe.Ingredients = []Ingredients{
+			{Name: "Flour", Amount: 250, Unit: "g", Group: "For the dough"},
+			{Name: "Apples", Amount: 3, AmountMax: 4},
+			{Name: "Cinnamon", Amount: NoAmountIngredient},
+		}
+		return recipe
+	}
+
+	read := func(csv string) ([]CSVRecipe, []CSVRowError) {
+		recipes, rowErrors, err := ReadRecipesCSV(strings.NewReader(csv))
+		Expect(err).ToNot(HaveOccurred())
+		return recipes, rowErrors
+	}
+
+	It("writes one row per ingredient", func() {
+		var buffer bytes.Buffer
+		Expect(WriteRecipesCSV(&buffer, []*Recipe{pie()})).To(Succeed())
+
+		lines := strings.Split(buffer.String(), "\n")
+		Expect(lines[0]).To(Equal("name,servings,prepMinutes,cookMinutes,difficulty,tags,description,steps,ingredient,amount,amountMax,unit,group"))
+		Expect(buffer.String()).To(ContainSubstring(`"Apple ""Grandma's"" Pie, deluxe",8,30,45,medium,baking;sweet,"Bake it,`))
+		Expect(buffer.String()).To(HaveSuffix(",Apples,3,4,,\n" + `"Apple ""Grandma's"" Pie, deluxe",8,30,45,medium,baking;sweet,"Bake it,` + "\nthen serve it\",\"Peel the apples\nBake, until golden\",Cinnamon,,,,\n"))
+	})
+
+	It("reads the recipes it writes", func() {
+		soup := NewRecipe(NewRecipeID())
+		soup.Name = "Soup"
+		var buffer bytes.Buffer
+		Expect(WriteRecipesCSV(&buffer, []*Recipe{pie(), soup})).To(Succeed())
+
+		recipes, rowErrors := read(buffer.String())
+		Expect(rowErrors).To(BeEmpty())
+		Expect(recipes).To(HaveLen(2))
+
+		expected := pie()
+		expected.ID = InvalidRecipeID()
+		Expect(recipes[0].Row).To(Equal(2))
+		Expect(recipes[0].Recipe).To(Equal(expected))
+		Expect(recipes[1].Row).To(Equal(5))
+		Expect(recipes[1].Recipe.Name).To(Equal("Soup"))
+		Expect(recipes[1].Recipe.Ingredients).To(BeEmpty())
+	})
+
+	It("groups rows by name and accepts columns in any order", func() {
+		recipes, rowErrors := read("\ufeffIngredient,Name,Amount,Notes\nFlour,Bread,\"1,5\",fine\nSalt,Pasta,,\nWater,Bread,300\n")
+		Expect(rowErrors).To(BeEmpty())
+		Expect(recipes).To(HaveLen(2))
+		Expect(recipes[0].Recipe.Name).To(Equal("Bread"))
+		Expect(recipes[0].Recipe.Ingredients).To(Equal([]Ingredients{{Name: "Flour", Amount: 1.5}, {Name: "Water", Amount: 300}}))
+		Expect(recipes[1].Recipe.Ingredients).To(Equal([]Ingredients{{Name: "Salt", Amount: NoAmountIngredient}}))
+	})
+
+	It("reports invalid rows and skips their recipes", func() {
+		recipes, rowErrors := read("name,servings,ingredient,amount\nBread,2,Flour,a lot\nBread,2,Water,300\n,2,Salt,1\nPasta,0,Noodles,500\nSoup,2,Water,1\n")
+		Expect(recipes).To(HaveLen(1))
+		Expect(recipes[0].Recipe.Name).To(Equal("Soup"))
+
+		Expect(rowErrors).To(HaveLen(3))
+		Expect(rowErrors[0]).To(Equal(CSVRowError{Row: 2, Recipe: "Bread", Message: "amount must be a number, not 'a lot'"}))
+		Expect(rowErrors[1]).To(Equal(CSVRowError{Row: 4, Message: "name must not be empty"}))
+		Expect(rowErrors[2].Row).To(Equal(5))
+		Expect(rowErrors[2].Message).To(ContainSubstring("servings must be positive"))
+	})
+
+	It("reports rows which are no valid CSV", func() {
+		recipes, rowErrors := read("name,ingredient\nBread,Fl\"our\nSoup,Water\n")
+		Expect(recipes).To(HaveLen(1))
+		Expect(rowErrors).To(HaveLen(1))
+		Expect(rowErrors[0].Row).To(Equal(2))
+	})
+
+	It("fails the recipes of rows which are no valid CSV", func() {
+		recipes, rowErrors := read("name,ingredient\nBread,Flour\nBread,Wa\"ter\nSoup,Water\n")
+		Expect(recipes).To(HaveLen(1))
+		Expect(recipes[0].Recipe.Name).To(Equal("Soup"))
+		Expect(rowErrors).To(HaveLen(1))
+		Expect(rowErrors[0].Row).To(Equal(3))
+		Expect(rowErrors[0].Recipe).To(Equal("Bread"))
+	})
+
+	It("guards texts which spreadsheets would evaluate as formulas", func() {
+		recipe := NewRecipe(InvalidRecipeID())
+		recipe.Name = `=HYPERLINK("http://evil.example")`
+		recipe.Servings = 2
+		recipe.Tags = []string{"+quick", "-1"}
+		recipe.Ingredients = []Ingredients{{Name: "@salt", Amount: 2, Unit: "-"}}
+		var buffer bytes.Buffer
+		Expect(WriteRecipesCSV(&buffer, []*Recipe{recipe})).To(Succeed())
+
+		Expect(strings.Split(buffer.String(), "\n")[1]).To(Equal(`"'=HYPERLINK(""http://evil.example"")",2,0,0,,'+quick;-1,,,'@salt,2,,'-,`))
+		recipes, rowErrors := read(buffer.String())
+		Expect(rowErrors).To(BeEmpty())
+		Expect(recipes[0].Recipe).To(Equal(recipe))
+	})
+
+	It("rejects files without the mandatory columns", func() {
+		for _, csv := range []string{"", "name,amount\nBread,1\n", "name,ingredient,name\n"} {
+			_, _, err := ReadRecipesCSV(strings.NewReader(csv))
+			Expect(err).To(MatchError(ErrInvalidCSVHeader), csv)
+		}
+	})
+})
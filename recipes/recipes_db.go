@@ -25,13 +25,85 @@
 package recipes
 
 import (
+	"context"
+	"errors"
 	"io"
+	"time"
+)
+
+var (
+	//ErrRecipeNotFound is returned when an operation targets a recipe that does not exist
+	ErrRecipeNotFound = errors.New("could not find recipe")
+	//ErrPictureNotFound is returned when an operation targets a picture that does not exist
+	ErrPictureNotFound = errors.New("could not find picture")
+	//ErrVersionConflict is returned when a recipe is updated based on an outdated version
+	ErrVersionConflict = errors.New("recipe was modified concurrently")
 )
 
 //RecipeDB is the interface that all DB implementations have to expose
 type RecipeDB interface {
 	io.Closer
 	Recipes
+	NotesDB
+	//IDsPaged lists at most limit ids of recipes matching the filter, skipping the first offset ids
+	IDsPaged(filterQuery *RecipeSearchFilter, offset int64, limit int64) RecipeList
+	//Summaries lists at most limit summaries of recipes matching the filter, skipping the first offset recipes
+	Summaries(filterQuery *RecipeSearchFilter, offset int64, limit int64) RecipeSummaries
+	//IDsSorted lists the ids of all recipes sorted by the field, e.g., SortByName, in the given order, e.g., OrderAscending
+	IDsSorted(field string, order string) RecipeList
+	//IDsChangedSince lists the ids of all recipes which were updated after t
+	IDsChangedSince(t time.Time) RecipeList
+	//LastModified returns the time of the latest change of the recipes, i.e., the latest UpdatedAt or removal of a recipe; zero if there was no change yet
+	LastModified() time.Time
+	//FindByMaxTime lists the ids of all recipes which can be prepared and cooked in the given minutes; recipes without times are omitted
+	FindByMaxTime(minutes int) RecipeList
+	//GetMany returns all existing recipes out of the given ids; missing recipes are omitted
+	GetMany(ids []RecipeID) []*Recipe
+	//Count the recipes matching the filter
+	Count(filterQuery *RecipeSearchFilter) int64
+	//FindByTag lists the ids of all recipes carrying the tag, ignoring the case
+	FindByTag(tag string) RecipeList
+	//Search recipes by their name, description, and ingredients. All terms of the query have to match; results are ordered by relevance.
+	Search(query string) []RecipeSearchResult
+	//SuggestIngredients lists at most limit distinct ingredient names starting with the prefix, ignoring the case, in alphabetical order.
+	//The names are normalized, i.e., in lower case and without redundant spaces. Archived recipes are not considered.
+	SuggestIngredients(prefix string, limit int) []string
+	//Tags lists all distinct tags in lower case and the number of recipes carrying them, the most used tag first; archived recipes are not counted
+	Tags() ([]TagCount, error)
+	//Stats aggregates the number of recipes, their average servings, their tags, and their most used ingredients; archived recipes are not counted
+	Stats() (*RecipeStats, error)
+	//FindSimilar lists the recipes whose ingredient names overlap with the ingredient names of the recipe by at least the threshold, most similar first.
+	//Archived recipes are never listed as similar. ErrRecipeNotFound is returned if there is no such recipe.
+	FindSimilar(id RecipeID, threshold float64) ([]SimilarRecipe, error)
+	//RandomFiltered returns a random recipe out of all recipes that match the filter; the recipe's id is InvalidRecipeID if none matches
+	RandomFiltered(filterQuery *RecipeSearchFilter) *Recipe
+	//RandomSeeded picks a recipe like RandomFiltered, but the same seed and the same recipes always yield the same recipe
+	RandomSeeded(filterQuery *RecipeSearchFilter, seed int64) *Recipe
+	//Merge the pictures, notes, and ratings of the duplicates into the primary recipe, remove the duplicates, and return the merged recipe.
+	//A picture is dropped if the primary recipe or a preceding duplicate has a picture with the same name.
+	//An error wrapping ErrRecipeNotFound is returned if one of the recipes does not exist; the recipes are not changed in this case.
+	//The merge is atomic, except for MongoDB deployments without transactions, i.e., standalone servers: there, a failed merge
+	//may be applied partially and has to be repeated with the same recipes to complete it.
+	Merge(primary RecipeID, duplicates []RecipeID) (*Recipe, error)
+	//BulkTag removes the tags to remove from and then adds the tags to add to all recipes, ignoring the case.
+	//The result tells for each id if the recipe exists; missing recipes do not stop the others from being tagged.
+	//The version of a recipe is incremented if its tags change.
+	BulkTag(ids []RecipeID, add []string, remove []string) (map[RecipeID]bool, error)
+	//Archive a recipe, i.e., exclude it from listings and searches without removing it.
+	//ErrRecipeNotFound is returned if there is no such recipe.
+	Archive(id RecipeID) error
+	//Restore an archived recipe, i.e., list it again. ErrRecipeNotFound is returned if there is no such recipe.
+	Restore(id RecipeID) error
+	//AddRating to a recipe and return the recipe's new average rating
+	AddRating(id RecipeID, rating int) (float32, error)
+	//DeletePicture of a recipe and remove it from the recipe's picture links
+	DeletePicture(id RecipeID, name string) error
+	//SetPictureOrder rearranges the picture links of a recipe, i.e., the primary picture first, then the other named pictures, then the remaining ones.
+	//The new picture links are returned. ErrRecipeNotFound is returned if there is no such recipe and an error wrapping ErrPictureNotFound if a named picture is not linked.
+	SetPictureOrder(id RecipeID, names []string, primary string) ([]string, error)
+	//WithContext returns a view of the database whose operations are cancelled with the context, e.g., when a request times out.
+	//The view must not be closed.
+	WithContext(ctx context.Context) RecipeDB
 	Ping() error
 	Clear()
 }
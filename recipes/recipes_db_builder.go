@@ -24,9 +24,110 @@
 
 package recipes
 
-//NewDatabaseClient builds a client to communicate with a database
-func NewDatabaseClient() (RecipeDB, error) {
+import (
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/ottenwbe/recipes-manager/utils"
+)
+
+const (
+	// memEnabledCfg is the configuration key to keep recipes, meal plans, and favorites in memory instead of a database
+	memEnabledCfg = "recipes.mem.enabled"
+	// memSeedFileCfg is the configuration key for a JSON file with recipes to load into memory on startup
+	memSeedFileCfg = "recipes.mem.seedFile"
+	// postgresDSNCfg is the configuration key for the PostgreSQL database; recipes are stored in MongoDB if it is empty
+	postgresDSNCfg = "recipes.postgres.dsn"
+	// postgresAutoMigrateCfg is the configuration key to create the PostgreSQL tables on startup
+	postgresAutoMigrateCfg = "recipes.postgres.automigrate"
+)
+
+func init() {
+	utils.Config.SetDefault(memEnabledCfg, false)
+	utils.Config.SetDefault(memSeedFileCfg, "")
+	utils.Config.SetDefault(postgresDSNCfg, "")
+	utils.Config.SetDefault(postgresAutoMigrateCfg, false)
+}
+
+//NewDatabaseClient builds a client to communicate with a database; the client keeps pictures in the configured PictureStore,
+//publishes changes to the events and the configured webhooks, and caches recipes if the cache is enabled
+func NewDatabaseClient(events *RecipeEvents) (RecipeDB, error) {
+	db, err := newDatabaseClient()
+	if err != nil {
+		return db, err
+	}
+	store, err := NewPictureStore()
+	if err != nil {
+		return db, err
+	}
+	if store != nil {
+		db = NewPictureStoreDB(db, store)
+	}
+	if webhooks := NewWebhooksFromConfig(); webhooks != nil {
+		db = NewEventDB(db, events, webhooks)
+	} else {
+		db = NewEventDB(db, events)
+	}
+	if !utils.Config.GetBool(cacheEnabledCfg) {
+		return db, nil
+	}
+	ttl, err := time.ParseDuration(utils.Config.GetString(cacheTTLCfg))
+	if err != nil {
+		return db, fmt.Errorf("invalid %v: %w", cacheTTLCfg, err)
+	}
+	return NewCachedDB(db, ttl), nil
+}
+
+func newDatabaseClient() (RecipeDB, error) {
+	if utils.Config.GetBool(memEnabledCfg) {
+		return newSeededInMemoryDB(utils.Config.GetString(memSeedFileCfg))
+	}
+	if dsn := utils.Config.GetString(postgresDSNCfg); dsn != "" {
+		return newPostgresDB(dsn, utils.Config.GetBool(postgresAutoMigrateCfg))
+	}
 	m := &MongoRecipeDB{}
 	err := m.StartDB()
 	return m, err
 }
+
+//NewMealPlanDatabaseClient builds a client to communicate with a database for meal plans
+func NewMealPlanDatabaseClient() (MealPlanDB, error) {
+	if utils.Config.GetBool(memEnabledCfg) {
+		return NewInMemoryMealPlanDB(), nil
+	}
+	m := &MongoMealPlanDB{}
+	err := m.StartDB()
+	return m, err
+}
+
+//NewFavoritesDatabaseClient builds a client to communicate with a database for favorites
+func NewFavoritesDatabaseClient() (FavoritesDB, error) {
+	if utils.Config.GetBool(memEnabledCfg) {
+		return NewInMemoryFavoritesDB(), nil
+	}
+	m := &MongoFavoritesDB{}
+	err := m.StartDB()
+	return m, err
+}
+
+func newSeededInMemoryDB(seedFile string) (RecipeDB, error) {
+	log.Warn("Recipes are kept in memory and are lost when the application stops")
+	m := NewInMemoryDB()
+	if seedFile == "" {
+		return m, nil
+	}
+	return m, m.SeedFromFile(seedFile)
+}
+
+func newPostgresDB(dsn string, autoMigrate bool) (RecipeDB, error) {
+	p, err := NewPostgresDB(dsn)
+	if err != nil {
+		return nil, err
+	}
+	if autoMigrate {
+		err = p.Migrate()
+	}
+	return p, err
+}
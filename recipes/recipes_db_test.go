@@ -25,6 +25,8 @@
 package recipes
 
 import (
+	"time"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"go.mongodb.org/mongo-driver/bson"
@@ -41,7 +43,7 @@ var _ = Describe("recipes db", func() {
 		})
 
 		It("can transform Recipe Query with 1 search parameter to BSON", func() {
-			expectedResult := bson.M{"name": bson.M{"$regex": "hi"}}
+			expectedResult := bson.M{"name": bson.M{"$regex": "hi", "$options": "i"}}
 			result := RecipeToBsonM(&RecipeSearchFilter{Name: "hi"})
 
 			Expect(expectedResult).To(Equal(result))
@@ -49,12 +51,55 @@ var _ = Describe("recipes db", func() {
 
 		It("can transform Recipe Query with 2 search parameter to BSON", func() {
 			expectedResult := bson.M{"$or": []bson.M{
-				{"name": bson.M{"$regex": "hi"}},
+				{"name": bson.M{"$regex": "hi", "$options": "i"}},
 				{"description": bson.M{"$regex": "there"}}}}
 			result := RecipeToBsonM(&RecipeSearchFilter{Name: "hi", Description: "there"})
 
 			Expect(expectedResult).To(Equal(result))
 		})
+
+		It("can transform Recipe Query with ingredients to BSON", func() {
+			expectedResult := bson.M{"ingredients.name": bson.M{"$regex": "(salt|pepper)", "$options": "i"}}
+			result := RecipeToBsonM(&RecipeSearchFilter{Ingredient: []string{"salt", "pepper"}})
+
+			Expect(expectedResult).To(Equal(result))
+		})
+
+		It("restricts other search terms to recipes with a given tag", func() {
+			tagQuery := bson.M{"tags": bson.M{"$regex": "^vegan$", "$options": "i"}}
+			expectedResult := bson.M{"$and": []bson.M{
+				{"name": bson.M{"$regex": "hi", "$options": "i"}},
+				tagQuery}}
+			result := RecipeToBsonM(&RecipeSearchFilter{Name: "hi", Tag: "vegan"})
+
+			Expect(expectedResult).To(Equal(result))
+			Expect(RecipeToBsonM(&RecipeSearchFilter{Tag: "vegan"})).To(Equal(tagQuery))
+		})
+
+		It("restricts other search terms to recipes changed after a given time", func() {
+			since := time.Date(2021, 7, 31, 12, 0, 0, 0, time.UTC)
+			expectedResult := bson.M{"$and": []bson.M{
+				{"name": bson.M{"$regex": "hi", "$options": "i"}},
+				{"updatedat": bson.M{"$gt": since}}}}
+			result := RecipeToBsonM(&RecipeSearchFilter{Name: "hi", ChangedSince: since})
+
+			Expect(result).To(Equal(expectedResult))
+		})
+
+		It("excludes archived recipes unless archived recipes are requested", func() {
+			Expect(filterToBsonM(&RecipeSearchFilter{})).To(Equal(bson.M{"archived": bson.M{"$ne": true}}))
+			Expect(filterToBsonM(&RecipeSearchFilter{Name: "hi", Archived: true})).To(Equal(bson.M{"$and": []bson.M{
+				{"name": bson.M{"$regex": "hi", "$options": "i"}},
+				{"archived": true},
+			}}))
+		})
+
+		It("escapes regular expressions in search terms", func() {
+			expectedResult := bson.M{"description": bson.M{"$regex": `1\+1`}}
+			result := RecipeToBsonM(&RecipeSearchFilter{Description: "1+1"})
+
+			Expect(expectedResult).To(Equal(result))
+		})
 	})
 
 	Context("connection", func() {
@@ -64,13 +109,14 @@ var _ = Describe("recipes db", func() {
 		)
 
 		BeforeEach(func() {
-			db, err = NewDatabaseClient()
+			db, err = NewDatabaseClient(NewRecipeEventsFromConfig())
 		})
 
 		AfterEach(func() {
 			// clean db for testing
 			db.(*MongoRecipeDB).mongoClient.Database("recipes-manager").Collection("pics").Drop(ctx())
 			db.(*MongoRecipeDB).mongoClient.Database("recipes-manager").Collection("recipes").Drop(ctx())
+			db.(*MongoRecipeDB).mongoClient.Database("recipes-manager").Collection("notes").Drop(ctx())
 			err = db.Close()
 		})
 
@@ -101,7 +147,7 @@ var _ = Describe("recipes db", func() {
 		}
 
 		BeforeEach(func() {
-			db, err = NewDatabaseClient()
+			db, err = NewDatabaseClient(NewRecipeEventsFromConfig())
 			prepareTestRecipes()
 		})
 
@@ -137,6 +183,38 @@ var _ = Describe("recipes db", func() {
 			Expect(recipe.PictureLink).To(ContainElement(expectedResult.Name))
 		})
 
+		It("can delete a Picture and its picturelink", func() {
+			pic := &RecipePicture{
+				ID:      testRecipe1.ID,
+				Name:    "testRecipePic",
+				Picture: "thisisabas64picture",
+			}
+			db.AddPicture(pic)
+
+			err = db.DeletePicture(pic.ID, pic.Name)
+
+			Expect(err).To(BeNil())
+			Expect(db.Picture(pic.ID, pic.Name).ID).To(Equal(InvalidRecipeID()))
+			Expect(db.Get(testRecipe1.ID).PictureLink).ToNot(ContainElement(pic.Name))
+		})
+
+		It("can order pictures", func() {
+			for _, name := range []string{"first", "second"} {
+				Expect(db.AddPicture(&RecipePicture{ID: testRecipe1.ID, Name: name, Picture: "thisisabas64picture"})).To(Succeed())
+			}
+
+			links, err := db.SetPictureOrder(testRecipe1.ID, nil, "second")
+
+			Expect(err).To(BeNil())
+			Expect(links[0]).To(Equal("second"))
+			Expect(db.Get(testRecipe1.ID).PictureLink).To(Equal(links))
+		})
+
+		It("cannot delete a Picture that does not exist", func() {
+			err = db.DeletePicture(testRecipe1.ID, "missing")
+			Expect(err).To(Equal(ErrPictureNotFound))
+		})
+
 		It("can insert multiple pictures and then read them", func() {
 			add := &RecipePicture{
 				ID:      testRecipe1.ID,
@@ -184,7 +262,7 @@ var _ = Describe("recipes db", func() {
 		)
 
 		BeforeEach(func() {
-			db, err = NewDatabaseClient()
+			db, err = NewDatabaseClient(NewRecipeEventsFromConfig())
 		})
 
 		AfterEach(func() {
@@ -229,6 +307,34 @@ var _ = Describe("recipes db", func() {
 			Expect(recipe).ToNot(Equal(testInput))
 		})
 
+		It("removes the notes of a removed Recipe", func() {
+			testInput := &Recipe{
+				ID:   NewRecipeID(),
+				Name: "notesTestRecipe",
+			}
+			err = db.Insert(testInput)
+			Expect(err).To(BeNil())
+			Expect(db.AddNote(&Note{ID: NewNoteID(), RecipeID: testInput.ID, Text: "first"})).To(Succeed())
+			Expect(db.AddNote(&Note{ID: NewNoteID(), RecipeID: testInput.ID, Text: "second"})).To(Succeed())
+			Expect(db.Notes(testInput.ID)[0].Text).To(Equal("second"))
+
+			Expect(db.Remove(testInput.ID)).To(Succeed())
+			Expect(db.Notes(testInput.ID)).To(BeEmpty())
+		})
+
+		It("tracks the last modification including removals", func() {
+			testInput := &Recipe{
+				ID:   NewRecipeID(),
+				Name: "lastModifiedTestRecipe",
+			}
+			Expect(db.Insert(testInput)).To(Succeed())
+			Expect(db.LastModified()).To(Equal(testInput.UpdatedAt))
+
+			time.Sleep(2 * time.Millisecond)
+			Expect(db.Remove(testInput.ID)).To(Succeed())
+			Expect(db.LastModified()).To(BeTemporally(">", testInput.UpdatedAt))
+		})
+
 		It("can remove a Recipe by name", func() {
 			testInput := &Recipe{
 				ID:          NewRecipeID(),
@@ -367,6 +473,143 @@ var _ = Describe("recipes db", func() {
 			Expect(r).To(Equal(expectedResult))
 		})
 
+		It("can find Recipes by tag ignoring the case", func() {
+			expectedResult := &Recipe{
+				ID:          NewRecipeID(),
+				Name:        "taggedRecipe",
+				Ingredients: []Ingredients{},
+				PictureLink: []string{},
+				Tags:        []string{"quick", "Vegan"},
+			}
+			db.Insert(expectedResult)
+			defer db.RemoveByName(expectedResult.Name)
+			untagged := NewRecipe(NewRecipeID())
+			db.Insert(untagged)
+			defer db.Remove(untagged.ID)
+
+			recipes := db.FindByTag("vegan")
+
+			Expect(recipes.Recipes).To(ConsistOf(expectedResult.ID.String()))
+		})
+
+		It("can rate a Recipe and update its average rating", func() {
+			recipe := NewRecipe(NewRecipeID())
+			db.Insert(recipe)
+			defer db.Remove(recipe.ID)
+
+			_, err := db.AddRating(recipe.ID, 5)
+			Expect(err).To(BeNil())
+			average, err := db.AddRating(recipe.ID, 2)
+			Expect(err).To(BeNil())
+
+			Expect(average).To(Equal(float32(3.5)))
+			Expect(db.Get(recipe.ID).RatingCount).To(Equal(2))
+		})
+
+		It("cannot rate a Recipe that does not exist", func() {
+			_, err := db.AddRating(NewRecipeID(), 5)
+			Expect(err).To(Equal(ErrRecipeNotFound))
+		})
+
+		It("can sort Recipes by rating", func() {
+			good := NewRecipe(NewRecipeID())
+			bad := NewRecipe(NewRecipeID())
+			db.Insert(bad)
+			defer db.Remove(bad.ID)
+			db.Insert(good)
+			defer db.Remove(good.ID)
+			db.AddRating(bad.ID, 1)
+			db.AddRating(good.ID, 5)
+
+			recipes := db.IDsPaged(&RecipeSearchFilter{Sort: SortByRating}, 0, 10)
+
+			Expect(recipes.Recipes).To(Equal([]string{good.ID.String(), bad.ID.String()}))
+		})
+
+		It("can pick a Recipe with a seed", func() {
+			for _, name := range []string{"seeded soup", "seeded stew", "seeded salad"} {
+				recipe := NewRecipe(NewRecipeID())
+				recipe.Name = name
+				db.Insert(recipe)
+				defer db.Remove(recipe.ID)
+			}
+			filter := &RecipeSearchFilter{Name: "seeded"}
+
+			picked := db.RandomSeeded(filter, 7)
+			Expect(picked.Name).To(HavePrefix("seeded"))
+			Expect(db.RandomSeeded(filter, 7).ID).To(Equal(picked.ID))
+			Expect(db.RandomSeeded(&RecipeSearchFilter{Name: "nothing matches"}, 7).ID).To(Equal(InvalidRecipeID()))
+		})
+
+		It("can merge duplicates and complete a partially applied merge", func() {
+			soup, duplicate := NewRecipe(NewRecipeID()), NewRecipe(NewRecipeID())
+			soup.Name, duplicate.Name = "merged soup", "merged soup"
+			duplicate.Rating, duplicate.RatingCount = 3, 2
+			db.Insert(soup)
+			defer db.Remove(soup.ID)
+			db.Insert(duplicate)
+			_, _ = db.AddRating(soup.ID, 5)
+			Expect(db.AddPicture(&RecipePicture{ID: duplicate.ID, Name: "bowl.png", Picture: "abc"})).To(Succeed())
+
+			merged, err := db.Merge(soup.ID, []RecipeID{duplicate.ID})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(merged.Rating).To(BeNumerically("~", 11.0/3.0, 0.0001))
+			Expect(merged.RatingCount).To(Equal(3))
+			Expect(merged.PictureLink).To(Equal([]string{"bowl.png"}))
+			Expect(db.Get(duplicate.ID).ID).To(Equal(InvalidRecipeID()))
+
+			// the duplicate remains if the merge fails before it is removed
+			db.Insert(duplicate)
+			merged, err = db.Merge(soup.ID, []RecipeID{duplicate.ID})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(merged.RatingCount).To(Equal(3))
+			Expect(merged.PictureLink).To(Equal([]string{"bowl.png"}))
+			Expect(db.Get(duplicate.ID).ID).To(Equal(InvalidRecipeID()))
+
+			_, err = db.Merge(soup.ID, []RecipeID{duplicate.ID})
+			Expect(err).ToNot(HaveOccurred())
+			_, err = db.Merge(soup.ID, []RecipeID{NewRecipeID()})
+			Expect(err).To(MatchError(ErrRecipeNotFound))
+		})
+
+		It("can tag Recipes in bulk ignoring the case and increments their version", func() {
+			recipe := NewRecipe(NewRecipeID())
+			recipe.Name, recipe.Tags = "testRecipe", []string{"Soup", "quick"}
+			Expect(db.Insert(recipe)).To(Succeed())
+			missing := NewRecipeID()
+
+			results, err := db.BulkTag([]RecipeID{recipe.ID, missing}, []string{"vegan", "soup", "$vegan"}, []string{"QUICK"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results).To(Equal(map[RecipeID]bool{recipe.ID: true, missing: false}))
+			Expect(db.Get(recipe.ID).Tags).To(Equal([]string{"Soup", "vegan", "$vegan"}))
+			Expect(db.Get(recipe.ID).Version).To(Equal(recipe.Version + 1))
+
+			_, err = db.BulkTag([]RecipeID{recipe.ID}, []string{"VEGAN"}, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(db.Get(recipe.ID).Version).To(Equal(recipe.Version + 1))
+		})
+
+		It("excludes archived recipes from suggestions, tags, statistics, and similar recipes", func() {
+			soup := NewRecipe(NewRecipeID())
+			soup.Name, soup.Tags = "soup", []string{"winter"}
+			soup.Ingredients = []Ingredients{{Name: "tomato"}, {Name: "onion"}}
+			Expect(db.Insert(soup)).To(Succeed())
+			stew := NewRecipe(NewRecipeID())
+			stew.Name, stew.Tags = "stew", []string{"hearty"}
+			stew.Ingredients = []Ingredients{{Name: "tomato"}, {Name: "turnip"}}
+			Expect(db.Insert(stew)).To(Succeed())
+			Expect(db.Archive(stew.ID)).To(Succeed())
+
+			Expect(db.SuggestIngredients("t", 10)).To(Equal([]string{"tomato"}))
+			Expect(db.Tags()).To(Equal([]TagCount{{Tag: "winter", Count: 1}}))
+			stats, err := db.Stats()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stats.Total).To(Equal(int64(1)))
+			Expect(stats.Tags).To(Equal([]TagCount{{Tag: "winter", Count: 1}}))
+			Expect(stats.TopIngredients).To(ConsistOf(IngredientCount{Name: "tomato", Count: 1}, IngredientCount{Name: "onion", Count: 1}))
+			Expect(db.FindSimilar(soup.ID, 0)).To(BeEmpty())
+		})
+
 		It("can aggregate the names of all elements", func() {
 			expectedResult := &Recipe{
 				ID:          NewRecipeID(),
@@ -0,0 +1,785 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/ottenwbe/recipes-manager/utils"
+)
+
+//ErrRecipeExists is returned when a recipe with the same id is inserted twice
+var ErrRecipeExists = errors.New("recipe already exists")
+
+//InMemoryDB implements the RecipeDB interface without a database, e.g., for development and tests.
+//Recipes are lost when the application stops. InMemoryDB is safe for concurrent use.
+type InMemoryDB struct {
+	mtx sync.RWMutex
+	//order of the recipes by insertion, like MongoDB's _id
+	order    []RecipeID
+	recipes  map[RecipeID]*Recipe
+	pictures map[RecipeID]map[string]*RecipePicture
+	//notes of a recipe in the order they were added
+	notes map[RecipeID][]*Note
+	//removedAt is the time the last recipe was removed
+	removedAt time.Time
+}
+
+//NewInMemoryDB returns an empty in-memory database
+func NewInMemoryDB() *InMemoryDB {
+	return &InMemoryDB{
+		order:    make([]RecipeID, 0),
+		recipes:  make(map[RecipeID]*Recipe),
+		pictures: make(map[RecipeID]map[string]*RecipePicture),
+		notes:    make(map[RecipeID][]*Note),
+	}
+}
+
+//SeedFromFile inserts all recipes of a JSON file with a list of recipes. Recipes without an id get a new one.
+func (m *InMemoryDB) SeedFromFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	var recipes []*Recipe
+	if err = json.NewDecoder(file).Decode(&recipes); err != nil {
+		return err
+	}
+
+	for _, recipe := range recipes {
+		if recipe.ID == "" {
+			recipe.ID = NewRecipeID()
+		}
+		if err = m.Insert(recipe); err != nil {
+			return err
+		}
+	}
+
+	log.WithField("file", path).Infof("Seeded %v recipes", len(recipes))
+	return nil
+}
+
+//Close does nothing, since there is no connection to close
+func (m *InMemoryDB) Close() error {
+	return nil
+}
+
+//Ping always succeeds
+func (m *InMemoryDB) Ping() error {
+	return nil
+}
+
+//WithContext returns the database itself, since its operations cannot block
+func (m *InMemoryDB) WithContext(ctx context.Context) RecipeDB {
+	return m
+}
+
+//Clear removes all recipes and pictures
+func (m *InMemoryDB) Clear() {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	m.order = make([]RecipeID, 0)
+	m.recipes = make(map[RecipeID]*Recipe)
+	m.pictures = make(map[RecipeID]map[string]*RecipePicture)
+	m.notes = make(map[RecipeID][]*Note)
+	m.removedAt = changeTime()
+}
+
+//List all recipes
+func (m *InMemoryDB) List() []*Recipe {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	recipes := make([]*Recipe, 0, len(m.order))
+	for _, id := range m.order {
+		recipes = append(recipes, m.recipes[id].clone())
+	}
+	return recipes
+}
+
+//Num returns the number of recipes which are not archived
+func (m *InMemoryDB) Num() int64 {
+	return m.Count(&RecipeSearchFilter{})
+}
+
+//IDs of all recipes matching the filter
+func (m *InMemoryDB) IDs(filterQuery *RecipeSearchFilter) RecipeList {
+	return m.IDsPaged(filterQuery, 0, math.MaxInt64)
+}
+
+//IDsPaged lists at most limit ids of recipes matching the filter, skipping the first offset ids
+func (m *InMemoryDB) IDsPaged(filterQuery *RecipeSearchFilter, offset int64, limit int64) RecipeList {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	result := make([]string, 0)
+	for i, recipe := range m.sorted(filterQuery) {
+		if int64(i) >= offset && int64(len(result)) < limit {
+			result = append(result, recipe.ID.String())
+		}
+	}
+	return RecipeList{Recipes: result}
+}
+
+//Summaries lists at most limit summaries of recipes matching the filter, skipping the first offset recipes
+func (m *InMemoryDB) Summaries(filterQuery *RecipeSearchFilter, offset int64, limit int64) RecipeSummaries {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	result := make([]RecipeSummary, 0)
+	for i, recipe := range m.sorted(filterQuery) {
+		if int64(i) >= offset && int64(len(result)) < limit {
+			result = append(result, recipe.Summary())
+		}
+	}
+	return RecipeSummaries{Recipes: result}
+}
+
+//Count the recipes matching the filter
+func (m *InMemoryDB) Count(filterQuery *RecipeSearchFilter) int64 {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	return int64(len(m.filter(filterQuery)))
+}
+
+//IDsSorted lists the ids of all recipes sorted by the field in the given order
+func (m *InMemoryDB) IDsSorted(field string, order string) RecipeList {
+	return m.IDs(&RecipeSearchFilter{Sort: field, Order: order})
+}
+
+//IDsChangedSince lists the ids of all recipes which were updated after t
+func (m *InMemoryDB) IDsChangedSince(t time.Time) RecipeList {
+	return m.IDs(&RecipeSearchFilter{ChangedSince: t})
+}
+
+//SuggestIngredients lists at most limit distinct ingredient names starting with the prefix, ignoring the case, in alphabetical order
+func (m *InMemoryDB) SuggestIngredients(prefix string, limit int) []string {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	prefix = normalizeIngredientName(prefix)
+	names := make(map[string]bool)
+	for _, recipe := range m.listed() {
+		for name := range ingredientNames(recipe) {
+			if strings.HasPrefix(name, prefix) {
+				names[name] = true
+			}
+		}
+	}
+
+	suggestions := make([]string, 0, len(names))
+	for name := range names {
+		suggestions = append(suggestions, name)
+	}
+	sort.Strings(suggestions)
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions
+}
+
+//Stats aggregates the number of recipes, their average servings, their tags, and their most used ingredients
+func (m *InMemoryDB) Stats() (*RecipeStats, error) {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	return computeStats(m.listed()), nil
+}
+
+//Tags lists all distinct tags in lower case and the number of recipes carrying them, the most used tag first
+func (m *InMemoryDB) Tags() ([]TagCount, error) {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	return countTags(m.listed()), nil
+}
+
+//listed returns the recipes which are not archived in the order of their creation; the caller has to hold the lock
+func (m *InMemoryDB) listed() []*Recipe {
+	recipes := make([]*Recipe, 0, len(m.order))
+	for _, id := range m.order {
+		if recipe := m.recipes[id]; !recipe.Archived {
+			recipes = append(recipes, recipe)
+		}
+	}
+	return recipes
+}
+
+//LastModified returns the time of the latest change of the recipes, i.e., the latest UpdatedAt or removal of a recipe
+func (m *InMemoryDB) LastModified() time.Time {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	lastModified := m.removedAt
+	for _, recipe := range m.recipes {
+		if recipe.UpdatedAt.After(lastModified) {
+			lastModified = recipe.UpdatedAt
+		}
+	}
+	return lastModified
+}
+
+//FindByMaxTime lists the ids of all recipes which can be prepared and cooked in the given minutes; recipes without times are omitted
+func (m *InMemoryDB) FindByMaxTime(minutes int) RecipeList {
+	return m.IDs(&RecipeSearchFilter{MaxTotalTime: minutes})
+}
+
+//FindByTag lists the ids of all recipes carrying the tag, ignoring the case
+func (m *InMemoryDB) FindByTag(tag string) RecipeList {
+	return m.IDs(&RecipeSearchFilter{Tag: tag})
+}
+
+//Search recipes by their name, description, and ingredients. The score is the number of occurrences of the terms.
+func (m *InMemoryDB) Search(query string) []RecipeSearchResult {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	results := make([]RecipeSearchResult, 0)
+
+	terms := SearchTerms(query)
+	if len(terms) == 0 {
+		return results
+	}
+
+	for _, id := range m.order {
+		recipe := m.recipes[id]
+		if recipe.Archived {
+			continue
+		}
+		texts := []string{recipe.Name, recipe.Description}
+		for _, ingredient := range recipe.Ingredients {
+			texts = append(texts, ingredient.Name)
+		}
+		text := strings.ToLower(strings.Join(texts, "\n"))
+
+		score := 0
+		for _, term := range terms {
+			n := strings.Count(text, strings.ToLower(term))
+			if n == 0 {
+				score = 0
+				break
+			}
+			score += n
+		}
+		if score > 0 {
+			results = append(results, RecipeSearchResult{ID: recipe.ID, Name: recipe.Name, Score: float64(score)})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	return results
+}
+
+//FindSimilar lists the recipes with similar ingredients, most similar first
+func (m *InMemoryDB) FindSimilar(id RecipeID, threshold float64) ([]SimilarRecipe, error) {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	recipe, ok := m.recipes[id]
+	if !ok {
+		return make([]SimilarRecipe, 0), ErrRecipeNotFound
+	}
+
+	return similarRecipes(recipe, m.listed(), threshold), nil
+}
+
+//Get a recipe by its id; the id of the returned recipe is InvalidRecipeID if there is no such recipe
+func (m *InMemoryDB) Get(id RecipeID) *Recipe {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	if recipe, ok := m.recipes[id]; ok {
+		return recipe.clone()
+	}
+	return NewInvalidRecipe()
+}
+
+//GetMany returns all existing recipes out of the given ids
+func (m *InMemoryDB) GetMany(ids []RecipeID) []*Recipe {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	recipes := make([]*Recipe, 0, len(ids))
+	for _, id := range ids {
+		if recipe, ok := m.recipes[id]; ok {
+			recipes = append(recipes, recipe.clone())
+		}
+	}
+	return recipes
+}
+
+//GetByName returns the first recipe with the given name
+func (m *InMemoryDB) GetByName(name string) (*Recipe, error) {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	for _, id := range m.order {
+		if m.recipes[id].Name == name {
+			return m.recipes[id].clone(), nil
+		}
+	}
+	return NewInvalidRecipe(), ErrRecipeNotFound
+}
+
+//Random returns a random recipe; the recipe's id is InvalidRecipeID if there are no recipes
+func (m *InMemoryDB) Random() *Recipe {
+	return m.RandomFiltered(&RecipeSearchFilter{})
+}
+
+//RandomFiltered returns a random recipe out of all recipes that match the filter
+func (m *InMemoryDB) RandomFiltered(filterQuery *RecipeSearchFilter) *Recipe {
+	return m.pick(filterQuery, rand.Intn)
+}
+
+//RandomSeeded picks a recipe out of the recipes matching the filter, ordered by their insertion, with a random generator seeded by seed
+func (m *InMemoryDB) RandomSeeded(filterQuery *RecipeSearchFilter, seed int64) *Recipe {
+	return m.pick(filterQuery, rand.New(rand.NewSource(seed)).Intn)
+}
+
+func (m *InMemoryDB) pick(filterQuery *RecipeSearchFilter, intn func(int) int) *Recipe {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	recipes := m.filter(filterQuery)
+	if len(recipes) == 0 {
+		return NewInvalidRecipe()
+	}
+	return recipes[intn(len(recipes))].clone()
+}
+
+//Insert a new recipe
+func (m *InMemoryDB) Insert(recipe *Recipe) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if _, ok := m.recipes[recipe.ID]; ok {
+		return ErrRecipeExists
+	}
+
+	recipe.NormalizeUnits()
+	recipe.CreatedAt = changeTime()
+	recipe.UpdatedAt = recipe.CreatedAt
+	m.recipes[recipe.ID] = recipe.clone()
+	m.order = append(m.order, recipe.ID)
+	return nil
+}
+
+//Update replaces an existing recipe if the versions match
+func (m *InMemoryDB) Update(id RecipeID, recipe *Recipe) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	stored, ok := m.recipes[id]
+	if !ok {
+		return nil
+	}
+	if stored.Version != recipe.Version {
+		return ErrVersionConflict
+	}
+
+	recipe.Version++
+	recipe.CreatedAt = stored.CreatedAt
+	recipe.UpdatedAt = changeTime()
+	recipe.Archived = stored.Archived
+	m.recipes[id] = recipe.clone()
+	return nil
+}
+
+//Remove a recipe and its pictures
+func (m *InMemoryDB) Remove(id RecipeID) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	m.remove(id)
+	return nil
+}
+
+//RemoveByName removes the first recipe with the given name
+func (m *InMemoryDB) RemoveByName(name string) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	for _, id := range m.order {
+		if m.recipes[id].Name == name {
+			m.remove(id)
+			break
+		}
+	}
+	return nil
+}
+
+func (m *InMemoryDB) remove(id RecipeID) {
+	if _, ok := m.recipes[id]; !ok {
+		return
+	}
+	delete(m.recipes, id)
+	delete(m.pictures, id)
+	delete(m.notes, id)
+	m.removedAt = changeTime()
+	for i := range m.order {
+		if m.order[i] == id {
+			m.order = append(m.order[:i], m.order[i+1:]...)
+			break
+		}
+	}
+}
+
+//Notes of a recipe, the newest note first
+func (m *InMemoryDB) Notes(id RecipeID) []*Note {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	notes := m.notes[id]
+	result := make([]*Note, 0, len(notes))
+	for i := len(notes) - 1; i >= 0; i-- {
+		note := *notes[i]
+		result = append(result, &note)
+	}
+	return result
+}
+
+//AddNote to a recipe and set its creation time
+func (m *InMemoryDB) AddNote(note *Note) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if _, ok := m.recipes[note.RecipeID]; !ok {
+		return ErrRecipeNotFound
+	}
+	note.CreatedAt = changeTime()
+	stored := *note
+	m.notes[note.RecipeID] = append(m.notes[note.RecipeID], &stored)
+	return nil
+}
+
+//Merge duplicates into the primary recipe
+func (m *InMemoryDB) Merge(primary RecipeID, duplicates []RecipeID) (*Recipe, error) {
+	request := MergeRequest{Primary: primary, Duplicates: duplicates}
+	if err := request.Validate(); err != nil {
+		return NewInvalidRecipe(), err
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	merged := make([]*Recipe, 0, len(duplicates)+1)
+	for _, id := range append([]RecipeID{primary}, duplicates...) {
+		recipe, ok := m.recipes[id]
+		if !ok {
+			return NewInvalidRecipe(), fmt.Errorf("%w: %v", ErrRecipeNotFound, id)
+		}
+		merged = append(merged, recipe)
+	}
+
+	recipe := merged[0]
+	if m.pictures[primary] == nil {
+		m.pictures[primary] = make(map[string]*RecipePicture)
+	}
+	for _, id := range duplicates {
+		names := make([]string, 0, len(m.pictures[id]))
+		for name := range m.pictures[id] {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if _, taken := m.pictures[primary][name]; !taken {
+				pic := m.pictures[id][name]
+				pic.ID = primary
+				m.pictures[primary][name] = pic
+				recipe.PictureLink = append(recipe.PictureLink, name)
+			}
+		}
+		for _, note := range m.notes[id] {
+			note.RecipeID = primary
+			m.notes[primary] = append(m.notes[primary], note)
+		}
+	}
+	sort.SliceStable(m.notes[primary], func(i, j int) bool {
+		return m.notes[primary][i].CreatedAt.Before(m.notes[primary][j].CreatedAt)
+	})
+
+	recipe.Rating, recipe.RatingCount = mergeRatings(merged)
+	recipe.PictureLink = utils.UniqueSlice(recipe.PictureLink)
+	recipe.UpdatedAt = changeTime()
+	for _, id := range duplicates {
+		m.remove(id)
+	}
+	return recipe.clone(), nil
+}
+
+//AddRating to a recipe and return the recipe's new average rating
+func (m *InMemoryDB) AddRating(id RecipeID, rating int) (float32, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	recipe, ok := m.recipes[id]
+	if !ok {
+		return 0, ErrRecipeNotFound
+	}
+
+	recipe.Rating = (recipe.Rating*float32(recipe.RatingCount) + float32(rating)) / float32(recipe.RatingCount+1)
+	recipe.RatingCount++
+	recipe.UpdatedAt = changeTime()
+	return recipe.Rating, nil
+}
+
+//BulkTag removes the tags to remove from and then adds the tags to add to all recipes, ignoring the case
+func (m *InMemoryDB) BulkTag(ids []RecipeID, add []string, remove []string) (map[RecipeID]bool, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	results := make(map[RecipeID]bool)
+	for _, id := range ids {
+		recipe, ok := m.recipes[id]
+		results[id] = ok
+		if ok && recipe.retag(add, remove) {
+			recipe.Version++
+			recipe.UpdatedAt = changeTime()
+		}
+	}
+	return results, nil
+}
+
+//Archive a recipe, i.e., exclude it from listings and searches without removing it
+func (m *InMemoryDB) Archive(id RecipeID) error {
+	return m.setArchived(id, true)
+}
+
+//Restore an archived recipe, i.e., list it again
+func (m *InMemoryDB) Restore(id RecipeID) error {
+	return m.setArchived(id, false)
+}
+
+func (m *InMemoryDB) setArchived(id RecipeID, archived bool) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	recipe, ok := m.recipes[id]
+	if !ok {
+		return ErrRecipeNotFound
+	}
+	if recipe.Archived != archived {
+		recipe.Archived = archived
+		recipe.UpdatedAt = changeTime()
+	}
+	return nil
+}
+
+//Picture of a recipe; the id of the returned picture is InvalidRecipeID if there is no such picture
+func (m *InMemoryDB) Picture(id RecipeID, name string) *RecipePicture {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	if pic, ok := m.pictures[id][name]; ok {
+		picCopy := *pic
+		return &picCopy
+	}
+	return NewInvalidRecipePicture()
+}
+
+//Pictures of a recipe by their name
+func (m *InMemoryDB) Pictures(id RecipeID) map[string]*RecipePicture {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	result := make(map[string]*RecipePicture)
+	for name, pic := range m.pictures[id] {
+		picCopy := *pic
+		result[name] = &picCopy
+	}
+	return result
+}
+
+//AddPicture to a recipe and link it in the recipe's picture links
+func (m *InMemoryDB) AddPicture(pic *RecipePicture) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	recipe, ok := m.recipes[pic.ID]
+	if !ok {
+		return ErrRecipeNotFound
+	}
+
+	recipe.PictureLink = utils.UniqueSlice(append(recipe.PictureLink, pic.Name))
+	recipe.UpdatedAt = changeTime()
+	if m.pictures[pic.ID] == nil {
+		m.pictures[pic.ID] = make(map[string]*RecipePicture)
+	}
+	picCopy := *pic
+	m.pictures[pic.ID][pic.Name] = &picCopy
+	return nil
+}
+
+//DeletePicture of a recipe and remove it from the recipe's picture links
+func (m *InMemoryDB) DeletePicture(id RecipeID, name string) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if _, ok := m.pictures[id][name]; !ok {
+		return ErrPictureNotFound
+	}
+	delete(m.pictures[id], name)
+
+	if recipe, ok := m.recipes[id]; ok {
+		links := make([]string, 0, len(recipe.PictureLink))
+		for _, link := range recipe.PictureLink {
+			if link != name {
+				links = append(links, link)
+			}
+		}
+		recipe.PictureLink = links
+		recipe.UpdatedAt = changeTime()
+	}
+	return nil
+}
+
+//SetPictureOrder rearranges the picture links of a recipe
+func (m *InMemoryDB) SetPictureOrder(id RecipeID, names []string, primary string) ([]string, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	recipe, ok := m.recipes[id]
+	if !ok {
+		return nil, ErrRecipeNotFound
+	}
+	links, err := orderPictures(recipe.PictureLink, names, primary)
+	if err != nil {
+		return nil, err
+	}
+	recipe.PictureLink = links
+	recipe.UpdatedAt = changeTime()
+	return append([]string{}, links...), nil
+}
+
+//filter returns the recipes matching the filter in the order of their insertion
+func (m *InMemoryDB) filter(filterQuery *RecipeSearchFilter) []*Recipe {
+	result := make([]*Recipe, 0)
+	for _, id := range m.order {
+		if matchesFilter(m.recipes[id], filterQuery) {
+			result = append(result, m.recipes[id])
+		}
+	}
+	return result
+}
+
+//sorted returns the recipes matching the filter in the order requested by the filter; ties keep the order of insertion
+func (m *InMemoryDB) sorted(filterQuery *RecipeSearchFilter) []*Recipe {
+	recipes := m.filter(filterQuery)
+	descending := filterQuery.descending()
+
+	var less func(a, b *Recipe) bool
+	switch filterQuery.Sort {
+	case SortByName:
+		less = func(a, b *Recipe) bool { return strings.ToLower(a.Name) < strings.ToLower(b.Name) }
+	case SortByRating:
+		less = func(a, b *Recipe) bool { return a.Rating < b.Rating }
+	case SortByCalories:
+		// like MongoDB, recipes without calories are the smallest
+		calories := func(r *Recipe) float64 {
+			if r.Nutrition == nil || r.Nutrition.Calories == nil {
+				return -1
+			}
+			return *r.Nutrition.Calories
+		}
+		less = func(a, b *Recipe) bool { return calories(a) < calories(b) }
+	default:
+		if descending {
+			for i, j := 0, len(recipes)-1; i < j; i, j = i+1, j-1 {
+				recipes[i], recipes[j] = recipes[j], recipes[i]
+			}
+		}
+		return recipes
+	}
+
+	sort.SliceStable(recipes, func(i, j int) bool {
+		if descending {
+			return less(recipes[j], recipes[i])
+		}
+		return less(recipes[i], recipes[j])
+	})
+	return recipes
+}
+
+//matchesFilter mirrors RecipeToBsonM: the search terms match if any of them matches, the tag has to match in addition
+func matchesFilter(recipe *Recipe, filterQuery *RecipeSearchFilter) bool {
+	if recipe.Archived != filterQuery.Archived {
+		return false
+	}
+	if filterQuery.Tag != "" && !containsFold(recipe.Tags, filterQuery.Tag) {
+		return false
+	}
+	if !filterQuery.ChangedSince.IsZero() && !recipe.UpdatedAt.After(filterQuery.ChangedSince) {
+		return false
+	}
+	if filterQuery.MaxTotalTime > 0 && (recipe.totalMinutes() == 0 || recipe.totalMinutes() > filterQuery.MaxTotalTime) {
+		return false
+	}
+
+	terms := 0
+	matches := false
+	if filterQuery.Name != "" {
+		terms++
+		matches = matches || strings.Contains(strings.ToLower(recipe.Name), strings.ToLower(filterQuery.Name))
+	}
+	if filterQuery.Description != "" {
+		terms++
+		matches = matches || strings.Contains(recipe.Description, filterQuery.Description)
+	}
+	if len(filterQuery.Ingredient) > 0 {
+		terms++
+		for _, ingredient := range recipe.Ingredients {
+			for _, searched := range filterQuery.Ingredient {
+				matches = matches || strings.Contains(strings.ToLower(ingredient.Name), strings.ToLower(searched))
+			}
+		}
+	}
+
+	return terms == 0 || matches
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,486 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("in-memory recipes db", func() {
+
+	var db *InMemoryDB
+
+	BeforeEach(func() {
+		db = NewInMemoryDB()
+	})
+
+	newRecipe := func(name string, tags ...string) *Recipe {
+		recipe := NewRecipe(NewRecipeID())
+		recipe.Name = name
+		recipe.Tags = append(recipe.Tags, tags...)
+		Expect(db.Insert(recipe)).To(Succeed())
+		return recipe
+	}
+
+	Context("reading and writing", func() {
+		It("should return inserted recipes", func() {
+			recipe := newRecipe("soup")
+			Expect(db.Get(recipe.ID)).To(Equal(recipe))
+			Expect(db.Num()).To(Equal(int64(1)))
+		})
+		It("should return an invalid recipe for unknown ids", func() {
+			Expect(db.Get(NewRecipeID()).ID).To(Equal(InvalidRecipeID()))
+		})
+		It("should reject recipes with the same id", func() {
+			recipe := newRecipe("soup")
+			Expect(db.Insert(recipe)).To(Equal(ErrRecipeExists))
+		})
+		It("should not share recipes with callers", func() {
+			recipe := newRecipe("soup")
+			recipe.Name = "changed"
+			db.Get(recipe.ID).Tags = append(db.Get(recipe.ID).Tags, "changed")
+			Expect(db.Get(recipe.ID).Name).To(Equal("soup"))
+			Expect(db.Get(recipe.ID).Tags).To(BeEmpty())
+		})
+		It("should update and remove recipes", func() {
+			recipe := newRecipe("soup")
+			recipe.Name = "stew"
+			Expect(db.Update(recipe.ID, recipe)).To(Succeed())
+			Expect(db.Get(recipe.ID).Name).To(Equal("stew"))
+			Expect(db.Remove(recipe.ID)).To(Succeed())
+			Expect(db.Num()).To(BeZero())
+			Expect(db.IDs(&RecipeSearchFilter{}).Recipes).To(BeEmpty())
+		})
+		It("should increment the version with each update", func() {
+			recipe := newRecipe("soup")
+			Expect(db.Update(recipe.ID, recipe)).To(Succeed())
+			Expect(recipe.Version).To(Equal(1))
+			Expect(db.Get(recipe.ID).Version).To(Equal(1))
+
+			outdated := db.Get(recipe.ID)
+			outdated.Version = 0
+			Expect(db.Update(recipe.ID, outdated)).To(Equal(ErrVersionConflict))
+		})
+		It("should return all existing recipes out of many ids", func() {
+			soup := newRecipe("soup")
+			stew := newRecipe("stew")
+			newRecipe("salad")
+			recipes := db.GetMany([]RecipeID{soup.ID, NewRecipeID(), stew.ID})
+			Expect(recipes).To(ConsistOf(soup, stew))
+		})
+		It("should set the creation and update time", func() {
+			recipe := newRecipe("soup")
+			Expect(recipe.CreatedAt).ToNot(BeZero())
+			Expect(recipe.UpdatedAt).To(Equal(recipe.CreatedAt))
+
+			changed := db.Get(recipe.ID)
+			changed.CreatedAt = time.Time{}
+			time.Sleep(2 * time.Millisecond)
+			Expect(db.Update(recipe.ID, changed)).To(Succeed())
+			Expect(db.Get(recipe.ID).CreatedAt).To(Equal(recipe.CreatedAt))
+			Expect(db.Get(recipe.ID).UpdatedAt).To(BeTemporally(">", recipe.UpdatedAt))
+		})
+		It("should list recipes changed since a given time", func() {
+			newRecipe("soup")
+			stew := newRecipe("stew")
+			since := db.Get(stew.ID).UpdatedAt
+			time.Sleep(2 * time.Millisecond)
+			Expect(db.IDsChangedSince(since).Recipes).To(BeEmpty())
+
+			_, err := db.AddRating(stew.ID, 4)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(db.IDsChangedSince(since).Recipes).To(Equal([]string{stew.ID.String()}))
+		})
+		It("should track the last modification including removals", func() {
+			Expect(db.LastModified()).To(BeZero())
+
+			soup := newRecipe("soup")
+			stew := newRecipe("stew")
+			Expect(db.LastModified()).To(Equal(db.Get(stew.ID).UpdatedAt))
+
+			time.Sleep(2 * time.Millisecond)
+			Expect(db.Remove(soup.ID)).To(Succeed())
+			Expect(db.LastModified()).To(BeTemporally(">", db.Get(stew.ID).UpdatedAt))
+		})
+		It("should find recipes which can be made in the given time", func() {
+			quick := NewRecipe(NewRecipeID())
+			quick.PrepMinutes, quick.CookMinutes = 10, 20
+			slow := NewRecipe(NewRecipeID())
+			slow.PrepMinutes, slow.CookMinutes = 10, 21
+			unknown := NewRecipe(NewRecipeID())
+			for _, recipe := range []*Recipe{quick, slow, unknown} {
+				Expect(db.Insert(recipe)).To(Succeed())
+			}
+
+			Expect(db.FindByMaxTime(30).Recipes).To(Equal([]string{quick.ID.String()}))
+		})
+		It("should find recipes by name", func() {
+			recipe := newRecipe("soup")
+			found, err := db.GetByName("soup")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found.ID).To(Equal(recipe.ID))
+			_, err = db.GetByName("stew")
+			Expect(err).To(Equal(ErrRecipeNotFound))
+		})
+		It("should be safe for concurrent use", func() {
+			var wg sync.WaitGroup
+			for i := 0; i < 20; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					defer GinkgoRecover()
+					recipe := NewRecipe(NewRecipeID())
+					Expect(db.Insert(recipe)).To(Succeed())
+					db.Get(recipe.ID)
+					db.IDs(&RecipeSearchFilter{})
+				}()
+			}
+			wg.Wait()
+			Expect(db.Num()).To(Equal(int64(20)))
+		})
+	})
+
+	Context("filtering", func() {
+		It("should list ids in the order of insertion", func() {
+			first := newRecipe("soup")
+			second := newRecipe("stew")
+			Expect(db.IDs(&RecipeSearchFilter{}).Recipes).To(Equal([]string{first.ID.String(), second.ID.String()}))
+			Expect(db.IDsPaged(&RecipeSearchFilter{}, 1, 5).Recipes).To(Equal([]string{second.ID.String()}))
+		})
+		It("should list summaries like ids", func() {
+			soup := newRecipe("soup", "vegan")
+			stew := newRecipe("stew")
+			Expect(db.Summaries(&RecipeSearchFilter{Sort: SortByName}, 0, 5).Recipes).To(Equal([]RecipeSummary{soup.Summary(), stew.Summary()}))
+			Expect(db.Summaries(&RecipeSearchFilter{Tag: "vegan"}, 0, 5).Recipes).To(Equal([]RecipeSummary{soup.Summary()}))
+			Expect(db.Summaries(&RecipeSearchFilter{}, 1, 5).Recipes).To(Equal([]RecipeSummary{stew.Summary()}))
+			Expect(db.Summaries(&RecipeSearchFilter{}, 0, 0).Recipes).To(BeEmpty())
+		})
+		It("should filter by name and tag", func() {
+			soup := newRecipe("Tomato Soup", "vegan")
+			newRecipe("Tomato Salad")
+			newRecipe("Lentil Soup")
+			Expect(db.IDs(&RecipeSearchFilter{Name: "soup", Tag: "Vegan"}).Recipes).To(Equal([]string{soup.ID.String()}))
+			Expect(db.Count(&RecipeSearchFilter{Name: "tomato"})).To(Equal(int64(2)))
+			Expect(db.FindByTag("VEGAN").Recipes).To(Equal([]string{soup.ID.String()}))
+		})
+		It("should sort recipes by rating", func() {
+			soup := newRecipe("soup")
+			stew := newRecipe("stew")
+			_, err := db.AddRating(stew.ID, 4)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(db.IDs(&RecipeSearchFilter{Sort: SortByRating}).Recipes).To(Equal([]string{stew.ID.String(), soup.ID.String()}))
+			Expect(db.IDsSorted(SortByRating, OrderAscending).Recipes).To(Equal([]string{soup.ID.String(), stew.ID.String()}))
+		})
+		It("should sort recipes by name and creation in both directions", func() {
+			stew := newRecipe("stew")
+			apple := newRecipe("Apple pie")
+			soup := newRecipe("soup")
+			Expect(db.IDsSorted(SortByName, "").Recipes).To(Equal([]string{apple.ID.String(), soup.ID.String(), stew.ID.String()}))
+			Expect(db.IDsSorted(SortByName, OrderDescending).Recipes).To(Equal([]string{stew.ID.String(), soup.ID.String(), apple.ID.String()}))
+			Expect(db.IDsSorted(SortByCreated, OrderDescending).Recipes).To(Equal([]string{soup.ID.String(), apple.ID.String(), stew.ID.String()}))
+		})
+	})
+
+	Context("archiving", func() {
+		It("should exclude archived recipes from listings and searches", func() {
+			soup := newRecipe("tomato soup")
+			stew := newRecipe("tomato stew")
+			Expect(db.Archive(soup.ID)).To(Succeed())
+
+			Expect(db.IDs(&RecipeSearchFilter{}).Recipes).To(Equal([]string{stew.ID.String()}))
+			Expect(db.IDs(&RecipeSearchFilter{Archived: true}).Recipes).To(Equal([]string{soup.ID.String()}))
+			Expect(db.Num()).To(Equal(int64(1)))
+			Expect(db.Search("tomato")).To(HaveLen(1))
+			Expect(db.Get(soup.ID).Archived).To(BeTrue())
+		})
+		It("should exclude archived recipes from suggestions, tags, statistics, and similar recipes", func() {
+			soup := NewRecipe(NewRecipeID())
+			soup.Name, soup.Tags = "soup", []string{"winter"}
+			soup.Ingredients = []Ingredients{{Name: "tomato"}, {Name: "onion"}}
+			Expect(db.Insert(soup)).To(Succeed())
+			stew := NewRecipe(NewRecipeID())
+			stew.Name, stew.Tags = "stew", []string{"hearty"}
+			stew.Ingredients = []Ingredients{{Name: "tomato"}, {Name: "turnip"}}
+			Expect(db.Insert(stew)).To(Succeed())
+			Expect(db.Archive(stew.ID)).To(Succeed())
+
+			Expect(db.SuggestIngredients("t", 10)).To(Equal([]string{"tomato"}))
+			Expect(db.Tags()).To(Equal([]TagCount{{Tag: "winter", Count: 1}}))
+			stats, err := db.Stats()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stats.Total).To(Equal(int64(1)))
+			Expect(stats.Tags).To(Equal([]TagCount{{Tag: "winter", Count: 1}}))
+			Expect(stats.TopIngredients).To(ConsistOf(IngredientCount{Name: "tomato", Count: 1}, IngredientCount{Name: "onion", Count: 1}))
+			Expect(db.FindSimilar(soup.ID, 0)).To(BeEmpty())
+		})
+		It("should list restored recipes again", func() {
+			soup := newRecipe("soup")
+			Expect(db.Archive(soup.ID)).To(Succeed())
+			Expect(db.Restore(soup.ID)).To(Succeed())
+			Expect(db.IDs(&RecipeSearchFilter{}).Recipes).To(Equal([]string{soup.ID.String()}))
+		})
+		It("should keep the archived state when a recipe is updated", func() {
+			soup := newRecipe("soup")
+			Expect(db.Archive(soup.ID)).To(Succeed())
+			soup.Version = db.Get(soup.ID).Version
+			Expect(db.Update(soup.ID, soup)).To(Succeed())
+			Expect(db.Get(soup.ID).Archived).To(BeTrue())
+		})
+		It("should return ErrRecipeNotFound for unknown recipes", func() {
+			Expect(db.Archive(NewRecipeID())).To(Equal(ErrRecipeNotFound))
+			Expect(db.Restore(NewRecipeID())).To(Equal(ErrRecipeNotFound))
+		})
+	})
+
+	Context("random recipes", func() {
+		It("should return an invalid recipe if no recipe matches", func() {
+			newRecipe("soup")
+			Expect(db.RandomFiltered(&RecipeSearchFilter{Name: "stew"}).ID).To(Equal(InvalidRecipeID()))
+		})
+		It("should pick the same recipe for the same seed", func() {
+			for i := 0; i < 10; i++ {
+				newRecipe("soup")
+			}
+			Expect(db.RandomSeeded(&RecipeSearchFilter{}, 7).ID).To(Equal(db.RandomSeeded(&RecipeSearchFilter{}, 7).ID))
+		})
+	})
+
+	Context("search", func() {
+		It("should rank recipes matching all terms", func() {
+			best := newRecipe("tomato soup")
+			best.Description = "tomato"
+			Expect(db.Update(best.ID, best)).To(Succeed())
+			other := newRecipe("tomato soup")
+			newRecipe("tomato salad")
+
+			results := db.Search("soup tomato")
+			Expect(results).To(HaveLen(2))
+			Expect(results[0].ID).To(Equal(best.ID))
+			Expect(results[1].ID).To(Equal(other.ID))
+		})
+	})
+
+	Context("ingredient suggestions", func() {
+		It("should suggest distinct names starting with the prefix", func() {
+			soup := NewRecipe(NewRecipeID())
+			soup.Ingredients = []Ingredients{{Name: "Tomatoes"}, {Name: "Tomato  Paste"}, {Name: "Potatoes"}}
+			Expect(db.Insert(soup)).To(Succeed())
+			salad := NewRecipe(NewRecipeID())
+			salad.Ingredients = []Ingredients{{Name: "tomatoes "}, {Name: "Tofu"}}
+			Expect(db.Insert(salad)).To(Succeed())
+
+			Expect(db.SuggestIngredients("TOMA", 10)).To(Equal([]string{"tomato paste", "tomatoes"}))
+			Expect(db.SuggestIngredients("to", 2)).To(Equal([]string{"tofu", "tomato paste"}))
+			Expect(db.SuggestIngredients("x", 10)).To(BeEmpty())
+		})
+	})
+
+	Context("tags", func() {
+		It("should tag many recipes and report missing ones", func() {
+			soup := newRecipe("soup", "old")
+			stew := newRecipe("stew")
+			missing := NewRecipeID()
+
+			results, err := db.BulkTag([]RecipeID{soup.ID, stew.ID, missing}, []string{"quick"}, []string{"Old"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results).To(Equal(map[RecipeID]bool{soup.ID: true, stew.ID: true, missing: false}))
+			Expect(db.Get(soup.ID).Tags).To(Equal([]string{"quick"}))
+			Expect(db.Get(stew.ID).Tags).To(Equal([]string{"quick"}))
+			Expect(db.Get(stew.ID).UpdatedAt).To(BeTemporally(">=", stew.UpdatedAt))
+			Expect(db.Get(stew.ID).Version).To(Equal(stew.Version + 1))
+		})
+
+		It("should keep the version of recipes whose tags do not change", func() {
+			soup := newRecipe("soup", "Quick")
+
+			_, err := db.BulkTag([]RecipeID{soup.ID}, []string{"quick"}, []string{"old"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(db.Get(soup.ID).Version).To(Equal(soup.Version))
+		})
+
+		It("should count the recipes carrying each tag ignoring the case", func() {
+			newRecipe("soup", "Vegan", "quick")
+			newRecipe("stew", "vegan", " Vegan ")
+
+			Expect(db.Tags()).To(Equal([]TagCount{{Tag: "vegan", Count: 2}, {Tag: "quick", Count: 1}}))
+		})
+	})
+
+	Context("stats", func() {
+		It("should aggregate all recipes", func() {
+			newRecipe("soup", "Vegan")
+			newRecipe("stew", "vegan", "hearty")
+
+			stats, err := db.Stats()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stats.Total).To(Equal(int64(2)))
+			Expect(stats.Tags).To(Equal([]TagCount{{Tag: "vegan", Count: 2}, {Tag: "hearty", Count: 1}}))
+		})
+	})
+
+	Context("similar recipes", func() {
+		It("should find recipes with similar ingredients", func() {
+			soup := newRecipe("soup")
+			soup.Ingredients = []Ingredients{{Name: "tomato"}, {Name: "onion"}}
+			Expect(db.Update(soup.ID, soup)).To(Succeed())
+			duplicate := newRecipe("soup (copy)")
+			duplicate.Ingredients = []Ingredients{{Name: "Tomato"}, {Name: "Onion"}}
+			Expect(db.Update(duplicate.ID, duplicate)).To(Succeed())
+			newRecipe("cake")
+
+			results, err := db.FindSimilar(soup.ID, 0.5)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results).To(Equal([]SimilarRecipe{{ID: duplicate.ID, Name: duplicate.Name, Score: 1}}))
+
+			_, err = db.FindSimilar(NewRecipeID(), 0.5)
+			Expect(err).To(Equal(ErrRecipeNotFound))
+		})
+	})
+
+	Context("ratings and pictures", func() {
+		It("should average ratings", func() {
+			recipe := newRecipe("soup")
+			_, _ = db.AddRating(recipe.ID, 5)
+			rating, err := db.AddRating(recipe.ID, 2)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rating).To(Equal(float32(3.5)))
+			_, err = db.AddRating(NewRecipeID(), 2)
+			Expect(err).To(Equal(ErrRecipeNotFound))
+		})
+		It("should add and delete pictures", func() {
+			recipe := newRecipe("soup")
+			Expect(db.AddPicture(&RecipePicture{ID: recipe.ID, Name: "pic", Picture: "abc"})).To(Succeed())
+			Expect(db.Picture(recipe.ID, "pic").Picture).To(Equal("abc"))
+			Expect(db.Get(recipe.ID).PictureLink).To(Equal([]string{"pic"}))
+
+			Expect(db.DeletePicture(recipe.ID, "pic")).To(Succeed())
+			Expect(db.Pictures(recipe.ID)).To(BeEmpty())
+			Expect(db.Get(recipe.ID).PictureLink).To(BeEmpty())
+			Expect(db.DeletePicture(recipe.ID, "pic")).To(Equal(ErrPictureNotFound))
+		})
+		It("should order pictures", func() {
+			recipe := newRecipe("soup")
+			for _, name := range []string{"a", "b", "c"} {
+				Expect(db.AddPicture(&RecipePicture{ID: recipe.ID, Name: name, Picture: name})).To(Succeed())
+			}
+
+			links, err := db.SetPictureOrder(recipe.ID, []string{"b"}, "c")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(links).To(Equal([]string{"c", "b", "a"}))
+			Expect(db.Get(recipe.ID).PictureLink).To(Equal([]string{"c", "b", "a"}))
+
+			_, err = db.SetPictureOrder(recipe.ID, []string{"missing"}, "")
+			Expect(errors.Is(err, ErrPictureNotFound)).To(BeTrue())
+			_, err = db.SetPictureOrder(NewRecipeID(), []string{"a"}, "")
+			Expect(err).To(Equal(ErrRecipeNotFound))
+		})
+		It("should list notes newest first and remove them with the recipe", func() {
+			recipe := newRecipe("soup")
+			Expect(db.AddNote(&Note{ID: NewNoteID(), RecipeID: recipe.ID, Text: "first"})).To(Succeed())
+			Expect(db.AddNote(&Note{ID: NewNoteID(), RecipeID: recipe.ID, Text: "second"})).To(Succeed())
+
+			notes := db.Notes(recipe.ID)
+			Expect(notes).To(HaveLen(2))
+			Expect(notes[0].Text).To(Equal("second"))
+			Expect(notes[1].Text).To(Equal("first"))
+			Expect(notes[0].CreatedAt).ToNot(BeZero())
+
+			Expect(db.Remove(recipe.ID)).To(Succeed())
+			Expect(db.Notes(recipe.ID)).To(BeEmpty())
+			Expect(db.AddNote(&Note{ID: NewNoteID(), RecipeID: recipe.ID, Text: "late"})).To(Equal(ErrRecipeNotFound))
+		})
+	})
+
+	Context("merging", func() {
+		It("should move pictures, notes, and ratings to the primary recipe", func() {
+			soup := newRecipe("soup")
+			duplicate := newRecipe("soup")
+			Expect(db.AddPicture(&RecipePicture{ID: soup.ID, Name: "soup.png", Picture: "abc"})).To(Succeed())
+			Expect(db.AddPicture(&RecipePicture{ID: duplicate.ID, Name: "soup.png", Picture: "def"})).To(Succeed())
+			Expect(db.AddPicture(&RecipePicture{ID: duplicate.ID, Name: "bowl.png", Picture: "ghi"})).To(Succeed())
+			Expect(db.AddNote(&Note{ID: NewNoteID(), RecipeID: soup.ID, Text: "first"})).To(Succeed())
+			Expect(db.AddNote(&Note{ID: NewNoteID(), RecipeID: duplicate.ID, Text: "second"})).To(Succeed())
+			_, _ = db.AddRating(soup.ID, 5)
+			_, _ = db.AddRating(duplicate.ID, 3)
+			_, _ = db.AddRating(duplicate.ID, 3)
+
+			merged, err := db.Merge(soup.ID, []RecipeID{duplicate.ID})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(merged.ID).To(Equal(soup.ID))
+			Expect(merged.Rating).To(BeNumerically("~", 11.0/3.0, 0.0001))
+			Expect(merged.RatingCount).To(Equal(3))
+			Expect(merged.PictureLink).To(Equal([]string{"soup.png", "bowl.png"}))
+
+			Expect(db.Picture(soup.ID, "soup.png").Picture).To(Equal("abc"))
+			Expect(db.Picture(soup.ID, "bowl.png").ID).To(Equal(soup.ID))
+			notes := db.Notes(soup.ID)
+			Expect(notes).To(HaveLen(2))
+			Expect(notes[0].Text).To(Equal("second"))
+			Expect(notes[0].RecipeID).To(Equal(soup.ID))
+
+			Expect(db.Get(duplicate.ID).ID).To(Equal(InvalidRecipeID()))
+			Expect(db.Pictures(duplicate.ID)).To(BeEmpty())
+			Expect(db.Notes(duplicate.ID)).To(BeEmpty())
+		})
+		It("should not change any recipe if a duplicate does not exist", func() {
+			soup := newRecipe("soup")
+			duplicate := newRecipe("soup")
+			_, _ = db.AddRating(duplicate.ID, 3)
+
+			_, err := db.Merge(soup.ID, []RecipeID{duplicate.ID, NewRecipeID()})
+			Expect(err).To(MatchError(ErrRecipeNotFound))
+			Expect(db.Get(duplicate.ID).ID).To(Equal(duplicate.ID))
+			Expect(db.Get(soup.ID).RatingCount).To(BeZero())
+		})
+	})
+
+	Context("seeding", func() {
+		It("should insert the recipes of a JSON file", func() {
+			file, err := ioutil.TempFile("", "recipes-*.json")
+			Expect(err).ToNot(HaveOccurred())
+			defer func() { _ = os.Remove(file.Name()) }()
+			_, err = file.WriteString(`[{"name":"soup","components":[{"name":"water","amount":1,"unit":"Liter"}]},{"name":"stew"}]`)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(file.Close()).To(Succeed())
+
+			Expect(db.SeedFromFile(file.Name())).To(Succeed())
+			Expect(db.Num()).To(Equal(int64(2)))
+			soup, err := db.GetByName("soup")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(soup.ID).ToNot(BeEmpty())
+			Expect(soup.Ingredients[0].Unit).To(Equal("l"))
+		})
+		It("should fail for files that do not exist", func() {
+			Expect(db.SeedFromFile("does-not-exist.json")).ToNot(Succeed())
+		})
+	})
+})
@@ -26,9 +26,18 @@ package recipes
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/satori/go.uuid"
 	log "github.com/sirupsen/logrus"
+
+	"github.com/ottenwbe/recipes-manager/core"
+	"github.com/ottenwbe/recipes-manager/units"
 )
 
 //Ingredients of a recipe
@@ -37,8 +46,45 @@ type Ingredients struct {
 	Name string `json:"name"`
 	//Amount needed in a recipe of an ingredient
 	Amount float64 `json:"amount"`
+	//AmountMax is the upper end of a range of amounts, e.g., 3 for '2-3 cloves'; 0 if the amount is no range
+	AmountMax float64 `json:"amountMax,omitempty"`
 	//Unit of the Amount
 	Unit string `json:"unit"`
+	//Group of the ingredient, e.g., 'For the sauce'; ingredients without a group are listed first
+	Group string `json:"group,omitempty"`
+}
+
+//NormalizeUnit replaces the ingredient's unit by its canonical form, e.g., 'Tbsp.' by 'tbsp'
+func (i *Ingredients) NormalizeUnit() {
+	i.Unit = units.Normalize(i.Unit)
+}
+
+//ConvertUnit of the ingredient to the given system of units.
+//Ingredients without an amount or with units that cannot be converted remain unchanged, e.g., volumes of ingredients without a known density for units.ByWeight.
+func (i *Ingredients) ConvertUnit(system units.System) {
+	if i.Amount <= 0 || i.Unit == "" {
+		return
+	}
+	var (
+		amount float64
+		unit   string
+		err    error
+	)
+	if system == units.ByWeight {
+		amount, unit, err = units.ConvertToWeight(i.Amount, i.Unit, i.Name)
+	} else {
+		amount, unit, err = units.Convert(i.Amount, i.Unit, system)
+	}
+	if err != nil {
+		log.WithError(err).WithField("unit", i.Unit).Debug("Could not convert unit of ingredient")
+		return
+	}
+	if i.AmountMax > 0 {
+		// both ends of a range share the unit chosen for the lower end
+		i.AmountMax = roundAmount(i.AmountMax * amount / i.Amount)
+	}
+	i.Amount = roundAmount(amount)
+	i.Unit = unit
 }
 
 const (
@@ -64,15 +110,23 @@ func NewRecipeID() RecipeID {
 	return RecipeID(uuid.NewV4().String())
 }
 
+//ErrInvalidRecipeID is returned when a string is not a syntactically valid recipe id, i.e., neither a uuid nor a slug
+var ErrInvalidRecipeID = errors.New("invalid recipe id")
+
+//validSlugID matches the ids generated by the SlugStrategy and the SequentialStrategy, e.g., apple-pie-2 or 42
+var validSlugID = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
 //NewRecipeIDFromString converts a string to a recipe id and returns this recipe id.
-//Returns the InvalidRecipeID iff the recipe id cannot be converted
-func NewRecipeIDFromString(recipeID string) (result RecipeID) {
-	if tmp, err := uuid.FromString(recipeID); err != nil {
-		result = InvalidRecipeID()
-	} else {
-		result = RecipeID(tmp.String())
+//Uuids as well as slugs, i.e., lower case letters and digits separated by dashes, are recipe ids.
+//Returns the InvalidRecipeID and an error wrapping ErrInvalidRecipeID iff the recipe id cannot be converted
+func NewRecipeIDFromString(recipeID string) (RecipeID, error) {
+	if tmp, err := uuid.FromString(recipeID); err == nil {
+		return RecipeID(tmp.String()), nil
+	}
+	if len(recipeID) <= maxSlugLength+maxSlugSuffixLength && validSlugID.MatchString(recipeID) {
+		return RecipeID(recipeID), nil
 	}
-	return
+	return InvalidRecipeID(), fmt.Errorf("%w '%v'", ErrInvalidRecipeID, recipeID)
 }
 
 //Recipe model
@@ -81,8 +135,132 @@ type Recipe struct {
 	Name        string        `json:"name"`
 	Ingredients []Ingredients `json:"components"`
 	Description string        `json:"description"`
-	PictureLink []string      `json:"pictureLink"`
-	Servings    int8          `json:"servings"`
+	//Steps are the structured instructions of the recipe; Description keeps the instructions of clients that do not know steps
+	Steps       []RecipeStep `json:"steps,omitempty"`
+	PictureLink []string     `json:"pictureLink"`
+	Servings    int8         `json:"servings"`
+	//PrepMinutes is the time to prepare the recipe before cooking; 0 if unknown
+	PrepMinutes int `json:"prepMinutes"`
+	//CookMinutes is the time to cook the recipe; 0 if unknown
+	CookMinutes int `json:"cookMinutes"`
+	//Difficulty of the recipe, i.e., DifficultyEasy, DifficultyMedium, or DifficultyHard; empty if unknown
+	Difficulty  string   `json:"difficulty,omitempty" enums:"easy,medium,hard"`
+	Tags        []string `json:"tags"`
+	Rating      float32  `json:"rating"`
+	RatingCount int      `json:"ratingCount"`
+	//Version is incremented with each update, so that concurrent updates do not overwrite each other
+	Version int `json:"version"`
+	//CreatedAt is set by the database when the recipe is inserted
+	CreatedAt time.Time `json:"createdAt"`
+	//UpdatedAt is set by the database whenever the recipe changes, e.g., when it is updated or rated
+	UpdatedAt time.Time  `json:"updatedAt"`
+	Nutrition *Nutrition `json:"nutrition,omitempty"`
+	//Archived recipes are excluded from listings until they are restored; updates keep the archived state
+	Archived bool `json:"archived,omitempty"`
+	//OriginalUnitSystem is the system of units the recipe was written in, i.e., metric or imperial; empty if unknown.
+	//Recipes are stored in their original units and converted when they are read.
+	OriginalUnitSystem string `json:"originalUnitSystem,omitempty" enums:"metric,imperial"`
+}
+
+//RecipeStep is one instruction of a recipe
+type RecipeStep struct {
+	Text string `json:"text"`
+	//Minutes the step takes, e.g., to let a dough rest; 0 if unknown
+	Minutes int `json:"minutes,omitempty"`
+}
+
+//RecipeTimer is a cooking timer for a step of a recipe
+type RecipeTimer struct {
+	//Step is the number of the step, starting with 1
+	Step int `json:"step"`
+	//Label is the text of the step
+	Label   string `json:"label"`
+	Seconds int    `json:"seconds"`
+}
+
+//Timers for all steps of the recipe that take some time
+func (r *Recipe) Timers() []RecipeTimer {
+	timers := make([]RecipeTimer, 0)
+	for i, step := range r.Steps {
+		if step.Minutes > 0 {
+			timers = append(timers, RecipeTimer{Step: i + 1, Label: strings.TrimSpace(step.Text), Seconds: step.Minutes * 60})
+		}
+	}
+	return timers
+}
+
+const (
+	//DifficultyEasy marks recipes for beginners
+	DifficultyEasy = "easy"
+	//DifficultyMedium marks recipes which need some experience
+	DifficultyMedium = "medium"
+	//DifficultyHard marks recipes for experienced cooks
+	DifficultyHard = "hard"
+)
+
+//validDifficulty checks if the difficulty is unknown, i.e., empty, or one of the known difficulties
+func validDifficulty(difficulty string) bool {
+	switch difficulty {
+	case "", DifficultyEasy, DifficultyMedium, DifficultyHard:
+		return true
+	}
+	return false
+}
+
+//totalMinutes to prepare and cook the recipe; 0 if neither time is known
+func (r *Recipe) totalMinutes() int {
+	return r.PrepMinutes + r.CookMinutes
+}
+
+//changeTime returns the time stamp for changes of recipes; MongoDB stores time stamps with a precision of milliseconds
+func changeTime() time.Time {
+	return time.Now().UTC().Truncate(time.Millisecond)
+}
+
+//Nutrition of one serving of a recipe. Values which are not known are nil, e.g., when only the calories are known.
+type Nutrition struct {
+	//Calories in kcal
+	Calories *float64 `json:"calories,omitempty"`
+	//Protein in g
+	Protein *float64 `json:"protein,omitempty"`
+	//Carbs in g
+	Carbs *float64 `json:"carbs,omitempty"`
+	//Fat in g
+	Fat *float64 `json:"fat,omitempty"`
+}
+
+func (n *Nutrition) copy() *Nutrition {
+	return &Nutrition{
+		Calories: copyValue(n.Calories),
+		Protein:  copyValue(n.Protein),
+		Carbs:    copyValue(n.Carbs),
+		Fat:      copyValue(n.Fat),
+	}
+}
+
+func copyValue(value *float64) *float64 {
+	if value == nil {
+		return nil
+	}
+	c := *value
+	return &c
+}
+
+const (
+	//MinRating is the worst rating a recipe can get
+	MinRating = 1
+	//MaxRating is the best rating a recipe can get
+	MaxRating = 5
+)
+
+//RatingInput models a single rating of a recipe
+type RatingInput struct {
+	Rating int `json:"rating"`
+}
+
+//RatingResult models the average rating of a recipe
+type RatingResult struct {
+	Rating float32 `json:"rating"`
 }
 
 //RecipePicture model
@@ -90,6 +268,23 @@ type RecipePicture struct {
 	ID      RecipeID `json:"id"`
 	Name    string   `json:"name"`
 	Picture string   `json:"picture"`
+	//Thumbnail is a base64 encoded, scaled down version of the picture; it is empty for pictures without a thumbnail
+	Thumbnail string `json:"-"`
+}
+
+//PictureUploadResult informs clients about the name of an uploaded picture
+type PictureUploadResult struct {
+	Name string `json:"name"`
+	//Width and Height of the stored picture in pixels; omitted if the picture's format is unknown, e.g., for webp pictures
+	Width  int `json:"width,omitempty"`
+	Height int `json:"height,omitempty"`
+}
+
+//RecipeBatch models the result of fetching multiple recipes at once
+type RecipeBatch struct {
+	Recipes []*Recipe `json:"recipes"`
+	//NotFound lists the requested ids of recipes that do not exist
+	NotFound []string `json:"notFound"`
 }
 
 //RecipeList models a list of recipes by ID
@@ -97,6 +292,38 @@ type RecipeList struct {
 	Recipes []string `json:"recipes"`
 }
 
+//RecipeSummary is a lightweight view of a recipe, e.g., to render lists of recipes without fetching each recipe
+type RecipeSummary struct {
+	ID   RecipeID `json:"id"`
+	Name string   `json:"name"`
+	//Picture is the link to the primary picture of the recipe; empty if the recipe has no pictures
+	Picture     string   `json:"picture,omitempty"`
+	Tags        []string `json:"tags"`
+	Rating      float32  `json:"rating"`
+	RatingCount int      `json:"ratingCount"`
+}
+
+//RecipeSummaries models a list of recipes by their summaries
+type RecipeSummaries struct {
+	Recipes []RecipeSummary `json:"recipes"`
+}
+
+//Summary of the recipe
+func (r *Recipe) Summary() RecipeSummary {
+	summary := RecipeSummary{
+		ID:          r.ID,
+		Name:        r.Name,
+		Tags:        make([]string, len(r.Tags)),
+		Rating:      r.Rating,
+		RatingCount: r.RatingCount,
+	}
+	copy(summary.Tags, r.Tags)
+	if len(r.PictureLink) > 0 {
+		summary.Picture = r.PictureLink[0]
+	}
+	return summary
+}
+
 //NewInvalidRecipePicture returns an invalid picture
 func NewInvalidRecipePicture() *RecipePicture {
 	return &RecipePicture{
@@ -111,6 +338,59 @@ type RecipeSearchFilter struct {
 	Name        string   `json:"name"`
 	Ingredient  []string `json:"ingredients"`
 	Description string   `json:"description"`
+	//Tag restricts the search to recipes carrying this tag
+	Tag string `json:"tag"`
+	//ChangedSince restricts the results to recipes updated after this time, unless it is the zero time
+	ChangedSince time.Time `json:"changedSince"`
+	//MaxTotalTime restricts the results to recipes which can be prepared and cooked in this many minutes, unless it is 0.
+	//Recipes without prep and cook times are excluded.
+	MaxTotalTime int `json:"maxTotalTime,omitempty"`
+	//Sort defines the order of the results, e.g., SortByRating; by default recipes are ordered by their creation
+	Sort string `json:"sort"`
+	//Order defines the direction of Sort, i.e., OrderAscending or OrderDescending; by default the direction depends on Sort
+	Order string `json:"order,omitempty"`
+	//Archived restricts the results to archived recipes; by default archived recipes are excluded
+	Archived bool `json:"archived,omitempty"`
+}
+
+const (
+	//SortByName orders recipes alphabetically by their name, ignoring the case
+	SortByName = "name"
+	//SortByCreated orders recipes by their creation, oldest recipes first
+	SortByCreated = "created"
+	//SortByRating orders recipes by their rating, best rated recipes first
+	SortByRating = "rating"
+	//SortByCalories orders recipes by the calories of one serving, lightest recipes first
+	SortByCalories = "calories"
+)
+
+const (
+	//OrderAscending sorts recipes with the smallest values first
+	OrderAscending = "asc"
+	//OrderDescending sorts recipes with the largest values first
+	OrderDescending = "desc"
+)
+
+//validSort checks if recipes can be sorted by the field; an empty field selects the default order
+func validSort(field string) bool {
+	switch field {
+	case "", SortByName, SortByCreated, SortByRating, SortByCalories:
+		return true
+	}
+	return false
+}
+
+//validOrder checks if order is a known direction; an empty order selects the default direction of the sort field
+func validOrder(order string) bool {
+	return order == "" || order == OrderAscending || order == OrderDescending
+}
+
+//descending reports if the results have to be sorted from the largest to the smallest value
+func (f *RecipeSearchFilter) descending() bool {
+	if f.Order == "" {
+		return f.Sort == SortByRating
+	}
+	return f.Order == OrderDescending
 }
 
 //Recipes interface is an abstraction for the provider of a collection of recipes, i.e., a data-base or a cache
@@ -123,7 +403,10 @@ type Recipes interface {
 	Picture(id RecipeID, name string) *RecipePicture
 	Pictures(id RecipeID) map[string]*RecipePicture
 	Random() *Recipe
+	//Insert a new recipe; ErrRecipeExists is returned if a recipe with the same id exists
 	Insert(recipe *Recipe) error
+	//Update replaces a recipe if the recipe's version is the stored version and increments the version.
+	//ErrVersionConflict is returned if the stored recipe has been updated in the meantime.
 	Update(id RecipeID, recipe *Recipe) error
 	AddPicture(pic *RecipePicture) error
 	Remove(id RecipeID) error
@@ -152,7 +435,101 @@ func NewRecipe(id RecipeID) *Recipe {
 		Description: "",
 		PictureLink: make([]string, 0),
 		Servings:    1,
+		Tags:        make([]string, 0),
+	}
+}
+
+//copySuffix is appended to the name of copied recipes
+const copySuffix = " (copy)"
+
+//Copy returns a deep copy of the recipe with a new id and a name marked as copy. The copy starts without ratings and with version 0.
+func (r *Recipe) Copy() *Recipe {
+	c := r.clone()
+	c.ID = NewRecipeID()
+	c.Name = r.Name + copySuffix
+	c.Rating = 0
+	c.RatingCount = 0
+	c.Version = 0
+	return c
+}
+
+//clone returns a deep copy of the recipe
+func (r *Recipe) clone() *Recipe {
+	c := *r
+	c.Ingredients = append(make([]Ingredients, 0, len(r.Ingredients)), r.Ingredients...)
+	c.PictureLink = append(make([]string, 0, len(r.PictureLink)), r.PictureLink...)
+	c.Tags = append(make([]string, 0, len(r.Tags)), r.Tags...)
+	if r.Steps != nil {
+		c.Steps = append(make([]RecipeStep, 0, len(r.Steps)), r.Steps...)
+	}
+	if r.Nutrition != nil {
+		c.Nutrition = r.Nutrition.copy()
+	}
+	return &c
+}
+
+//ValidationError lists all invalid fields of a recipe
+type ValidationError struct {
+	Fields []core.FieldError
+}
+
+//Error lists the invalid fields
+func (e *ValidationError) Error() string {
+	fields := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		fields[i] = fmt.Sprintf("%v %v", field.Field, field.Message)
+	}
+	return "invalid recipe: " + strings.Join(fields, ", ")
+}
+
+//Validate checks that the recipe has a name, a positive number of servings, that all ingredients have a name and
+//a non-negative amount or NoAmountIngredient, and that all steps have a text. The returned error is a *ValidationError.
+func (r *Recipe) Validate() error {
+	fields := make([]core.FieldError, 0)
+	if strings.TrimSpace(r.Name) == "" {
+		fields = append(fields, core.FieldError{Field: "name", Message: "must not be empty"})
+	}
+	if r.Servings <= 0 {
+		fields = append(fields, core.FieldError{Field: "servings", Message: "must be positive"})
+	}
+	if r.PrepMinutes < 0 {
+		fields = append(fields, core.FieldError{Field: "prepMinutes", Message: "must not be negative"})
 	}
+	if r.CookMinutes < 0 {
+		fields = append(fields, core.FieldError{Field: "cookMinutes", Message: "must not be negative"})
+	}
+	if !validDifficulty(r.Difficulty) {
+		fields = append(fields, core.FieldError{Field: "difficulty", Message: "must be easy, medium, or hard"})
+	}
+	if system, err := units.ParseSystem(r.OriginalUnitSystem); r.OriginalUnitSystem != "" && (err != nil || system == units.ByWeight) {
+		fields = append(fields, core.FieldError{Field: "originalUnitSystem", Message: "must be metric or imperial"})
+	}
+	for i, ingredient := range r.Ingredients {
+		if strings.TrimSpace(ingredient.Name) == "" {
+			fields = append(fields, core.FieldError{Field: fmt.Sprintf("components[%v].name", i), Message: "must not be empty"})
+		}
+		if ingredient.Amount < 0 && ingredient.Amount != NoAmountIngredient {
+			fields = append(fields, core.FieldError{Field: fmt.Sprintf("components[%v].amount", i), Message: "must not be negative"})
+		}
+		if ingredient.AmountMax < 0 {
+			fields = append(fields, core.FieldError{Field: fmt.Sprintf("components[%v].amountMax", i), Message: "must not be negative"})
+		} else if ingredient.AmountMax > 0 && ingredient.AmountMax < ingredient.Amount {
+			fields = append(fields, core.FieldError{Field: fmt.Sprintf("components[%v].amountMax", i), Message: "must not be less than amount"})
+		}
+	}
+	for i, step := range r.Steps {
+		if strings.TrimSpace(step.Text) == "" {
+			fields = append(fields, core.FieldError{Field: fmt.Sprintf("steps[%v].text", i), Message: "must not be empty"})
+		}
+		if step.Minutes < 0 {
+			fields = append(fields, core.FieldError{Field: fmt.Sprintf("steps[%v].minutes", i), Message: "must not be negative"})
+		}
+	}
+
+	if len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+	return nil
 }
 
 //JSON returns the encoded version of the recipe. If an error occurs, '{}' is returned.
@@ -170,18 +547,52 @@ func (r *Recipe) String() string {
 	return string(r.JSON())
 }
 
-//ScaleBy a factor (of servings) all ingredients of the recipe
+//ScaleBy a factor (of servings) all ingredients of the recipe.
+//Ingredients without an amount, e.g., 'salt to taste', are not scaled. Both ends of ranges are scaled.
+//Scaled amounts are rounded to 2 decimals.
 func (r *Recipe) ScaleBy(factor float64) {
 	for i := range r.Ingredients {
 		if r.Ingredients[i].Amount > 0 {
-			r.Ingredients[i].Amount *= factor
+			r.Ingredients[i].Amount = roundAmount(r.Ingredients[i].Amount * factor)
+		}
+		if r.Ingredients[i].AmountMax > 0 {
+			r.Ingredients[i].AmountMax = roundAmount(r.Ingredients[i].AmountMax * factor)
 		}
 	}
 }
 
-//ScaleTo a desired number of servings
+//ScaleTo a desired number of servings. Recipes without servings cannot be scaled and remain unchanged.
+//The nutrition is given per serving and, hence, does not change.
 func (r *Recipe) ScaleTo(servings int8) {
+	if r.Servings == 0 {
+		log.WithField("recipe", r.ID).Warn("Cannot scale a recipe without servings")
+		return
+	}
 	factor := float64(servings) / float64(r.Servings)
 	r.Servings = servings
 	r.ScaleBy(factor)
 }
+
+//roundAmount to 2 decimals to avoid floating point noise, e.g., 0.30000000000000004
+func roundAmount(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+//NormalizeUnits of all ingredients of the recipe
+func (r *Recipe) NormalizeUnits() {
+	for i := range r.Ingredients {
+		r.Ingredients[i].NormalizeUnit()
+	}
+}
+
+//ConvertUnits of all ingredients of the recipe to the given system of units.
+//Temperatures in the description and the steps, e.g., 350°F, are converted as well.
+func (r *Recipe) ConvertUnits(system units.System) {
+	for i := range r.Ingredients {
+		r.Ingredients[i].ConvertUnit(system)
+	}
+	r.Description = units.ConvertTemperatures(r.Description, system)
+	for i := range r.Steps {
+		r.Steps[i].Text = units.ConvertTemperatures(r.Steps[i].Text, system)
+	}
+}
@@ -25,8 +25,14 @@
 package recipes
 
 import (
+	"errors"
+	"strings"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+
+	"github.com/ottenwbe/recipes-manager/core"
+	"github.com/ottenwbe/recipes-manager/units"
 )
 
 var _ = Describe("recipes", func() {
@@ -46,13 +52,25 @@ var _ = Describe("recipes", func() {
 
 		It("should be able to convert an id from and to a string", func() {
 			idString := "40ac4297-d5b3-435e-9f42-e5e3479d0ae8"
-			id := NewRecipeIDFromString(idString)
+			id, err := NewRecipeIDFromString(idString)
+			Expect(err).ToNot(HaveOccurred())
 			Expect(id.String()).To(Equal(idString))
 		})
 
-		It("should return an invalid id when it cannot convert a string to a uuid", func() {
-			idFromString := NewRecipeIDFromString("inv")
-			Expect(idFromString).To(Equal(InvalidRecipeID()))
+		It("should return an invalid id when it cannot convert a string to a uuid or a slug", func() {
+			for _, invalid := range []string{"inv!", "Apple-Pie", "apple--pie", "-apple", "apple pie", "", strings.Repeat("a", 100)} {
+				idFromString, err := NewRecipeIDFromString(invalid)
+				Expect(idFromString).To(Equal(InvalidRecipeID()), invalid)
+				Expect(errors.Is(err, ErrInvalidRecipeID)).To(BeTrue(), invalid)
+			}
+		})
+
+		It("should accept slugs and numbers as ids", func() {
+			for _, valid := range []string{"apple-pie", "apple-pie-2", "42"} {
+				id, err := NewRecipeIDFromString(valid)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(id.String()).To(Equal(valid))
+			}
 		})
 
 	})
@@ -71,15 +89,171 @@ var _ = Describe("recipes", func() {
 		})
 	})
 
+	Context("copy", func() {
+		It("should copy a recipe with a new id and without ratings", func() {
+			calories := 420.0
+			recipe := &Recipe{
+				ID:          NewRecipeID(),
+				Name:        "soup",
+				Ingredients: []Ingredients{{Amount: 1, Name: "test1", Unit: "l"}},
+				Tags:        []string{"vegan"},
+				Rating:      4.5,
+				RatingCount: 2,
+				Nutrition:   &Nutrition{Calories: &calories},
+			}
+			recipeCopy := recipe.Copy()
+			Expect(recipeCopy.ID).ToNot(Equal(recipe.ID))
+			Expect(recipeCopy.Name).To(Equal("soup (copy)"))
+			Expect(recipeCopy.Ingredients).To(Equal(recipe.Ingredients))
+			Expect(recipeCopy.Tags).To(Equal(recipe.Tags))
+			Expect(recipeCopy.Rating).To(BeZero())
+			Expect(recipeCopy.RatingCount).To(BeZero())
+			Expect(*recipeCopy.Nutrition.Calories).To(Equal(calories))
+		})
+		It("should not share ingredients or nutrition with the original recipe", func() {
+			calories := 420.0
+			recipe := &Recipe{
+				Ingredients: []Ingredients{{Amount: 1, Name: "test1", Unit: "l"}},
+				Nutrition:   &Nutrition{Calories: &calories},
+			}
+			recipeCopy := recipe.Copy()
+			recipeCopy.Ingredients[0].Amount = 2
+			*recipeCopy.Nutrition.Calories = 100
+			Expect(recipe.Ingredients[0].Amount).To(Equal(1.0))
+			Expect(*recipe.Nutrition.Calories).To(Equal(420.0))
+		})
+		It("should not share steps with the original recipe", func() {
+			recipe := &Recipe{Steps: []RecipeStep{{Text: "boil", Minutes: 10}}}
+			recipeCopy := recipe.Copy()
+			recipeCopy.Steps[0].Minutes = 20
+			Expect(recipe.Steps).To(Equal([]RecipeStep{{Text: "boil", Minutes: 10}}))
+		})
+	})
+
+	Context("summary", func() {
+		It("should summarize a recipe with its primary picture", func() {
+			recipe := &Recipe{
+				ID:          NewRecipeID(),
+				Name:        "soup",
+				PictureLink: []string{"primary", "other"},
+				Tags:        []string{"vegan"},
+				Rating:      4.5,
+				RatingCount: 2,
+			}
+			Expect(recipe.Summary()).To(Equal(RecipeSummary{ID: recipe.ID, Name: "soup", Picture: "primary", Tags: []string{"vegan"}, Rating: 4.5, RatingCount: 2}))
+		})
+		It("should summarize a recipe without pictures or tags", func() {
+			summary := (&Recipe{Name: "soup"}).Summary()
+			Expect(summary.Picture).To(BeEmpty())
+			Expect(summary.Tags).To(BeEmpty())
+			Expect(summary.Tags).ToNot(BeNil())
+		})
+	})
+
+	Context("validation", func() {
+		It("should accept valid recipes", func() {
+			recipe := Recipe{
+				Name:     "soup",
+				Servings: 2,
+				Ingredients: []Ingredients{
+					{Amount: 1, Name: "water", Unit: "l"},
+					{Amount: NoAmountIngredient, Name: "salt"},
+				},
+			}
+			Expect(recipe.Validate()).To(Succeed())
+		})
+		It("should report all invalid fields", func() {
+			recipe := Recipe{
+				Name:     " ",
+				Servings: 0,
+				Ingredients: []Ingredients{
+					{Amount: 1, Name: "water", Unit: "l"},
+					{Amount: -2, Name: ""},
+				},
+			}
+			err := recipe.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.(*ValidationError).Fields).To(Equal([]core.FieldError{
+				{Field: "name", Message: "must not be empty"},
+				{Field: "servings", Message: "must be positive"},
+				{Field: "components[1].name", Message: "must not be empty"},
+				{Field: "components[1].amount", Message: "must not be negative"},
+			}))
+			Expect(err.Error()).To(HavePrefix("invalid recipe: name must not be empty, servings must be positive"))
+		})
+		It("should reject negative times and unknown difficulties", func() {
+			recipe := Recipe{Name: "soup", Servings: 2, PrepMinutes: -1, CookMinutes: -5, Difficulty: "impossible"}
+			err := recipe.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.(*ValidationError).Fields).To(Equal([]core.FieldError{
+				{Field: "prepMinutes", Message: "must not be negative"},
+				{Field: "cookMinutes", Message: "must not be negative"},
+				{Field: "difficulty", Message: "must be easy, medium, or hard"},
+			}))
+
+			recipe = Recipe{Name: "soup", Servings: 2, PrepMinutes: 10, CookMinutes: 20, Difficulty: DifficultyMedium}
+			Expect(recipe.Validate()).To(Succeed())
+		})
+		It("should reject unknown systems of units", func() {
+			recipe := Recipe{Name: "soup", Servings: 2, OriginalUnitSystem: "nautical"}
+			err := recipe.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.(*ValidationError).Fields).To(Equal([]core.FieldError{
+				{Field: "originalUnitSystem", Message: "must be metric or imperial"},
+			}))
+
+			recipe.OriginalUnitSystem = string(units.ByWeight)
+			Expect(recipe.Validate()).ToNot(Succeed())
+
+			recipe.OriginalUnitSystem = string(units.Metric)
+			Expect(recipe.Validate()).To(Succeed())
+		})
+		It("should reject ranges of amounts with an invalid upper end", func() {
+			recipe := Recipe{Name: "soup", Servings: 2, Ingredients: []Ingredients{
+				{Name: "garlic", Amount: 3, AmountMax: 2},
+				{Name: "salt", Amount: 1, AmountMax: -1},
+				{Name: "pepper", Amount: 1, AmountMax: 2},
+			}}
+			err := recipe.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.(*ValidationError).Fields).To(Equal([]core.FieldError{
+				{Field: "components[0].amountMax", Message: "must not be less than amount"},
+				{Field: "components[1].amountMax", Message: "must not be negative"},
+			}))
+		})
+		It("should reject steps without text and with negative minutes", func() {
+			recipe := Recipe{Name: "soup", Servings: 2, Steps: []RecipeStep{{Text: "boil", Minutes: 10}, {Text: " ", Minutes: -1}}}
+			err := recipe.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.(*ValidationError).Fields).To(Equal([]core.FieldError{
+				{Field: "steps[1].text", Message: "must not be empty"},
+				{Field: "steps[1].minutes", Message: "must not be negative"},
+			}))
+		})
+	})
+
+	Context("timers", func() {
+		It("should list the steps which take some time", func() {
+			recipe := Recipe{Steps: []RecipeStep{{Text: "Mix everything."}, {Text: " Let the dough rest. ", Minutes: 30}, {Text: "Bake.", Minutes: 45}}}
+			Expect(recipe.Timers()).To(Equal([]RecipeTimer{
+				{Step: 2, Label: "Let the dough rest.", Seconds: 1800},
+				{Step: 3, Label: "Bake.", Seconds: 2700},
+			}))
+		})
+		It("should be empty without steps", func() {
+			Expect((&Recipe{Description: "Bake for 45 minutes."}).Timers()).To(BeEmpty())
+		})
+	})
+
 	Context("conversion", func() {
 		It("should be able to convert a recipe to a string", func() {
-			expected := "{\"id\":\"\",\"name\":\"\",\"components\":null,\"description\":\"\",\"pictureLink\":null,\"servings\":0}"
+			expected := "{\"id\":\"\",\"name\":\"\",\"components\":null,\"description\":\"\",\"pictureLink\":null,\"servings\":0,\"prepMinutes\":0,\"cookMinutes\":0,\"tags\":null,\"rating\":0,\"ratingCount\":0,\"version\":0,\"createdAt\":\"0001-01-01T00:00:00Z\",\"updatedAt\":\"0001-01-01T00:00:00Z\"}"
 			retrieved := &Recipe{}
 			Expect(retrieved.String()).To(Equal(expected))
 		})
 
 		It("should be able to convert a recipe to a json byte string", func() {
-			expected := []byte("{\"id\":\"\",\"name\":\"\",\"components\":null,\"description\":\"\",\"pictureLink\":null,\"servings\":0}")
+			expected := []byte("{\"id\":\"\",\"name\":\"\",\"components\":null,\"description\":\"\",\"pictureLink\":null,\"servings\":0,\"prepMinutes\":0,\"cookMinutes\":0,\"tags\":null,\"rating\":0,\"ratingCount\":0,\"version\":0,\"createdAt\":\"0001-01-01T00:00:00Z\",\"updatedAt\":\"0001-01-01T00:00:00Z\"}")
 			r := &Recipe{}
 			Expect(r.JSON()).To(Equal(expected))
 		})
@@ -146,5 +320,128 @@ var _ = Describe("recipes", func() {
 			Expect(recipe.Ingredients[0].Amount).To(Equal(-1.0))
 			Expect(recipe.Ingredients[1].Amount).To(Equal(1.0))
 		})
+		It("should ignore amounts of 0", func() {
+			recipe := Recipe{
+				Servings: 2,
+				Ingredients: []Ingredients{
+					{Amount: 0, Name: "salt"},
+				},
+			}
+			recipe.ScaleTo(4)
+			Expect(recipe.Ingredients[0].Amount).To(Equal(0.0))
+		})
+		It("should round scaled amounts to 2 decimals", func() {
+			recipe := Recipe{
+				Servings: 1,
+				Ingredients: []Ingredients{
+					{Amount: 0.1, Name: "test1", Unit: "l"},
+					{Amount: 1, Name: "test2", Unit: "g"},
+				},
+			}
+			recipe.ScaleTo(3)
+			Expect(recipe.Ingredients[0].Amount).To(Equal(0.3))
+			recipe.ScaleTo(1)
+			Expect(recipe.Ingredients[1].Amount).To(Equal(1.0))
+		})
+		It("should scale both ends of ranges", func() {
+			recipe := Recipe{
+				Servings: 2,
+				Ingredients: []Ingredients{
+					{Amount: 2, AmountMax: 3, Name: "garlic"},
+					{Amount: 100, Name: "butter", Unit: "g"},
+				},
+			}
+			recipe.ScaleTo(4)
+			Expect(recipe.Ingredients[0]).To(Equal(Ingredients{Amount: 4, AmountMax: 6, Name: "garlic"}))
+			Expect(recipe.Ingredients[1]).To(Equal(Ingredients{Amount: 200, Name: "butter", Unit: "g"}))
+		})
+		It("should not scale recipes without servings", func() {
+			recipe := Recipe{
+				Servings: 0,
+				Ingredients: []Ingredients{
+					{Amount: 2, Name: "test1", Unit: "g"},
+				},
+			}
+			recipe.ScaleTo(4)
+			Expect(recipe.Servings).To(Equal(int8(0)))
+			Expect(recipe.Ingredients[0].Amount).To(Equal(2.0))
+		})
+	})
+
+	Context("nutrition", func() {
+		It("should allow partial nutrition", func() {
+			calories := 420.0
+			recipe := &Recipe{Nutrition: &Nutrition{Calories: &calories}}
+			Expect(recipe.String()).To(HaveSuffix(",\"updatedAt\":\"0001-01-01T00:00:00Z\",\"nutrition\":{\"calories\":420}}"))
+		})
+		It("should keep the nutrition per serving when scaling to a number of servings", func() {
+			calories, fat := 420.0, 12.0
+			recipe := Recipe{Servings: 2, Nutrition: &Nutrition{Calories: &calories, Fat: &fat}}
+			recipe.ScaleTo(4)
+			Expect(*recipe.Nutrition.Calories).To(Equal(420.0))
+			Expect(*recipe.Nutrition.Fat).To(Equal(12.0))
+			Expect(recipe.Nutrition.Protein).To(BeNil())
+		})
+	})
+
+	Context("units", func() {
+		It("should normalize the units of all ingredients", func() {
+			recipe := Recipe{
+				Ingredients: []Ingredients{
+					{Amount: 2, Name: "test1", Unit: "Tablespoons"},
+					{Amount: 1, Name: "test2", Unit: "Tbsp."},
+					{Amount: 1, Name: "test3", Unit: "Prise"},
+				},
+			}
+			recipe.NormalizeUnits()
+			Expect(recipe.Ingredients[0].Unit).To(Equal("tbsp"))
+			Expect(recipe.Ingredients[1].Unit).To(Equal("tbsp"))
+			Expect(recipe.Ingredients[2].Unit).To(Equal("Prise"))
+		})
+		It("should convert the units of all ingredients", func() {
+			recipe := Recipe{
+				Ingredients: []Ingredients{
+					{Amount: 1, Name: "test1", Unit: "lb"},
+					{Amount: 1, Name: "test2", Unit: "pinch"},
+					{Amount: NoAmountIngredient, Name: "test3", Unit: "oz"},
+				},
+			}
+			recipe.ConvertUnits(units.Metric)
+			Expect(recipe.Ingredients[0]).To(Equal(Ingredients{Amount: 453.59, Name: "test1", Unit: "g"}))
+			Expect(recipe.Ingredients[1]).To(Equal(Ingredients{Amount: 1, Name: "test2", Unit: "pinch"}))
+			Expect(recipe.Ingredients[2]).To(Equal(Ingredients{Amount: NoAmountIngredient, Name: "test3", Unit: "oz"}))
+		})
+		It("should weigh ingredients with a known density", func() {
+			recipe := Recipe{
+				Ingredients: []Ingredients{
+					{Amount: 1, AmountMax: 2, Name: "Sugar", Unit: "cups"},
+					{Amount: 1, Name: "water", Unit: "l"},
+					{Amount: 2, Name: "lemon juice", Unit: "tbsp"},
+				},
+			}
+			recipe.ConvertUnits(units.ByWeight)
+			Expect(recipe.Ingredients[0]).To(Equal(Ingredients{Amount: 201.1, AmountMax: 402.2, Name: "Sugar", Unit: "g"}))
+			Expect(recipe.Ingredients[1]).To(Equal(Ingredients{Amount: 1, Name: "water", Unit: "kg"}))
+			Expect(recipe.Ingredients[2]).To(Equal(Ingredients{Amount: 2, Name: "lemon juice", Unit: "tbsp"}))
+		})
+		It("should convert both ends of ranges to the same unit", func() {
+			recipe := Recipe{
+				Ingredients: []Ingredients{
+					{Amount: 1, AmountMax: 2, Name: "flour", Unit: "cup"},
+				},
+			}
+			recipe.ConvertUnits(units.Metric)
+			Expect(recipe.Ingredients[0].Unit).To(Equal("ml"))
+			Expect(recipe.Ingredients[0].AmountMax).To(BeNumerically("~", 2*recipe.Ingredients[0].Amount, 0.01))
+		})
+		It("should convert temperatures in the description and the steps", func() {
+			recipe := Recipe{
+				Description: "Preheat the oven to 200°C.",
+				Steps:       []RecipeStep{{Text: "Bake at 180 °C"}, {Text: "Let it cool"}},
+			}
+			recipe.ConvertUnits(units.Imperial)
+			Expect(recipe.Description).To(Equal("Preheat the oven to 390°F."))
+			Expect(recipe.Steps).To(Equal([]RecipeStep{{Text: "Bake at 355°F"}, {Text: "Let it cool"}}))
+		})
 	})
 })
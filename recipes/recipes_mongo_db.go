@@ -35,8 +35,12 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
 	"go.mongodb.org/mongo-driver/x/bsonx"
+	"math/rand"
+	"regexp"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/ottenwbe/recipes-manager/utils"
 )
@@ -48,8 +52,15 @@ const (
 	RECIPES = "recipes"
 	//PICTURES index
 	PICTURES = "pics"
+	//NOTES index
+	NOTES = "notes"
+	//REMOVALS index, which keeps the time of the last removal of recipes
+	REMOVALS = "removals"
 )
 
+//removalsID identifies the single document of the REMOVALS collection
+const removalsID = "recipes"
+
 var mongoAddress string
 
 func init() {
@@ -61,33 +72,60 @@ type MongoRecipeDB struct {
 	mongoClient *mongo.Client
 	//mtx avoids race conditions while connecting to the database and while closing the connection
 	mtx sync.Mutex
+	//context cancels the operations of a view created by WithContext
+	context context.Context
+}
+
+//WithContext returns a view of the database whose operations are cancelled with the context; the view shares the connection and must not be closed
+func (m *MongoRecipeDB) WithContext(c context.Context) RecipeDB {
+	return &MongoRecipeDB{mongoClient: m.mongoClient, context: c}
+}
+
+//ctx of the operations; operations of the database itself are never cancelled
+func (m *MongoRecipeDB) ctx() context.Context {
+	if m.context == nil {
+		return ctx()
+	}
+	return m.context
 }
 
 // Clear drops all collections
 func (m *MongoRecipeDB) Clear() {
 	c := m.getRecipesCollection()
-	if err := c.Drop(ctx()); err != nil {
+	if err := c.Drop(m.ctx()); err != nil {
 		log.WithError(err).Error("Could not drop recipes from MongoDB")
 	}
 	r := m.getPictureCollection()
-	if err := r.Drop(ctx()); err != nil {
+	if err := r.Drop(m.ctx()); err != nil {
 		log.WithError(err).Error("Could not drop pictures from MongoDB")
 	}
+	n := m.getNotesCollection()
+	if err := n.Drop(m.ctx()); err != nil {
+		log.WithError(err).Error("Could not drop notes from MongoDB")
+	}
+	if err := m.recordRemoval(); err != nil {
+		log.WithError(err).Error("Could not record the removal of recipes in MongoDB")
+	}
 }
 
 //List all recipes from the db
 func (m *MongoRecipeDB) List() (recipes []*Recipe) {
+	return m.find(bson.M{})
+}
+
+//find all recipes matching the query
+func (m *MongoRecipeDB) find(query bson.M) (recipes []*Recipe) {
 
 	collection := m.getRecipesCollection()
 
 	recipes = make([]*Recipe, 0)
-	cursor, err := collection.Find(ctx(), bson.M{})
+	cursor, err := collection.Find(m.ctx(), query)
 	if err != nil {
 		log.WithError(err).Info("Error while finding recipe in MongoDB")
 		return
 	}
-	defer func() { _ = cursor.Close(ctx()) }()
-	err = cursor.All(ctx(), &recipes)
+	defer func() { _ = cursor.Close(m.ctx()) }()
+	err = cursor.All(m.ctx(), &recipes)
 	if err != nil {
 		log.WithError(err).Info("Error while finding recipe in MongoDB")
 		return
@@ -95,34 +133,80 @@ func (m *MongoRecipeDB) List() (recipes []*Recipe) {
 	return
 }
 
-//Num counts the number of recipes in the db
+//Num counts the number of recipes in the db which are not archived
 func (m *MongoRecipeDB) Num() int64 {
+	return m.Count(&RecipeSearchFilter{})
+}
 
-	collection := m.getRecipesCollection()
+//RecipeToBsonM converts a RecipeSearchFilter to a search query (bson.M).
+//Names and ingredients are matched case-insensitively, descriptions are matched as plain substrings.
+//A tag, the time of the last change, and the total time further restrict the results of the other search terms.
+func RecipeToBsonM(searchQuery *RecipeSearchFilter) bson.M {
+	query := searchTermsToBsonM(searchQuery)
 
-	num, err := collection.CountDocuments(ctx(), bson.M{})
-	if err != nil {
-		log.WithError(err).Info("Error while counting recipes in MongoDB")
+	restrictions := make([]bson.M, 0)
+	if len(query) > 0 {
+		restrictions = append(restrictions, query)
+	}
+	if searchQuery.Tag != "" {
+		restrictions = append(restrictions, bson.M{"tags": bson.M{"$regex": "^" + regexp.QuoteMeta(searchQuery.Tag) + "$", "$options": "i"}})
+	}
+	if !searchQuery.ChangedSince.IsZero() {
+		restrictions = append(restrictions, bson.M{"updatedat": bson.M{"$gt": searchQuery.ChangedSince}})
+	}
+	if searchQuery.MaxTotalTime > 0 {
+		// recipes stored without times lack the fields, their sum is null and does not match
+		total := bson.M{"$add": []interface{}{"$prepminutes", "$cookminutes"}}
+		restrictions = append(restrictions, bson.M{"$expr": bson.M{"$and": []bson.M{
+			{"$gt": []interface{}{total, 0}},
+			{"$lte": []interface{}{total, searchQuery.MaxTotalTime}},
+		}}})
 	}
 
-	return num
+	switch len(restrictions) {
+	case 0:
+		return query
+	case 1:
+		return restrictions[0]
+	}
+	return bson.M{"$and": restrictions}
 }
 
-//RecipeToBsonM converts a RecipeSearchFilter to a search query (bson.M)
-func RecipeToBsonM(searchQuery *RecipeSearchFilter) bson.M {
+//filterToBsonM restricts the query of RecipeToBsonM to archived recipes or to the other recipes, as requested by the filter
+func filterToBsonM(searchQuery *RecipeSearchFilter) bson.M {
+	query := RecipeToBsonM(searchQuery)
+	if len(query) == 0 {
+		return archivedToBsonM(searchQuery.Archived)
+	}
+	return bson.M{"$and": []bson.M{query, archivedToBsonM(searchQuery.Archived)}}
+}
+
+//archivedToBsonM matches archived recipes or the other recipes; recipes stored before archiving was introduced lack the field
+func archivedToBsonM(archived bool) bson.M {
+	if archived {
+		return bson.M{"archived": true}
+	}
+	return bson.M{"archived": bson.M{"$ne": true}}
+}
+
+func searchTermsToBsonM(searchQuery *RecipeSearchFilter) bson.M {
 	query := bson.M{}
 
 	var queryPart = make([]bson.M, 0)
 
 	if searchQuery.Name != "" {
-		queryPart = append(queryPart, bson.M{"name": bson.M{"$regex": searchQuery.Name}})
+		queryPart = append(queryPart, bson.M{"name": bson.M{"$regex": regexp.QuoteMeta(searchQuery.Name), "$options": "i"}})
 	}
 	if searchQuery.Description != "" {
-		queryPart = append(queryPart, bson.M{"description": bson.M{"$regex": searchQuery.Description}})
+		queryPart = append(queryPart, bson.M{"description": bson.M{"$regex": regexp.QuoteMeta(searchQuery.Description)}})
 	}
 	if searchQuery.Ingredient != nil && len(searchQuery.Ingredient) > 0 {
-		rgx := fmt.Sprintf("(%v)", strings.Join(searchQuery.Ingredient, "|"))
-		queryPart = append(queryPart, bson.M{"description": bson.M{"$regex": rgx}})
+		ingredients := make([]string, len(searchQuery.Ingredient))
+		for i, ingredient := range searchQuery.Ingredient {
+			ingredients[i] = regexp.QuoteMeta(ingredient)
+		}
+		rgx := fmt.Sprintf("(%v)", strings.Join(ingredients, "|"))
+		queryPart = append(queryPart, bson.M{"ingredients.name": bson.M{"$regex": rgx, "$options": "i"}})
 	}
 
 	if len(queryPart) > 1 {
@@ -136,26 +220,306 @@ func RecipeToBsonM(searchQuery *RecipeSearchFilter) bson.M {
 
 //IDs lists all ids of all recipes
 func (m *MongoRecipeDB) IDs(searchQuery *RecipeSearchFilter) RecipeList {
+	return m.ids(searchQuery, findSorted(searchQuery))
+}
+
+//findSorted returns the options to find recipes in the order requested by the filter
+func findSorted(searchQuery *RecipeSearchFilter) *options.FindOptions {
+	findOptions := options.Find().SetSort(sortOrder(searchQuery))
+	if searchQuery.Sort == SortByName {
+		// compare names ignoring the case
+		findOptions.SetCollation(&options.Collation{Locale: "en", Strength: 2})
+	}
+	return findOptions
+}
+
+//sortOrder of the search results; ties are ordered by _id, i.e., by creation, for a stable order across pages
+func sortOrder(searchQuery *RecipeSearchFilter) bson.D {
+	direction := 1
+	if searchQuery.descending() {
+		direction = -1
+	}
+
+	switch searchQuery.Sort {
+	case SortByName:
+		return bson.D{{Key: "name", Value: direction}, {Key: "_id", Value: 1}}
+	case SortByRating:
+		return bson.D{{Key: "rating", Value: direction}, {Key: "_id", Value: 1}}
+	case SortByCalories:
+		return bson.D{{Key: "nutrition.calories", Value: direction}, {Key: "_id", Value: 1}}
+	}
+	return bson.D{{Key: "_id", Value: direction}}
+}
+
+//IDsPaged lists at most limit ids of recipes matching the filter, skipping the first offset ids
+func (m *MongoRecipeDB) IDsPaged(searchQuery *RecipeSearchFilter, offset int64, limit int64) RecipeList {
+	if limit <= 0 {
+		// a limit of 0 would be interpreted as 'no limit' by MongoDB
+		return RecipeList{Recipes: make([]string, 0)}
+	}
+
+	findOptions := findSorted(searchQuery)
+	findOptions.SetSkip(offset)
+	findOptions.SetLimit(limit)
+
+	return m.ids(searchQuery, findOptions)
+}
+
+//Summaries lists at most limit summaries of recipes matching the filter, skipping the first offset recipes
+func (m *MongoRecipeDB) Summaries(searchQuery *RecipeSearchFilter, offset int64, limit int64) RecipeSummaries {
+	result := make([]RecipeSummary, 0)
+	if limit <= 0 {
+		// a limit of 0 would be interpreted as 'no limit' by MongoDB
+		return RecipeSummaries{Recipes: result}
+	}
+
+	findOptions := findSorted(searchQuery)
+	findOptions.SetSkip(offset)
+	findOptions.SetLimit(limit)
+	findOptions.SetProjection(bson.M{"id": 1, "name": 1, "picturelink": 1, "tags": 1, "rating": 1, "ratingcount": 1})
+
+	recipes := make([]*Recipe, 0)
+	cursor, err := m.getRecipesCollection().Find(m.ctx(), filterToBsonM(searchQuery), findOptions)
+	if err != nil {
+		log.WithError(err).Info("Error while finding recipe summaries")
+		return RecipeSummaries{Recipes: result}
+	}
+	defer func() { _ = cursor.Close(m.ctx()) }()
+	if err = cursor.All(m.ctx(), &recipes); err != nil {
+		log.WithError(err).Info("Error while reading recipe summaries")
+	}
+
+	for _, recipe := range recipes {
+		result = append(result, recipe.Summary())
+	}
+	return RecipeSummaries{Recipes: result}
+}
+
+//IDsSorted lists the ids of all recipes sorted by the field in the given order
+func (m *MongoRecipeDB) IDsSorted(field string, order string) RecipeList {
+	return m.IDs(&RecipeSearchFilter{Sort: field, Order: order})
+}
+
+//IDsChangedSince lists the ids of all recipes which were updated after t
+func (m *MongoRecipeDB) IDsChangedSince(t time.Time) RecipeList {
+	return m.IDs(&RecipeSearchFilter{ChangedSince: t})
+}
+
+//SuggestIngredients lists at most limit distinct ingredient names starting with the prefix, ignoring the case, in alphabetical order.
+//Redundant spaces are only removed from the ends of the names.
+func (m *MongoRecipeDB) SuggestIngredients(prefix string, limit int) []string {
+	suggestions := make([]string, 0)
+
+	prefix = normalizeIngredientName(prefix)
+	var names []struct {
+		Name string `bson:"_id"`
+	}
+	// the first stage only considers listed recipes with a matching ingredient
+	match := archivedToBsonM(false)
+	match["ingredients.name"] = bson.M{"$regex": `^\s*` + regexp.QuoteMeta(prefix), "$options": "i"}
+	err := m.aggregate(m.getRecipesCollection(), []bson.M{
+		{"$match": match},
+		{"$unwind": "$ingredients"},
+		{"$group": bson.M{"_id": bson.M{"$toLower": bson.M{"$trim": bson.M{"input": "$ingredients.name"}}}}},
+		{"$match": bson.M{"_id": bson.M{"$regex": "^" + regexp.QuoteMeta(prefix)}}},
+		{"$sort": bson.M{"_id": 1}},
+		{"$limit": limit},
+	}, &names)
+	if err != nil {
+		log.WithError(err).Info("Error while suggesting ingredients in MongoDB")
+		return suggestions
+	}
+
+	for _, name := range names {
+		suggestions = append(suggestions, name.Name)
+	}
+	return suggestions
+}
+
+//Stats aggregates the number of recipes, their average servings, their tags, and their most used ingredients
+func (m *MongoRecipeDB) Stats() (*RecipeStats, error) {
+	collection := m.getRecipesCollection()
+	stats := newRecipeStats()
+
+	var totals []struct {
+		Total           int64   `bson:"total"`
+		AverageServings float64 `bson:"averageservings"`
+	}
+	err := m.aggregate(collection, []bson.M{
+		{"$match": archivedToBsonM(false)},
+		{"$group": bson.M{"_id": nil, "total": bson.M{"$sum": 1}, "averageservings": bson.M{"$avg": "$servings"}}},
+	}, &totals)
+	if err != nil {
+		return nil, err
+	}
+	if len(totals) > 0 {
+		stats.Total, stats.AverageServings = totals[0].Total, totals[0].AverageServings
+	}
+
+	if stats.Tags, err = m.Tags(); err != nil {
+		return nil, err
+	}
+
+	var counts []struct {
+		Name  string `bson:"_id"`
+		Count int64  `bson:"count"`
+	}
+	// each recipe counts once per ingredient, even if it lists an ingredient multiple times
+	err = m.aggregate(collection, []bson.M{
+		{"$match": archivedToBsonM(false)},
+		{"$unwind": "$ingredients"},
+		{"$group": bson.M{"_id": bson.M{"id": "$id", "name": bson.M{"$toLower": bson.M{"$trim": bson.M{"input": "$ingredients.name"}}}}}},
+		{"$match": bson.M{"_id.name": bson.M{"$ne": ""}}},
+		{"$group": bson.M{"_id": "$_id.name", "count": bson.M{"$sum": 1}}},
+		{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+		{"$limit": topIngredientsLimit},
+	}, &counts)
+	if err != nil {
+		return nil, err
+	}
+	for _, count := range counts {
+		stats.TopIngredients = append(stats.TopIngredients, IngredientCount{Name: count.Name, Count: count.Count})
+	}
+
+	return stats, nil
+}
+
+//Tags lists all distinct tags in lower case and the number of recipes carrying them, the most used tag first
+func (m *MongoRecipeDB) Tags() ([]TagCount, error) {
+	var counts []struct {
+		Name  string `bson:"_id"`
+		Count int64  `bson:"count"`
+	}
+	// each recipe counts once per tag, even if it lists a tag multiple times
+	err := m.aggregate(m.getRecipesCollection(), []bson.M{
+		{"$match": archivedToBsonM(false)},
+		{"$unwind": "$tags"},
+		{"$group": bson.M{"_id": bson.M{"id": "$id", "name": bson.M{"$toLower": bson.M{"$trim": bson.M{"input": "$tags"}}}}}},
+		{"$match": bson.M{"_id.name": bson.M{"$ne": ""}}},
+		{"$group": bson.M{"_id": "$_id.name", "count": bson.M{"$sum": 1}}},
+		{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+	}, &counts)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make([]TagCount, 0, len(counts))
+	for _, count := range counts {
+		tags = append(tags, TagCount{Tag: count.Name, Count: count.Count})
+	}
+	return tags, nil
+}
+
+//aggregate runs the pipeline on the collection and decodes all results
+func (m *MongoRecipeDB) aggregate(collection *mongo.Collection, pipeline []bson.M, results interface{}) error {
+	cursor, err := collection.Aggregate(m.ctx(), pipeline)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = cursor.Close(m.ctx()) }()
+	return cursor.All(m.ctx(), results)
+}
+
+//LastModified returns the time of the latest change of the recipes, i.e., the latest UpdatedAt or removal of a recipe
+func (m *MongoRecipeDB) LastModified() time.Time {
+	var updated struct {
+		UpdatedAt time.Time `bson:"updatedat"`
+	}
+	findOptions := options.FindOne().SetSort(bson.M{"updatedat": -1}).SetProjection(bson.M{"updatedat": 1})
+	if err := m.getRecipesCollection().FindOne(m.ctx(), bson.M{}, findOptions).Decode(&updated); err != nil && err != mongo.ErrNoDocuments {
+		log.WithError(err).Info("Error while finding the last update of recipes")
+	}
+
+	var removed struct {
+		RemovedAt time.Time `bson:"removedat"`
+	}
+	if err := m.getRemovalsCollection().FindOne(m.ctx(), bson.M{"_id": removalsID}).Decode(&removed); err != nil && err != mongo.ErrNoDocuments {
+		log.WithError(err).Info("Error while finding the last removal of recipes")
+	}
+
+	if removed.RemovedAt.After(updated.UpdatedAt) {
+		return removed.RemovedAt.UTC()
+	}
+	return updated.UpdatedAt.UTC()
+}
+
+//FindByMaxTime lists the ids of all recipes which can be prepared and cooked in the given minutes; recipes without times are omitted
+func (m *MongoRecipeDB) FindByMaxTime(minutes int) RecipeList {
+	return m.IDs(&RecipeSearchFilter{MaxTotalTime: minutes})
+}
+
+//FindByTag lists the ids of all recipes carrying the tag, ignoring the case
+func (m *MongoRecipeDB) FindByTag(tag string) RecipeList {
+	return m.IDs(&RecipeSearchFilter{Tag: tag})
+}
+
+//Search uses the text index of the recipes. Each term is passed as a phrase to MongoDB, since phrases have to match all.
+func (m *MongoRecipeDB) Search(query string) []RecipeSearchResult {
+	results := make([]RecipeSearchResult, 0)
+
+	terms := SearchTerms(query)
+	if len(terms) == 0 {
+		return results
+	}
+	phrases := make([]string, len(terms))
+	for i, term := range terms {
+		phrases[i] = fmt.Sprintf("\"%v\"", strings.ReplaceAll(term, "\"", ""))
+	}
+
+	score := bson.M{"$meta": "textScore"}
+	findOptions := options.Find().
+		SetProjection(bson.M{"id": 1, "name": 1, "score": score}).
+		SetSort(bson.M{"score": score})
+
+	filter := bson.M{"$text": bson.M{"$search": strings.Join(phrases, " ")}, "archived": bson.M{"$ne": true}}
+	cursor, err := m.getRecipesCollection().Find(m.ctx(), filter, findOptions)
+	if err != nil {
+		log.WithError(err).Info("Error while searching recipes")
+		return results
+	}
+	defer func() { _ = cursor.Close(m.ctx()) }()
+
+	if err = cursor.All(m.ctx(), &results); err != nil {
+		log.WithError(err).Info("Error while converting search results from MongoDB")
+		return make([]RecipeSearchResult, 0)
+	}
+
+	return results
+}
+
+//Count the recipes matching the filter
+func (m *MongoRecipeDB) Count(searchQuery *RecipeSearchFilter) int64 {
+
+	collection := m.getRecipesCollection()
+
+	num, err := collection.CountDocuments(m.ctx(), filterToBsonM(searchQuery))
+	if err != nil {
+		log.WithError(err).Info("Error while counting recipes in MongoDB")
+	}
+
+	return num
+}
+
+func (m *MongoRecipeDB) ids(searchQuery *RecipeSearchFilter, findOptions *options.FindOptions) RecipeList {
 
 	collection := m.getRecipesCollection()
 
 	recipes := make([]*Recipe, 0)
 	result := make([]string, 0)
 
-	dbSearch := RecipeToBsonM(searchQuery)
+	dbSearch := filterToBsonM(searchQuery)
 
-	findOptions := options.Find()
 	findOptions.SetProjection(bson.M{"id": 1}) //only get id field
 
 	bsonS, _ := json.Marshal(dbSearch)
 	log.WithField("json", string(bsonS)).Debug("Query for IDs")
 
-	cursor, err := collection.Find(ctx(), dbSearch, findOptions)
+	cursor, err := collection.Find(m.ctx(), dbSearch, findOptions)
 	if err != nil {
 		log.WithError(err).Info("Error while finding recipe")
 	}
-	defer func() { _ = cursor.Close(ctx()) }()
-	err = cursor.All(ctx(), &recipes)
+	defer func() { _ = cursor.Close(m.ctx()) }()
+	err = cursor.All(m.ctx(), &recipes)
 
 	log.Debugf("Found %v recipes", len(recipes))
 
@@ -166,13 +530,23 @@ func (m *MongoRecipeDB) IDs(searchQuery *RecipeSearchFilter) RecipeList {
 	return RecipeList{Recipes: result}
 }
 
+//FindSimilar lists the recipes with similar ingredients, most similar first
+func (m *MongoRecipeDB) FindSimilar(id RecipeID, threshold float64) ([]SimilarRecipe, error) {
+	recipe := m.Get(id)
+	if recipe.ID == InvalidRecipeID() {
+		return make([]SimilarRecipe, 0), ErrRecipeNotFound
+	}
+	// ingredient names are normalized before they are compared, hence, all listed recipes are candidates
+	return similarRecipes(recipe, m.find(archivedToBsonM(false)), threshold), nil
+}
+
 //Get a recipe by ID
 func (m *MongoRecipeDB) Get(id RecipeID) *Recipe {
 
 	collection := m.getRecipesCollection()
 
 	recipe := NewInvalidRecipe()
-	result := collection.FindOne(ctx(), bson.M{"id": id})
+	result := collection.FindOne(m.ctx(), bson.M{"id": id})
 
 	err := result.Decode(recipe)
 	if err != nil {
@@ -182,6 +556,27 @@ func (m *MongoRecipeDB) Get(id RecipeID) *Recipe {
 	return recipe
 }
 
+//GetMany returns all existing recipes out of the given ids with a single query
+func (m *MongoRecipeDB) GetMany(ids []RecipeID) []*Recipe {
+
+	collection := m.getRecipesCollection()
+
+	recipes := make([]*Recipe, 0)
+	cursor, err := collection.Find(m.ctx(), bson.M{"id": bson.M{"$in": ids}})
+	if err != nil {
+		log.WithError(err).Info("Error while finding recipes in MongoDB")
+		return recipes
+	}
+	defer func() { _ = cursor.Close(m.ctx()) }()
+
+	if err = cursor.All(m.ctx(), &recipes); err != nil {
+		log.WithError(err).Info("Error while finding recipes in MongoDB")
+		return make([]*Recipe, 0)
+	}
+
+	return recipes
+}
+
 //Pictures returns all pictures for a given recipe
 func (m *MongoRecipeDB) Pictures(id RecipeID) map[string]*RecipePicture {
 
@@ -190,14 +585,14 @@ func (m *MongoRecipeDB) Pictures(id RecipeID) map[string]*RecipePicture {
 	recipePictures := make([]*RecipePicture, 0)
 	result := make(map[string]*RecipePicture, 0)
 
-	cursor, err := collection.Find(ctx(), bson.M{"id": id})
+	cursor, err := collection.Find(m.ctx(), bson.M{"id": id})
 	if err != nil {
 		log.WithError(err).Info("Error while finding recipe pictures")
 		return result
 	}
-	defer func() { _ = cursor.Close(ctx()) }()
+	defer func() { _ = cursor.Close(m.ctx()) }()
 
-	err = cursor.All(ctx(), &recipePictures)
+	err = cursor.All(m.ctx(), &recipePictures)
 	if err != nil {
 		log.WithError(err).Info("Error while finding recipe pictures")
 		return result
@@ -214,18 +609,214 @@ func (m *MongoRecipeDB) Pictures(id RecipeID) map[string]*RecipePicture {
 func (m *MongoRecipeDB) Remove(id RecipeID) error {
 	c := m.getRecipesCollection()
 
-	_, err := c.DeleteOne(ctx(), bson.M{"id": id})
+	result, err := c.DeleteOne(m.ctx(), bson.M{"id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount > 0 {
+		if err = m.recordRemoval(); err != nil {
+			return err
+		}
+	}
+
+	return m.removeNotes(id)
+}
+
+//recordRemoval remembers the time of the last removal of recipes
+func (m *MongoRecipeDB) recordRemoval() error {
+	_, err := m.getRemovalsCollection().UpdateOne(m.ctx(), bson.M{"_id": removalsID}, bson.M{"$set": bson.M{"removedat": changeTime()}}, options.Update().SetUpsert(true))
+	return err
+}
+
+//removeNotes of a recipe, since notes cannot exist without their recipe
+func (m *MongoRecipeDB) removeNotes(id RecipeID) error {
+	c := m.getNotesCollection()
+
+	_, err := c.DeleteMany(m.ctx(), bson.M{"recipeid": id})
 
 	return err
 }
 
+//Notes of a recipe, the newest note first
+func (m *MongoRecipeDB) Notes(id RecipeID) []*Note {
+
+	collection := m.getNotesCollection()
+
+	result := make([]*Note, 0)
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "createdat", Value: -1}, {Key: "_id", Value: -1}})
+	cursor, err := collection.Find(m.ctx(), bson.M{"recipeid": id}, findOptions)
+	if err != nil {
+		log.WithError(err).Info("Error while finding notes")
+		return result
+	}
+	defer func() { _ = cursor.Close(m.ctx()) }()
+
+	if err = cursor.All(m.ctx(), &result); err != nil {
+		log.WithError(err).Info("Error while reading notes")
+	}
+
+	return result
+}
+
+//AddNote to a recipe and set its creation time
+func (m *MongoRecipeDB) AddNote(note *Note) error {
+
+	if m.Get(note.RecipeID).ID == InvalidRecipeID() {
+		return ErrRecipeNotFound
+	}
+
+	note.CreatedAt = changeTime()
+	_, err := m.getNotesCollection().InsertOne(m.ctx(), *note)
+	if err != nil {
+		log.WithError(err).Error("Could not add note")
+		return err
+	}
+
+	return nil
+}
+
+//Merge duplicates into the primary recipe in a transaction. Standalone servers do not support transactions,
+//in this case the duplicates are merged step by step and a failed merge is completed by repeating it, see merge.
+func (m *MongoRecipeDB) Merge(primary RecipeID, duplicates []RecipeID) (*Recipe, error) {
+	request := MergeRequest{Primary: primary, Duplicates: duplicates}
+	if err := request.Validate(); err != nil {
+		return NewInvalidRecipe(), err
+	}
+
+	var recipe *Recipe
+	err := m.mongoClient.UseSession(m.ctx(), func(session mongo.SessionContext) error {
+		result, err := session.WithTransaction(session, func(tx mongo.SessionContext) (interface{}, error) {
+			return (&MongoRecipeDB{mongoClient: m.mongoClient, context: tx}).merge(primary, duplicates)
+		})
+		if err == nil {
+			recipe = result.(*Recipe)
+		}
+		return err
+	})
+	if transactionsNotSupported(err) {
+		log.Warn("Transactions are not supported by the database, recipes are merged without a transaction")
+		recipe, err = m.merge(primary, duplicates)
+	}
+	if err != nil {
+		return NewInvalidRecipe(), err
+	}
+	return recipe, nil
+}
+
+//transactionsNotSupported checks if an error is caused by a standalone server, which does not support transactions
+func transactionsNotSupported(err error) bool {
+	var commandErr mongo.CommandError
+	return errors.As(err, &commandErr) && commandErr.Code == illegalOperationCode
+}
+
+//illegalOperationCode is returned by standalone servers for operations in a transaction
+const illegalOperationCode = 20
+
+//mergedFrom lists the duplicates whose ratings were merged into a primary recipe already
+type mergedFrom struct {
+	MergedFrom []RecipeID `bson:"mergedfrom"`
+}
+
+//merge the duplicates into the primary recipe. Without a transaction, a failed merge can be repeated to complete it:
+//the ratings of duplicates are recorded along with the merged rating, so that they are not counted twice,
+//and all other steps, i.e., moving pictures and notes and removing the duplicates, can be repeated anyway.
+func (m *MongoRecipeDB) merge(primary RecipeID, duplicates []RecipeID) (*Recipe, error) {
+	recipe := m.Get(primary)
+	if recipe.ID == InvalidRecipeID() {
+		return NewInvalidRecipe(), fmt.Errorf("%w: %v", ErrRecipeNotFound, primary)
+	}
+	var done mergedFrom
+	if err := m.getRecipesCollection().FindOne(m.ctx(), bson.M{"id": primary}).Decode(&done); err != nil {
+		log.WithError(err).Error("Could not read merged duplicates")
+		return NewInvalidRecipe(), err
+	}
+	alreadyMerged := make(map[RecipeID]bool, len(done.MergedFrom))
+	for _, id := range done.MergedFrom {
+		alreadyMerged[id] = true
+	}
+
+	merged := []*Recipe{recipe}
+	for _, id := range duplicates {
+		duplicate := m.Get(id)
+		if duplicate.ID == InvalidRecipeID() && !alreadyMerged[id] {
+			return NewInvalidRecipe(), fmt.Errorf("%w: %v", ErrRecipeNotFound, id)
+		}
+		if !alreadyMerged[id] {
+			merged = append(merged, duplicate)
+		}
+	}
+
+	pictures := m.getPictureCollection()
+	taken := m.Pictures(primary)
+	moved := make(map[RecipeID][]string, len(duplicates))
+	for _, id := range duplicates {
+		names := make([]string, 0)
+		for name := range m.Pictures(id) {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if _, ok := taken[name]; ok {
+				continue
+			}
+			taken[name] = nil
+			moved[id] = append(moved[id], name)
+			recipe.PictureLink = append(recipe.PictureLink, name)
+		}
+	}
+
+	recipe.Rating, recipe.RatingCount = mergeRatings(merged)
+	recipe.PictureLink = utils.UniqueSlice(recipe.PictureLink)
+	_, err := m.getRecipesCollection().UpdateOne(m.ctx(), bson.M{"id": primary}, bson.M{
+		"$set": bson.M{
+			"rating":      recipe.Rating,
+			"ratingcount": recipe.RatingCount,
+			"picturelink": recipe.PictureLink,
+			"updatedat":   changeTime(),
+		},
+		"$addToSet": bson.M{"mergedfrom": bson.M{"$each": duplicates}},
+	})
+	if err != nil {
+		log.WithError(err).Error("Could not update merged recipe")
+		return NewInvalidRecipe(), err
+	}
+
+	for _, id := range duplicates {
+		for _, name := range moved[id] {
+			if _, err := pictures.UpdateMany(m.ctx(), bson.M{"id": id, "name": name}, bson.M{"$set": bson.M{"id": primary}}); err != nil {
+				log.WithError(err).Error("Could not move picture")
+				return NewInvalidRecipe(), err
+			}
+		}
+	}
+
+	_, err = m.getNotesCollection().UpdateMany(m.ctx(), bson.M{"recipeid": bson.M{"$in": duplicates}}, bson.M{"$set": bson.M{"recipeid": primary}})
+	if err != nil {
+		log.WithError(err).Error("Could not move notes")
+		return NewInvalidRecipe(), err
+	}
+
+	// pictures which were not moved are dropped
+	if _, err = pictures.DeleteMany(m.ctx(), bson.M{"id": bson.M{"$in": duplicates}}); err != nil {
+		log.WithError(err).Error("Could not delete pictures of duplicates")
+		return NewInvalidRecipe(), err
+	}
+	if _, err = m.getRecipesCollection().DeleteMany(m.ctx(), bson.M{"id": bson.M{"$in": duplicates}}); err != nil {
+		log.WithError(err).Error("Could not delete duplicates")
+		return NewInvalidRecipe(), err
+	}
+
+	return m.Get(primary), nil
+}
+
 //Picture returns a specific picture with a specific name for a specific recipe
 func (m *MongoRecipeDB) Picture(id RecipeID, name string) *RecipePicture {
 
 	collection := m.getPictureCollection()
 
 	recipePicture := NewInvalidRecipePicture()
-	dbResult := collection.FindOne(ctx(), bson.M{"id": id, "name": name})
+	dbResult := collection.FindOne(m.ctx(), bson.M{"id": id, "name": name})
 
 	err := dbResult.Decode(recipePicture)
 	if err != nil {
@@ -242,7 +833,7 @@ func (m *MongoRecipeDB) AddPicture(pic *RecipePicture) error {
 
 	recipe := m.Get(pic.ID)
 	if recipe.ID == InvalidRecipeID() {
-		return errors.New("could not find recipe")
+		return ErrRecipeNotFound
 	}
 
 	recipe.PictureLink = utils.UniqueSlice(append(recipe.PictureLink, pic.Name))
@@ -253,7 +844,7 @@ func (m *MongoRecipeDB) AddPicture(pic *RecipePicture) error {
 		return err
 	}
 
-	_, err = collection.InsertOne(ctx(), *pic)
+	_, err = collection.InsertOne(m.ctx(), *pic)
 	if err != nil {
 		log.WithError(err).Error("Could not insert picture")
 		return err
@@ -262,20 +853,209 @@ func (m *MongoRecipeDB) AddPicture(pic *RecipePicture) error {
 	return nil
 }
 
+//DeletePicture of a recipe. The picture is also removed from the recipe's picture links.
+func (m *MongoRecipeDB) DeletePicture(id RecipeID, name string) error {
+
+	collection := m.getPictureCollection()
+
+	result, err := collection.DeleteMany(m.ctx(), bson.M{"id": id, "name": name})
+	if err != nil {
+		log.WithError(err).Error("Could not delete picture")
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrPictureNotFound
+	}
+
+	_, err = m.getRecipesCollection().UpdateOne(m.ctx(), bson.M{"id": id}, bson.M{"$pull": bson.M{"picturelink": name}, "$set": bson.M{"updatedat": changeTime()}})
+	if err != nil {
+		log.WithError(err).Error("Could not remove picture from recipe")
+		return err
+	}
+
+	return nil
+}
+
+//SetPictureOrder rearranges the picture links of a recipe. ErrVersionConflict is returned if the links change in the meantime.
+func (m *MongoRecipeDB) SetPictureOrder(id RecipeID, names []string, primary string) ([]string, error) {
+
+	recipe := m.Get(id)
+	if recipe.ID == InvalidRecipeID() {
+		return nil, ErrRecipeNotFound
+	}
+	links, err := orderPictures(recipe.PictureLink, names, primary)
+	if err != nil {
+		return nil, err
+	}
+
+	// only update the links which have been read, so that concurrently added or deleted pictures are not lost
+	result, err := m.getRecipesCollection().UpdateOne(m.ctx(), bson.M{"id": id, "picturelink": recipe.PictureLink},
+		bson.M{"$set": bson.M{"picturelink": links, "updatedat": changeTime()}})
+	if err != nil {
+		log.WithError(err).Error("Could not order pictures")
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, ErrVersionConflict
+	}
+	return links, nil
+}
+
+//AddRating to a recipe. The running average is updated atomically by the database.
+func (m *MongoRecipeDB) AddRating(id RecipeID, rating int) (float32, error) {
+
+	collection := m.getRecipesCollection()
+
+	count := bson.M{"$ifNull": []interface{}{"$ratingcount", 0}}
+	average := bson.M{"$ifNull": []interface{}{"$rating", 0}}
+	update := []bson.M{{"$set": bson.M{
+		"rating":      bson.M{"$divide": []interface{}{bson.M{"$add": []interface{}{bson.M{"$multiply": []interface{}{average, count}}, rating}}, bson.M{"$add": []interface{}{count, 1}}}},
+		"ratingcount": bson.M{"$add": []interface{}{count, 1}},
+		"updatedat":   changeTime(),
+	}}}
+
+	recipe := NewInvalidRecipe()
+	result := collection.FindOneAndUpdate(m.ctx(), bson.M{"id": id}, update, options.FindOneAndUpdate().SetReturnDocument(options.After))
+
+	err := result.Decode(recipe)
+	if err == mongo.ErrNoDocuments {
+		return 0, ErrRecipeNotFound
+	} else if err != nil {
+		log.WithError(err).Error("Could not rate recipe")
+		return 0, err
+	}
+
+	return recipe.Rating, nil
+}
+
+//BulkTag removes the tags to remove from and then adds the tags to add to all recipes, ignoring the case.
+//The recipes are tagged one after the other; each recipe is tagged by a single update, so that concurrent changes of its tags are not lost.
+func (m *MongoRecipeDB) BulkTag(ids []RecipeID, add []string, remove []string) (map[RecipeID]bool, error) {
+	collection := m.getRecipesCollection()
+	update := retagPipeline(add, remove, changeTime())
+
+	results := make(map[RecipeID]bool)
+	for _, id := range ids {
+		result, err := collection.UpdateOne(m.ctx(), bson.M{"id": id}, update)
+		if err != nil {
+			log.WithError(err).Error("Could not tag recipe")
+			return nil, err
+		}
+		results[id] = result.MatchedCount > 0
+	}
+	return results, nil
+}
+
+//retagPipeline changes the tags of a recipe like Recipe.retag does; the version and the time of the update only change if the tags change.
+//A pipeline is needed, since the same tags cannot be pulled and added in one update and $addToSet does not ignore the case.
+func retagPipeline(add []string, remove []string, updatedAt time.Time) []bson.M {
+	removed := make([]string, 0, len(remove))
+	for _, tag := range remove {
+		removed = append(removed, normalizeTag(tag))
+	}
+	added := make([]bson.M, 0, len(add))
+	seen := make(map[string]bool)
+	for _, tag := range add {
+		tag = strings.TrimSpace(tag)
+		if key := normalizeTag(tag); !seen[key] {
+			seen[key] = true
+			added = append(added, bson.M{"tag": tag, "key": key})
+		}
+	}
+
+	normalized := func(tag string) bson.M {
+		return bson.M{"$toLower": bson.M{"$trim": bson.M{"input": tag}}}
+	}
+	tags := bson.M{"$ifNull": bson.A{"$tags", bson.A{}}}
+	// tags are literals, since tags like $name would be read as fields otherwise
+	kept := bson.M{"$filter": bson.M{"input": tags, "as": "tag",
+		"cond": bson.M{"$not": bson.A{bson.M{"$in": bson.A{normalized("$$tag"), bson.M{"$literal": removed}}}}}}}
+	keptKeys := bson.M{"$map": bson.M{"input": "$$kept", "as": "tag", "in": normalized("$$tag")}}
+	missing := bson.M{"$filter": bson.M{"input": bson.M{"$literal": added}, "as": "added",
+		"cond": bson.M{"$not": bson.A{bson.M{"$in": bson.A{"$$added.key", keptKeys}}}}}}
+	retagged := bson.M{"$let": bson.M{"vars": bson.M{"kept": kept},
+		"in": bson.M{"$concatArrays": bson.A{"$$kept", bson.M{"$map": bson.M{"input": missing, "as": "added", "in": "$$added.tag"}}}}}}
+
+	changed := bson.M{"$ne": bson.A{"$retagged", tags}}
+	return []bson.M{
+		{"$set": bson.M{"retagged": retagged}},
+		{"$set": bson.M{
+			"tags":      "$retagged",
+			"version":   bson.M{"$cond": bson.A{changed, bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$version", 0}}, 1}}, "$version"}},
+			"updatedat": bson.M{"$cond": bson.A{changed, updatedAt, "$updatedat"}},
+		}},
+		{"$unset": "retagged"},
+	}
+}
+
+//Archive a recipe, i.e., exclude it from listings and searches without removing it
+func (m *MongoRecipeDB) Archive(id RecipeID) error {
+	return m.setArchived(id, true)
+}
+
+//Restore an archived recipe, i.e., list it again
+func (m *MongoRecipeDB) Restore(id RecipeID) error {
+	return m.setArchived(id, false)
+}
+
+//setArchived changes the archived state of a recipe; UpdatedAt is only changed if the state changes
+func (m *MongoRecipeDB) setArchived(id RecipeID, archived bool) error {
+	collection := m.getRecipesCollection()
+
+	result, err := collection.UpdateOne(m.ctx(), bson.M{"$and": []bson.M{{"id": id}, archivedToBsonM(!archived)}},
+		bson.M{"$set": bson.M{"archived": archived, "updatedat": changeTime()}})
+	if err != nil {
+		log.WithError(err).Error("Could not archive or restore recipe")
+		return err
+	}
+	if result.MatchedCount == 0 && m.Get(id).ID == InvalidRecipeID() {
+		return ErrRecipeNotFound
+	}
+	return nil
+}
+
 //Random picture will be returned
 func (m *MongoRecipeDB) Random() *Recipe {
+	return m.RandomFiltered(&RecipeSearchFilter{})
+}
+
+//RandomFiltered returns a random recipe out of all recipes that match the filter
+func (m *MongoRecipeDB) RandomFiltered(searchQuery *RecipeSearchFilter) *Recipe {
+	return m.sample(filterToBsonM(searchQuery))
+}
+
+//RandomSeeded picks a recipe out of the recipes matching the filter, ordered by their id, with a random generator seeded by seed.
+//Only the picked recipe is read, the others are skipped by the database.
+func (m *MongoRecipeDB) RandomSeeded(searchQuery *RecipeSearchFilter, seed int64) *Recipe {
+	num := m.Count(searchQuery)
+	if num == 0 {
+		return NewInvalidRecipe()
+	}
+	skip := int64(rand.New(rand.NewSource(seed)).Intn(int(num)))
+
+	recipe := NewInvalidRecipe()
+	findOptions := options.FindOne().SetSort(bson.D{{Key: "_id", Value: 1}}).SetSkip(skip)
+	if err := m.getRecipesCollection().FindOne(m.ctx(), filterToBsonM(searchQuery), findOptions).Decode(recipe); err != nil {
+		log.WithError(err).Info("Error while finding recipe in MongoDB")
+		return NewInvalidRecipe()
+	}
+	return recipe
+}
+
+//sample a random recipe out of all recipes that match the query
+func (m *MongoRecipeDB) sample(query bson.M) *Recipe {
 
 	collection := m.getRecipesCollection()
 
-	cursor, err := collection.Aggregate(ctx(), []bson.M{{"$sample": bson.M{"size": 1}}})
+	cursor, err := collection.Aggregate(m.ctx(), []bson.M{{"$match": query}, {"$sample": bson.M{"size": 1}}})
 	if err != nil {
 		log.WithError(err).Info("Error while finding recipe in MongoDB")
 		return NewInvalidRecipe()
 	}
-	defer func() { _ = cursor.Close(ctx()) }()
+	defer func() { _ = cursor.Close(m.ctx()) }()
 
 	recipes := make([]*Recipe, 0)
-	err = cursor.All(ctx(), &recipes)
+	err = cursor.All(m.ctx(), &recipes)
 	if err != nil || len(recipes) == 0 {
 		log.WithError(err).Info("Error while converting recipe from MongoDB")
 		return NewInvalidRecipe()
@@ -289,22 +1069,61 @@ func (m *MongoRecipeDB) Update(id RecipeID, recipe *Recipe) error {
 
 	collection := m.getRecipesCollection()
 
-	_, err := collection.ReplaceOne(ctx(), bson.M{"id": id}, recipe)
+	filter := bson.M{"id": id, "version": recipe.Version}
+	if recipe.Version == 0 {
+		// recipes stored before versioning was introduced have no version
+		filter = bson.M{"id": id, "$or": []bson.M{{"version": 0}, {"version": bson.M{"$exists": false}}}}
+	}
+	updated := *recipe
+	updated.Version++
+	updated.CreatedAt, updated.Archived = m.storedState(id)
+	updated.UpdatedAt = changeTime()
+
+	result, err := collection.ReplaceOne(m.ctx(), filter, updated)
 	if err != nil {
 		log.WithError(err).Error("Could not update recipe")
 		return err
 	}
+	if result.MatchedCount == 0 {
+		if m.Get(id).ID != InvalidRecipeID() {
+			return ErrVersionConflict
+		}
+		return nil
+	}
 
+	recipe.Version = updated.Version
+	recipe.CreatedAt = updated.CreatedAt
+	recipe.UpdatedAt = updated.UpdatedAt
+	recipe.Archived = updated.Archived
 	return nil
 }
 
+//storedState returns the creation time and the archived state of a stored recipe, since updates cannot change them
+func (m *MongoRecipeDB) storedState(id RecipeID) (time.Time, bool) {
+	stored := struct {
+		CreatedAt time.Time
+		Archived  bool
+	}{}
+	err := m.getRecipesCollection().FindOne(m.ctx(), bson.M{"id": id}, options.FindOne().SetProjection(bson.M{"createdat": 1, "archived": 1})).Decode(&stored)
+	if err != nil && err != mongo.ErrNoDocuments {
+		log.WithError(err).Error("Could not read the creation time of a recipe")
+	}
+	return stored.CreatedAt, stored.Archived
+}
+
 //Insert a recipe into the database
 func (m *MongoRecipeDB) Insert(recipe *Recipe) error {
 
 	collection := m.getRecipesCollection()
 
-	_, err := collection.InsertOne(ctx(), *recipe)
-	if err != nil {
+	recipe.NormalizeUnits()
+	recipe.CreatedAt = changeTime()
+	recipe.UpdatedAt = recipe.CreatedAt
+
+	_, err := collection.InsertOne(m.ctx(), *recipe)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrRecipeExists
+	} else if err != nil {
 		log.WithError(err).Error("Could not insert recipe")
 		return err
 	}
@@ -314,16 +1133,19 @@ func (m *MongoRecipeDB) Insert(recipe *Recipe) error {
 
 //Ping MongoDB
 func (m *MongoRecipeDB) Ping() error {
-	return m.mongoClient.Ping(ctx(), readpref.Primary())
+	return m.mongoClient.Ping(m.ctx(), readpref.Primary())
 }
 
 //RemoveByName a recipe by name
 func (m *MongoRecipeDB) RemoveByName(name string) error {
-	c := m.getRecipesCollection()
-
-	_, err := c.DeleteOne(ctx(), bson.M{"name": name})
+	recipe, err := m.GetByName(name)
+	if err == mongo.ErrNoDocuments {
+		return nil
+	} else if err != nil {
+		return err
+	}
 
-	return err
+	return m.Remove(recipe.ID)
 }
 
 //GetByName a recipe from the database
@@ -332,7 +1154,7 @@ func (m *MongoRecipeDB) GetByName(name string) (*Recipe, error) {
 	collection := m.getRecipesCollection()
 
 	recipe := *NewInvalidRecipe()
-	cur := collection.FindOne(ctx(), bson.M{"name": name})
+	cur := collection.FindOne(m.ctx(), bson.M{"name": name})
 	err := cur.Decode(&recipe)
 
 	return &recipe, err
@@ -368,7 +1190,7 @@ func (m *MongoRecipeDB) StopDB() (err error) {
 	defer m.mtx.Unlock()
 
 	if m.mongoClient != nil {
-		err = m.mongoClient.Disconnect(ctx())
+		err = m.mongoClient.Disconnect(m.ctx())
 	}
 	m.mongoClient = nil
 
@@ -382,7 +1204,7 @@ func (m *MongoRecipeDB) connectToDB() (err error) {
 		log.WithError(err).Info("Could not create MongoDB client")
 		return
 	}
-	err = m.mongoClient.Connect(ctx())
+	err = m.mongoClient.Connect(m.ctx())
 	if err != nil {
 		log.WithError(err).Info("Could not connect to MongoDB")
 		return
@@ -403,6 +1225,11 @@ func (m *MongoRecipeDB) connectToDB() (err error) {
 		log.WithError(err).Info("Could not create mongo db picture index")
 		return
 	}
+	err = m.ensureNotesIndex()
+	if err != nil {
+		log.WithError(err).Info("Could not create mongo db notes index")
+		return
+	}
 
 	return
 }
@@ -439,7 +1266,7 @@ func (m *MongoRecipeDB) createDefaultRecipeIndex(c *mongo.Collection) error {
 		Options: options.Index().SetUnique(true).SetSparse(true),
 	}
 
-	_, err := c.Indexes().CreateMany(ctx(), []mongo.IndexModel{indexID, indexName})
+	_, err := c.Indexes().CreateMany(m.ctx(), []mongo.IndexModel{indexID, indexName})
 	if err != nil {
 		log.Info("idx error", err)
 	}
@@ -456,7 +1283,7 @@ func (m *MongoRecipeDB) createTextIndex(c *mongo.Collection) error {
 		Options: options.Index().SetUnique(false),
 	}
 
-	_, err := c.Indexes().CreateOne(ctx(), textIndex)
+	_, err := c.Indexes().CreateOne(m.ctx(), textIndex)
 
 	return err
 }
@@ -470,7 +1297,7 @@ func (m *MongoRecipeDB) ensurePictureIndex() error {
 		},
 		Options: options.Index().SetUnique(false).SetSparse(true),
 	}
-	_, err := c.Indexes().CreateOne(ctx(), index)
+	_, err := c.Indexes().CreateOne(m.ctx(), index)
 
 	if err != nil {
 		return err
@@ -479,6 +1306,24 @@ func (m *MongoRecipeDB) ensurePictureIndex() error {
 	return nil
 }
 
+func (m *MongoRecipeDB) ensureNotesIndex() error {
+
+	c := m.getNotesCollection()
+	index := mongo.IndexModel{
+		Keys: bson.M{
+			"recipeid": 1, // index in ascending order
+		},
+		Options: options.Index().SetUnique(false),
+	}
+	_, err := c.Indexes().CreateOne(m.ctx(), index)
+
+	return err
+}
+
+func (m *MongoRecipeDB) getNotesCollection() *mongo.Collection {
+	return m.mongoClient.Database(DATABASE).Collection(NOTES)
+}
+
 func (m *MongoRecipeDB) getRecipesCollection() *mongo.Collection {
 	return m.mongoClient.Database(DATABASE).Collection(RECIPES)
 }
@@ -487,6 +1332,10 @@ func (m *MongoRecipeDB) getPictureCollection() *mongo.Collection {
 	return m.mongoClient.Database(DATABASE).Collection(PICTURES)
 }
 
+func (m *MongoRecipeDB) getRemovalsCollection() *mongo.Collection {
+	return m.mongoClient.Database(DATABASE).Collection(REMOVALS)
+}
+
 func ctx() context.Context {
 	defaultContext := context.Background()
 	return defaultContext
@@ -0,0 +1,250 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	"os"
+	"time"
+
+	"github.com/lib/pq"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+//postgresTestDSN configures the database of the integration tests of the PostgresDB, which are skipped without it
+const postgresTestDSN = "GO_COOK_TEST_POSTGRES_DSN"
+
+var _ = Describe("postgres recipes db", func() {
+
+	Context("filter", func() {
+		It("should only exclude archived recipes with an empty filter", func() {
+			where, args := recipeFilterSQL(&RecipeSearchFilter{})
+			Expect(where).To(Equal(" WHERE NOT r.archived"))
+			Expect(args).To(BeEmpty())
+		})
+		It("should restrict the results to archived recipes", func() {
+			where, args := recipeFilterSQL(&RecipeSearchFilter{Archived: true})
+			Expect(where).To(Equal(" WHERE r.archived"))
+			Expect(args).To(BeEmpty())
+		})
+		It("should match any search term and the tag", func() {
+			where, args := recipeFilterSQL(&RecipeSearchFilter{Name: "soup", Ingredient: []string{"tomato", "basil"}, Tag: "vegan"})
+			Expect(where).To(Equal(" WHERE (r.name ILIKE $1 OR EXISTS (SELECT 1 FROM ingredients i WHERE i.recipe_id = r.id AND i.name ILIKE ANY($2))) " +
+				"AND EXISTS (SELECT 1 FROM unnest(r.tags) t WHERE lower(t) = lower($3)) AND NOT r.archived"))
+			Expect(args).To(Equal([]interface{}{"%soup%", pq.Array([]string{"%tomato%", "%basil%"}), "vegan"}))
+		})
+		It("should restrict the results to recipes changed after a given time", func() {
+			since := time.Date(2021, 7, 31, 12, 0, 0, 0, time.UTC)
+			where, args := recipeFilterSQL(&RecipeSearchFilter{Tag: "vegan", ChangedSince: since})
+			Expect(where).To(Equal(" WHERE EXISTS (SELECT 1 FROM unnest(r.tags) t WHERE lower(t) = lower($1)) AND r.updated_at > $2 AND NOT r.archived"))
+			Expect(args).To(Equal([]interface{}{"vegan", since}))
+		})
+		It("should restrict the results to recipes with a known total time within the limit", func() {
+			where, args := recipeFilterSQL(&RecipeSearchFilter{MaxTotalTime: 30})
+			Expect(where).To(Equal(" WHERE r.prep_minutes + r.cook_minutes > 0 AND r.prep_minutes + r.cook_minutes <= $1 AND NOT r.archived"))
+			Expect(args).To(Equal([]interface{}{30}))
+		})
+		It("should escape wildcards of search terms", func() {
+			Expect(likePattern(`100%_\`)).To(Equal(`%100\%\_\\%`))
+		})
+	})
+
+	Context("order", func() {
+		It("should order by insertion by default", func() {
+			Expect(recipeOrderSQL(&RecipeSearchFilter{})).To(Equal(" ORDER BY r.seq"))
+		})
+		It("should order by rating and calories", func() {
+			Expect(recipeOrderSQL(&RecipeSearchFilter{Sort: SortByRating})).To(HavePrefix(" ORDER BY r.rating DESC"))
+			Expect(recipeOrderSQL(&RecipeSearchFilter{Sort: SortByCalories})).To(HavePrefix(" ORDER BY (r.nutrition->>'calories')"))
+		})
+		It("should order by name in the requested direction", func() {
+			Expect(recipeOrderSQL(&RecipeSearchFilter{Sort: SortByName})).To(Equal(" ORDER BY lower(r.name), r.seq"))
+			Expect(recipeOrderSQL(&RecipeSearchFilter{Sort: SortByName, Order: OrderDescending})).To(Equal(" ORDER BY lower(r.name) DESC, r.seq"))
+			Expect(recipeOrderSQL(&RecipeSearchFilter{Sort: SortByCreated, Order: OrderDescending})).To(Equal(" ORDER BY r.seq DESC"))
+		})
+	})
+
+	Context("search", func() {
+		It("should require all terms to match", func() {
+			query, args := searchSQL([]string{"tomato", "olive oil"})
+			Expect(args).To(Equal([]interface{}{"%tomato%", "%olive oil%"}))
+			Expect(query).To(ContainSubstring("r.name ILIKE $1 OR"))
+			Expect(query).To(ContainSubstring(") AND (r.name ILIKE $2 OR"))
+			Expect(query).To(ContainSubstring("WHERE NOT r.archived AND"))
+			Expect(query).To(HaveSuffix("ORDER BY score DESC, r.seq"))
+		})
+	})
+
+	Context("database", func() {
+
+		var db *PostgresDB
+
+		BeforeEach(func() {
+			dsn := os.Getenv(postgresTestDSN)
+			if dsn == "" {
+				Skip(postgresTestDSN + " is not set")
+			}
+			var err error
+			db, err = NewPostgresDB(dsn)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(db.Migrate()).To(Succeed())
+			db.Clear()
+		})
+
+		AfterEach(func() {
+			if db != nil {
+				db.Clear()
+				Expect(db.Close()).To(Succeed())
+			}
+		})
+
+		newRecipe := func(name string) *Recipe {
+			recipe := NewRecipe(NewRecipeID())
+			recipe.Name = name
+			Expect(db.Insert(recipe)).To(Succeed())
+			return recipe
+		}
+
+		It("should insert and read a recipe", func() {
+			recipe := NewRecipe(NewRecipeID())
+			recipe.Name = "soup"
+			recipe.Ingredients = []Ingredients{{Amount: 1, AmountMax: 2, Unit: "l", Name: "water"}}
+			Expect(db.Insert(recipe)).To(Succeed())
+
+			stored := db.Get(recipe.ID)
+			Expect(stored.Name).To(Equal("soup"))
+			Expect(stored.Ingredients).To(Equal(recipe.Ingredients))
+			Expect(stored.Version).To(BeZero())
+			Expect(stored.CreatedAt).To(BeTemporally("==", recipe.CreatedAt))
+		})
+
+		It("should update a recipe and reject stale versions", func() {
+			recipe := newRecipe("soup")
+			stale := db.Get(recipe.ID)
+
+			update := db.Get(recipe.ID)
+			update.Name = "stew"
+			Expect(db.Update(recipe.ID, update)).To(Succeed())
+			Expect(update.Version).To(Equal(1))
+			Expect(db.Get(recipe.ID).Name).To(Equal("stew"))
+
+			stale.Name = "salad"
+			Expect(db.Update(recipe.ID, stale)).To(MatchError(ErrVersionConflict))
+			Expect(db.Get(recipe.ID).Name).To(Equal("stew"))
+
+			Expect(db.Update(NewRecipeID(), NewRecipe(NewRecipeID()))).To(Succeed())
+		})
+
+		It("should merge duplicates into the primary recipe", func() {
+			soup := newRecipe("soup")
+			duplicate := newRecipe("soup")
+			Expect(db.AddPicture(&RecipePicture{ID: soup.ID, Name: "soup.png", Picture: "abc"})).To(Succeed())
+			Expect(db.AddPicture(&RecipePicture{ID: duplicate.ID, Name: "soup.png", Picture: "def"})).To(Succeed())
+			Expect(db.AddPicture(&RecipePicture{ID: duplicate.ID, Name: "bowl.png", Picture: "ghi"})).To(Succeed())
+			Expect(db.AddNote(&Note{ID: NewNoteID(), RecipeID: duplicate.ID, Text: "note"})).To(Succeed())
+			_, _ = db.AddRating(soup.ID, 5)
+			_, _ = db.AddRating(duplicate.ID, 3)
+			_, _ = db.AddRating(duplicate.ID, 3)
+
+			merged, err := db.Merge(soup.ID, []RecipeID{duplicate.ID})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(merged.Rating).To(BeNumerically("~", 11.0/3.0, 0.0001))
+			Expect(merged.RatingCount).To(Equal(3))
+			Expect(merged.PictureLink).To(Equal([]string{"soup.png", "bowl.png"}))
+			Expect(db.Picture(soup.ID, "soup.png").Picture).To(Equal("abc"))
+			Expect(db.Notes(soup.ID)).To(HaveLen(1))
+			Expect(db.Get(duplicate.ID).ID).To(Equal(InvalidRecipeID()))
+			Expect(db.Pictures(duplicate.ID)).To(BeEmpty())
+		})
+
+		It("should not change any recipe if a duplicate does not exist", func() {
+			soup := newRecipe("soup")
+			duplicate := newRecipe("soup")
+			_, _ = db.AddRating(duplicate.ID, 3)
+
+			_, err := db.Merge(soup.ID, []RecipeID{duplicate.ID, NewRecipeID()})
+			Expect(err).To(MatchError(ErrRecipeNotFound))
+			Expect(db.Get(duplicate.ID).ID).To(Equal(duplicate.ID))
+			Expect(db.Get(soup.ID).RatingCount).To(BeZero())
+		})
+
+		It("should tag recipes in bulk", func() {
+			recipe := NewRecipe(NewRecipeID())
+			recipe.Tags = []string{"Soup", "quick"}
+			Expect(db.Insert(recipe)).To(Succeed())
+			missing := NewRecipeID()
+
+			results, err := db.BulkTag([]RecipeID{recipe.ID, missing}, []string{"vegan", "soup"}, []string{"QUICK"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results).To(Equal(map[RecipeID]bool{recipe.ID: true, missing: false}))
+			Expect(db.Get(recipe.ID).Tags).To(Equal([]string{"Soup", "vegan"}))
+			Expect(db.Get(recipe.ID).Version).To(Equal(recipe.Version + 1))
+		})
+
+		It("should archive and restore recipes", func() {
+			recipe := newRecipe("soup")
+
+			Expect(db.Archive(recipe.ID)).To(Succeed())
+			Expect(db.Get(recipe.ID).Archived).To(BeTrue())
+			Expect(db.IDs(&RecipeSearchFilter{}).Recipes).To(BeEmpty())
+			Expect(db.IDs(&RecipeSearchFilter{Archived: true}).Recipes).To(Equal([]string{recipe.ID.String()}))
+
+			Expect(db.Restore(recipe.ID)).To(Succeed())
+			Expect(db.IDs(&RecipeSearchFilter{}).Recipes).To(Equal([]string{recipe.ID.String()}))
+			Expect(db.Archive(NewRecipeID())).To(MatchError(ErrRecipeNotFound))
+		})
+
+		It("should exclude archived recipes from suggestions, tags, statistics, and similar recipes", func() {
+			soup := NewRecipe(NewRecipeID())
+			soup.Name, soup.Tags = "soup", []string{"winter"}
+			soup.Ingredients = []Ingredients{{Name: "tomato"}, {Name: "onion"}}
+			Expect(db.Insert(soup)).To(Succeed())
+			stew := NewRecipe(NewRecipeID())
+			stew.Name, stew.Tags = "stew", []string{"hearty"}
+			stew.Ingredients = []Ingredients{{Name: "tomato"}, {Name: "turnip"}}
+			Expect(db.Insert(stew)).To(Succeed())
+			Expect(db.Archive(stew.ID)).To(Succeed())
+
+			Expect(db.SuggestIngredients("t", 10)).To(Equal([]string{"tomato"}))
+			Expect(db.Tags()).To(Equal([]TagCount{{Tag: "winter", Count: 1}}))
+			stats, err := db.Stats()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stats.Total).To(Equal(int64(1)))
+			Expect(stats.Tags).To(Equal([]TagCount{{Tag: "winter", Count: 1}}))
+			Expect(stats.TopIngredients).To(ConsistOf(IngredientCount{Name: "tomato", Count: 1}, IngredientCount{Name: "onion", Count: 1}))
+			Expect(db.FindSimilar(soup.ID, 0)).To(BeEmpty())
+		})
+
+		It("should track the last modification of recipes, including removals", func() {
+			recipe := newRecipe("soup")
+			Expect(db.LastModified()).To(BeTemporally(">=", recipe.UpdatedAt))
+
+			time.Sleep(2 * time.Millisecond)
+			Expect(db.Remove(recipe.ID)).To(Succeed())
+			Expect(db.LastModified()).To(BeTemporally(">", recipe.UpdatedAt))
+		})
+	})
+})
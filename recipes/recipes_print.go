@@ -0,0 +1,132 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	"bytes"
+	"html/template"
+)
+
+//printTemplate lays out a recipe as a self-contained HTML page for printing from a browser
+var printTemplate = template.Must(template.New("print").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Name}}</title>
+<style>
+body { font-family: Georgia, "Times New Roman", serif; color: #222; max-width: 42em; margin: 2em auto; padding: 0 1em; line-height: 1.4; }
+h1 { font-size: 1.8em; margin-bottom: 0.2em; }
+h2 { font-size: 1.3em; border-bottom: 1px solid #999; padding-bottom: 0.1em; margin-top: 1.4em; }
+h3 { font-size: 1.05em; margin: 0.8em 0 0.3em; }
+.meta { color: #555; font-style: italic; margin: 0; }
+ul { padding-left: 1.2em; }
+ol li { margin-bottom: 0.5em; }
+@media print {
+  body { margin: 0; max-width: none; }
+  h2, h3 { page-break-after: avoid; }
+  li { page-break-inside: avoid; }
+}
+</style>
+</head>
+<body>
+<h1>{{.Name}}</h1>
+{{- if .Servings}}
+<p class="meta">Servings: {{.Servings}}</p>
+{{- end}}
+{{- if or .PrepMinutes .CookMinutes}}
+<p class="meta">{{if .PrepMinutes}}Preparation: {{.PrepMinutes}} min{{end}}{{if and .PrepMinutes .CookMinutes}} &middot; {{end}}{{if .CookMinutes}}Cooking: {{.CookMinutes}} min{{end}}</p>
+{{- end}}
+{{- if .Difficulty}}
+<p class="meta">Difficulty: {{.Difficulty}}</p>
+{{- end}}
+{{- if .Tags}}
+<p class="meta">Tags: {{range $i, $tag := .Tags}}{{if $i}}, {{end}}{{$tag}}{{end}}</p>
+{{- end}}
+{{- if .Groups}}
+<h2>Ingredients</h2>
+{{- range .Groups}}
+{{- if .Name}}
+<h3>{{.Name}}</h3>
+{{- end}}
+<ul>
+{{- range .Ingredients}}
+<li>{{.}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- end}}
+{{- if .Instructions}}
+<h2>Instructions</h2>
+<ol>
+{{- range .Instructions}}
+<li>{{.}}</li>
+{{- end}}
+</ol>
+{{- end}}
+</body>
+</html>
+`))
+
+//printView is the data of the printTemplate
+type printView struct {
+	Name         string
+	Servings     int8
+	PrepMinutes  int
+	CookMinutes  int
+	Difficulty   string
+	Tags         []string
+	Groups       []printIngredientGroup
+	Instructions []string
+}
+
+//printIngredientGroup is a section of formatted ingredients
+type printIngredientGroup struct {
+	Name        string
+	Ingredients []string
+}
+
+//renderHTML renders a recipe as printable HTML page; all texts of the recipe are escaped
+func renderHTML(r Recipe) ([]byte, error) {
+	view := printView{
+		Name:         r.Name,
+		Servings:     r.Servings,
+		PrepMinutes:  r.PrepMinutes,
+		CookMinutes:  r.CookMinutes,
+		Difficulty:   r.Difficulty,
+		Tags:         r.Tags,
+		Instructions: instructions(r),
+	}
+	for _, group := range ingredientGroups(r.Ingredients) {
+		formatted := make([]string, 0, len(group.Ingredients))
+		for _, ingredient := range group.Ingredients {
+			formatted = append(formatted, formatIngredient(ingredient))
+		}
+		view.Groups = append(view.Groups, printIngredientGroup{Name: group.Name, Ingredients: formatted})
+	}
+
+	var b bytes.Buffer
+	err := printTemplate.Execute(&b, view)
+	return b.Bytes(), err
+}
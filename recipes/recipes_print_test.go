@@ -0,0 +1,79 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("print view", func() {
+
+	recipe := Recipe{
+		ID:   NewRecipeID(),
+		Name: "Pancakes",
+		Ingredients: []Ingredients{
+			{Name: "Flour", Amount: 250, Unit: "g"},
+			{Name: "Maple syrup", Amount: 2, Unit: "tbsp", Group: "For serving"},
+		},
+		Steps:       []RecipeStep{{Text: "Mix everything."}, {Text: "Fry in a pan.", Minutes: 5}},
+		Servings:    2,
+		PrepMinutes: 10,
+	}
+
+	It("renders a complete HTML page with inline CSS", func() {
+		result, err := renderHTML(recipe)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(result)).To(HavePrefix("<!DOCTYPE html>"))
+		Expect(string(result)).To(ContainSubstring("<style>"))
+		Expect(string(result)).To(ContainSubstring("<title>Pancakes</title>"))
+		Expect(string(result)).To(ContainSubstring("Servings: 2"))
+		Expect(string(result)).To(ContainSubstring("Preparation: 10 min"))
+		Expect(string(result)).To(ContainSubstring("<li>250 g Flour</li>"))
+		Expect(string(result)).To(ContainSubstring("<h3>For serving</h3>\n<ul>\n<li>2 tbsp Maple syrup</li>"))
+		Expect(string(result)).To(ContainSubstring("<ol>\n<li>Mix everything.</li>\n<li>Fry in a pan. (5 min)</li>\n</ol>"))
+	})
+
+	It("escapes the texts of the recipe", func() {
+		result, err := renderHTML(Recipe{
+			Name:        "<script>alert(1)</script>",
+			Ingredients: []Ingredients{{Name: `<img src=x onerror="alert(1)">`, Group: "</h3><b>"}},
+			Description: "Stir & <b>serve</b>",
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(result)).ToNot(ContainSubstring("<script>"))
+		Expect(string(result)).ToNot(ContainSubstring("<img"))
+		Expect(string(result)).ToNot(ContainSubstring("<b>"))
+		Expect(string(result)).To(ContainSubstring("&lt;script&gt;alert(1)&lt;/script&gt;"))
+		Expect(string(result)).To(ContainSubstring("Stir &amp; &lt;b&gt;serve&lt;/b&gt;"))
+	})
+
+	It("omits empty sections", func() {
+		result, err := renderHTML(Recipe{Name: "Nothing"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(result)).ToNot(ContainSubstring("<h2>"))
+		Expect(string(result)).ToNot(ContainSubstring("Servings"))
+	})
+})
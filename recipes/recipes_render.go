@@ -0,0 +1,267 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+const (
+	//FormatJSON renders recipes as JSON
+	FormatJSON = "json"
+	//FormatMarkdown renders recipes as Markdown
+	FormatMarkdown = "markdown"
+	//FormatPlainText renders recipes as plain text
+	FormatPlainText = "plaintext"
+	//FormatPDF renders recipes as printable PDF
+	FormatPDF = "pdf"
+)
+
+//ErrUnknownFormat is returned when a recipe should be rendered in a format that is not supported
+var ErrUnknownFormat = errors.New("unknown format")
+
+//Render a recipe in the given format. The rendered recipe is returned together with its content type.
+//Recipes are rendered as JSON if no format is given.
+func Render(r Recipe, format string) ([]byte, string, error) {
+	switch format {
+	case "", FormatJSON:
+		result, err := json.Marshal(r)
+		return result, "application/json; charset=utf-8", err
+	case FormatMarkdown:
+		return renderMarkdown(r), "text/markdown; charset=utf-8", nil
+	case FormatPlainText:
+		return renderPlainText(r), "text/plain; charset=utf-8", nil
+	case FormatPDF:
+		result, err := renderPDF(r)
+		return result, "application/pdf", err
+	default:
+		return nil, "", ErrUnknownFormat
+	}
+}
+
+func renderMarkdown(r Recipe) []byte {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "# %v\n", r.Name)
+	if r.Servings > 0 {
+		fmt.Fprintf(&b, "\n_Servings: %v_\n", r.Servings)
+	}
+	if len(r.Tags) > 0 {
+		fmt.Fprintf(&b, "\n_Tags: %v_\n", strings.Join(r.Tags, ", "))
+	}
+	if len(r.Ingredients) > 0 {
+		b.WriteString("\n## Ingredients\n")
+		for _, group := range ingredientGroups(r.Ingredients) {
+			if group.Name != "" {
+				fmt.Fprintf(&b, "\n### %v\n", group.Name)
+			}
+			b.WriteString("\n")
+			for _, ingredient := range group.Ingredients {
+				fmt.Fprintf(&b, "- %v\n", formatIngredient(ingredient))
+			}
+		}
+	}
+	if steps := instructions(r); len(steps) > 0 {
+		b.WriteString("\n## Instructions\n\n")
+		for i, step := range steps {
+			fmt.Fprintf(&b, "%v. %v\n", i+1, step)
+		}
+	}
+
+	return b.Bytes()
+}
+
+func renderPlainText(r Recipe) []byte {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "%v\n", r.Name)
+	if r.Servings > 0 {
+		fmt.Fprintf(&b, "\nServings: %v\n", r.Servings)
+	}
+	if len(r.Tags) > 0 {
+		fmt.Fprintf(&b, "Tags: %v\n", strings.Join(r.Tags, ", "))
+	}
+	if len(r.Ingredients) > 0 {
+		b.WriteString("\nIngredients:\n")
+		for _, group := range ingredientGroups(r.Ingredients) {
+			indent := "  "
+			if group.Name != "" {
+				fmt.Fprintf(&b, "  %v:\n", group.Name)
+				indent = "    "
+			}
+			for _, ingredient := range group.Ingredients {
+				fmt.Fprintf(&b, "%v%v\n", indent, formatIngredient(ingredient))
+			}
+		}
+	}
+	if steps := instructions(r); len(steps) > 0 {
+		b.WriteString("\nInstructions:\n")
+		for i, step := range steps {
+			fmt.Fprintf(&b, "  %v. %v\n", i+1, step)
+		}
+	}
+
+	return b.Bytes()
+}
+
+//renderPDF lays out a recipe card with the title, a table of ingredients, and the instructions
+func renderPDF(r Recipe) ([]byte, error) {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	// core fonts only support cp1252, i.e., umlauts need to be translated
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.SetTitle(r.Name, true)
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", 20)
+	pdf.MultiCell(0, 10, tr(r.Name), "", "L", false)
+
+	pdf.SetFont("Helvetica", "I", 11)
+	if r.Servings > 0 {
+		pdf.CellFormat(0, 7, tr(fmt.Sprintf("Servings: %v", r.Servings)), "", 1, "L", false, 0, "")
+	}
+	if len(r.Tags) > 0 {
+		pdf.CellFormat(0, 7, tr(fmt.Sprintf("Tags: %v", strings.Join(r.Tags, ", "))), "", 1, "L", false, 0, "")
+	}
+
+	if len(r.Ingredients) > 0 {
+		pdf.Ln(4)
+		pdf.SetFont("Helvetica", "B", 14)
+		pdf.CellFormat(0, 9, "Ingredients", "", 1, "L", false, 0, "")
+		for _, group := range ingredientGroups(r.Ingredients) {
+			if group.Name != "" {
+				pdf.SetFont("Helvetica", "B", 11)
+				pdf.CellFormat(0, 8, tr(group.Name), "", 1, "L", false, 0, "")
+			}
+			pdf.SetFont("Helvetica", "", 11)
+			for _, ingredient := range group.Ingredients {
+				amount := ""
+				if ingredient.Amount > 0 {
+					amount = strings.TrimSpace(formatAmount(ingredient) + " " + ingredient.Unit)
+				}
+				pdf.CellFormat(40, 7, tr(amount), "B", 0, "R", false, 0, "")
+				pdf.CellFormat(0, 7, tr(ingredient.Name), "B", 1, "L", false, 0, "")
+			}
+		}
+	}
+
+	if steps := instructions(r); len(steps) > 0 {
+		pdf.Ln(4)
+		pdf.SetFont("Helvetica", "B", 14)
+		pdf.CellFormat(0, 9, "Instructions", "", 1, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 11)
+		for i, step := range steps {
+			pdf.MultiCell(0, 6, tr(fmt.Sprintf("%v. %v", i+1, step)), "", "L", false)
+		}
+	}
+
+	var b bytes.Buffer
+	err := pdf.Output(&b)
+	return b.Bytes(), err
+}
+
+//ingredientGroup is a section of a recipe's ingredients
+type ingredientGroup struct {
+	Name        string
+	Ingredients []Ingredients
+}
+
+//ingredientGroups sections the ingredients by their group. Ingredients without a group come first,
+//the other groups are ordered by their first occurrence.
+func ingredientGroups(ingredients []Ingredients) []ingredientGroup {
+	groups := []ingredientGroup{{}}
+	positions := map[string]int{"": 0}
+
+	for _, ingredient := range ingredients {
+		name := strings.TrimSpace(ingredient.Group)
+		pos, ok := positions[name]
+		if !ok {
+			pos = len(groups)
+			positions[name] = pos
+			groups = append(groups, ingredientGroup{Name: name})
+		}
+		groups[pos].Ingredients = append(groups[pos].Ingredients, ingredient)
+	}
+
+	if len(groups[0].Ingredients) == 0 {
+		groups = groups[1:]
+	}
+	return groups
+}
+
+//formatIngredient as '<amount> <unit> <name>'; amount and unit are omitted if they are not given
+func formatIngredient(ingredient Ingredients) string {
+	parts := make([]string, 0, 3)
+	if ingredient.Amount > 0 {
+		parts = append(parts, formatAmount(ingredient))
+		if ingredient.Unit != "" {
+			parts = append(parts, ingredient.Unit)
+		}
+	}
+	parts = append(parts, ingredient.Name)
+	return strings.Join(parts, " ")
+}
+
+//formatAmount of an ingredient, e.g., '2' or, for a range, '2–3'
+func formatAmount(ingredient Ingredients) string {
+	amount := strconv.FormatFloat(ingredient.Amount, 'f', -1, 64)
+	if ingredient.AmountMax > ingredient.Amount {
+		amount += "–" + strconv.FormatFloat(ingredient.AmountMax, 'f', -1, 64)
+	}
+	return amount
+}
+
+//instructions of a recipe, i.e., its steps or, if it has no steps, the lines of its description. The minutes of steps are added to their text.
+func instructions(r Recipe) []string {
+	if len(r.Steps) == 0 {
+		return instructionSteps(r.Description)
+	}
+	steps := make([]string, 0, len(r.Steps))
+	for _, step := range r.Steps {
+		if step.Minutes > 0 {
+			steps = append(steps, fmt.Sprintf("%v (%v min)", step.Text, step.Minutes))
+		} else {
+			steps = append(steps, step.Text)
+		}
+	}
+	return steps
+}
+
+//instructionSteps splits a description into its non-empty lines
+func instructionSteps(description string) []string {
+	steps := make([]string, 0)
+	for _, line := range strings.Split(description, "\n") {
+		if step := strings.TrimSpace(line); step != "" {
+			steps = append(steps, step)
+		}
+	}
+	return steps
+}
@@ -0,0 +1,149 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("rendering", func() {
+
+	recipe := Recipe{
+		ID:   NewRecipeID(),
+		Name: "Pancakes",
+		Ingredients: []Ingredients{
+			{Name: "Flour", Amount: 250, Unit: "g"},
+			{Name: "Eggs", Amount: 2},
+			{Name: "Salt", Amount: NoAmountIngredient},
+		},
+		Description: "\nMix everything.\n\nFry in a pan.",
+		Servings:    2,
+	}
+
+	It("renders JSON by default", func() {
+		result, contentType, err := Render(recipe, "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(contentType).To(HavePrefix("application/json"))
+
+		var decoded Recipe
+		Expect(json.Unmarshal(result, &decoded)).To(Succeed())
+		Expect(decoded).To(Equal(recipe))
+	})
+
+	It("renders Markdown with an ingredient list and numbered steps", func() {
+		result, contentType, err := Render(recipe, FormatMarkdown)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(contentType).To(HavePrefix("text/markdown"))
+		Expect(string(result)).To(Equal("# Pancakes\n\n_Servings: 2_\n\n## Ingredients\n\n- 250 g Flour\n- 2 Eggs\n- Salt\n\n## Instructions\n\n1. Mix everything.\n2. Fry in a pan.\n"))
+	})
+
+	It("renders plain text", func() {
+		result, contentType, err := Render(recipe, FormatPlainText)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(contentType).To(HavePrefix("text/plain"))
+		Expect(string(result)).To(ContainSubstring("  250 g Flour\n"))
+		Expect(string(result)).To(ContainSubstring("  2. Fry in a pan.\n"))
+	})
+
+	It("renders PDF", func() {
+		result, contentType, err := Render(recipe, FormatPDF)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(contentType).To(Equal("application/pdf"))
+		Expect(string(result)).To(HavePrefix("%PDF-"))
+	})
+
+	It("renders PDF without optional fields", func() {
+		result, _, err := Render(Recipe{Name: "Nothing"}, FormatPDF)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(result)).To(HavePrefix("%PDF-"))
+	})
+
+	It("numbers the steps instead of the lines of the description", func() {
+		withSteps := recipe
+		withSteps.Steps = []RecipeStep{{Text: "Mix everything."}, {Text: "Let the dough rest.", Minutes: 30}}
+
+		result, _, err := Render(withSteps, FormatMarkdown)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(result)).To(HaveSuffix("## Instructions\n\n1. Mix everything.\n2. Let the dough rest. (30 min)\n"))
+
+		result, _, err = Render(withSteps, FormatPlainText)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(result)).To(ContainSubstring("  2. Let the dough rest. (30 min)\n"))
+	})
+
+	It("sections the ingredients by their group", func() {
+		grouped := Recipe{
+			Name: "Lasagne",
+			Ingredients: []Ingredients{
+				{Name: "Minced meat", Amount: 500, Unit: "g", Group: "For the sauce"},
+				{Name: "Lasagne sheets", Amount: 12},
+				{Name: "Milk", Amount: 500, Unit: "ml", Group: "For the béchamel"},
+				{Name: "Tomatoes", Amount: 800, Unit: "g", Group: "For the sauce"},
+			},
+		}
+
+		result, _, err := Render(grouped, FormatMarkdown)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(result)).To(Equal("# Lasagne\n\n## Ingredients\n\n- 12 Lasagne sheets\n\n### For the sauce\n\n- 500 g Minced meat\n- 800 g Tomatoes\n\n### For the béchamel\n\n- 500 ml Milk\n"))
+
+		result, _, err = Render(grouped, FormatPlainText)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(result)).To(Equal("Lasagne\n\nIngredients:\n  12 Lasagne sheets\n  For the sauce:\n    500 g Minced meat\n    800 g Tomatoes\n  For the béchamel:\n    500 ml Milk\n"))
+
+		result, _, err = Render(grouped, FormatPDF)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(result)).To(HavePrefix("%PDF-"))
+	})
+
+	It("renders ranges of amounts", func() {
+		ranged := Recipe{Name: "Garlic bread", Ingredients: []Ingredients{{Name: "garlic", Amount: 2, AmountMax: 3, Unit: "cloves"}}}
+		result, _, err := Render(ranged, FormatMarkdown)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(result)).To(ContainSubstring("- 2–3 cloves garlic\n"))
+
+		result, _, err = Render(ranged, FormatPDF)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(result)).To(HavePrefix("%PDF-"))
+	})
+
+	It("omits empty sections", func() {
+		result, _, err := Render(Recipe{Name: "Nothing"}, FormatMarkdown)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(result)).To(Equal("# Nothing\n"))
+	})
+
+	It("derives file names from recipe names", func() {
+		Expect(exportFileName(" Apple Pie! ", "pdf")).To(Equal("Apple-Pie.pdf"))
+		Expect(exportFileName("???", "pdf")).To(Equal("recipe.pdf"))
+	})
+
+	It("rejects unknown formats", func() {
+		_, _, err := Render(recipe, "docx")
+		Expect(err).To(Equal(ErrUnknownFormat))
+	})
+})
@@ -0,0 +1,68 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	"strings"
+	"unicode"
+)
+
+//RecipeSearchResult is a recipe found by a full-text search, ranked by its relevance
+type RecipeSearchResult struct {
+	ID   RecipeID `json:"id"`
+	Name string   `json:"name"`
+	//Score is the relevance of the recipe for the search; higher scores are more relevant
+	Score float64 `json:"score"`
+}
+
+//SearchTerms splits a search query into terms. Quoted phrases, e.g., "olive oil", are kept as one term.
+//An unterminated quote extends the phrase to the end of the query.
+func SearchTerms(query string) []string {
+	terms := make([]string, 0)
+
+	var term strings.Builder
+	inPhrase := false
+	addTerm := func() {
+		if t := strings.TrimSpace(term.String()); t != "" {
+			terms = append(terms, t)
+		}
+		term.Reset()
+	}
+
+	for _, r := range query {
+		switch {
+		case r == '"':
+			addTerm()
+			inPhrase = !inPhrase
+		case unicode.IsSpace(r) && !inPhrase:
+			addTerm()
+		default:
+			term.WriteRune(r)
+		}
+	}
+	addTerm()
+
+	return terms
+}
@@ -0,0 +1,48 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("search", func() {
+
+	Context("terms", func() {
+		It("should split a query by whitespace", func() {
+			Expect(SearchTerms("  tomato \t basil ")).To(Equal([]string{"tomato", "basil"}))
+		})
+		It("should keep quoted phrases as one term", func() {
+			Expect(SearchTerms(`pasta "olive oil" garlic`)).To(Equal([]string{"pasta", "olive oil", "garlic"}))
+		})
+		It("should extend an unterminated phrase to the end of the query", func() {
+			Expect(SearchTerms(`pasta "olive oil`)).To(Equal([]string{"pasta", "olive oil"}))
+		})
+		It("should ignore empty phrases", func() {
+			Expect(SearchTerms(`"" " "`)).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,105 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	"fmt"
+	"strings"
+)
+
+//ShoppingListItem references a recipe that should be cooked for a number of servings
+type ShoppingListItem struct {
+	ID RecipeID `json:"id"`
+	//Servings the recipe is scaled to; the recipe's own servings are used if no servings are given
+	Servings int8 `json:"servings"`
+}
+
+//ShoppingListRequest models the recipes for which a shopping list is requested
+type ShoppingListRequest struct {
+	Recipes []ShoppingListItem `json:"recipes"`
+}
+
+//ShoppingList returns the merged ingredients of all scaled recipes.
+//An error wrapping ErrRecipeNotFound is returned if one of the recipes does not exist.
+func ShoppingList(recipes Recipes, items []ShoppingListItem) ([]Ingredients, error) {
+	ingredients := make([]Ingredients, 0)
+
+	for _, item := range items {
+		recipe := recipes.Get(item.ID)
+		if recipe.ID == InvalidRecipeID() {
+			return nil, fmt.Errorf("%w: %v", ErrRecipeNotFound, item.ID)
+		}
+		if item.Servings > 0 {
+			recipe.ScaleTo(item.Servings)
+		}
+		ingredients = append(ingredients, recipe.Ingredients...)
+	}
+
+	return MergeIngredients(ingredients), nil
+}
+
+//MergeIngredients sums up the amounts of ingredients with the same name and unit, ignoring the case.
+//Units are normalized first, ingredients with different units are kept apart. The order of the first occurrences is preserved.
+//Groups are ignored, i.e., the merged ingredients have no group. Ranges are merged by summing up both ends.
+func MergeIngredients(ingredients []Ingredients) []Ingredients {
+	merged := make([]Ingredients, 0, len(ingredients))
+	positions := make(map[string]int)
+
+	for _, ingredient := range ingredients {
+		ingredient.NormalizeUnit()
+		ingredient.Group = ""
+		key := strings.ToLower(strings.TrimSpace(ingredient.Name)) + "|" + strings.ToLower(ingredient.Unit)
+
+		pos, ok := positions[key]
+		if !ok {
+			positions[key] = len(merged)
+			merged = append(merged, ingredient)
+			continue
+		}
+
+		switch {
+		case ingredient.Amount <= 0:
+			// nothing to add, e.g., 'salt to taste'
+		case merged[pos].Amount <= 0:
+			merged[pos].Amount = ingredient.Amount
+			merged[pos].AmountMax = ingredient.AmountMax
+		default:
+			if merged[pos].AmountMax > 0 || ingredient.AmountMax > 0 {
+				merged[pos].AmountMax = roundAmount(maxAmount(merged[pos]) + maxAmount(ingredient))
+			}
+			merged[pos].Amount = roundAmount(merged[pos].Amount + ingredient.Amount)
+		}
+	}
+
+	return merged
+}
+
+//maxAmount is the upper end of the ingredient's range or, if the amount is no range, the amount
+func maxAmount(ingredient Ingredients) float64 {
+	if ingredient.AmountMax > 0 {
+		return ingredient.AmountMax
+	}
+	return ingredient.Amount
+}
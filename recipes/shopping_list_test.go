@@ -0,0 +1,89 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("shopping list", func() {
+
+	It("sums up ingredients with the same name and unit", func() {
+		merged := MergeIngredients([]Ingredients{
+			{Name: "Flour", Amount: 200, Unit: "g"},
+			{Name: "Milk", Amount: 0.5, Unit: "l"},
+			{Name: "flour ", Amount: 50, Unit: "grams"},
+		})
+		Expect(merged).To(Equal([]Ingredients{
+			{Name: "Flour", Amount: 250, Unit: "g"},
+			{Name: "Milk", Amount: 0.5, Unit: "l"},
+		}))
+	})
+
+	It("ignores the groups of ingredients", func() {
+		merged := MergeIngredients([]Ingredients{
+			{Name: "Butter", Amount: 50, Unit: "g", Group: "For the dough"},
+			{Name: "Butter", Amount: 30, Unit: "g", Group: "For the crumble"},
+		})
+		Expect(merged).To(Equal([]Ingredients{
+			{Name: "Butter", Amount: 80, Unit: "g"},
+		}))
+	})
+
+	It("keeps ingredients with different units apart", func() {
+		merged := MergeIngredients([]Ingredients{
+			{Name: "Sugar", Amount: 100, Unit: "g"},
+			{Name: "Sugar", Amount: 2, Unit: "tbsp"},
+		})
+		Expect(merged).To(HaveLen(2))
+	})
+
+	It("sums up both ends of ranges", func() {
+		merged := MergeIngredients([]Ingredients{
+			{Name: "Garlic", Amount: 2, AmountMax: 3},
+			{Name: "Garlic", Amount: 1},
+			{Name: "Onions", Amount: NoAmountIngredient},
+			{Name: "Onions", Amount: 1, AmountMax: 2},
+		})
+		Expect(merged).To(Equal([]Ingredients{
+			{Name: "Garlic", Amount: 3, AmountMax: 4},
+			{Name: "Onions", Amount: 1, AmountMax: 2},
+		}))
+	})
+
+	It("merges ingredients without an amount", func() {
+		merged := MergeIngredients([]Ingredients{
+			{Name: "Salt", Amount: NoAmountIngredient},
+			{Name: "Salt", Amount: NoAmountIngredient},
+			{Name: "Pepper", Amount: NoAmountIngredient},
+			{Name: "Pepper", Amount: 1},
+		})
+		Expect(merged).To(Equal([]Ingredients{
+			{Name: "Salt", Amount: NoAmountIngredient},
+			{Name: "Pepper", Amount: 1},
+		}))
+	})
+})
@@ -0,0 +1,93 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	"sort"
+	"strings"
+)
+
+//SimilarRecipe is a recipe whose ingredients overlap with the ingredients of another recipe
+type SimilarRecipe struct {
+	ID   RecipeID `json:"id"`
+	Name string   `json:"name"`
+	//Score is the Jaccard similarity of the recipes' ingredient names, between 0 (no common ingredient) and 1 (the same ingredients)
+	Score float64 `json:"score"`
+}
+
+//normalizeIngredientName ignores the case and the whitespace of ingredient names when comparing them
+func normalizeIngredientName(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
+
+//ingredientNames returns the set of the normalized ingredient names of a recipe
+func ingredientNames(recipe *Recipe) map[string]bool {
+	names := make(map[string]bool)
+	for _, ingredient := range recipe.Ingredients {
+		if name := normalizeIngredientName(ingredient.Name); name != "" {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+//jaccard similarity of two sets, i.e., the size of their intersection divided by the size of their union
+func jaccard(a map[string]bool, b map[string]bool) float64 {
+	intersection := 0
+	for name := range a {
+		if b[name] {
+			intersection++
+		}
+	}
+	union := len(a) + len(b) - intersection
+	if union == 0 {
+		return 0
+	}
+	return float64(intersection) / float64(union)
+}
+
+//similarRecipes selects the candidates with a similarity to the recipe of at least the threshold, most similar first.
+//Candidates without any common ingredient are never similar and the recipe itself is omitted.
+func similarRecipes(recipe *Recipe, candidates []*Recipe, threshold float64) []SimilarRecipe {
+	results := make([]SimilarRecipe, 0)
+
+	names := ingredientNames(recipe)
+	for _, candidate := range candidates {
+		if candidate.ID == recipe.ID {
+			continue
+		}
+		if score := jaccard(names, ingredientNames(candidate)); score > 0 && score >= threshold {
+			results = append(results, SimilarRecipe{ID: candidate.ID, Name: candidate.Name, Score: score})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Name < results[j].Name
+	})
+	return results
+}
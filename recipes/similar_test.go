@@ -0,0 +1,69 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("similar recipes", func() {
+
+	withIngredients := func(name string, ingredients ...string) *Recipe {
+		recipe := NewRecipe(NewRecipeID())
+		recipe.Name = name
+		for _, ingredient := range ingredients {
+			recipe.Ingredients = append(recipe.Ingredients, Ingredients{Name: ingredient})
+		}
+		return recipe
+	}
+
+	It("should compare ingredient names ignoring case and whitespace", func() {
+		Expect(ingredientNames(withIngredients("soup", " Olive  Oil", "olive oil", "", "Salt"))).To(Equal(map[string]bool{"olive oil": true, "salt": true}))
+	})
+
+	It("should compute the Jaccard similarity", func() {
+		Expect(jaccard(map[string]bool{"a": true, "b": true, "c": true}, map[string]bool{"b": true, "c": true, "d": true})).To(Equal(0.5))
+		Expect(jaccard(map[string]bool{}, map[string]bool{})).To(BeZero())
+	})
+
+	It("should list similar recipes with a score above the threshold, most similar first", func() {
+		soup := withIngredients("soup", "tomato", "onion", "salt", "pepper")
+		same := withIngredients("tomato soup", "Tomato", "Onion", "Salt", "Pepper")
+		stew := withIngredients("stew", "tomato", "onion", "salt", "beef")
+		far := withIngredients("salad", "tomato", "lettuce", "oil", "vinegar")
+
+		results := similarRecipes(soup, []*Recipe{soup, far, stew, same}, 0.5)
+		Expect(results).To(Equal([]SimilarRecipe{
+			{ID: same.ID, Name: same.Name, Score: 1},
+			{ID: stew.ID, Name: stew.Name, Score: 0.6},
+		}))
+	})
+
+	It("should never list recipes without common ingredients", func() {
+		soup := withIngredients("soup", "tomato")
+		Expect(similarRecipes(soup, []*Recipe{withIngredients("cake", "flour")}, 0)).To(BeEmpty())
+	})
+})
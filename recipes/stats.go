@@ -0,0 +1,161 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+//topIngredientsLimit is the number of most used ingredients in the statistics
+const topIngredientsLimit = 10
+
+//RecipeStats are aggregated numbers about all recipes, e.g., for a dashboard
+type RecipeStats struct {
+	//Total number of recipes
+	Total int64 `json:"total"`
+	//AverageServings of all recipes; 0 if there are no recipes
+	AverageServings float64 `json:"averageServings"`
+	//Tags and the number of recipes carrying them, the most used tag first; tags are compared ignoring the case
+	Tags []TagCount `json:"tags"`
+	//TopIngredients are the ingredients used by most recipes, the most used ingredient first
+	TopIngredients []IngredientCount `json:"topIngredients"`
+}
+
+//TagCount is the number of recipes carrying a tag
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int64  `json:"count"`
+}
+
+//IngredientCount is the number of recipes using an ingredient
+type IngredientCount struct {
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+//newRecipeStats returns empty statistics, i.e., the statistics of no recipes
+func newRecipeStats() *RecipeStats {
+	return &RecipeStats{
+		Tags:           make([]TagCount, 0),
+		TopIngredients: make([]IngredientCount, 0),
+	}
+}
+
+//computeStats aggregates the statistics of the recipes
+func computeStats(recipes []*Recipe) *RecipeStats {
+	stats := newRecipeStats()
+	stats.Total = int64(len(recipes))
+	if len(recipes) == 0 {
+		return stats
+	}
+
+	var servings int64
+	ingredients := make(map[string]int64)
+	for _, recipe := range recipes {
+		servings += int64(recipe.Servings)
+		for name := range ingredientNames(recipe) {
+			ingredients[name]++
+		}
+	}
+	stats.AverageServings = float64(servings) / float64(len(recipes))
+	stats.Tags = countTags(recipes)
+
+	for name, count := range ingredients {
+		stats.TopIngredients = append(stats.TopIngredients, IngredientCount{Name: name, Count: count})
+	}
+	sort.Slice(stats.TopIngredients, func(i, j int) bool {
+		if stats.TopIngredients[i].Count != stats.TopIngredients[j].Count {
+			return stats.TopIngredients[i].Count > stats.TopIngredients[j].Count
+		}
+		return stats.TopIngredients[i].Name < stats.TopIngredients[j].Name
+	})
+	if len(stats.TopIngredients) > topIngredientsLimit {
+		stats.TopIngredients = stats.TopIngredients[:topIngredientsLimit]
+	}
+
+	return stats
+}
+
+//countTags counts the recipes carrying each tag, the most used tag first. Tags are compared in lower case.
+func countTags(recipes []*Recipe) []TagCount {
+	counts := make(map[string]int64)
+	for _, recipe := range recipes {
+		seen := make(map[string]bool)
+		for _, tag := range recipe.Tags {
+			if tag = normalizeTag(tag); tag != "" && !seen[tag] {
+				seen[tag] = true
+				counts[tag]++
+			}
+		}
+	}
+
+	tags := make([]TagCount, 0, len(counts))
+	for tag, count := range counts {
+		tags = append(tags, TagCount{Tag: tag, Count: count})
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].Count != tags[j].Count {
+			return tags[i].Count > tags[j].Count
+		}
+		return tags[i].Tag < tags[j].Tag
+	})
+	return tags
+}
+
+//normalizeTag trims the tag and converts it to lower case
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+//statsCache keeps the statistics for a ttl, since computing them has to look at all recipes
+type statsCache struct {
+	mtx     sync.Mutex
+	ttl     time.Duration
+	stats   *RecipeStats
+	expires time.Time
+}
+
+func newStatsCache(ttl time.Duration) *statsCache {
+	return &statsCache{ttl: ttl}
+}
+
+//get the cached statistics or compute them if they expired; errors are not cached
+func (s *statsCache) get(compute func() (*RecipeStats, error)) (*RecipeStats, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if s.stats != nil && time.Now().Before(s.expires) {
+		return s.stats, nil
+	}
+	stats, err := compute()
+	if err != nil {
+		return nil, err
+	}
+	s.stats, s.expires = stats, time.Now().Add(s.ttl)
+	return stats, nil
+}
@@ -0,0 +1,111 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("stats", func() {
+
+	recipe := func(servings int8, tags []string, ingredients ...string) *Recipe {
+		r := NewRecipe(NewRecipeID())
+		r.Servings = servings
+		r.Tags = tags
+		for _, name := range ingredients {
+			r.Ingredients = append(r.Ingredients, Ingredients{Name: name})
+		}
+		return r
+	}
+
+	It("should aggregate the recipes", func() {
+		stats := computeStats([]*Recipe{
+			recipe(2, []string{"Vegan", "quick"}, "Flour", "Sugar", "flour"),
+			recipe(4, []string{"vegan", "Vegan "}, "Sugar", "Olive  Oil"),
+			recipe(3, nil, "olive oil", "Salt"),
+		})
+		Expect(stats.Total).To(Equal(int64(3)))
+		Expect(stats.AverageServings).To(Equal(3.0))
+		Expect(stats.Tags).To(Equal([]TagCount{{Tag: "vegan", Count: 2}, {Tag: "quick", Count: 1}}))
+		Expect(stats.TopIngredients).To(Equal([]IngredientCount{
+			{Name: "olive oil", Count: 2},
+			{Name: "sugar", Count: 2},
+			{Name: "flour", Count: 1},
+			{Name: "salt", Count: 1},
+		}))
+	})
+
+	It("should limit the most used ingredients", func() {
+		names := make([]string, 0)
+		for i := 0; i < 2*topIngredientsLimit; i++ {
+			names = append(names, string(rune('a'+i)))
+		}
+		Expect(computeStats([]*Recipe{recipe(1, nil, names...)}).TopIngredients).To(HaveLen(topIngredientsLimit))
+	})
+
+	It("should have empty lists without recipes", func() {
+		Expect(computeStats(nil)).To(Equal(newRecipeStats()))
+	})
+
+	Context("cache", func() {
+		It("should compute the statistics only once within the ttl", func() {
+			cache := newStatsCache(time.Minute)
+			calls := 0
+			compute := func() (*RecipeStats, error) {
+				calls++
+				return newRecipeStats(), nil
+			}
+			_, _ = cache.get(compute)
+			_, _ = cache.get(compute)
+			Expect(calls).To(Equal(1))
+		})
+
+		It("should compute the statistics again after the ttl", func() {
+			cache := newStatsCache(0)
+			calls := 0
+			compute := func() (*RecipeStats, error) {
+				calls++
+				return newRecipeStats(), nil
+			}
+			_, _ = cache.get(compute)
+			_, _ = cache.get(compute)
+			Expect(calls).To(Equal(2))
+		})
+
+		It("should not cache errors", func() {
+			cache := newStatsCache(time.Minute)
+			_, err := cache.get(func() (*RecipeStats, error) { return nil, errors.New("failed") })
+			Expect(err).To(HaveOccurred())
+
+			stats, err := cache.get(func() (*RecipeStats, error) { return newRecipeStats(), nil })
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stats).ToNot(BeNil())
+		})
+	})
+})
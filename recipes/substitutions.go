@@ -0,0 +1,114 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/ottenwbe/recipes-manager/utils"
+)
+
+const (
+	// substitutionsFileCfg is the configuration key for a JSON file with substitutes of ingredients
+	substitutionsFileCfg = "recipes.substitutions.file"
+)
+
+func init() {
+	utils.Config.SetDefault(substitutionsFileCfg, "")
+}
+
+//Substitutions maps the names of ingredients to ingredients which can be used instead, e.g., butter to margarine.
+//Names are matched case-insensitively.
+type Substitutions map[string][]string
+
+//IngredientSubstitutions lists the substitutes of one ingredient of a recipe
+type IngredientSubstitutions struct {
+	Ingredient  string   `json:"ingredient"`
+	Substitutes []string `json:"substitutes"`
+}
+
+//NewSubstitutions returns a lookup table for the given substitutes
+func NewSubstitutions(substitutes map[string][]string) Substitutions {
+	s := make(Substitutions, len(substitutes))
+	for name, alternatives := range substitutes {
+		key := substitutionKey(name)
+		s[key] = utils.UniqueSlice(append(s[key], alternatives...))
+	}
+	return s
+}
+
+//LoadSubstitutions reads substitutes from a JSON file, i.e., {"butter": ["margarine", "coconut oil"]}
+func LoadSubstitutions(path string) (Substitutions, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	var substitutes map[string][]string
+	if err = json.NewDecoder(file).Decode(&substitutes); err != nil {
+		return nil, err
+	}
+	return NewSubstitutions(substitutes), nil
+}
+
+//configuredSubstitutions loads the file configured by recipes.substitutions.file. Without a valid file there are no substitutes.
+func configuredSubstitutions() Substitutions {
+	path := utils.Config.GetString(substitutionsFileCfg)
+	if path == "" {
+		return NewSubstitutions(nil)
+	}
+	substitutions, err := LoadSubstitutions(path)
+	if err != nil {
+		log.WithError(err).WithField("file", path).Error("Could not load substitutions of ingredients")
+		return NewSubstitutions(nil)
+	}
+	return substitutions
+}
+
+//For returns the substitutes of an ingredient; the list is empty if no substitute is known
+func (s Substitutions) For(ingredient string) []string {
+	return append(make([]string, 0), s[substitutionKey(ingredient)]...)
+}
+
+//Suggest substitutes for each ingredient of the recipe in the order of the ingredients
+func (s Substitutions) Suggest(recipe *Recipe) []IngredientSubstitutions {
+	suggestions := make([]IngredientSubstitutions, 0, len(recipe.Ingredients))
+	for _, ingredient := range recipe.Ingredients {
+		suggestions = append(suggestions, IngredientSubstitutions{
+			Ingredient:  ingredient.Name,
+			Substitutes: s.For(ingredient.Name),
+		})
+	}
+	return suggestions
+}
+
+func substitutionKey(ingredient string) string {
+	return strings.ToLower(strings.TrimSpace(ingredient))
+}
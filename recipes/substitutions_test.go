@@ -0,0 +1,82 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("substitutions", func() {
+
+	It("matches ingredients case-insensitively", func() {
+		substitutions := NewSubstitutions(map[string][]string{"Butter ": {"Margarine"}})
+		Expect(substitutions.For("butter")).To(Equal([]string{"Margarine"}))
+	})
+
+	It("returns an empty list for unknown ingredients", func() {
+		substitutions := NewSubstitutions(nil)
+		Expect(substitutions.For("Salt")).ToNot(BeNil())
+		Expect(substitutions.For("Salt")).To(BeEmpty())
+	})
+
+	It("suggests substitutes for every ingredient of a recipe", func() {
+		substitutions := NewSubstitutions(map[string][]string{"butter": {"margarine", "coconut oil"}})
+		recipe := NewRecipe(NewRecipeID())
+		recipe.Ingredients = []Ingredients{{Name: "Butter"}, {Name: "Salt"}}
+
+		Expect(substitutions.Suggest(recipe)).To(Equal([]IngredientSubstitutions{
+			{Ingredient: "Butter", Substitutes: []string{"margarine", "coconut oil"}},
+			{Ingredient: "Salt", Substitutes: []string{}},
+		}))
+	})
+
+	It("loads substitutes from a JSON file", func() {
+		dir, err := ioutil.TempDir("", "substitutions")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(dir)
+		path := filepath.Join(dir, "substitutions.json")
+		Expect(ioutil.WriteFile(path, []byte(`{"Butter": ["margarine"]}`), 0600)).To(Succeed())
+
+		substitutions, err := LoadSubstitutions(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(substitutions.For("butter")).To(Equal([]string{"margarine"}))
+	})
+
+	It("rejects files which are not a JSON object", func() {
+		dir, err := ioutil.TempDir("", "substitutions")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(dir)
+		path := filepath.Join(dir, "substitutions.json")
+		Expect(ioutil.WriteFile(path, []byte(`["margarine"]`), 0600)).To(Succeed())
+
+		_, err = LoadSubstitutions(path)
+		Expect(err).To(HaveOccurred())
+	})
+})
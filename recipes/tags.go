@@ -0,0 +1,93 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+//BulkTagRequest adds tags to and removes tags from many recipes at once
+type BulkTagRequest struct {
+	IDs    []RecipeID `json:"ids"`
+	Add    []string   `json:"add"`
+	Remove []string   `json:"remove"`
+}
+
+//Validate ensures that there are valid ids, at most maxLimit of them, and at least one tag to add or to remove; tags must not be empty
+func (b *BulkTagRequest) Validate() error {
+	if len(b.IDs) == 0 {
+		return errors.New("at least one id is required")
+	}
+	if len(b.IDs) > maxLimit {
+		return fmt.Errorf("at most %v ids are allowed", maxLimit)
+	}
+	for _, id := range b.IDs {
+		if _, err := NewRecipeIDFromString(id.String()); err != nil {
+			return err
+		}
+	}
+	if len(b.Add) == 0 && len(b.Remove) == 0 {
+		return errors.New("at least one tag to add or to remove is required")
+	}
+	for _, tag := range append(append([]string{}, b.Add...), b.Remove...) {
+		if strings.TrimSpace(tag) == "" {
+			return errors.New("tags must not be empty")
+		}
+	}
+	return nil
+}
+
+//retag removes the tags to remove and then adds the tags to add, which the recipe does not carry yet. Tags are compared ignoring the case.
+//The result is true if the tags of the recipe changed.
+func (r *Recipe) retag(add []string, remove []string) bool {
+	removed := make(map[string]bool)
+	for _, tag := range remove {
+		removed[normalizeTag(tag)] = true
+	}
+
+	tags := make([]string, 0, len(r.Tags)+len(add))
+	carried := make(map[string]bool)
+	for _, tag := range r.Tags {
+		if !removed[normalizeTag(tag)] {
+			tags = append(tags, tag)
+			carried[normalizeTag(tag)] = true
+		}
+	}
+	changed := len(tags) != len(r.Tags)
+	for _, tag := range add {
+		if tag = strings.TrimSpace(tag); !carried[normalizeTag(tag)] {
+			tags = append(tags, tag)
+			carried[normalizeTag(tag)] = true
+			changed = true
+		}
+	}
+
+	if changed {
+		r.Tags = tags
+	}
+	return changed
+}
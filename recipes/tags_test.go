@@ -0,0 +1,61 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("tags", func() {
+
+	Context("bulk tag request", func() {
+		It("should accept ids with tags to add or to remove", func() {
+			Expect((&BulkTagRequest{IDs: []RecipeID{NewRecipeID()}, Add: []string{"quick"}}).Validate()).To(Succeed())
+			Expect((&BulkTagRequest{IDs: []RecipeID{NewRecipeID()}, Remove: []string{"old"}}).Validate()).To(Succeed())
+		})
+
+		It("should reject requests without ids or tags", func() {
+			Expect((&BulkTagRequest{Add: []string{"quick"}}).Validate()).ToNot(Succeed())
+			Expect((&BulkTagRequest{IDs: []RecipeID{NewRecipeID()}}).Validate()).ToNot(Succeed())
+			Expect((&BulkTagRequest{IDs: []RecipeID{NewRecipeID()}, Add: []string{" "}}).Validate()).ToNot(Succeed())
+			Expect((&BulkTagRequest{IDs: []RecipeID{"in valid"}, Add: []string{"quick"}}).Validate()).ToNot(Succeed())
+		})
+	})
+
+	Context("retag", func() {
+		It("should remove and add tags ignoring the case", func() {
+			recipe := Recipe{Tags: []string{"Old", "vegan"}}
+			Expect(recipe.retag([]string{" quick ", "Vegan"}, []string{"old"})).To(BeTrue())
+			Expect(recipe.Tags).To(Equal([]string{"vegan", "quick"}))
+		})
+
+		It("should report unchanged tags", func() {
+			recipe := Recipe{Tags: []string{"vegan"}}
+			Expect(recipe.retag([]string{"VEGAN"}, []string{"old"})).To(BeFalse())
+			Expect(recipe.Tags).To(Equal([]string{"vegan"}))
+		})
+	})
+})
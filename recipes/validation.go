@@ -0,0 +1,96 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ottenwbe/recipes-manager/core"
+	"github.com/ottenwbe/recipes-manager/units"
+)
+
+//RecipeValidation previews how a recipe would be saved
+type RecipeValidation struct {
+	//Valid is true if the recipe can be saved, i.e., there are no errors
+	Valid bool `json:"valid"`
+	//Recipe as it would be saved, e.g., with normalized units
+	Recipe *Recipe `json:"recipe"`
+	//Errors lists the invalid fields which prevent the recipe from being saved
+	Errors []core.FieldError `json:"errors"`
+	//Warnings lists fields which can be saved, but are likely not intended, e.g., unknown units
+	Warnings []core.FieldError `json:"warnings"`
+}
+
+//ValidateRecipe normalizes the units of the recipe and validates it, like it is done before a recipe is saved.
+//Besides the errors of Recipe.Validate, warnings about unknown units and duplicate ingredients are returned.
+func ValidateRecipe(recipe *Recipe) *RecipeValidation {
+	recipe.NormalizeUnits()
+
+	validation := &RecipeValidation{
+		Valid:    true,
+		Recipe:   recipe,
+		Errors:   make([]core.FieldError, 0),
+		Warnings: recipeWarnings(recipe),
+	}
+
+	if err := recipe.Validate(); err != nil {
+		validation.Valid = false
+		var validationErr *ValidationError
+		if errors.As(err, &validationErr) {
+			validation.Errors = validationErr.Fields
+		} else {
+			validation.Errors = append(validation.Errors, core.FieldError{Field: "", Message: err.Error()})
+		}
+	}
+	return validation
+}
+
+//recipeWarnings lists the fields of the recipe which are valid, but likely not intended
+func recipeWarnings(recipe *Recipe) []core.FieldError {
+	warnings := make([]core.FieldError, 0)
+	if len(recipe.Ingredients) == 0 {
+		warnings = append(warnings, core.FieldError{Field: "components", Message: "should not be empty"})
+	}
+
+	seen := make(map[string]bool)
+	for i, ingredient := range recipe.Ingredients {
+		if ingredient.Unit != "" && !units.Known(ingredient.Unit) {
+			warnings = append(warnings, core.FieldError{Field: fmt.Sprintf("components[%v].unit", i), Message: "is not a known unit and cannot be converted"})
+		}
+
+		key := strings.ToLower(strings.TrimSpace(ingredient.Group)) + "\x00" + normalizeIngredientName(ingredient.Name)
+		if ingredient.Name != "" && seen[key] {
+			warnings = append(warnings, core.FieldError{Field: fmt.Sprintf("components[%v].name", i), Message: "is listed more than once"})
+		}
+		seen[key] = true
+	}
+
+	if strings.TrimSpace(recipe.Description) == "" && len(recipe.Steps) == 0 {
+		warnings = append(warnings, core.FieldError{Field: "description", Message: "should not be empty if there are no steps"})
+	}
+	return warnings
+}
@@ -0,0 +1,99 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ottenwbe/recipes-manager/core"
+)
+
+var _ = Describe("validating recipes", func() {
+
+	var recipe *Recipe
+
+	BeforeEach(func() {
+		recipe = NewRecipe(NewRecipeID())
+		recipe.Name = "Pancakes"
+		recipe.Servings = 2
+		recipe.Description = "Mix and fry"
+		recipe.Ingredients = []Ingredients{
+			{Name: "Flour", Amount: 200, Unit: "Grams"},
+			{Name: "Milk", Amount: 1, Unit: "Cups"},
+		}
+	})
+
+	It("accepts valid recipes and normalizes their units", func() {
+		validation := ValidateRecipe(recipe)
+
+		Expect(validation.Valid).To(BeTrue())
+		Expect(validation.Errors).To(BeEmpty())
+		Expect(validation.Warnings).To(BeEmpty())
+		Expect(validation.Recipe.Ingredients[0].Unit).To(Equal("g"))
+		Expect(validation.Recipe.Ingredients[1].Unit).To(Equal("cup"))
+	})
+
+	It("lists the errors of invalid recipes", func() {
+		recipe.Name = " "
+		recipe.Servings = 0
+
+		validation := ValidateRecipe(recipe)
+
+		Expect(validation.Valid).To(BeFalse())
+		Expect(validation.Errors).To(ConsistOf(
+			core.FieldError{Field: "name", Message: "must not be empty"},
+			core.FieldError{Field: "servings", Message: "must be positive"},
+		))
+	})
+
+	It("warns about unknown units and duplicate ingredients", func() {
+		recipe.Ingredients = append(recipe.Ingredients,
+			Ingredients{Name: "flour ", Amount: 2, Unit: "handfuls"},
+			Ingredients{Name: "Flour", Amount: 50, Unit: "g", Group: "For the topping"},
+		)
+
+		validation := ValidateRecipe(recipe)
+
+		Expect(validation.Valid).To(BeTrue())
+		Expect(validation.Warnings).To(ConsistOf(
+			core.FieldError{Field: "components[2].unit", Message: "is not a known unit and cannot be converted"},
+			core.FieldError{Field: "components[2].name", Message: "is listed more than once"},
+		))
+	})
+
+	It("warns about recipes without ingredients or instructions", func() {
+		recipe.Ingredients = []Ingredients{}
+		recipe.Description = ""
+
+		validation := ValidateRecipe(recipe)
+
+		Expect(validation.Valid).To(BeTrue())
+		Expect(validation.Warnings).To(ConsistOf(
+			core.FieldError{Field: "components", Message: "should not be empty"},
+			core.FieldError{Field: "description", Message: "should not be empty if there are no steps"},
+		))
+	})
+})
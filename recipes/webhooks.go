@@ -0,0 +1,243 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/ottenwbe/recipes-manager/utils"
+)
+
+const (
+	// webhooksEndpointsCfg is the configuration key for a comma separated list of urls which are notified about changes of recipes
+	webhooksEndpointsCfg = "recipes.webhooks.endpoints"
+	// webhooksSecretCfg is the configuration key for the secret to sign the events with
+	webhooksSecretCfg = "recipes.webhooks.secret"
+	// webhooksRetriesCfg is the configuration key for the number of retries of failed deliveries
+	webhooksRetriesCfg = "recipes.webhooks.retries"
+	// webhooksBackoffCfg is the configuration key for the time to wait before the first retry; the time doubles with each retry
+	webhooksBackoffCfg = "recipes.webhooks.backoff"
+	// webhooksTimeoutCfg is the configuration key for the maximal duration of one delivery
+	webhooksTimeoutCfg = "recipes.webhooks.timeout"
+)
+
+const (
+	// webhookSignatureHeader carries the HMAC-SHA256 of the body, i.e., sha256=<hex>, if a secret is configured
+	webhookSignatureHeader = "X-Webhook-Signature"
+	// webhookEventHeader carries the type of the event
+	webhookEventHeader = "X-Webhook-Event"
+	// webhookQueueSize is the number of events buffered for each endpoint; further events are dropped
+	webhookQueueSize = 100
+)
+
+var (
+	webhookRetries int
+	webhookBackoff time.Duration
+	webhookTimeout time.Duration
+)
+
+func init() {
+	utils.Config.SetDefault(webhooksEndpointsCfg, "")
+	utils.Config.SetDefault(webhooksSecretCfg, "")
+	utils.Config.SetDefault(webhooksRetriesCfg, 3)
+	utils.Config.SetDefault(webhooksBackoffCfg, "1s")
+	utils.Config.SetDefault(webhooksTimeoutCfg, "10s")
+	webhookRetries = int(utils.Config.GetInt64(webhooksRetriesCfg))
+	webhookBackoff = utils.Config.GetDuration(webhooksBackoffCfg)
+	webhookTimeout = utils.Config.GetDuration(webhooksTimeoutCfg)
+}
+
+//Webhooks posts events to endpoints in the background. Each endpoint has its own queue,
+//so that a slow or failing endpoint does not delay the others. Failed deliveries are retried with an exponential backoff.
+type Webhooks struct {
+	endpoints []*webhookEndpoint
+	secret    []byte
+	client    *http.Client
+	retries   int
+	backoff   time.Duration
+	stop      chan struct{}
+	wg        sync.WaitGroup
+	//mtx guards closed, so that no event is queued after the queues are closed
+	mtx    sync.RWMutex
+	closed bool
+}
+
+//webhookEndpoint is the url and the queue of events of an endpoint
+type webhookEndpoint struct {
+	url    string
+	events chan RecipeEvent
+}
+
+//NewWebhooksFromConfig returns the Webhooks of the configured endpoints; nil if no endpoint is configured
+func NewWebhooksFromConfig() *Webhooks {
+	endpoints := make([]string, 0)
+	for _, endpoint := range strings.Split(utils.Config.GetString(webhooksEndpointsCfg), ",") {
+		if trimmed := strings.TrimSpace(endpoint); trimmed != "" {
+			endpoints = append(endpoints, trimmed)
+		}
+	}
+	if len(endpoints) == 0 {
+		return nil
+	}
+	return NewWebhooks(endpoints, utils.Config.GetString(webhooksSecretCfg))
+}
+
+//NewWebhooks starts to deliver events to the endpoints; the events are signed with the secret unless it is empty
+func NewWebhooks(endpoints []string, secret string) *Webhooks {
+	w := &Webhooks{
+		endpoints: make([]*webhookEndpoint, len(endpoints)),
+		secret:    []byte(secret),
+		client:    &http.Client{Timeout: webhookTimeout},
+		retries:   webhookRetries,
+		backoff:   webhookBackoff,
+		stop:      make(chan struct{}),
+	}
+	for i, url := range endpoints {
+		w.endpoints[i] = &webhookEndpoint{url: url, events: make(chan RecipeEvent, webhookQueueSize)}
+		w.wg.Add(1)
+		go w.deliverAll(w.endpoints[i])
+	}
+	log.WithField("endpoints", len(endpoints)).Info("Webhooks are notified about changes of recipes")
+	return w
+}
+
+//Notify all endpoints about a change of a recipe without waiting for the deliveries
+func (w *Webhooks) Notify(eventType string, id RecipeID) {
+	w.mtx.RLock()
+	defer w.mtx.RUnlock()
+	if w.closed {
+		return
+	}
+
+	event := RecipeEvent{Type: eventType, ID: id, At: changeTime()}
+	for _, endpoint := range w.endpoints {
+		select {
+		case endpoint.events <- event:
+		default:
+			log.WithField("url", endpoint.url).WithField("type", eventType).Warn("Webhook queue is full, event is dropped")
+		}
+	}
+}
+
+//Close stops the deliveries; events in flight are not retried and queued events are dropped
+func (w *Webhooks) Close() error {
+	w.mtx.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.stop)
+		for _, endpoint := range w.endpoints {
+			close(endpoint.events)
+		}
+	}
+	w.mtx.Unlock()
+
+	w.wg.Wait()
+	return nil
+}
+
+func (w *Webhooks) deliverAll(endpoint *webhookEndpoint) {
+	defer w.wg.Done()
+	for event := range endpoint.events {
+		select {
+		case <-w.stop:
+			log.WithField("url", endpoint.url).WithField("dropped", len(endpoint.events)+1).Warn("Webhook events are dropped, since webhooks are stopped")
+			return
+		default:
+			w.deliverWithRetries(endpoint.url, event)
+		}
+	}
+}
+
+//deliverWithRetries posts the event until the endpoint accepts it or the retries are exhausted
+func (w *Webhooks) deliverWithRetries(url string, event RecipeEvent) {
+	logger := log.WithField("url", url).WithField("type", event.Type).WithField("recipe", event.ID)
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		logger.WithError(err).Error("Could not encode webhook event")
+		return
+	}
+
+	backoff := w.backoff
+	for attempt := 0; ; attempt++ {
+		err = w.deliver(url, event.Type, body)
+		if err == nil {
+			return
+		}
+		if attempt >= w.retries {
+			logger.WithError(err).Error("Could not deliver webhook event, giving up")
+			return
+		}
+		logger.WithError(err).WithField("retryIn", backoff).Warn("Could not deliver webhook event")
+
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-w.stop:
+			logger.Warn("Webhook event is not retried, since webhooks are stopped")
+			return
+		}
+	}
+}
+
+func (w *Webhooks) deliver(url string, eventType string, body []byte) error {
+	request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(webhookEventHeader, eventType)
+	if len(w.secret) > 0 {
+		request.Header.Set(webhookSignatureHeader, webhookSignature(w.secret, body))
+	}
+
+	response, err := w.client.Do(request)
+	if err != nil {
+		return err
+	}
+	_ = response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("endpoint responded with %v", response.Status)
+	}
+	return nil
+}
+
+//webhookSignature of a body, i.e., sha256=<hex encoded HMAC-SHA256 of the body>
+func webhookSignature(secret []byte, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	_, _ = mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,205 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package recipes
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("webhooks", func() {
+
+	type received struct {
+		event     RecipeEvent
+		header    string
+		signature string
+		body      []byte
+	}
+
+	var (
+		server        *httptest.Server
+		events        chan received
+		failures      int32
+		backoff       time.Duration
+		webhooks      *Webhooks
+		respondStatus func() int
+	)
+
+	BeforeEach(func() {
+		events = make(chan received, 10)
+		failures = 0
+		respondStatus = func() int { return http.StatusOK }
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+			status := respondStatus()
+			if status != http.StatusOK {
+				atomic.AddInt32(&failures, 1)
+				w.WriteHeader(status)
+				return
+			}
+			var event RecipeEvent
+			_ = json.Unmarshal(body, &event)
+			select {
+			case events <- received{event: event, header: r.Header.Get(webhookEventHeader), signature: r.Header.Get(webhookSignatureHeader), body: body}:
+			default:
+			}
+		}))
+
+		backoff = webhookBackoff
+		webhookBackoff = 10 * time.Millisecond
+	})
+
+	AfterEach(func() {
+		if webhooks != nil {
+			_ = webhooks.Close()
+			webhooks = nil
+		}
+		server.Close()
+		webhookBackoff = backoff
+	})
+
+	It("is disabled without endpoints", func() {
+		Expect(NewWebhooksFromConfig()).To(BeNil())
+	})
+
+	It("posts signed events", func() {
+		webhooks = NewWebhooks([]string{server.URL}, "secret")
+		id := NewRecipeID()
+
+		webhooks.Notify(RecipeCreatedEvent, id)
+
+		var r received
+		Eventually(events).Should(Receive(&r))
+		Expect(r.event.Type).To(Equal(RecipeCreatedEvent))
+		Expect(r.event.ID).To(Equal(id))
+		Expect(r.event.At.IsZero()).To(BeFalse())
+		Expect(r.header).To(Equal(RecipeCreatedEvent))
+		Expect(r.signature).To(Equal(webhookSignature([]byte("secret"), r.body)))
+	})
+
+	It("does not sign events without a secret", func() {
+		webhooks = NewWebhooks([]string{server.URL}, "")
+
+		webhooks.Notify(RecipeUpdatedEvent, NewRecipeID())
+
+		var r received
+		Eventually(events).Should(Receive(&r))
+		Expect(r.signature).To(BeEmpty())
+	})
+
+	It("retries failed deliveries", func() {
+		var calls int32
+		respondStatus = func() int {
+			if atomic.AddInt32(&calls, 1) <= 2 {
+				return http.StatusInternalServerError
+			}
+			return http.StatusOK
+		}
+		webhooks = NewWebhooks([]string{server.URL}, "")
+
+		webhooks.Notify(RecipeDeletedEvent, NewRecipeID())
+
+		var r received
+		Eventually(events).Should(Receive(&r))
+		Expect(r.event.Type).To(Equal(RecipeDeletedEvent))
+		Expect(atomic.LoadInt32(&failures)).To(Equal(int32(2)))
+	})
+
+	It("does not block when the endpoint is slow", func() {
+		release := make(chan struct{})
+		respondStatus = func() int {
+			<-release
+			return http.StatusOK
+		}
+		webhooks = NewWebhooks([]string{server.URL}, "")
+
+		done := make(chan struct{})
+		go func() {
+			for i := 0; i < 2*webhookQueueSize; i++ {
+				webhooks.Notify(RecipeUpdatedEvent, NewRecipeID())
+			}
+			close(done)
+		}()
+
+		Eventually(done).Should(BeClosed())
+		close(release)
+	})
+
+	It("signs bodies with HMAC-SHA256", func() {
+		Expect(webhookSignature([]byte("key"), []byte("The quick brown fox jumps over the lazy dog"))).
+			To(Equal("sha256=f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8"))
+	})
+
+	Context("db", func() {
+
+		var (
+			db     *EventDB
+			recipe *Recipe
+		)
+
+		BeforeEach(func() {
+			webhooks = NewWebhooks([]string{server.URL}, "")
+			db = NewEventDB(NewInMemoryDB(), webhooks)
+			recipe = NewRecipe(NewRecipeID())
+			recipe.Name = "Soup"
+		})
+
+		expectEvent := func(eventType string, id RecipeID) {
+			var r received
+			Eventually(events).Should(Receive(&r))
+			Expect(r.event.Type).To(Equal(eventType))
+			Expect(r.event.ID).To(Equal(id))
+		}
+
+		It("notifies about created, updated, archived, restored, and removed recipes", func() {
+			Expect(db.Insert(recipe)).To(Succeed())
+			expectEvent(RecipeCreatedEvent, recipe.ID)
+
+			Expect(db.Update(recipe.ID, recipe)).To(Succeed())
+			expectEvent(RecipeUpdatedEvent, recipe.ID)
+
+			Expect(db.Archive(recipe.ID)).To(Succeed())
+			expectEvent(RecipeDeletedEvent, recipe.ID)
+
+			Expect(db.Restore(recipe.ID)).To(Succeed())
+			expectEvent(RecipeCreatedEvent, recipe.ID)
+
+			Expect(db.RemoveByName(recipe.Name)).To(Succeed())
+			expectEvent(RecipeDeletedEvent, recipe.ID)
+		})
+
+		It("does not notify about failed changes", func() {
+			Expect(db.Archive(NewRecipeID())).ToNot(Succeed())
+			Consistently(events, 50*time.Millisecond).ShouldNot(Receive())
+		})
+	})
+})
@@ -26,7 +26,6 @@ package sources
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 
@@ -38,7 +37,6 @@ import (
 	"io"
 	"io/ioutil"
 	"math/rand"
-	"os"
 	"sync"
 
 	"github.com/ottenwbe/recipes-manager/recipes"
@@ -203,6 +201,8 @@ var (
 type DriveClient struct {
 	driveRecipes recipes.Recipes
 	oAuthConfig  *oauth2.Config
+	tokens       TokenStore
+	tokenSource  oauth2.TokenSource
 }
 
 //ID of this SourceClient
@@ -234,20 +234,26 @@ func (c *DriveClient) Recipes() recipes.Recipes {
 //Refresh cleans the internal cache of recipes and refreshes the token from file
 func (c *DriveClient) Refresh() (err error) {
 	c.driveRecipes = nil
+	c.tokenSource = nil
 	c.oAuthConfig, err = c.OAuthLoginConfig()
 	if err != nil {
 		return
 	}
-	tok, tokenErr := c.tokenFromFile()
+	tok, tokenErr := c.tokens.Token(c.ID())
 	if tokenErr == nil {
 		err = c.configureDriveConnection(tok)
 	}
 	return
 }
 
-//OpenNewGoogleDriveConnection with an empty cache of recipes
+//OpenNewGoogleDriveConnection with an empty cache of recipes and the default token store
 func OpenNewGoogleDriveConnection() *DriveClient {
-	c := &DriveClient{}
+	return NewGoogleDriveConnection(DefaultTokenStore())
+}
+
+//NewGoogleDriveConnection with an empty cache of recipes, which persists its tokens in the given store
+func NewGoogleDriveConnection(tokens TokenStore) *DriveClient {
+	c := &DriveClient{tokens: tokens}
 	c.Refresh()
 	return c
 }
@@ -272,16 +278,31 @@ func (c *DriveClient) getToken(code string) (*oauth2.Token, error) {
 	if err != nil {
 		return nil, err
 	}
-	err = c.saveToken(tok)
+	err = c.tokens.SaveToken(c.ID(), tok)
 	return tok, err
 }
 
+//RefreshToken renews an expired access token. The connection is dropped if the refresh is rejected.
+func (c *DriveClient) RefreshToken() error {
+	if c.tokenSource == nil {
+		return errors.New("drive is not connected")
+	}
+	if _, err := c.tokenSource.Token(); err != nil {
+		log.WithError(err).WithField("source", c.Name()).Warn("Could not refresh token, disconnecting source")
+		c.driveRecipes = nil
+		c.tokenSource = nil
+		return err
+	}
+	return nil
+}
+
 func (c *DriveClient) configureDriveConnection(token *oauth2.Token) (err error) {
-	client := c.oAuthConfig.Client(context.Background(), token)
-	service, err := drive.New(client)
+	tokenSource := newPersistingTokenSource(c.ID(), c.tokens, c.oAuthConfig, token)
+	service, err := drive.New(oauth2.NewClient(context.Background(), tokenSource))
 	if err != nil {
 		return err
 	}
+	c.tokenSource = tokenSource
 	c.driveRecipes = newDriveRecipes(service)
 	return nil
 }
@@ -304,37 +325,6 @@ func (c *DriveClient) OAuthLoginConfig() (*oauth2.Config, error) {
 	return c.oAuthLoginConfig()
 }
 
-// tokenFromFile retrieves a Token from a given file path.
-// It returns the retrieved Token and any read error encountered.
-func (c *DriveClient) tokenFromFile() (*oauth2.Token, error) {
-	file := c.tokenFile()
-	f, err := os.Open(file)
-	if err != nil {
-		return nil, err
-	}
-	tok := &oauth2.Token{}
-	err = json.NewDecoder(f).Decode(tok)
-	defer f.Close()
-	return tok, err
-}
-
-// saveToken uses a file path to create a file and store the
-// token in it.
-func (c *DriveClient) saveToken(token *oauth2.Token) error {
-	file := c.tokenFile()
-	log.Infof("Saving credential file to: %s\n", file)
-	f, err := os.OpenFile(file, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
-	if err != nil {
-		return err
-	}
-	defer func() { _ = f.Close() }()
-	return json.NewEncoder(f).Encode(token)
-}
-
-func (c *DriveClient) tokenFile() string {
-	return "token-" + c.ID().String() + ".json"
-}
-
 func getRecipesList(srv *drive.Service) *drive.FileList {
 	r, err := srv.Files.List().PageSize(10).OrderBy("folder").Do()
 	if err != nil {
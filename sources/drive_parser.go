@@ -189,6 +189,7 @@ func (p *driveRecipeParser) handleText(text string) {
 
 var (
 	validNumber  = regexp.MustCompile(`^[0-9]+`)
+	validRange   = regexp.MustCompile(`^[0-9]+\s*[-–]\s*([0-9]+)`)
 	validStrings = regexp.MustCompile(`[a-zA-zßäüöÄÜÖ]+`)
 )
 
@@ -206,6 +207,14 @@ func handleIngredient(p *driveRecipeParser, text string) {
 		}
 	}
 
+	var amountMax = 0.0
+	if bounds := validRange.FindStringSubmatch(text); bounds != nil {
+		amountMax, err = strconv.ParseFloat(bounds[1], 32)
+		if err != nil || amountMax <= amount {
+			amountMax = 0
+		}
+	}
+
 	var unit = ""
 	if len(strs) > 1 {
 		unit = strs[0]
@@ -218,7 +227,7 @@ func handleIngredient(p *driveRecipeParser, text string) {
 		name = strings.Join(strs[1:], " ")
 	}
 
-	p.recipe.Ingredients = append(p.recipe.Ingredients, recipes.Ingredients{Name: name, Amount: amount, Unit: unit})
+	p.recipe.Ingredients = append(p.recipe.Ingredients, recipes.Ingredients{Name: name, Amount: amount, AmountMax: amountMax, Unit: unit})
 }
 
 func (p *driveRecipeParser) finalizeRecipe() {
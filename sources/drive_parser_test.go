@@ -54,6 +54,7 @@ var _ = Describe("drive parser", func() {
 			Description: "\nDo sth.\n\nAnd sth else.\n\nAnd some salad.",
 			PictureLink: []string{"IMG_20141227_132212.jpg"},
 			Servings:    1,
+			Tags:        []string{},
 		}
 	)
 
@@ -76,6 +77,16 @@ var _ = Describe("drive parser", func() {
 		//Expect(pictures).To(Equal(expectedPicture))
 	})
 
+	It("parses ranges of amounts", func() {
+		parser := newDriveRecipeParser(expectedID)
+		handleIngredient(parser, "2-3 Zehen Knoblauch")
+		handleIngredient(parser, "2 Zehen Knoblauch")
+		Expect(parser.recipe.Ingredients).To(Equal([]recipes.Ingredients{
+			{Name: "Knoblauch", Amount: 2, AmountMax: 3, Unit: "Zehen"},
+			{Name: "Knoblauch", Amount: 2, Unit: "Zehen"},
+		}))
+	})
+
 	It("returns an error when no valid recipe is present in html", func() {
 		_, _, err := ParseRecipe(strings.NewReader("<html>"), expectedID)
 		Expect(err).ToNot(BeNil())
@@ -0,0 +1,293 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package sources
+
+import (
+	"archive/zip"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/satori/go.uuid"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+
+	"github.com/ottenwbe/recipes-manager/recipes"
+	"github.com/ottenwbe/recipes-manager/utils"
+)
+
+const (
+	paprikaFileCfg = "paprika.file"
+	//paprikaSourceID is the id of the source and the namespace of the ids of imported recipes
+	paprikaSourceID = "2b0b4a8e-5f0c-4d0c-9c1e-7a4f3d6c8e21"
+	//maxPaprikaEntryBytes limits the size of an unpacked recipe of an archive, including its photos
+	maxPaprikaEntryBytes = 50 << 20
+	//defaultPaprikaPhotoName is used for photos without a file name
+	defaultPaprikaPhotoName = "photo.jpg"
+)
+
+var (
+	paprikaFile string
+)
+
+//paprikaRecipe is an entry of a Paprika export
+type paprikaRecipe struct {
+	UID         string         `json:"uid"`
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Ingredients string         `json:"ingredients"`
+	Directions  string         `json:"directions"`
+	Servings    string         `json:"servings"`
+	Categories  []string       `json:"categories"`
+	Rating      int            `json:"rating"`
+	Photo       string         `json:"photo"`
+	PhotoData   string         `json:"photo_data"`
+	Photos      []paprikaPhoto `json:"photos"`
+}
+
+//paprikaPhoto is an additional photo of a recipe in a Paprika export
+type paprikaPhoto struct {
+	Name     string `json:"name"`
+	Filename string `json:"filename"`
+	Data     string `json:"data"`
+}
+
+//PaprikaSource imports the recipes of a Paprika export, i.e., a .paprikarecipes archive
+type PaprikaSource struct {
+	file    string
+	recipes *recipes.InMemoryDB
+	mtx     sync.Mutex
+}
+
+//OpenPaprikaArchive returns a source for the archive that is configured by paprika.file; the archive is read on demand
+func OpenPaprikaArchive() *PaprikaSource {
+	return NewPaprikaSource(paprikaFile)
+}
+
+//NewPaprikaSource returns a source for the archive at path; the archive is read on demand
+func NewPaprikaSource(path string) *PaprikaSource {
+	return &PaprikaSource{file: path}
+}
+
+//ID of this SourceClient
+func (p *PaprikaSource) ID() SourceID {
+	id, err := uuid.FromString(paprikaSourceID)
+
+	if err != nil {
+		return SourceID(uuid.Nil)
+	}
+
+	return SourceID(id)
+}
+
+//Name of this SourceClient
+func (p *PaprikaSource) Name() string {
+	return "Paprika SourceClient"
+}
+
+//Version of this SourceClient
+func (p *PaprikaSource) Version() string {
+	return "0.1.0"
+}
+
+//Connected returns true if the archive exists
+func (p *PaprikaSource) Connected() bool {
+	_, err := os.Stat(p.file)
+	return err == nil
+}
+
+//ConnectOAuth is not supported, since the archive is a local file
+func (p *PaprikaSource) ConnectOAuth(code string) error {
+	return errors.New(recipes.NotSupportedError)
+}
+
+//OAuthLoginConfig is not supported, since the archive is a local file
+func (p *PaprikaSource) OAuthLoginConfig() (*oauth2.Config, error) {
+	return nil, errors.New(recipes.NotSupportedError)
+}
+
+//Recipes of the archive. The archive is read once; entries which cannot be read are skipped.
+func (p *PaprikaSource) Recipes() recipes.Recipes {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if p.recipes != nil {
+		return p.recipes
+	}
+
+	db := recipes.NewInMemoryDB()
+	recipeList, pictures, err := ReadPaprikaArchive(p.file)
+	if err != nil {
+		log.WithError(err).WithField("file", p.file).Error("Could not read Paprika archive")
+		return db
+	}
+	for _, recipe := range recipeList {
+		if err = db.Insert(recipe); err != nil {
+			log.WithError(err).WithField("recipe", recipe.Name).Warn("Skipping Paprika recipe")
+			continue
+		}
+		for _, pic := range pictures[recipe.ID] {
+			_ = db.AddPicture(pic)
+		}
+	}
+
+	p.recipes = db
+	return p.recipes
+}
+
+//Refresh drops the recipes that have been read, so that the archive is read again, e.g., after a new export
+func (p *PaprikaSource) Refresh() {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.recipes = nil
+}
+
+//ReadPaprikaArchive reads all recipes and their photos of a Paprika export, i.e., a zip archive of gzipped JSON recipes.
+//Recipes keep their ids across exports, since the ids are derived from Paprika's ids.
+//Entries which cannot be read or which are no valid recipes are skipped with a warning.
+func ReadPaprikaArchive(path string) ([]*recipes.Recipe, map[recipes.RecipeID]map[string]*recipes.RecipePicture, error) {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = archive.Close() }()
+
+	resultRecipes := make([]*recipes.Recipe, 0)
+	resultPictures := make(map[recipes.RecipeID]map[string]*recipes.RecipePicture)
+	for _, file := range archive.File {
+		entry, err := readPaprikaEntry(file)
+		if err != nil {
+			log.WithError(err).WithField("entry", file.Name).Warn("Skipping malformed Paprika recipe")
+			continue
+		}
+
+		recipe, pictures := entry.toRecipe()
+		if err = recipe.Validate(); err != nil {
+			log.WithError(err).WithField("entry", file.Name).Warn("Skipping invalid Paprika recipe")
+			continue
+		}
+		resultRecipes = append(resultRecipes, recipe)
+		resultPictures = appendPictures(pictures, resultPictures, recipe.ID)
+	}
+
+	return resultRecipes, resultPictures, nil
+}
+
+func readPaprikaEntry(file *zip.File) (*paprikaRecipe, error) {
+	compressed, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = compressed.Close() }()
+
+	content, err := gzip.NewReader(compressed)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = content.Close() }()
+
+	entry := &paprikaRecipe{}
+	err = json.NewDecoder(io.LimitReader(content, maxPaprikaEntryBytes)).Decode(entry)
+	return entry, err
+}
+
+//toRecipe converts the entry and decodes its photos; photos which cannot be decoded are skipped
+func (e *paprikaRecipe) toRecipe() (*recipes.Recipe, map[string]*recipes.RecipePicture) {
+	id := recipes.NewRecipeID()
+	if e.UID != "" {
+		id = recipes.RecipeID(uuid.NewV5(uuid.FromStringOrNil(paprikaSourceID), e.UID).String())
+	}
+
+	recipe := recipes.NewRecipe(id)
+	recipe.Name = strings.TrimSpace(e.Name)
+	for _, line := range strings.Split(e.Ingredients, "\n") {
+		if strings.TrimSpace(line) != "" {
+			recipe.Ingredients = append(recipe.Ingredients, parseIngredientLine(line))
+		}
+	}
+	recipe.OriginalUnitSystem = originalUnitSystem(recipe.Ingredients)
+	recipe.Description = strings.TrimSpace(strings.Join([]string{strings.TrimSpace(e.Description), strings.TrimSpace(e.Directions)}, "\n\n"))
+	if servings := parseServings([]string{e.Servings}); servings > 0 {
+		recipe.Servings = servings
+	}
+	recipe.Tags = append(recipe.Tags, e.Categories...)
+	if e.Rating > 0 {
+		recipe.Rating = float32(e.Rating)
+		recipe.RatingCount = 1
+	}
+
+	pictures := make(map[string]*recipes.RecipePicture)
+	addPhoto := func(name string, data string) {
+		if data == "" {
+			return
+		}
+		if name == "" {
+			name = defaultPaprikaPhotoName
+		}
+		picture, err := decodePaprikaPhoto(data)
+		if err != nil {
+			log.WithError(err).WithField("recipe", recipe.Name).Warn("Skipping malformed Paprika photo")
+			return
+		}
+		recipe.PictureLink = utils.UniqueSlice(append(recipe.PictureLink, name))
+		pictures[name] = &recipes.RecipePicture{ID: id, Name: name, Picture: picture}
+	}
+	addPhoto(e.Photo, e.PhotoData)
+	for _, photo := range e.Photos {
+		name := photo.Filename
+		if name == "" {
+			name = photo.Name
+		}
+		addPhoto(name, photo.Data)
+	}
+
+	return recipe, pictures
+}
+
+//decodePaprikaPhoto converts a base64 encoded photo to a data url with the photo's content type
+func decodePaprikaPhoto(data string) (string, error) {
+	img, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return "", err
+	}
+	contentType := http.DetectContentType(img)
+	if !strings.HasPrefix(contentType, "image/") {
+		return "", fmt.Errorf("photo is no image but %v", contentType)
+	}
+	return utils.IMGToBase64(contentType, img), nil
+}
+
+func init() {
+	utils.Config.SetDefault(paprikaFileCfg, "")
+
+	paprikaFile = utils.Config.GetString(paprikaFileCfg)
+}
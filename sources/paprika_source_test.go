@@ -0,0 +1,153 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package sources
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ottenwbe/recipes-manager/core"
+	"github.com/ottenwbe/recipes-manager/recipes"
+)
+
+var _ = Describe("paprika source", func() {
+
+	var (
+		dir     string
+		archive string
+	)
+
+	gzipped := func(content []byte) []byte {
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		_, _ = w.Write(content)
+		_ = w.Close()
+		return buf.Bytes()
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "paprika")
+		Expect(err).ToNot(HaveOccurred())
+		archive = filepath.Join(dir, "export.paprikarecipes")
+
+		soup, _ := json.Marshal(paprikaRecipe{
+			UID:         "5A3F-SOUP",
+			Name:        "Tomato Soup",
+			Description: "A quick soup",
+			Ingredients: "800g tomatoes\n\n1 1/2 cups water\nsalt",
+			Directions:  "Cook everything.",
+			Servings:    "4 servings",
+			Categories:  []string{"Soups"},
+			Rating:      5,
+			Photo:       "soup.png",
+			PhotoData:   base64.StdEncoding.EncodeToString([]byte("\x89PNG\x0D\x0A\x1A\x0A")),
+			Photos:      []paprikaPhoto{{Filename: "broken.jpg", Data: "not base64!"}},
+		})
+		unnamed, _ := json.Marshal(paprikaRecipe{UID: "5A3F-UNNAMED", Ingredients: "salt"})
+
+		f, err := os.Create(archive)
+		Expect(err).ToNot(HaveOccurred())
+		w := zip.NewWriter(f)
+		for name, content := range map[string][]byte{
+			"Tomato Soup.paprikarecipe": gzipped(soup),
+			"Not Gzipped.paprikarecipe": soup,
+			"No JSON.paprikarecipe":     gzipped([]byte("{")),
+			"Unnamed.paprikarecipe":     gzipped(unnamed),
+		} {
+			entry, _ := w.Create(name)
+			_, _ = entry.Write(content)
+		}
+		Expect(w.Close()).To(Succeed())
+		Expect(f.Close()).To(Succeed())
+	})
+
+	AfterEach(func() {
+		_ = os.RemoveAll(dir)
+	})
+
+	It("reads the recipes and photos of an archive and skips malformed entries", func() {
+		recipeList, pictures, err := ReadPaprikaArchive(archive)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(recipeList).To(HaveLen(1))
+		recipe := recipeList[0]
+		Expect(recipe.Name).To(Equal("Tomato Soup"))
+		Expect(recipe.Description).To(Equal("A quick soup\n\nCook everything."))
+		Expect(recipe.Servings).To(Equal(int8(4)))
+		Expect(recipe.Tags).To(Equal([]string{"Soups"}))
+		Expect(recipe.Rating).To(Equal(float32(5)))
+		Expect(recipe.Ingredients).To(Equal([]recipes.Ingredients{
+			{Name: "tomatoes", Amount: 800, Unit: "g"},
+			{Name: "water", Amount: 1.5, Unit: "cup"},
+			{Name: "salt", Amount: recipes.NoAmountIngredient},
+		}))
+		Expect(recipe.PictureLink).To(Equal([]string{"soup.png"}))
+		Expect(pictures[recipe.ID]).To(HaveLen(1))
+		Expect(pictures[recipe.ID]["soup.png"].Picture).To(HavePrefix("data:image/png;base64,"))
+	})
+
+	It("keeps the ids of recipes across exports", func() {
+		first, _, _ := ReadPaprikaArchive(archive)
+		second, _, _ := ReadPaprikaArchive(archive)
+		Expect(first[0].ID).To(Equal(second[0].ID))
+	})
+
+	It("fails for files which are no archive", func() {
+		_, _, err := ReadPaprikaArchive(filepath.Join(dir, "missing.paprikarecipes"))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("can be synchronized with the recipes db", func() {
+		source := NewPaprikaSource(archive)
+		Expect(source.Connected()).To(BeTrue())
+
+		sources := NewSources()
+		Expect(sources.Add(NewSourceDescription(source.ID(), source.Name(), source.Version(), nil), source)).To(Succeed())
+		recipeDB := recipes.NewInMemoryDB()
+		handler := core.NewHandler()
+		NewSourceAPI(sources, recipeDB).PrepareAPI(handler, sources, recipeDB)
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPatch, "/api/v1/sources/"+source.ID().String()+"/recipes", nil))
+
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(recipeDB.Num()).To(Equal(int64(1)))
+		recipe, err := recipeDB.GetByName("Tomato Soup")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(recipeDB.Picture(recipe.ID, "soup.png").ID).To(Equal(recipe.ID))
+	})
+})
@@ -53,6 +53,18 @@ type SourceClient interface {
 	OAuthLoginConfig() (*oauth2.Config, error)
 }
 
+//TokenRefresher is implemented by SourceClients which renew expired OAuth tokens before recipes are downloaded
+type TokenRefresher interface {
+	RefreshToken() error
+}
+
+const (
+	//CapabilityOAuth marks sources which have to be connected with the OAuth flow before recipes can be downloaded
+	CapabilityOAuth = "oauth"
+	//CapabilityDownload marks sources whose recipes can be downloaded to the recipes db
+	CapabilityDownload = "download"
+)
+
 //SourceDescription describes the sourceClient in detail
 type SourceDescription struct {
 	ID          SourceID       `json:"id"`
@@ -60,27 +72,36 @@ type SourceDescription struct {
 	Connected   bool           `json:"connected"`
 	Version     string         `json:"version"`
 	OAuthConfig *oauth2.Config `json:"-"`
+	//Capabilities of the source, e.g., CapabilityOAuth
+	Capabilities []string `json:"capabilities"`
 }
 
-//NewSourceDescription is the designated way to create a SourceDescription
+//NewSourceDescription is the designated way to create a SourceDescription.
+//Sources with an oauthConfig have the capability CapabilityOAuth, all sources have the capability CapabilityDownload.
 func NewSourceDescription(id SourceID, name string, version string, oauthConfig *oauth2.Config) *SourceDescription {
+	capabilities := []string{CapabilityDownload}
+	if oauthConfig != nil {
+		capabilities = append([]string{CapabilityOAuth}, capabilities...)
+	}
 	return &SourceDescription{
-		ID:          id,
-		Name:        name,
-		Connected:   true,
-		Version:     version,
-		OAuthConfig: oauthConfig,
+		ID:           id,
+		Name:         name,
+		Connected:    true,
+		Version:      version,
+		OAuthConfig:  oauthConfig,
+		Capabilities: capabilities,
 	}
 }
 
 //NewInvalidSourceDescription returns a SourceDescription with all fields set to invalid values
 func NewInvalidSourceDescription() *SourceDescription {
 	return &SourceDescription{
-		ID:          SourceID(uuid.Nil),
-		Name:        "invalid",
-		Connected:   true,
-		Version:     "0.0.0",
-		OAuthConfig: nil,
+		ID:           SourceID(uuid.Nil),
+		Name:         "invalid",
+		Connected:    true,
+		Version:      "0.0.0",
+		OAuthConfig:  nil,
+		Capabilities: make([]string, 0),
 	}
 }
 
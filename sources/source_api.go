@@ -25,6 +25,8 @@
 package sources
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"net/url"
 
@@ -42,6 +44,8 @@ type SourceResponse struct {
 	Name      string `json:"name"`
 	Connected bool   `json:"connected"`
 	Version   string `json:"version"`
+	//Capabilities tell clients how to use the source, e.g., 'oauth' sources have to be connected before recipes can be downloaded
+	Capabilities []string `json:"capabilities"`
 }
 
 // SourceOAuthConnectResponse informs about the oAuth url
@@ -50,12 +54,18 @@ type SourceOAuthConnectResponse struct {
 	OAuthURL string `json:"oAuthURL"`
 }
 
+// WebImportRequest names the web page of a recipe to import
+type WebImportRequest struct {
+	URL string `json:"url"`
+}
+
 func newSourceResponse(sourceDescription *SourceDescription) *SourceResponse {
 	return &SourceResponse{
-		ID:        sourceDescription.ID.String(),
-		Name:      sourceDescription.Name,
-		Connected: sourceDescription.Connected,
-		Version:   sourceDescription.Version,
+		ID:           sourceDescription.ID.String(),
+		Name:         sourceDescription.Name,
+		Connected:    sourceDescription.Connected,
+		Version:      sourceDescription.Version,
+		Capabilities: append(make([]string, 0), sourceDescription.Capabilities...),
 	}
 }
 
@@ -63,11 +73,12 @@ func newSourceResponse(sourceDescription *SourceDescription) *SourceResponse {
 type API struct {
 	sources Sources
 	recipes recipes.RecipeDB
+	web     *WebScraperSource
 }
 
 //NewSourceAPI creates the API for sources
 func NewSourceAPI(sources Sources, recipes recipes.RecipeDB) API {
-	return API{sources, recipes}
+	return API{sources, recipes, NewWebScraperSource()}
 }
 
 // PrepareAPI registers all api endpoints
@@ -89,7 +100,10 @@ func (s API) prepareV1API(router core.Handler, sources Sources, recipes recipes.
 	v1.GET("/sources", listSources(sources))
 
 	// sync recipes from sourceClient with local Recipe DB
-	v1.PATCH("/sources/:source/recipes", synchronizeSourceRecipes(sources, recipes))
+	v1.Secured().PATCH("/sources/:source/recipes", synchronizeSourceRecipes(sources, recipes))
+
+	// import a recipe from a web page
+	v1.Secured().POST("/sources/web/import", importWebRecipe(s.web, recipes, v1.Path()))
 }
 
 // oAuthHandler example
@@ -148,13 +162,13 @@ func oAuthConnect(sources Sources) func(c *core.APICallContext) {
 
 		src, err := sourceClient(sourceID, sources)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, err.Error())
+			core.RespondJSON(c, http.StatusBadRequest, err.Error())
 			return
 		}
 
 		config, err := src.OAuthLoginConfig()
 		if err != nil {
-			c.JSON(http.StatusBadRequest, err.Error())
+			core.RespondJSON(c, http.StatusBadRequest, err.Error())
 			return
 		}
 
@@ -163,7 +177,7 @@ func oAuthConnect(sources Sources) func(c *core.APICallContext) {
 			OAuthURL: config.AuthCodeURL(sourceID, oauth2.AccessTypeOffline),
 		}
 
-		c.JSON(http.StatusOK, oAuthResponse)
+		core.RespondJSON(c, http.StatusOK, oAuthResponse)
 	}
 }
 
@@ -176,17 +190,21 @@ func oAuthConnect(sources Sources) func(c *core.APICallContext) {
 // @Router /sources [get]
 func listSources(sources Sources) func(c *core.APICallContext) {
 	return func(c *core.APICallContext) {
-		sources, err := sources.List()
+		descriptions, err := sources.List()
 		if err != nil {
 			c.String(http.StatusBadRequest, "Sources could not be listed")
 			return
 		}
 		result := map[string]*SourceResponse{}
-		for srcID, source := range sources {
-			result[srcID.String()] = newSourceResponse(source)
+		for srcID, source := range descriptions {
+			response := newSourceResponse(source)
+			if client, err := sources.Client(srcID); err == nil {
+				response.Connected = client.Connected()
+			}
+			result[srcID.String()] = response
 		}
 
-		c.JSON(http.StatusOK, result)
+		core.RespondJSON(c, http.StatusOK, result)
 	}
 }
 
@@ -197,7 +215,9 @@ func listSources(sources Sources) func(c *core.APICallContext) {
 // @Produce json
 // @Param source path string true "Source ID"
 // @Success 200
-// @Router /sources/{source}/recipes [get]
+// @Failure 409 {string} string "Source is not connected"
+// @Security ApiKeyAuth
+// @Router /sources/{source}/recipes [patch]
 func synchronizeSourceRecipes(sources Sources, recipes recipes.RecipeDB) func(c *core.APICallContext) {
 	return func(c *core.APICallContext) {
 		sourceID := c.Param("source")
@@ -209,6 +229,16 @@ func synchronizeSourceRecipes(sources Sources, recipes recipes.RecipeDB) func(c
 			return
 		}
 
+		if refresher, ok := src.(TokenRefresher); ok {
+			if err = refresher.RefreshToken(); err != nil {
+				log.WithError(err).WithField("sourceID", sourceID).Warn("Could not refresh the token of the source")
+			}
+		}
+		if !src.Connected() {
+			c.String(http.StatusConflict, "Source is not connected")
+			return
+		}
+
 		for _, recipe := range src.Recipes().List() {
 			log.WithField("sourceID", sourceID).Infof("Inserted New Recipe: %v", recipe.String())
 			err = recipes.Insert(recipe)
@@ -228,6 +258,61 @@ func synchronizeSourceRecipes(sources Sources, recipes recipes.RecipeDB) func(c
 	}
 }
 
+// importWebRecipe example
+// @Summary Import a Recipe from a Web Page
+// @Description The recipe is read from the schema.org/Recipe structured data (JSON-LD) of the web page, including the recipe's image.
+// @Description Pages without a complete recipe, i.e., with a name and ingredients, are rejected.
+// @Tags Sources
+// @Param message body WebImportRequest true "Web page of the recipe"
+// @Accept json
+// @Produce json
+// @Success 201 {object} recipes.Recipe
+// @Header 201 {string} Location "Path of the new recipe"
+// @Failure 400 {object} core.APIError
+// @Failure 422 {object} core.APIError
+// @Failure 502 {object} core.APIError
+// @Security ApiKeyAuth
+// @Router /sources/web/import [post]
+func importWebRecipe(web *WebScraperSource, recipeDB recipes.RecipeDB, apiPath string) func(c *core.APICallContext) {
+	return func(c *core.APICallContext) {
+		var request WebImportRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			core.AbortWithAPIError(c, http.StatusBadRequest, "Could not read JSON input", err.Error())
+			return
+		}
+		logger := core.LoggerFrom(c).WithField("url", request.URL)
+
+		recipe, pictures, err := web.Import(request.URL)
+		switch {
+		case errors.Is(err, ErrInvalidURL):
+			core.AbortWithAPIError(c, http.StatusBadRequest, "Invalid url", err.Error())
+			return
+		case errors.Is(err, ErrNoStructuredData):
+			core.AbortWithAPIError(c, http.StatusUnprocessableEntity, "No recipe found on the web page", err.Error())
+			return
+		case err != nil:
+			logger.WithError(err).Info("Could not download web page")
+			core.AbortWithAPIError(c, http.StatusBadGateway, "Could not download the web page", err.Error())
+			return
+		}
+
+		if err = recipeDB.Insert(recipe); err != nil {
+			logger.WithError(err).Error("Could not insert an imported recipe")
+			core.AbortWithAPIError(c, http.StatusInternalServerError, "Could not persist Recipe", "")
+			return
+		}
+		for _, pic := range pictures {
+			if err = recipeDB.AddPicture(pic); err != nil {
+				logger.WithError(err).Error("Could not insert the picture of an imported recipe")
+			}
+		}
+
+		logger.Infof("Imported New Recipe: %v", recipe.ID)
+		c.Header("Location", fmt.Sprintf("%v/recipes/r/%v", apiPath, recipe.ID))
+		core.RespondJSON(c, http.StatusCreated, recipeDB.Get(recipe.ID))
+	}
+}
+
 func sourceClient(sourceID string, sources Sources) (SourceClient, error) {
 	sid, err := SourceIDFromString(sourceID)
 	if err != nil {
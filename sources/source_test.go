@@ -27,10 +27,16 @@ package sources
 import (
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/satori/go.uuid"
+	"golang.org/x/oauth2"
 
+	"github.com/ottenwbe/recipes-manager/core"
+	"github.com/ottenwbe/recipes-manager/recipes"
 	. "github.com/ottenwbe/recipes-manager/utils"
 )
 
@@ -50,12 +56,35 @@ var _ = Describe("SourceClient", func() {
 		})
 	})
 
+	Context("Capabilities", func() {
+		It("include oauth for sources with an oauth config", func() {
+			meta := NewSourceDescription(SourceID(uuid.NewV4()), "name", "test", &oauth2.Config{})
+			Expect(meta.Capabilities).To(Equal([]string{CapabilityOAuth, CapabilityDownload}))
+		})
+
+		It("are listed with the sources", func() {
+			sources := NewSources()
+			meta := NewSourceDescription(SourceID(uuid.NewV4()), "name", "test", nil)
+			Expect(sources.Add(meta, NewPaprikaSource(""))).To(Succeed())
+			handler := core.NewHandler()
+			NewSourceAPI(sources, recipes.NewInMemoryDB()).PrepareAPI(handler, sources, recipes.NewInMemoryDB())
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/sources", nil))
+
+			var listing map[string]SourceResponse
+			Expect(json.NewDecoder(w.Body).Decode(&listing)).To(Succeed())
+			Expect(listing[meta.ID.String()].Capabilities).To(Equal([]string{CapabilityDownload}))
+			Expect(listing[meta.ID.String()].Name).To(Equal("name"))
+		})
+	})
+
 	Context("Marshalling", func() {
 		It("of a SourceDescription to json is possible", func() {
 
 			id := uuid.NewV4()
 			meta := NewSourceDescription(SourceID(id), "name", "test", nil)
-			expected := fmt.Sprintf("{\"id\":%v,\"name\":\"name\",\"connected\":true,\"version\":\"test\"}", CBytes(id.Bytes()))
+			expected := fmt.Sprintf("{\"id\":%v,\"name\":\"name\",\"connected\":true,\"version\":\"test\",\"capabilities\":[\"download\"]}", CBytes(id.Bytes()))
 
 			b, err := json.Marshal(meta)
 
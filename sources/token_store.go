@@ -0,0 +1,141 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package sources
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+
+	"github.com/ottenwbe/recipes-manager/utils"
+)
+
+const (
+	sourceTokensDirCfg = "source.tokens.dir"
+)
+
+var (
+	tokensDir string
+)
+
+//TokenStore persists the OAuth tokens of sources, so that connections survive restarts
+type TokenStore interface {
+	//Token of a source; an error is returned if no token is stored
+	Token(id SourceID) (*oauth2.Token, error)
+	//SaveToken of a source and replace a previously stored token
+	SaveToken(id SourceID, token *oauth2.Token) error
+}
+
+//FileTokenStore stores the token of each source as JSON file in a directory
+type FileTokenStore struct {
+	dir string
+}
+
+//NewFileTokenStore returns a store for tokens in the directory dir
+func NewFileTokenStore(dir string) *FileTokenStore {
+	return &FileTokenStore{dir: dir}
+}
+
+//DefaultTokenStore stores tokens in the directory configured by source.tokens.dir
+func DefaultTokenStore() TokenStore {
+	return NewFileTokenStore(tokensDir)
+}
+
+//Token reads the token of a source from its file
+func (s *FileTokenStore) Token(id SourceID) (*oauth2.Token, error) {
+	f, err := os.Open(s.file(id))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	tok := &oauth2.Token{}
+	err = json.NewDecoder(f).Decode(tok)
+	return tok, err
+}
+
+//SaveToken writes the token of a source to its file, which is only readable by the owner
+func (s *FileTokenStore) SaveToken(id SourceID, token *oauth2.Token) error {
+	file := s.file(id)
+	log.Infof("Saving credential file to: %s", file)
+	f, err := os.OpenFile(file, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	return json.NewEncoder(f).Encode(token)
+}
+
+func (s *FileTokenStore) file(id SourceID) string {
+	return filepath.Join(s.dir, "token-"+id.String()+".json")
+}
+
+//persistingTokenSource saves tokens whenever the underlying token source has refreshed them
+type persistingTokenSource struct {
+	id     SourceID
+	store  TokenStore
+	source oauth2.TokenSource
+	mtx    sync.Mutex
+	last   string
+}
+
+//newPersistingTokenSource renews the token with config's token endpoint once it expires and saves renewed tokens to the store
+func newPersistingTokenSource(id SourceID, store TokenStore, config *oauth2.Config, token *oauth2.Token) *persistingTokenSource {
+	return &persistingTokenSource{
+		id:     id,
+		store:  store,
+		source: config.TokenSource(context.Background(), token),
+		last:   token.AccessToken,
+	}
+}
+
+//Token returns a valid token; an expired token is refreshed and saved
+func (s *persistingTokenSource) Token() (*oauth2.Token, error) {
+	tok, err := s.source.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if tok.AccessToken != s.last {
+		s.last = tok.AccessToken
+		if err = s.store.SaveToken(s.id, tok); err != nil {
+			log.WithError(err).WithField("source", s.id.String()).Warn("Could not save refreshed token")
+		}
+	}
+	return tok, nil
+}
+
+func init() {
+	utils.Config.SetDefault(sourceTokensDirCfg, ".")
+
+	tokensDir = utils.Config.GetString(sourceTokensDirCfg)
+}
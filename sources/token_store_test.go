@@ -0,0 +1,159 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package sources
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/oauth2"
+
+	"github.com/ottenwbe/recipes-manager/core"
+	"github.com/ottenwbe/recipes-manager/recipes"
+)
+
+var _ = Describe("token store", func() {
+
+	var (
+		dir    string
+		store  *FileTokenStore
+		server *httptest.Server
+		reject bool
+		config *oauth2.Config
+		id     = (&DriveClient{}).ID()
+	)
+
+	expiredToken := func() *oauth2.Token {
+		return &oauth2.Token{AccessToken: "old", RefreshToken: "refresh", Expiry: time.Now().Add(-time.Hour)}
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "tokens")
+		Expect(err).ToNot(HaveOccurred())
+		store = NewFileTokenStore(dir)
+
+		reject = false
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			if reject {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"access_token":  "new",
+				"token_type":    "Bearer",
+				"refresh_token": "refresh",
+				"expires_in":    3600,
+			})
+		}))
+		config = &oauth2.Config{ClientID: "client", Endpoint: oauth2.Endpoint{TokenURL: server.URL}}
+	})
+
+	AfterEach(func() {
+		server.Close()
+		_ = os.RemoveAll(dir)
+	})
+
+	It("saves and loads tokens", func() {
+		Expect(store.SaveToken(id, &oauth2.Token{AccessToken: "a", RefreshToken: "r"})).To(Succeed())
+
+		tok, err := store.Token(id)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(tok.AccessToken).To(Equal("a"))
+		Expect(tok.RefreshToken).To(Equal("r"))
+	})
+
+	It("fails to load missing tokens", func() {
+		_, err := store.Token(id)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("saves refreshed tokens", func() {
+		tok, err := newPersistingTokenSource(id, store, config, expiredToken()).Token()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(tok.AccessToken).To(Equal("new"))
+
+		saved, err := store.Token(id)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(saved.AccessToken).To(Equal("new"))
+	})
+
+	It("does not save valid tokens again", func() {
+		valid := &oauth2.Token{AccessToken: "valid", Expiry: time.Now().Add(time.Hour)}
+		_, err := newPersistingTokenSource(id, store, config, valid).Token()
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = store.Token(id)
+		Expect(err).To(HaveOccurred())
+	})
+
+	Context("of the drive client", func() {
+
+		var client *DriveClient
+
+		BeforeEach(func() {
+			client = &DriveClient{tokens: store, oAuthConfig: config}
+			Expect(client.configureDriveConnection(expiredToken())).To(Succeed())
+		})
+
+		It("refreshes expired tokens", func() {
+			Expect(client.RefreshToken()).To(Succeed())
+			Expect(client.Connected()).To(BeTrue())
+		})
+
+		It("disconnects when the refresh is rejected", func() {
+			reject = true
+			Expect(client.RefreshToken()).ToNot(Succeed())
+			Expect(client.Connected()).To(BeFalse())
+		})
+
+		It("reports a rejected refresh via the API", func() {
+			reject = true
+			sources := NewSources()
+			meta := NewSourceDescription(client.ID(), client.Name(), client.Version(), config)
+			Expect(sources.Add(meta, client)).To(Succeed())
+			handler := core.NewHandler()
+			NewSourceAPI(sources, recipes.NewInMemoryDB()).PrepareAPI(handler, sources, recipes.NewInMemoryDB())
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodPatch, "/api/v1/sources/"+meta.ID.String()+"/recipes", nil))
+			Expect(w.Code).To(Equal(http.StatusConflict))
+
+			w = httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/sources", nil))
+			var listing map[string]SourceResponse
+			Expect(json.NewDecoder(w.Body).Decode(&listing)).To(Succeed())
+			Expect(listing[meta.ID.String()].Connected).To(BeFalse())
+		})
+	})
+})
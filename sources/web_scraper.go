@@ -0,0 +1,418 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package sources
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/html"
+
+	"github.com/ottenwbe/recipes-manager/recipes"
+	"github.com/ottenwbe/recipes-manager/units"
+	"github.com/ottenwbe/recipes-manager/utils"
+)
+
+var (
+	//ErrInvalidURL is returned for urls which cannot be imported, i.e., urls which are no absolute http(s) urls
+	ErrInvalidURL = errors.New("url has to be an absolute http or https url")
+	//ErrNoStructuredData is returned for web pages which do not describe a complete recipe with schema.org/Recipe JSON-LD
+	ErrNoStructuredData = errors.New("page contains no schema.org/Recipe structured data")
+)
+
+const (
+	//webRequestTimeout limits the time to download a web page or an image
+	webRequestTimeout = 10 * time.Second
+	//maxWebBytes limits the size of downloaded web pages and images
+	maxWebBytes = 5 << 20
+	//defaultPictureName is used for images whose url has no file name
+	defaultPictureName = "image"
+)
+
+//WebScraperSource imports recipes from web pages which describe them with schema.org/Recipe JSON-LD
+type WebScraperSource struct {
+	client *http.Client
+}
+
+//NewWebScraperSource is the designated way to create a WebScraperSource
+func NewWebScraperSource() *WebScraperSource {
+	return &WebScraperSource{client: &http.Client{Timeout: webRequestTimeout}}
+}
+
+//Import downloads the web page and converts the recipe it describes, including the recipe's image
+func (w *WebScraperSource) Import(pageURL string) (*recipes.Recipe, map[string]*recipes.RecipePicture, error) {
+	page, err := url.Parse(pageURL)
+	if err != nil || (page.Scheme != "http" && page.Scheme != "https") || page.Host == "" {
+		return nil, nil, ErrInvalidURL
+	}
+
+	body, _, err := w.download(page)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	recipe, imageURL, err := ParseJSONLDRecipe(bytes.NewReader(body), recipes.NewRecipeID())
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = recipe.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrNoStructuredData, err)
+	}
+
+	pictures := make(map[string]*recipes.RecipePicture)
+	if imageURL != "" {
+		pic, err := w.downloadPicture(recipe.ID, page, imageURL)
+		if err != nil {
+			log.WithError(err).WithField("url", imageURL).Warn("Could not download the image of a recipe")
+		} else {
+			recipe.PictureLink = append(recipe.PictureLink, pic.Name)
+			pictures[pic.Name] = pic
+		}
+	}
+
+	return recipe, pictures, nil
+}
+
+func (w *WebScraperSource) download(u *url.URL) ([]byte, string, error) {
+	response, err := w.client.Get(u.String())
+	if err != nil {
+		return nil, "", err
+	}
+	defer func() { _ = response.Body.Close() }()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("could not download %v: %v", u, response.Status)
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(response.Body, maxWebBytes))
+	return body, response.Header.Get("Content-Type"), err
+}
+
+//downloadPicture resolves the image's url relative to the page and names the picture after the image's file
+func (w *WebScraperSource) downloadPicture(id recipes.RecipeID, page *url.URL, imageURL string) (*recipes.RecipePicture, error) {
+	image, err := page.Parse(imageURL)
+	if err != nil {
+		return nil, err
+	}
+
+	img, contentType, err := w.download(image)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(contentType, "image/") {
+		contentType = http.DetectContentType(img)
+	}
+	if !strings.HasPrefix(contentType, "image/") {
+		return nil, fmt.Errorf("%v is no image but %v", image, contentType)
+	}
+
+	name := path.Base(image.Path)
+	if name == "." || name == "/" {
+		name = defaultPictureName
+	}
+
+	return &recipes.RecipePicture{ID: id, Name: name, Picture: utils.IMGToBase64(strings.Split(contentType, ";")[0], img)}, nil
+}
+
+//ParseJSONLDRecipe reads the first schema.org/Recipe of the html page's JSON-LD scripts.
+//It returns the recipe and the url of the recipe's image, which is empty if the recipe has no image.
+func ParseJSONLDRecipe(page io.Reader, id recipes.RecipeID) (*recipes.Recipe, string, error) {
+	tokenizer := html.NewTokenizer(page)
+	inJSONLD := false
+
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			if err := tokenizer.Err(); err != io.EOF {
+				return nil, "", err
+			}
+			return nil, "", ErrNoStructuredData
+		case html.StartTagToken:
+			name, hasAttr := tokenizer.TagName()
+			inJSONLD = string(name) == "script" && isJSONLDScript(hasAttr, tokenizer)
+		case html.TextToken:
+			if !inJSONLD {
+				continue
+			}
+			var data interface{}
+			if err := json.Unmarshal(tokenizer.Text(), &data); err != nil {
+				log.WithError(err).Debug("Skipping malformed JSON-LD")
+				continue
+			}
+			if node := findRecipeNode(data); node != nil {
+				return jsonLDToRecipe(node, id)
+			}
+		case html.EndTagToken:
+			inJSONLD = false
+		}
+	}
+}
+
+func isJSONLDScript(hasAttr bool, tokenizer *html.Tokenizer) bool {
+	for hasAttr {
+		var key, val []byte
+		key, val, hasAttr = tokenizer.TagAttr()
+		if string(key) == "type" && strings.EqualFold(strings.TrimSpace(string(val)), "application/ld+json") {
+			return true
+		}
+	}
+	return false
+}
+
+//findRecipeNode searches the JSON-LD for a node of type Recipe, also in lists, graphs, and main entities of web pages
+func findRecipeNode(data interface{}) map[string]interface{} {
+	switch value := data.(type) {
+	case []interface{}:
+		for _, element := range value {
+			if node := findRecipeNode(element); node != nil {
+				return node
+			}
+		}
+	case map[string]interface{}:
+		if isRecipeType(value["@type"]) {
+			return value
+		}
+		for _, key := range []string{"@graph", "mainEntity"} {
+			if node := findRecipeNode(value[key]); node != nil {
+				return node
+			}
+		}
+	}
+	return nil
+}
+
+func isRecipeType(t interface{}) bool {
+	for _, name := range jsonLDTexts(t) {
+		if name == "Recipe" || strings.HasSuffix(name, "/Recipe") || strings.HasSuffix(name, ":Recipe") {
+			return true
+		}
+	}
+	return false
+}
+
+func jsonLDToRecipe(node map[string]interface{}, id recipes.RecipeID) (*recipes.Recipe, string, error) {
+	recipe := recipes.NewRecipe(id)
+
+	recipe.Name = firstText(jsonLDTexts(node["name"]))
+	if recipe.Name == "" {
+		return nil, "", fmt.Errorf("%w: the recipe has no name", ErrNoStructuredData)
+	}
+
+	lines := jsonLDTexts(node["recipeIngredient"])
+	if len(lines) == 0 {
+		// ingredients is the superseded name of recipeIngredient
+		lines = jsonLDTexts(node["ingredients"])
+	}
+	if len(lines) == 0 {
+		return nil, "", fmt.Errorf("%w: the recipe has no ingredients", ErrNoStructuredData)
+	}
+	for _, line := range lines {
+		recipe.Ingredients = append(recipe.Ingredients, parseIngredientLine(line))
+	}
+	recipe.OriginalUnitSystem = originalUnitSystem(recipe.Ingredients)
+
+	if servings := parseServings(jsonLDTexts(node["recipeYield"])); servings > 0 {
+		recipe.Servings = servings
+	}
+
+	if instructions := jsonLDTexts(node["recipeInstructions"], "itemListElement", "text"); len(instructions) > 0 {
+		recipe.Description = strings.Join(instructions, "\n")
+	} else {
+		recipe.Description = firstText(jsonLDTexts(node["description"]))
+	}
+
+	return recipe, firstText(jsonLDTexts(node["image"], "url", "contentUrl")), nil
+}
+
+var htmlTags = regexp.MustCompile(`<[^>]*>`)
+
+//jsonLDTexts flattens JSON-LD values, which can be texts, lists, or nodes, to a list of texts.
+//The texts of nodes are read from the first of the keys the node has, e.g., the text of a HowToStep.
+func jsonLDTexts(data interface{}, keys ...string) []string {
+	texts := make([]string, 0)
+	switch value := data.(type) {
+	case string:
+		text := strings.Join(strings.Fields(html.UnescapeString(htmlTags.ReplaceAllString(value, " "))), " ")
+		if text != "" {
+			texts = append(texts, text)
+		}
+	case float64:
+		texts = append(texts, strconv.FormatFloat(value, 'f', -1, 64))
+	case []interface{}:
+		for _, element := range value {
+			texts = append(texts, jsonLDTexts(element, keys...)...)
+		}
+	case map[string]interface{}:
+		for _, key := range keys {
+			if nested, ok := value[key]; ok {
+				return jsonLDTexts(nested, keys...)
+			}
+		}
+	}
+	return texts
+}
+
+func firstText(texts []string) string {
+	if len(texts) == 0 {
+		return ""
+	}
+	return texts[0]
+}
+
+var servingsPattern = regexp.MustCompile(`\d+`)
+
+//parseServings returns the first number of servings in yields like '4 servings', or 0 if there is none
+func parseServings(yields []string) int8 {
+	for _, yield := range yields {
+		if servings, err := strconv.ParseInt(servingsPattern.FindString(yield), 10, 8); err == nil && servings > 0 {
+			return int8(servings)
+		}
+	}
+	return 0
+}
+
+var (
+	amountPattern   = regexp.MustCompile(`^(\d+(?:[.,]\d+)?)(?:/(\d+))?(.*)$`)
+	vulgarFractions = map[rune]float64{'½': 1.0 / 2, '⅓': 1.0 / 3, '⅔': 2.0 / 3, '¼': 1.0 / 4, '¾': 3.0 / 4, '⅛': 1.0 / 8}
+)
+
+//parseIngredientLine splits lines like '1 1/2 cups flour', '200g sugar', or '2-3 cloves garlic' into the amount, the unit, and the name of an ingredient.
+//Only known units are split from the name, i.e., '2 eggs' has no unit.
+func parseIngredientLine(line string) recipes.Ingredients {
+	ingredient := recipes.Ingredients{Name: strings.TrimSpace(line), Amount: recipes.NoAmountIngredient}
+
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return ingredient
+	}
+	amount, rest, ok := parseAmount(fields[0])
+	if !ok {
+		return ingredient
+	}
+	fields = fields[1:]
+
+	amountMax := 0.0
+	if upper, isRange := cutRangeDash(rest); isRange {
+		if upperAmount, upperRest, isAmount := parseAmount(upper); isAmount && upperAmount > amount {
+			amountMax, rest = upperAmount, upperRest
+		}
+	} else if rest == "" && len(fields) > 1 && isRangeDash(fields[0]) {
+		// ranges with spaces like 2 - 3
+		if upperAmount, upperRest, isAmount := parseAmount(fields[1]); isAmount && upperAmount > amount {
+			amountMax, rest, fields = upperAmount, upperRest, fields[2:]
+		}
+	}
+
+	if rest != "" {
+		fields = append([]string{rest}, fields...)
+	} else if len(fields) > 0 {
+		// mixed numbers like 1 1/2
+		if fraction, fractionRest, isAmount := parseAmount(fields[0]); isAmount && fractionRest == "" && fraction < 1 {
+			amount += fraction
+			fields = fields[1:]
+		}
+	}
+
+	if len(fields) == 0 {
+		// no name remains, e.g., for a count without an ingredient
+		return ingredient
+	}
+
+	unit := ""
+	if len(fields) > 1 && units.Known(fields[0]) {
+		unit, fields = units.Normalize(fields[0]), fields[1:]
+	}
+
+	return recipes.Ingredients{Name: strings.Join(fields, " "), Amount: amount, AmountMax: amountMax, Unit: unit}
+}
+
+//originalUnitSystem detects the system of units of imported ingredients; empty if the units do not tell
+func originalUnitSystem(ingredients []recipes.Ingredients) string {
+	names := make([]string, len(ingredients))
+	for i, ingredient := range ingredients {
+		names[i] = ingredient.Unit
+	}
+	return string(units.Detect(names))
+}
+
+//rangeDashes separate the ends of ranges of amounts, i.e., a hyphen or an en dash
+var rangeDashes = []string{"-", "–"}
+
+func isRangeDash(token string) bool {
+	for _, dash := range rangeDashes {
+		if token == dash {
+			return true
+		}
+	}
+	return false
+}
+
+//cutRangeDash removes a leading range dash, e.g., of -3 in 2-3, and reports whether there was one
+func cutRangeDash(token string) (string, bool) {
+	for _, dash := range rangeDashes {
+		if strings.HasPrefix(token, dash) {
+			return token[len(dash):], true
+		}
+	}
+	return token, false
+}
+
+//parseAmount reads a leading amount like 2, 1.5, 1,5, 1/2, ½, or 1½ and returns the rest of the token, e.g., the unit of 200g
+func parseAmount(token string) (float64, string, bool) {
+	amount, rest, ok := 0.0, token, false
+
+	if match := amountPattern.FindStringSubmatch(token); match != nil {
+		amount, _ = strconv.ParseFloat(strings.Replace(match[1], ",", ".", 1), 64)
+		if match[2] != "" {
+			denominator, _ := strconv.ParseFloat(match[2], 64)
+			if denominator == 0 {
+				return 0, token, false
+			}
+			amount /= denominator
+		}
+		rest, ok = match[3], true
+	}
+
+	if r, size := utf8.DecodeRuneInString(rest); r != utf8.RuneError {
+		if fraction, isFraction := vulgarFractions[r]; isFraction {
+			amount, rest, ok = amount+fraction, rest[size:], true
+		}
+	}
+
+	return amount, rest, ok
+}
@@ -0,0 +1,183 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package sources
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ottenwbe/recipes-manager/core"
+	"github.com/ottenwbe/recipes-manager/recipes"
+)
+
+var _ = Describe("web scraper", func() {
+
+	const recipeFile = "fixtures/jsonld-recipe.html"
+
+	// pngHeader is sufficient to be detected as image/png
+	pngHeader := []byte("\x89PNG\x0D\x0A\x1A\x0A")
+
+	Context("parsing", func() {
+		It("converts the schema.org recipe of a page", func() {
+			page, err := os.Open(recipeFile)
+			Expect(err).ToNot(HaveOccurred())
+			defer page.Close()
+
+			id := recipes.NewRecipeID()
+			recipe, image, err := ParseJSONLDRecipe(page, id)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(image).To(Equal("/images/tomato-soup.png"))
+			Expect(recipe.ID).To(Equal(id))
+			Expect(recipe.Name).To(Equal("Tomato Soup & Bread"))
+			Expect(recipe.Servings).To(Equal(int8(4)))
+			Expect(recipe.Ingredients).To(Equal([]recipes.Ingredients{
+				{Name: "tomatoes", Amount: 800, Unit: "g"},
+				{Name: "water", Amount: 1.5, Unit: "cup"},
+				{Name: "salt", Amount: 0.5, Unit: "tsp"},
+				{Name: "onions", Amount: 2, Unit: ""},
+				{Name: "Pepper", Amount: recipes.NoAmountIngredient, Unit: ""},
+			}))
+			Expect(recipe.Description).To(Equal("Chop the onions.\nCook everything for 20 minutes.\nServe with bread."))
+		})
+
+		It("rejects pages without structured data", func() {
+			_, _, err := ParseJSONLDRecipe(strings.NewReader("<html><body><h1>Tomato Soup</h1></body></html>"), recipes.NewRecipeID())
+			Expect(err).To(MatchError(ErrNoStructuredData))
+		})
+
+		It("rejects recipes without ingredients", func() {
+			page := `<script type="application/ld+json">{"@type": "Recipe", "name": "Soup"}</script>`
+			_, _, err := ParseJSONLDRecipe(strings.NewReader(page), recipes.NewRecipeID())
+			Expect(err).To(MatchError(ContainSubstring("no ingredients")))
+		})
+
+		It("splits ingredient lines into amount, unit, and name", func() {
+			Expect(parseIngredientLine("1,5 l milk")).To(Equal(recipes.Ingredients{Name: "milk", Amount: 1.5, Unit: "l"}))
+			Expect(parseIngredientLine("1½ Tablespoons sugar")).To(Equal(recipes.Ingredients{Name: "sugar", Amount: 1.5, Unit: "tbsp"}))
+			Expect(parseIngredientLine("3/4 cup flour")).To(Equal(recipes.Ingredients{Name: "flour", Amount: 0.75, Unit: "cup"}))
+			Expect(parseIngredientLine("salt to taste")).To(Equal(recipes.Ingredients{Name: "salt to taste", Amount: recipes.NoAmountIngredient}))
+		})
+
+		It("detects the system of units of the ingredients", func() {
+			imperial := []recipes.Ingredients{parseIngredientLine("2 cups flour"), parseIngredientLine("8 oz butter"), parseIngredientLine("1 tsp salt")}
+			Expect(originalUnitSystem(imperial)).To(Equal("imperial"))
+			Expect(originalUnitSystem([]recipes.Ingredients{parseIngredientLine("200g flour")})).To(Equal("metric"))
+			Expect(originalUnitSystem([]recipes.Ingredients{parseIngredientLine("2 eggs")})).To(BeEmpty())
+		})
+
+		It("splits ranges of amounts", func() {
+			Expect(parseIngredientLine("2-3 cloves garlic")).To(Equal(recipes.Ingredients{Name: "cloves garlic", Amount: 2, AmountMax: 3}))
+			Expect(parseIngredientLine("100–150g sugar")).To(Equal(recipes.Ingredients{Name: "sugar", Amount: 100, AmountMax: 150, Unit: "g"}))
+			Expect(parseIngredientLine("1 - 1½ cups milk")).To(Equal(recipes.Ingredients{Name: "milk", Amount: 1, AmountMax: 1.5, Unit: "cup"}))
+		})
+	})
+
+	Context("importing", func() {
+
+		var (
+			server   *httptest.Server
+			recipeDB *recipes.InMemoryDB
+			handler  core.Handler
+		)
+
+		BeforeEach(func() {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/soup", func(w http.ResponseWriter, r *http.Request) {
+				http.ServeFile(w, r, recipeFile)
+			})
+			mux.HandleFunc("/images/tomato-soup.png", func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write(pngHeader)
+			})
+			mux.HandleFunc("/empty", func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte("<html></html>"))
+			})
+			server = httptest.NewServer(mux)
+
+			recipeDB = recipes.NewInMemoryDB()
+			handler = core.NewHandler()
+			NewSourceAPI(NewSources(), recipeDB).PrepareAPI(handler, NewSources(), recipeDB)
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		importURL := func(url string) *httptest.ResponseRecorder {
+			body, _ := json.Marshal(WebImportRequest{URL: url})
+			w := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodPost, "/api/v1/sources/web/import", bytes.NewBuffer(body))
+			request.Header.Set("Content-Type", "application/json")
+			handler.ServeHTTP(w, request)
+			return w
+		}
+
+		It("downloads the recipe and its image", func() {
+			recipe, pictures, err := NewWebScraperSource().Import(server.URL + "/soup")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(recipe.PictureLink).To(Equal([]string{"tomato-soup.png"}))
+			Expect(pictures).To(HaveKey("tomato-soup.png"))
+			Expect(pictures["tomato-soup.png"].ID).To(Equal(recipe.ID))
+			Expect(pictures["tomato-soup.png"].Picture).To(HavePrefix("data:image/png;base64,"))
+		})
+
+		It("persists imported recipes", func() {
+			w := importURL(server.URL + "/soup")
+
+			Expect(w.Code).To(Equal(http.StatusCreated))
+			var recipe recipes.Recipe
+			Expect(json.NewDecoder(w.Body).Decode(&recipe)).To(Succeed())
+			Expect(w.Header().Get("Location")).To(Equal("/api/v1/recipes/r/" + recipe.ID.String()))
+			Expect(recipeDB.Get(recipe.ID).Name).To(Equal("Tomato Soup & Bread"))
+			Expect(recipeDB.Picture(recipe.ID, "tomato-soup.png").ID).To(Equal(recipe.ID))
+		})
+
+		It("rejects pages without a recipe with 422", func() {
+			w := importURL(server.URL + "/empty")
+
+			Expect(w.Code).To(Equal(http.StatusUnprocessableEntity))
+			body, _ := ioutil.ReadAll(w.Body)
+			Expect(string(body)).To(ContainSubstring("schema.org/Recipe"))
+			Expect(recipeDB.Num()).To(BeZero())
+		})
+
+		It("rejects urls other than http(s) with 400", func() {
+			Expect(importURL("file:///etc/passwd").Code).To(Equal(http.StatusBadRequest))
+		})
+
+		It("reports pages which cannot be downloaded with 502", func() {
+			Expect(importURL(server.URL + "/missing").Code).To(Equal(http.StatusBadGateway))
+		})
+	})
+})
@@ -0,0 +1,163 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package units
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/ottenwbe/recipes-manager/utils"
+)
+
+const (
+	// densityFileCfg is the configuration key for a JSON file with densities of ingredients in g per ml
+	densityFileCfg = "units.densityFile"
+)
+
+//ErrUnknownDensity is returned when a volume cannot be weighed since the density of the ingredient is not known
+var ErrUnknownDensity = errors.New("unknown density")
+
+//defaultDensities of common baking ingredients in g per ml, based on the weights of a cup (236.588 ml) bakers use
+var defaultDensities = map[string]float64{
+	"water":             1.0,
+	"milk":              1.03,
+	"buttermilk":        1.03,
+	"cream":             1.0,
+	"yogurt":            1.03,
+	"butter":            0.96,
+	"oil":               0.92,
+	"honey":             1.42,
+	"maple syrup":       1.32,
+	"flour":             0.51,
+	"all-purpose flour": 0.51,
+	"bread flour":       0.51,
+	"whole wheat flour": 0.48,
+	"sugar":             0.85,
+	"brown sugar":       0.9,
+	"powdered sugar":    0.51,
+	"icing sugar":       0.51,
+	"salt":              1.22,
+	"cocoa powder":      0.36,
+	"cornstarch":        0.47,
+	"baking powder":     0.81,
+	"baking soda":       1.22,
+	"rolled oats":       0.38,
+	"oats":              0.38,
+	"rice":              0.78,
+}
+
+var (
+	densities    = make(map[string]float64)
+	densitiesMtx sync.RWMutex
+)
+
+func init() {
+	AddDensities(defaultDensities)
+
+	utils.Config.SetDefault(densityFileCfg, "")
+	if path := utils.Config.GetString(densityFileCfg); path != "" {
+		if err := LoadDensities(path); err != nil {
+			log.WithError(err).WithField("file", path).Error("Could not load densities of ingredients")
+		}
+	}
+}
+
+//AddDensities of ingredients in g per ml; densities of known ingredients are replaced. Densities which are not positive are ignored.
+func AddDensities(additional map[string]float64) {
+	densitiesMtx.Lock()
+	defer densitiesMtx.Unlock()
+
+	for name, density := range additional {
+		if density > 0 {
+			densities[densityKey(name)] = density
+		}
+	}
+}
+
+//LoadDensities reads densities from a JSON file, i.e., {"almond flour": 0.4, "rye flour": 0.43}, and adds them
+func LoadDensities(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	var additional map[string]float64
+	if err = json.NewDecoder(file).Decode(&additional); err != nil {
+		return err
+	}
+	AddDensities(additional)
+	return nil
+}
+
+//Density of an ingredient in g per ml. Names are matched case-insensitively and without preparation notes after a comma, e.g., 'Flour, sifted'.
+//If the name is not known, it is looked up without its leading words and in its singular, e.g., 'organic rolled oats' as 'rolled oats'.
+func Density(ingredient string) (float64, bool) {
+	densitiesMtx.RLock()
+	defer densitiesMtx.RUnlock()
+
+	words := strings.Fields(densityKey(strings.SplitN(ingredient, ",", 2)[0]))
+	for i := range words {
+		name := strings.Join(words[i:], " ")
+		for _, candidate := range []string{name, strings.TrimSuffix(name, "s"), strings.TrimSuffix(name, "es")} {
+			if density, ok := densities[candidate]; ok {
+				return density, true
+			}
+		}
+	}
+	return 0, false
+}
+
+//ConvertToWeight converts an amount of an ingredient to metric weight units, e.g., 2 cups of flour to about 240 g.
+//Volumes are weighed with the density of the ingredient; ErrUnknownDensity is returned if it is not known.
+//ErrUnknownUnit is returned for units that cannot be converted.
+func ConvertToWeight(amount float64, name string, ingredient string) (float64, string, error) {
+	canonical := Normalize(name)
+	from, ok := knownUnits[canonical]
+	if !ok {
+		return amount, name, ErrUnknownUnit
+	}
+
+	base := amount * from.factor
+	if from.dimension == Volume {
+		density, known := Density(ingredient)
+		if !known {
+			return amount, canonical, ErrUnknownDensity
+		}
+		base *= density
+	}
+	to := targetUnit(base, Weight, Metric)
+
+	return base / knownUnits[to].factor, to, nil
+}
+
+func densityKey(ingredient string) string {
+	return strings.Join(strings.Fields(strings.ToLower(ingredient)), " ")
+}
@@ -0,0 +1,123 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package units
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("densities", func() {
+
+	density := func(ingredient string) float64 {
+		value, ok := Density(ingredient)
+		Expect(ok).To(BeTrue(), ingredient)
+		return value
+	}
+
+	Context("lookup", func() {
+		It("knows common baking ingredients", func() {
+			Expect(density("flour")).To(Equal(0.51))
+			Expect(density(" Brown  Sugar ")).To(Equal(0.9))
+		})
+
+		It("ignores preparation notes, leading words, and plurals", func() {
+			Expect(density("Flour, sifted")).To(Equal(0.51))
+			Expect(density("organic rolled oats")).To(Equal(0.38))
+			Expect(density("unsalted butter")).To(Equal(0.96))
+		})
+
+		It("does not know other ingredients", func() {
+			_, ok := Density("saffron")
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Context("weighing", func() {
+		It("weighs volumes of ingredients with a known density", func() {
+			amount, unit, err := ConvertToWeight(2, "cups", "flour")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(amount).To(BeNumerically("~", 241.32, 0.01))
+			Expect(unit).To(Equal(Gram))
+		})
+
+		It("chooses the most readable weight unit", func() {
+			amount, unit, err := ConvertToWeight(2, "l", "milk")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(amount).To(BeNumerically("~", 2.06, 0.001))
+			Expect(unit).To(Equal(Kilogram))
+		})
+
+		It("converts weights regardless of the ingredient", func() {
+			amount, unit, err := ConvertToWeight(1, "lb", "saffron")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(amount).To(BeNumerically("~", 453.59, 0.01))
+			Expect(unit).To(Equal(Gram))
+		})
+
+		It("does not weigh volumes of ingredients with an unknown density", func() {
+			amount, unit, err := ConvertToWeight(1, "Tablespoon", "saffron")
+			Expect(err).To(MatchError(ErrUnknownDensity))
+			Expect(amount).To(Equal(1.0))
+			Expect(unit).To(Equal(Tablespoon))
+		})
+
+		It("does not weigh unknown units", func() {
+			_, unit, err := ConvertToWeight(1, "pinch", "salt")
+			Expect(err).To(MatchError(ErrUnknownUnit))
+			Expect(unit).To(Equal("pinch"))
+		})
+
+		It("leaves amounts unchanged when converting without an ingredient", func() {
+			amount, unit, err := Convert(1, "cup", ByWeight)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(amount).To(Equal(1.0))
+			Expect(unit).To(Equal(Cup))
+		})
+	})
+
+	Context("loading", func() {
+		It("adds the densities of a file", func() {
+			dir, err := ioutil.TempDir("", "densities")
+			Expect(err).ToNot(HaveOccurred())
+			defer func() { _ = os.RemoveAll(dir) }()
+			path := filepath.Join(dir, "densities.json")
+			Expect(ioutil.WriteFile(path, []byte(`{"Almond Flour": 0.4, "ghee": -1}`), 0600)).To(Succeed())
+
+			Expect(LoadDensities(path)).To(Succeed())
+			Expect(density("almond flour")).To(Equal(0.4))
+			_, ok := Density("ghee")
+			Expect(ok).To(BeFalse())
+		})
+
+		It("fails for invalid files", func() {
+			Expect(LoadDensities(filepath.Join(os.TempDir(), "does-not-exist.json"))).ToNot(Succeed())
+		})
+	})
+})
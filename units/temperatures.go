@@ -0,0 +1,60 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package units
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+//temperaturePattern matches temperatures like 350°F, 180 °C, or 180,5°C
+var temperaturePattern = regexp.MustCompile(`(\d+(?:[.,]\d+)?)\s*°\s*([CcFf])\b`)
+
+//ConvertTemperatures in a text, e.g., 'Bake at 350°F', to the given system of units, i.e., °C for metric and °F for imperial.
+//Converted temperatures are rounded to 5 degrees like the marks of ovens; the rest of the text remains unchanged.
+func ConvertTemperatures(text string, system System) string {
+	return temperaturePattern.ReplaceAllStringFunc(text, func(match string) string {
+		groups := temperaturePattern.FindStringSubmatch(match)
+		degrees, err := strconv.ParseFloat(strings.Replace(groups[1], ",", ".", 1), 64)
+		if err != nil {
+			return match
+		}
+
+		switch scale := strings.ToUpper(groups[2]); {
+		case system == Metric && scale == "F":
+			return fmt.Sprintf("%v°C", roundTemperature((degrees-32)*5/9))
+		case system == Imperial && scale == "C":
+			return fmt.Sprintf("%v°F", roundTemperature(degrees*9/5+32))
+		}
+		return match
+	})
+}
+
+func roundTemperature(degrees float64) int {
+	return int(math.Round(degrees/5) * 5)
+}
@@ -0,0 +1,260 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package units
+
+import (
+	"errors"
+	"strings"
+)
+
+//System of units, i.e., metric or imperial
+type System string
+
+const (
+	//Metric units like g or ml
+	Metric System = "metric"
+	//Imperial units like oz or cups
+	Imperial System = "imperial"
+	//ByWeight converts volumes of ingredients with a known density and all weights to metric weight units, see ConvertToWeight
+	ByWeight System = "weight"
+	//anySystem marks units that are common in all systems, e.g., tbsp
+	anySystem System = ""
+)
+
+//Dimension that is measured by a unit
+type Dimension int
+
+const (
+	//Volume is measured in ml
+	Volume Dimension = iota
+	//Weight is measured in g
+	Weight
+)
+
+//Canonical names of all known units
+const (
+	Milligram  = "mg"
+	Gram       = "g"
+	Kilogram   = "kg"
+	Milliliter = "ml"
+	Centiliter = "cl"
+	Deciliter  = "dl"
+	Liter      = "l"
+	Teaspoon   = "tsp"
+	Tablespoon = "tbsp"
+	FluidOunce = "fl oz"
+	Cup        = "cup"
+	Pint       = "pt"
+	Quart      = "qt"
+	Gallon     = "gal"
+	Ounce      = "oz"
+	Pound      = "lb"
+)
+
+var (
+	//ErrUnknownUnit is returned when a unit cannot be converted since it is not known
+	ErrUnknownUnit = errors.New("unknown unit")
+	//ErrUnknownSystem is returned when a system of units is not known
+	ErrUnknownSystem = errors.New("unknown system of units")
+)
+
+//unit describes how a unit relates to the base unit (ml or g) of its dimension
+type unit struct {
+	dimension Dimension
+	system    System
+	//factor to convert an amount to the base unit
+	factor float64
+}
+
+var knownUnits = map[string]unit{
+	Milligram:  {Weight, Metric, 0.001},
+	Gram:       {Weight, Metric, 1},
+	Kilogram:   {Weight, Metric, 1000},
+	Milliliter: {Volume, Metric, 1},
+	Centiliter: {Volume, Metric, 10},
+	Deciliter:  {Volume, Metric, 100},
+	Liter:      {Volume, Metric, 1000},
+	Teaspoon:   {Volume, anySystem, 4.92892},
+	Tablespoon: {Volume, anySystem, 14.7868},
+	FluidOunce: {Volume, Imperial, 29.5735},
+	Cup:        {Volume, Imperial, 236.588},
+	Pint:       {Volume, Imperial, 473.176},
+	Quart:      {Volume, Imperial, 946.353},
+	Gallon:     {Volume, Imperial, 3785.41},
+	Ounce:      {Weight, Imperial, 28.3495},
+	Pound:      {Weight, Imperial, 453.592},
+}
+
+//aliases maps common spellings of units to their canonical name
+var aliases = map[string]string{
+	"milligram":    Milligram,
+	"milligrams":   Milligram,
+	"gram":         Gram,
+	"grams":        Gram,
+	"gr":           Gram,
+	"kilogram":     Kilogram,
+	"kilograms":    Kilogram,
+	"kilo":         Kilogram,
+	"milliliter":   Milliliter,
+	"milliliters":  Milliliter,
+	"millilitre":   Milliliter,
+	"millilitres":  Milliliter,
+	"centiliter":   Centiliter,
+	"centiliters":  Centiliter,
+	"deciliter":    Deciliter,
+	"deciliters":   Deciliter,
+	"liter":        Liter,
+	"liters":       Liter,
+	"litre":        Liter,
+	"litres":       Liter,
+	"teaspoon":     Teaspoon,
+	"teaspoons":    Teaspoon,
+	"tsps":         Teaspoon,
+	"tl":           Teaspoon,
+	"tablespoon":   Tablespoon,
+	"tablespoons":  Tablespoon,
+	"tbs":          Tablespoon,
+	"tbsps":        Tablespoon,
+	"el":           Tablespoon,
+	"fluid ounce":  FluidOunce,
+	"fluid ounces": FluidOunce,
+	"floz":         FluidOunce,
+	"cups":         Cup,
+	"c":            Cup,
+	"pint":         Pint,
+	"pints":        Pint,
+	"quart":        Quart,
+	"quarts":       Quart,
+	"gallon":       Gallon,
+	"gallons":      Gallon,
+	"ounce":        Ounce,
+	"ounces":       Ounce,
+	"pound":        Pound,
+	"pounds":       Pound,
+	"lbs":          Pound,
+}
+
+//Normalize returns the canonical name of a unit, e.g., 'tbsp' for 'Tablespoons' or 'Tbsp.'.
+//Units that are not known are returned without surrounding whitespace but otherwise unchanged.
+func Normalize(name string) string {
+	trimmed := strings.TrimSpace(name)
+	key := strings.TrimSuffix(strings.ToLower(trimmed), ".")
+
+	if _, ok := knownUnits[key]; ok {
+		return key
+	}
+	if canonical, ok := aliases[key]; ok {
+		return canonical
+	}
+	return trimmed
+}
+
+//Known reports if the unit, or a common spelling of it, is known, e.g., 'Tablespoons'
+func Known(name string) bool {
+	key := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), ".")
+	_, known := knownUnits[key]
+	_, alias := aliases[key]
+	return known || alias
+}
+
+//ParseSystem returns the system of units with the given name
+func ParseSystem(name string) (System, error) {
+	switch System(strings.ToLower(name)) {
+	case Metric:
+		return Metric, nil
+	case Imperial:
+		return Imperial, nil
+	case ByWeight:
+		return ByWeight, nil
+	default:
+		return anySystem, ErrUnknownSystem
+	}
+}
+
+//Detect the system of units most of the units belong to, e.g., imperial for cups and oz.
+//Units that are common in all systems and unknown units are ignored.
+//The returned System is empty if none of the units belongs to a system or if both systems are used equally often.
+func Detect(names []string) System {
+	counts := make(map[System]int)
+	for _, name := range names {
+		if u, ok := knownUnits[Normalize(name)]; ok {
+			counts[u.system]++
+		}
+	}
+
+	switch {
+	case counts[Metric] > counts[Imperial]:
+		return Metric
+	case counts[Imperial] > counts[Metric]:
+		return Imperial
+	default:
+		return anySystem
+	}
+}
+
+//Convert an amount of a unit to the given system of units.
+//The most readable unit of the target system is chosen, e.g., 1.5 kg instead of 1500 g.
+//Amounts of units that are common in all systems (tsp, tbsp) or already in the target system are returned unchanged.
+//Amounts are returned unchanged for ByWeight as well, since weighing needs the ingredient, see ConvertToWeight.
+//ErrUnknownUnit is returned for units that cannot be converted.
+func Convert(amount float64, name string, system System) (float64, string, error) {
+	canonical := Normalize(name)
+	from, ok := knownUnits[canonical]
+	if !ok {
+		return amount, name, ErrUnknownUnit
+	}
+	if from.system == anySystem || from.system == system || system == ByWeight {
+		return amount, canonical, nil
+	}
+
+	base := amount * from.factor
+	to := targetUnit(base, from.dimension, system)
+
+	return base / knownUnits[to].factor, to, nil
+}
+
+//targetUnit selects the most readable unit for an amount (in the base unit) of a dimension in a system
+func targetUnit(base float64, dimension Dimension, system System) string {
+	switch {
+	case system == Metric && dimension == Volume:
+		return largestUnit(base, Liter, Milliliter)
+	case system == Metric && dimension == Weight:
+		return largestUnit(base, Kilogram, Gram)
+	case system == Imperial && dimension == Volume:
+		return largestUnit(base, Cup, Tablespoon, Teaspoon)
+	default:
+		return largestUnit(base, Pound, Ounce)
+	}
+}
+
+//largestUnit returns the first (largest) unit that results in an amount of at least 1 or, if none exists, the last (smallest) unit
+func largestUnit(base float64, candidates ...string) string {
+	for _, candidate := range candidates {
+		if base >= knownUnits[candidate].factor {
+			return candidate
+		}
+	}
+	return candidates[len(candidates)-1]
+}
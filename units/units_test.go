@@ -0,0 +1,128 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package units
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("units", func() {
+
+	Context("normalization", func() {
+		It("maps aliases to their canonical form", func() {
+			Expect(Normalize("tablespoon")).To(Equal(Tablespoon))
+			Expect(Normalize("Tbsp.")).To(Equal(Tablespoon))
+			Expect(Normalize(" Grams ")).To(Equal(Gram))
+		})
+
+		It("keeps unknown units", func() {
+			Expect(Normalize(" Prise ")).To(Equal("Prise"))
+		})
+
+		It("knows units and their aliases", func() {
+			Expect(Known("g")).To(BeTrue())
+			Expect(Known("Tablespoons")).To(BeTrue())
+			Expect(Known("Prise")).To(BeFalse())
+		})
+	})
+
+	Context("systems", func() {
+		It("can be parsed", func() {
+			s, err := ParseSystem("Imperial")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(s).To(Equal(Imperial))
+		})
+
+		It("cannot be parsed when unknown", func() {
+			_, err := ParseSystem("nautical")
+			Expect(err).To(Equal(ErrUnknownSystem))
+		})
+	})
+
+	Context("conversion", func() {
+		It("converts weights to metric units", func() {
+			amount, unit, err := Convert(2, "lbs", Metric)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(unit).To(Equal(Gram))
+			Expect(amount).To(BeNumerically("~", 907.18, 0.01))
+		})
+
+		It("converts volumes to imperial units", func() {
+			amount, unit, err := Convert(0.5, "l", Imperial)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(unit).To(Equal(Cup))
+			Expect(amount).To(BeNumerically("~", 2.11, 0.01))
+		})
+
+		It("chooses a readable unit", func() {
+			amount, unit, err := Convert(64, "oz", Metric)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(unit).To(Equal(Kilogram))
+			Expect(amount).To(BeNumerically("~", 1.81, 0.01))
+		})
+
+		It("leaves units that are common in all systems unchanged", func() {
+			amount, unit, err := Convert(2, "Tbsp", Metric)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(unit).To(Equal(Tablespoon))
+			Expect(amount).To(Equal(2.0))
+		})
+
+		It("leaves unknown units unchanged", func() {
+			amount, unit, err := Convert(2, "pinch", Metric)
+			Expect(err).To(Equal(ErrUnknownUnit))
+			Expect(unit).To(Equal("pinch"))
+			Expect(amount).To(Equal(2.0))
+		})
+	})
+
+	Context("detection", func() {
+		It("detects the system most units belong to", func() {
+			Expect(Detect([]string{"cups", "oz", "g", "tbsp"})).To(Equal(Imperial))
+			Expect(Detect([]string{"g", "ml", "tsp", "pinch"})).To(Equal(Metric))
+		})
+
+		It("detects no system without units of a system or with a tie", func() {
+			Expect(Detect([]string{"tbsp", "pinch", ""})).To(BeEmpty())
+			Expect(Detect([]string{"cup", "ml"})).To(BeEmpty())
+		})
+	})
+
+	Context("temperatures", func() {
+		It("converts Fahrenheit to Celsius for the metric system", func() {
+			Expect(ConvertTemperatures("Bake at 350°F for 20 minutes, then at 425 °F.", Metric)).
+				To(Equal("Bake at 175°C for 20 minutes, then at 220°C."))
+		})
+
+		It("converts Celsius to Fahrenheit for the imperial system", func() {
+			Expect(ConvertTemperatures("Preheat the oven to 200°C", Imperial)).To(Equal("Preheat the oven to 390°F"))
+		})
+
+		It("leaves temperatures of the target system and other numbers unchanged", func() {
+			Expect(ConvertTemperatures("Bake 2 trays at 180°C", Metric)).To(Equal("Bake 2 trays at 180°C"))
+		})
+	})
+})
@@ -28,12 +28,16 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 	"strings"
+	"time"
 )
 
 // RecipeConfig allows the recipe application to retrieve configuration data
 type RecipeConfig interface {
 	GetInt64(key string) int64
+	GetFloat64(key string) float64
+	GetDuration(key string) time.Duration
 	GetString(key string) string
+	GetBool(key string) bool
 	SetDefault(key string, val interface{})
 	BindEnv(key string)
 	Debug()
@@ -70,6 +74,21 @@ func (*viperConfig) GetInt64(key string) int64 {
 	return viper.GetInt64(key)
 }
 
+// GetFloat64 returns a floating point number for the given key
+func (*viperConfig) GetFloat64(key string) float64 {
+	return viper.GetFloat64(key)
+}
+
+// GetDuration returns a duration for the given key, i.e., values like "15s"
+func (*viperConfig) GetDuration(key string) time.Duration {
+	return viper.GetDuration(key)
+}
+
+// GetBool returns a boolean for the given key
+func (*viperConfig) GetBool(key string) bool {
+	return viper.GetBool(key)
+}
+
 // SetDefault sets the default value for a key
 func (*viperConfig) SetDefault(key string, val interface{}) {
 	viper.SetDefault(key, val)
@@ -25,6 +25,8 @@
 package utils
 
 import (
+	"time"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
@@ -50,6 +52,24 @@ var _ = Describe("Config", func() {
 			Expect(i).To(Equal(int64(123)))
 		})
 
+		It("can read floating point values from files with arbitrary name and path", func() {
+			c := NewViperConfig("test-config", []string{"fixtures"})
+			f := c.GetFloat64("float")
+			Expect(f).To(Equal(0.5))
+		})
+
+		It("can read duration values from files with arbitrary name and path", func() {
+			c := NewViperConfig("test-config", []string{"fixtures"})
+			d := c.GetDuration("duration")
+			Expect(d).To(Equal(90 * time.Second))
+		})
+
+		It("can read boolean values from files with arbitrary name and path", func() {
+			c := NewViperConfig("test-config", []string{"fixtures"})
+			b := c.GetBool("bool")
+			Expect(b).To(BeTrue())
+		})
+
 		It("can handle string default values", func() {
 			const expected = "default"
 			const testKey = "default-str"
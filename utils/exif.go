@@ -0,0 +1,194 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2020 Beate Ottenwälder
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/jpeg"
+)
+
+const (
+	jpegMarkerSOI  = 0xD8
+	jpegMarkerEOI  = 0xD9
+	jpegMarkerSOS  = 0xDA
+	jpegMarkerAPP1 = 0xE1
+	// exifOrientationTag identifies the orientation in the first image file directory of the EXIF metadata
+	exifOrientationTag = 0x0112
+)
+
+var exifHeader = []byte("Exif\x00\x00")
+
+//ErrInvalidJPEG indicates a jpeg image whose segments cannot be read
+var ErrInvalidJPEG = errors.New("invalid jpeg image")
+
+//UprightJPEG rotates and mirrors a jpeg image according to its EXIF orientation and removes the EXIF and XMP metadata of the image.
+//Images which are upright already are not re-encoded. Images which are no jpeg images are returned as they are.
+//Images which need to be rotated and have more than MaxImagePixels pixels are rejected with ErrImageTooLarge.
+func UprightJPEG(img []byte) ([]byte, error) {
+	if len(img) < 2 || img[0] != 0xFF || img[1] != jpegMarkerSOI {
+		return img, nil
+	}
+
+	stripped, orientation, err := stripJPEGMetadata(img)
+	if err != nil {
+		return nil, err
+	}
+	if orientation < 2 || orientation > 8 {
+		return stripped, nil
+	}
+
+	config, err := jpeg.DecodeConfig(bytes.NewReader(stripped))
+	if err != nil {
+		return nil, err
+	}
+	if err = checkPixels(config); err != nil {
+		return nil, err
+	}
+	src, err := jpeg.Decode(bytes.NewReader(stripped))
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	err = jpeg.Encode(&buf, orient(src, orientation), &jpeg.Options{Quality: 90})
+	return buf.Bytes(), err
+}
+
+//stripJPEGMetadata removes all APP1 segments, i.e., EXIF and XMP metadata, from a jpeg image and returns the EXIF orientation of the image
+func stripJPEGMetadata(img []byte) ([]byte, int, error) {
+	result := make([]byte, 0, len(img))
+	result = append(result, img[:2]...)
+	orientation := 0
+
+	pos := 2
+	for pos < len(img) {
+		if img[pos] != 0xFF || pos+1 >= len(img) {
+			return nil, 0, ErrInvalidJPEG
+		}
+		marker := img[pos+1]
+		switch {
+		case marker == 0xFF:
+			// fill byte before a marker
+			pos++
+			continue
+		case marker == jpegMarkerSOS || marker == jpegMarkerEOI:
+			// the compressed image data follows, it does not contain any further metadata
+			return append(result, img[pos:]...), orientation, nil
+		case marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7):
+			// markers without a segment
+			result = append(result, img[pos:pos+2]...)
+			pos += 2
+			continue
+		}
+
+		if pos+4 > len(img) {
+			return nil, 0, ErrInvalidJPEG
+		}
+		end := pos + 2 + int(binary.BigEndian.Uint16(img[pos+2:]))
+		if end < pos+4 || end > len(img) {
+			return nil, 0, ErrInvalidJPEG
+		}
+
+		if marker != jpegMarkerAPP1 {
+			result = append(result, img[pos:end]...)
+		} else if payload := img[pos+4 : end]; bytes.HasPrefix(payload, exifHeader) {
+			orientation = exifOrientation(payload[len(exifHeader):])
+		}
+		pos = end
+	}
+	return result, orientation, nil
+}
+
+//exifOrientation reads the orientation from the TIFF structure of EXIF metadata; 0 is returned if there is no orientation
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 0
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+
+	ifd := int64(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > int64(len(tiff)) {
+		return 0
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < entries; i++ {
+		entry := int(ifd) + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		if order.Uint16(tiff[entry:]) == exifOrientationTag {
+			return int(order.Uint16(tiff[entry+8:]))
+		}
+	}
+	return 0
+}
+
+//orient transforms an image with the given EXIF orientation, so that it is upright
+func orient(src image.Image, orientation int) image.Image {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if orientation >= 5 {
+		// orientations 5 to 8 swap width and height
+		width, height = height, width
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // mirrored horizontally
+				sx, sy = width-1-x, y
+			case 3: // rotated by 180°
+				sx, sy = width-1-x, height-1-y
+			case 4: // mirrored vertically
+				sx, sy = x, height-1-y
+			case 5: // mirrored along the top-left to bottom-right diagonal
+				sx, sy = y, x
+			case 6: // rotated by 90° counterclockwise
+				sx, sy = y, width-1-x
+			case 7: // mirrored along the top-right to bottom-left diagonal
+				sx, sy = height-1-y, width-1-x
+			case 8: // rotated by 90° clockwise
+				sx, sy = height-1-y, x
+			default:
+				sx, sy = x, y
+			}
+			dst.Set(x, y, src.At(bounds.Min.X+sx, bounds.Min.Y+sy))
+		}
+	}
+	return dst
+}
@@ -25,14 +25,29 @@
 package utils
 
 import (
+	"bytes"
 	"encoding/base64"
+	"errors"
+	"fmt"
 	log "github.com/sirupsen/logrus"
+	"image"
+	"image/color"
+	_ "image/gif" // decode gif images
+	"image/jpeg"
+	"image/png"
 	"io/ioutil"
 	"net/http"
+	"strings"
 )
 
 const metaData = "data:image/jpeg;base64,"
 
+// MaxImagePixels limits the number of pixels of images which are decoded, since decoding allocates memory for each pixel
+const MaxImagePixels = 64 * 1000 * 1000
+
+// ErrImageTooLarge indicates an image with more than MaxImagePixels pixels
+var ErrImageTooLarge = errors.New("image too large")
+
 // DownloadIMGAsBase64 will download an image from an url. It returns a base64 encoded image.
 func DownloadIMGAsBase64(url string) (base64img string, err error) {
 
@@ -57,6 +72,21 @@ func DownloadIMGAsBase64(url string) (base64img string, err error) {
 	return base64img, err
 }
 
+// Base64ToIMG decodes a base64 encoded image. A leading data url prefix, i.e., data:image/jpeg;base64, is ignored.
+func Base64ToIMG(base64img string) ([]byte, error) {
+	if strings.HasPrefix(base64img, "data:") {
+		if idx := strings.Index(base64img, ","); idx >= 0 {
+			base64img = base64img[idx+1:]
+		}
+	}
+	return base64.StdEncoding.DecodeString(base64img)
+}
+
+// IMGToBase64 encodes an image with the given content type, i.e., image/png, as base64 data url.
+func IMGToBase64(contentType string, img []byte) string {
+	return fmt.Sprintf("data:%v;base64,%v", contentType, base64.StdEncoding.EncodeToString(img))
+}
+
 // IMGFileToBase64 reads an image from a file at given path, i.e., /home/user/test.jpeg. This image is returned as base64 encoded string.
 func IMGFileToBase64(path string) string {
 	buf, err := ioutil.ReadFile(path)
@@ -73,3 +103,92 @@ func IMGFileToBase64(path string) string {
 
 	return base64img
 }
+
+// Thumbnail scales a jpeg, png, or gif image down, so that its longer side is at most size pixels; the aspect ratio is preserved.
+// Images which are small enough are returned as they are, images with more than MaxImagePixels pixels are rejected. Thumbnails of jpeg images are jpeg images, all other thumbnails are png images.
+func Thumbnail(img []byte, size int) ([]byte, error) {
+	thumb, _, err := fitInto(img, size, 85)
+	return thumb, err
+}
+
+// Downscale scales a jpeg, png, or gif image down, so that neither side is longer than maxDimension pixels; the aspect ratio is preserved.
+// Images which are small enough are returned as they are, images with more than MaxImagePixels pixels are rejected. Downscaled jpeg images remain jpeg images, all others become png images,
+// i.e., only the first frame of animated gif images is kept. The dimensions of the returned image are returned as well.
+func Downscale(img []byte, maxDimension int) ([]byte, image.Point, error) {
+	return fitInto(img, maxDimension, 90)
+}
+
+// fitInto scales an image down, so that its longer side is at most size pixels, and encodes jpeg images with the given quality
+func fitInto(img []byte, size int, quality int) ([]byte, image.Point, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(img))
+	if err != nil {
+		return nil, image.Point{}, err
+	}
+	if err = checkPixels(config); err != nil {
+		return nil, image.Point{}, err
+	}
+	width, height := config.Width, config.Height
+	if size <= 0 || (width <= size && height <= size) {
+		return img, image.Pt(width, height), nil
+	}
+
+	src, format, err := image.Decode(bytes.NewReader(img))
+	if err != nil {
+		return nil, image.Point{}, err
+	}
+
+	scaledWidth, scaledHeight := size, size
+	if width >= height {
+		scaledHeight = maxInt(1, height*size/width)
+	} else {
+		scaledWidth = maxInt(1, width*size/height)
+	}
+	scaled := scaleDown(src, scaledWidth, scaledHeight)
+
+	var buf bytes.Buffer
+	if format == "jpeg" {
+		err = jpeg.Encode(&buf, scaled, &jpeg.Options{Quality: quality})
+	} else {
+		err = png.Encode(&buf, scaled)
+	}
+	return buf.Bytes(), image.Pt(scaledWidth, scaledHeight), err
+}
+
+// checkPixels rejects images with more than MaxImagePixels pixels before they are decoded
+func checkPixels(config image.Config) error {
+	if int64(config.Width)*int64(config.Height) > MaxImagePixels {
+		return ErrImageTooLarge
+	}
+	return nil
+}
+
+// scaleDown averages the colors of all pixels of src that are covered by a pixel of the scaled image
+func scaleDown(src image.Image, width int, height int) image.Image {
+	bounds := src.Bounds()
+	dst := image.NewRGBA64(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0 := bounds.Min.Y + y*bounds.Dy()/height
+		y1 := bounds.Min.Y + (y+1)*bounds.Dy()/height
+		for x := 0; x < width; x++ {
+			x0 := bounds.Min.X + x*bounds.Dx()/width
+			x1 := bounds.Min.X + (x+1)*bounds.Dx()/width
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r, g, b, a, n = r+uint64(cr), g+uint64(cg), b+uint64(cb), a+uint64(ca), n+1
+				}
+			}
+			dst.SetRGBA64(x, y, color.RGBA64{R: uint16(r / n), G: uint16(g / n), B: uint16(b / n), A: uint16(a / n)})
+		}
+	}
+	return dst
+}
+
+func maxInt(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -25,6 +25,15 @@
 package utils
 
 import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
@@ -32,8 +41,193 @@ import (
 var _ = Describe("Utils", func() {
 	Context("CBytes", func() {
 		It("should transform a byte array to an comma separated string", func() {
-			bytes := []byte{100, 200, 50}
-			Expect(CBytes(bytes)).To(Equal("[100,200,50]"))
+			input := []byte{100, 200, 50}
+			Expect(CBytes(input)).To(Equal("[100,200,50]"))
+		})
+	})
+
+	Context("IMGToBase64", func() {
+		It("should encode an image as data url", func() {
+			Expect(IMGToBase64("image/png", []byte("png"))).To(Equal("data:image/png;base64,cG5n"))
 		})
 	})
+
+	Context("Base64ToIMG", func() {
+		It("should decode a data url", func() {
+			img, err := Base64ToIMG("data:image/png;base64,cG5n")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(img).To(Equal([]byte("png")))
+		})
+
+		It("should decode plain base64", func() {
+			img, err := Base64ToIMG("cG5n")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(img).To(Equal([]byte("png")))
+		})
+	})
+
+	Context("Thumbnail", func() {
+		decode := func(img []byte) (image.Image, string) {
+			decoded, format, err := image.Decode(bytes.NewReader(img))
+			Expect(err).ToNot(HaveOccurred())
+			return decoded, format
+		}
+
+		It("should scale the longer side down and preserve the aspect ratio", func() {
+			thumb, err := Thumbnail(testImage(png.Encode, 400, 100), 200)
+			Expect(err).ToNot(HaveOccurred())
+
+			decoded, format := decode(thumb)
+			Expect(format).To(Equal("png"))
+			Expect(decoded.Bounds().Dx()).To(Equal(200))
+			Expect(decoded.Bounds().Dy()).To(Equal(50))
+			Expect(color.RGBAModel.Convert(decoded.At(10, 10))).To(Equal(color.RGBA{R: 200, G: 100, B: 50, A: 255}))
+		})
+
+		It("should keep the format of jpeg images", func() {
+			jpegEncode := func(w io.Writer, m image.Image) error { return jpeg.Encode(w, m, nil) }
+			thumb, err := Thumbnail(testImage(jpegEncode, 100, 300), 30)
+			Expect(err).ToNot(HaveOccurred())
+
+			decoded, format := decode(thumb)
+			Expect(format).To(Equal("jpeg"))
+			Expect(decoded.Bounds().Dx()).To(Equal(10))
+			Expect(decoded.Bounds().Dy()).To(Equal(30))
+		})
+
+		It("should return small images as they are", func() {
+			img := testImage(png.Encode, 20, 10)
+			Expect(Thumbnail(img, 256)).To(Equal(img))
+		})
+
+		It("should reject data which is no image", func() {
+			_, err := Thumbnail([]byte("png"), 256)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should reject images with too many pixels before decoding them", func() {
+			_, err := Thumbnail(pngOfSize(100000, 100000), 256)
+			Expect(err).To(Equal(ErrImageTooLarge))
+		})
+	})
+
+	Context("Downscale", func() {
+		It("should scale images down to the maximal dimension", func() {
+			scaled, size, err := Downscale(testImage(png.Encode, 100, 400), 200)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(size).To(Equal(image.Pt(50, 200)))
+
+			decoded, _, err := image.Decode(bytes.NewReader(scaled))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(decoded.Bounds().Size()).To(Equal(size))
+		})
+
+		It("should return images within the bounds as they are", func() {
+			img := testImage(png.Encode, 200, 100)
+			scaled, size, err := Downscale(img, 200)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(scaled).To(Equal(img))
+			Expect(size).To(Equal(image.Pt(200, 100)))
+		})
+
+		It("should reject data which is no image", func() {
+			_, _, err := Downscale([]byte("png"), 2048)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should reject images with too many pixels before decoding them", func() {
+			_, _, err := Downscale(pngOfSize(100000, 100000), 2048)
+			Expect(err).To(Equal(ErrImageTooLarge))
+		})
+	})
+
+	Context("UprightJPEG", func() {
+		// halves creates a jpeg image with a red left half and a blue right half
+		halves := func(width int, height int) []byte {
+			img := image.NewRGBA(image.Rect(0, 0, width, height))
+			for y := 0; y < height; y++ {
+				for x := 0; x < width; x++ {
+					if x < width/2 {
+						img.Set(x, y, color.RGBA{R: 255, A: 255})
+					} else {
+						img.Set(x, y, color.RGBA{B: 255, A: 255})
+					}
+				}
+			}
+			var buf bytes.Buffer
+			_ = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 100})
+			return buf.Bytes()
+		}
+
+		It("should rotate images upright", func() {
+			upright, err := UprightJPEG(withExifOrientation(halves(32, 16), 6))
+			Expect(err).ToNot(HaveOccurred())
+
+			img, err := jpeg.Decode(bytes.NewReader(upright))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(img.Bounds().Dx()).To(Equal(16))
+			Expect(img.Bounds().Dy()).To(Equal(32))
+			r, _, b, _ := img.At(8, 4).RGBA()
+			Expect(r).To(BeNumerically(">", b))
+			r, _, b, _ = img.At(8, 28).RGBA()
+			Expect(b).To(BeNumerically(">", r))
+		})
+
+		It("should remove the EXIF metadata without re-encoding upright images", func() {
+			original := halves(32, 16)
+			upright, err := UprightJPEG(withExifOrientation(original, 1))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(upright).To(Equal(original))
+		})
+
+		It("should return other images as they are", func() {
+			img := testImage(png.Encode, 20, 10)
+			Expect(UprightJPEG(img)).To(Equal(img))
+		})
+
+		It("should reject broken jpeg images", func() {
+			_, err := UprightJPEG([]byte("\xFF\xD8\xFF\xE1\x00"))
+			Expect(err).To(Equal(ErrInvalidJPEG))
+		})
+	})
+
 })
+
+// testImage encodes an image of a single color
+func testImage(encode func(w io.Writer, m image.Image) error, width int, height int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: 200, G: 100, B: 50, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	_ = encode(&buf, img)
+	return buf.Bytes()
+}
+
+// pngOfSize creates a png image which consists of its header only, which suffices to read the dimensions of the image
+func pngOfSize(width uint32, height uint32) []byte {
+	ihdr := []byte("IHDR\x00\x00\x00\x00\x00\x00\x00\x00\x08\x06\x00\x00\x00")
+	binary.BigEndian.PutUint32(ihdr[4:], width)
+	binary.BigEndian.PutUint32(ihdr[8:], height)
+	var buf bytes.Buffer
+	buf.WriteString("\x89PNG\x0D\x0A\x1A\x0A")
+	_ = binary.Write(&buf, binary.BigEndian, uint32(len(ihdr)-4))
+	buf.Write(ihdr)
+	_ = binary.Write(&buf, binary.BigEndian, crc32.ChecksumIEEE(ihdr))
+	return buf.Bytes()
+}
+
+// withExifOrientation adds EXIF metadata with the given orientation to a jpeg image
+func withExifOrientation(img []byte, orientation uint16) []byte {
+	tiff := []byte{'M', 'M', 0x00, 0x2A, 0x00, 0x00, 0x00, 0x08, 0x00, 0x01,
+		0x01, 0x12, 0x00, 0x03, 0x00, 0x00, 0x00, 0x01, byte(orientation >> 8), byte(orientation), 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00}
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := append([]byte{0xFF, 0xE1, byte((len(payload) + 2) >> 8), byte(len(payload) + 2)}, payload...)
+
+	result := append([]byte{}, img[:2]...)
+	result = append(result, segment...)
+	return append(result, img[2:]...)
+}